@@ -0,0 +1,390 @@
+package api
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/satori/go.uuid"
+)
+
+const (
+	// Random bytes of API key secrets
+	API_KEY_SECRET_SIZE = 32
+	// Separator between identifier and secret in API keys
+	API_KEY_SEPARATOR = "."
+)
+
+// TYPE DEFINITIONS
+
+// API key of a user, used by service accounts to authenticate without OIDC tokens. Only the hash of
+// its secret is stored. External id of the user is stored to authenticate it without retrieving the user.
+type APIKey struct {
+	ID         string     `json:"id, omitempty"`
+	Name       string     `json:"name, omitempty"`
+	UserID     string     `json:"userId, omitempty"`
+	ExternalID string     `json:"externalId, omitempty"`
+	Hash       string     `json:"-"`
+	CreateAt   time.Time  `json:"createAt, omitempty"`
+	RotateAt   time.Time  `json:"rotateAt, omitempty"`
+	ExpireAt   *time.Time `json:"expireAt, omitempty"`
+	RevokeAt   *time.Time `json:"revokeAt, omitempty"`
+}
+
+func (k APIKey) String() string {
+	return fmt.Sprintf("[id: %v, name: %v, userId: %v, externalId: %v, createAt: %v, rotateAt: %v, expireAt: %v, revokeAt: %v]",
+		k.ID, k.Name, k.UserID, k.ExternalID, k.CreateAt.Format("2006-01-02 15:04:05 MST"),
+		k.RotateAt.Format("2006-01-02 15:04:05 MST"), formatOptionalTime(k.ExpireAt), formatOptionalTime(k.RevokeAt))
+}
+
+// API key with its secret key, only returned when the key is created or rotated
+type APIKeySecret struct {
+	APIKey
+	Key string `json:"key, omitempty"`
+}
+
+// API KEY API IMPLEMENTATION
+
+func (api AuthAPI) CreateAPIKey(requestInfo RequestInfo, externalID string, name string, expireAt *time.Time) (*APIKeySecret, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if err := validateExpireAt(expireAt); err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.getUserAuthorized(requestInfo, externalID, USER_ACTION_CREATE_API_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, hash, err := createAPIKeySecret()
+	if err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+	now := time.Now().UTC()
+	apiKey := APIKey{
+		ID:         uuid.NewV4().String(),
+		Name:       name,
+		UserID:     user.ID,
+		ExternalID: user.ExternalID,
+		Hash:       hash,
+		CreateAt:   now,
+		RotateAt:   now,
+		ExpireAt:   expireAt,
+	}
+
+	createdKey, err := api.APIKeyRepo.AddAPIKey(apiKey)
+
+	// Check unexpected DB error
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("API key created %+v", createdKey))
+	api.recordAuditEvent(requestInfo, USER_ACTION_CREATE_API_KEY, user.Urn, nil, createdKey)
+	return &APIKeySecret{
+		APIKey: *createdKey,
+		Key:    createdKey.ID + API_KEY_SEPARATOR + secret,
+	}, nil
+}
+
+func (api AuthAPI) GetAPIKey(requestInfo RequestInfo, externalID string, id string) (*APIKey, error) {
+	// Call repo to retrieve the user
+	user, err := api.getUserAuthorized(requestInfo, externalID, USER_ACTION_GET_API_KEY)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.getAPIKey(user, id)
+}
+
+func (api AuthAPI) ListAPIKeys(requestInfo RequestInfo, externalID string, filter *Filter) ([]APIKey, int, error) {
+	// Check parameters
+	var total int
+	if filter.Limit > MAX_LIMIT_SIZE {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DEFAULT_LIMIT_SIZE
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.getUserAuthorized(requestInfo, externalID, USER_ACTION_LIST_API_KEYS)
+	if err != nil {
+		return nil, total, err
+	}
+
+	// Call repo to retrieve the keys
+	apiKeys, total, err := api.APIKeyRepo.GetAPIKeysByUserID(user.ID, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, total, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return apiKeys, total, nil
+}
+
+func (api AuthAPI) RotateAPIKey(requestInfo RequestInfo, externalID string, id string, expireAt *time.Time) (*APIKeySecret, error) {
+	if err := validateExpireAt(expireAt); err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the user and the key
+	user, err := api.getUserAuthorized(requestInfo, externalID, USER_ACTION_ROTATE_API_KEY)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := api.getAPIKey(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokeAt != nil {
+		return nil, &Error{
+			Code:    API_KEY_REVOKED,
+			Message: fmt.Sprintf("Unable to rotate API key, API key with id %v is revoked", id),
+		}
+	}
+
+	// Previous secret stops working, expiration is kept if a new one isn't received
+	secret, hash, err := createAPIKeySecret()
+	if err != nil {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: err.Error(),
+		}
+	}
+	keyToUpdate := *apiKey
+	keyToUpdate.Hash = hash
+	keyToUpdate.RotateAt = time.Now().UTC()
+	if expireAt != nil {
+		keyToUpdate.ExpireAt = expireAt
+	}
+
+	rotatedKey, err := api.updateAPIKey(keyToUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("API key rotated from %+v to %+v", apiKey, rotatedKey))
+	api.recordAuditEvent(requestInfo, USER_ACTION_ROTATE_API_KEY, user.Urn, apiKey, rotatedKey)
+	return &APIKeySecret{
+		APIKey: *rotatedKey,
+		Key:    rotatedKey.ID + API_KEY_SEPARATOR + secret,
+	}, nil
+}
+
+func (api AuthAPI) RevokeAPIKey(requestInfo RequestInfo, externalID string, id string) (*APIKey, error) {
+	// Call repo to retrieve the user and the key
+	user, err := api.getUserAuthorized(requestInfo, externalID, USER_ACTION_REVOKE_API_KEY)
+	if err != nil {
+		return nil, err
+	}
+	apiKey, err := api.getAPIKey(user, id)
+	if err != nil {
+		return nil, err
+	}
+	if apiKey.RevokeAt != nil {
+		return nil, &Error{
+			Code:    API_KEY_REVOKED,
+			Message: fmt.Sprintf("Unable to revoke API key, API key with id %v is already revoked", id),
+		}
+	}
+
+	// Revoked keys are kept to know when they were revoked
+	now := time.Now().UTC()
+	keyToUpdate := *apiKey
+	keyToUpdate.RevokeAt = &now
+
+	revokedKey, err := api.updateAPIKey(keyToUpdate)
+	if err != nil {
+		return nil, err
+	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("API key revoked %+v", revokedKey))
+	api.recordAuditEvent(requestInfo, USER_ACTION_REVOKE_API_KEY, user.Urn, apiKey, revokedKey)
+	return revokedKey, nil
+}
+
+func (api AuthAPI) AuthenticateAPIKey(key string) (string, error) {
+	// Split identifier and secret
+	parts := strings.SplitN(key, API_KEY_SEPARATOR, 2)
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", &Error{
+			Code:    INVALID_API_KEY,
+			Message: "Invalid API key format",
+		}
+	}
+
+	apiKey, err := api.APIKeyRepo.GetAPIKeyByID(parts[0])
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		if dbError.Code == database.API_KEY_NOT_FOUND {
+			return "", &Error{
+				Code:    INVALID_API_KEY,
+				Message: dbError.Message,
+			}
+		}
+		return "", &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check secret and validity
+	if subtle.ConstantTimeCompare([]byte(hashAPIKeySecret(parts[1])), []byte(apiKey.Hash)) != 1 {
+		return "", &Error{
+			Code:    INVALID_API_KEY,
+			Message: fmt.Sprintf("Invalid secret for API key with id %v", apiKey.ID),
+		}
+	}
+	if apiKey.RevokeAt != nil {
+		return "", &Error{
+			Code:    INVALID_API_KEY,
+			Message: fmt.Sprintf("API key with id %v is revoked", apiKey.ID),
+		}
+	}
+	if apiKey.ExpireAt != nil && !time.Now().Before(*apiKey.ExpireAt) {
+		return "", &Error{
+			Code:    INVALID_API_KEY,
+			Message: fmt.Sprintf("API key with id %v is expired", apiKey.ID),
+		}
+	}
+
+	return apiKey.ExternalID, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve user checking that requestInfo is allowed to do the action over it
+func (api AuthAPI) getUserAuthorized(requestInfo RequestInfo, externalID string, action string) (*User, error) {
+	user, err := api.GetUserByExternalID(requestInfo, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, action, []User{*user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	return user, nil
+}
+
+// Retrieve API key of the user, keys of other users aren't found
+func (api AuthAPI) getAPIKey(user *User, id string) (*APIKey, error) {
+	apiKey, err := api.APIKeyRepo.GetAPIKeyByID(id)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		// API key doesn't exist in DB
+		if dbError.Code == database.API_KEY_NOT_FOUND {
+			return nil, &Error{
+				Code:    API_KEY_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		}
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	if apiKey.UserID != user.ID {
+		return nil, &Error{
+			Code:    API_KEY_NOT_FOUND,
+			Message: fmt.Sprintf("API key with id %v not found", id),
+		}
+	}
+
+	return apiKey, nil
+}
+
+func (api AuthAPI) updateAPIKey(apiKey APIKey) (*APIKey, error) {
+	updatedKey, err := api.APIKeyRepo.UpdateAPIKey(apiKey)
+
+	// Check unexpected DB error
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return updatedKey, nil
+}
+
+// Expiration date is optional, but it must be in the future
+func validateExpireAt(expireAt *time.Time) error {
+	if expireAt != nil && !expireAt.After(time.Now()) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: expireAt %v must be in the future", expireAt.Format(time.RFC3339)),
+		}
+	}
+	return nil
+}
+
+// Create a random secret and its hash. Secrets have enough entropy to store a SHA-256 hash without salt.
+func createAPIKeySecret() (string, string, error) {
+	data := make([]byte, API_KEY_SECRET_SIZE)
+	if _, err := rand.Read(data); err != nil {
+		return "", "", err
+	}
+	secret := hex.EncodeToString(data)
+	return secret, hashAPIKeySecret(secret), nil
+}
+
+func hashAPIKeySecret(secret string) string {
+	hash := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(hash[:])
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format("2006-01-02 15:04:05 MST")
+}
@@ -0,0 +1,594 @@
+package api
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestAuthAPI_CreateAPIKey(t *testing.T) {
+	future := time.Now().UTC().Add(time.Hour)
+	past := time.Now().UTC().Add(-time.Hour)
+	testUser := &User{
+		ID:         "USER-ID",
+		ExternalID: "service",
+		Path:       "/service-accounts/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/service-accounts/", "service"),
+	}
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		externalID  string
+		name        string
+		expireAt    *time.Time
+
+		wantError error
+
+		getUserByExternalIDResult *User
+		getUserByExternalIDErr    error
+		addAPIKeyMethodErr        error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:                "service",
+			name:                      "deploy",
+			getUserByExternalIDResult: testUser,
+		},
+		"OKCaseWithExpiration": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:                "service",
+			name:                      "deploy",
+			expireAt:                  &future,
+			getUserByExternalIDResult: testUser,
+		},
+		"ErrorCaseInvalidName": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "service",
+			name:       "*%~#@|",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: name *%~#@|",
+			},
+		},
+		"ErrorCaseExpired": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "service",
+			name:       "deploy",
+			expireAt:   &past,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: expireAt " + past.Format(time.RFC3339) + " must be in the future",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "service",
+			name:       "deploy",
+			wantError: &Error{
+				Code: USER_BY_EXTERNAL_ID_NOT_FOUND,
+			},
+			getUserByExternalIDErr: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+		"ErrorCaseNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			externalID:                "service",
+			name:                      "deploy",
+			getUserByExternalIDResult: testUser,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam::user/service-accounts/service",
+			},
+		},
+		"ErrorCaseAddAPIKeyDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID:                "service",
+			name:                      "deploy",
+			getUserByExternalIDResult: testUser,
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			addAPIKeyMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDErr
+		testRepo.SpecialFuncs[AddAPIKeyMethod] = func(apiKey APIKey) (*APIKey, error) {
+			if testcase.addAPIKeyMethodErr != nil {
+				return nil, testcase.addAPIKeyMethodErr
+			}
+			return &apiKey, nil
+		}
+		apiKey, err := testAPI.CreateAPIKey(testcase.requestInfo, testcase.externalID, testcase.name, testcase.expireAt)
+		if testcase.wantError != nil {
+			checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", x, err)
+			continue
+		}
+
+		// Key has the identifier and the secret, and only the hash of the secret is stored
+		stored := testRepo.ArgsIn[AddAPIKeyMethod][0].(APIKey)
+		parts := strings.SplitN(apiKey.Key, API_KEY_SEPARATOR, 2)
+		if len(parts) != 2 || parts[0] != stored.ID || hashAPIKeySecret(parts[1]) != stored.Hash {
+			t.Errorf("Test %v failed. Received key %v doesn't match stored key %v", x, apiKey.Key, stored)
+			continue
+		}
+		expected := APIKey{
+			ID:         stored.ID,
+			Name:       testcase.name,
+			UserID:     testUser.ID,
+			ExternalID: testUser.ExternalID,
+			Hash:       stored.Hash,
+			CreateAt:   stored.CreateAt,
+			RotateAt:   stored.CreateAt,
+			ExpireAt:   testcase.expireAt,
+		}
+		if diff := pretty.Compare(apiKey.APIKey, expected); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", x, diff)
+		}
+	}
+}
+
+func TestAuthAPI_GetAPIKey(t *testing.T) {
+	testUser := &User{
+		ID:         "USER-ID",
+		ExternalID: "service",
+		Path:       "/service-accounts/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/service-accounts/", "service"),
+	}
+	testAPIKey := &APIKey{
+		ID:         "KEY-ID",
+		Name:       "deploy",
+		UserID:     "USER-ID",
+		ExternalID: "service",
+	}
+	testcases := map[string]struct {
+		id string
+
+		expectedResponse *APIKey
+		wantError        error
+
+		getAPIKeyByIDResult *APIKey
+		getAPIKeyByIDErr    error
+	}{
+		"OKCase": {
+			id:                  "KEY-ID",
+			expectedResponse:    testAPIKey,
+			getAPIKeyByIDResult: testAPIKey,
+		},
+		"ErrorCaseAPIKeyNotFound": {
+			id: "KEY-ID",
+			wantError: &Error{
+				Code:    API_KEY_NOT_FOUND,
+				Message: "API key with id KEY-ID not found",
+			},
+			getAPIKeyByIDErr: &database.Error{
+				Code:    database.API_KEY_NOT_FOUND,
+				Message: "API key with id KEY-ID not found",
+			},
+		},
+		"ErrorCaseAPIKeyOfOtherUser": {
+			id: "KEY-ID",
+			wantError: &Error{
+				Code:    API_KEY_NOT_FOUND,
+				Message: "API key with id KEY-ID not found",
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:     "KEY-ID",
+				UserID: "OTHER-USER-ID",
+			},
+		},
+		"ErrorCaseGetAPIKeyDBErr": {
+			id: "KEY-ID",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getAPIKeyByIDErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testUser
+		testRepo.ArgsOut[GetAPIKeyByIDMethod][0] = testcase.getAPIKeyByIDResult
+		testRepo.ArgsOut[GetAPIKeyByIDMethod][1] = testcase.getAPIKeyByIDErr
+		apiKey, err := testAPI.GetAPIKey(RequestInfo{Identifier: "123456", Admin: true}, "service", testcase.id)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, apiKey)
+	}
+}
+
+func TestAuthAPI_ListAPIKeys(t *testing.T) {
+	testUser := &User{
+		ID:         "USER-ID",
+		ExternalID: "service",
+		Path:       "/service-accounts/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/service-accounts/", "service"),
+	}
+	testAPIKeys := []APIKey{
+		{
+			ID:         "KEY-ID",
+			Name:       "deploy",
+			UserID:     "USER-ID",
+			ExternalID: "service",
+		},
+	}
+	testcases := map[string]struct {
+		filter *Filter
+
+		expectedResponse []APIKey
+		expectedLimit    int
+		totalResult      int
+		wantError        error
+
+		getAPIKeysByUserIDResult []APIKey
+		getAPIKeysByUserIDErr    error
+	}{
+		"OKCase": {
+			filter:                   &Filter{},
+			expectedResponse:         testAPIKeys,
+			expectedLimit:            DEFAULT_LIMIT_SIZE,
+			totalResult:              1,
+			getAPIKeysByUserIDResult: testAPIKeys,
+		},
+		"ErrorCaseMaxLimitSize": {
+			filter: &Filter{
+				Limit: 10000,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 10000, max limit allowed: 1000",
+			},
+		},
+		"ErrorCaseGetAPIKeysDBErr": {
+			filter: &Filter{},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getAPIKeysByUserIDErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testUser
+		testRepo.ArgsOut[GetAPIKeysByUserIDMethod][0] = testcase.getAPIKeysByUserIDResult
+		testRepo.ArgsOut[GetAPIKeysByUserIDMethod][1] = testcase.totalResult
+		testRepo.ArgsOut[GetAPIKeysByUserIDMethod][2] = testcase.getAPIKeysByUserIDErr
+		apiKeys, total, err := testAPI.ListAPIKeys(RequestInfo{Identifier: "123456", Admin: true}, "service", testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, apiKeys)
+		if testcase.wantError == nil {
+			if testcase.totalResult != total {
+				t.Errorf("Test case %v. Received different total (wanted:%v / received:%v)", x, testcase.totalResult, total)
+			}
+			if testcase.filter.Limit != testcase.expectedLimit {
+				t.Errorf("Test case %v. Received different limit (wanted:%v / received:%v)", x, testcase.expectedLimit, testcase.filter.Limit)
+			}
+			if userID := testRepo.ArgsIn[GetAPIKeysByUserIDMethod][0]; userID != testUser.ID {
+				t.Errorf("Test case %v. Received different user id (wanted:%v / received:%v)", x, testUser.ID, userID)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_RotateAPIKey(t *testing.T) {
+	now := time.Now().UTC()
+	expireAt := now.Add(time.Hour)
+	newExpireAt := now.Add(2 * time.Hour)
+	testUser := &User{
+		ID:         "USER-ID",
+		ExternalID: "service",
+		Path:       "/service-accounts/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/service-accounts/", "service"),
+	}
+	testAPIKey := &APIKey{
+		ID:         "KEY-ID",
+		Name:       "deploy",
+		UserID:     "USER-ID",
+		ExternalID: "service",
+		Hash:       "hash",
+		CreateAt:   now,
+		RotateAt:   now,
+		ExpireAt:   &expireAt,
+	}
+	testcases := map[string]struct {
+		expireAt *time.Time
+
+		expectedExpireAt *time.Time
+		wantError        error
+
+		getAPIKeyByIDResult *APIKey
+		updateAPIKeyErr     error
+	}{
+		"OKCaseKeepExpiration": {
+			expectedExpireAt:    &expireAt,
+			getAPIKeyByIDResult: testAPIKey,
+		},
+		"OKCaseNewExpiration": {
+			expireAt:            &newExpireAt,
+			expectedExpireAt:    &newExpireAt,
+			getAPIKeyByIDResult: testAPIKey,
+		},
+		"ErrorCaseRevoked": {
+			wantError: &Error{
+				Code:    API_KEY_REVOKED,
+				Message: "Unable to rotate API key, API key with id KEY-ID is revoked",
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:       "KEY-ID",
+				UserID:   "USER-ID",
+				RevokeAt: &now,
+			},
+		},
+		"ErrorCaseUpdateAPIKeyDBErr": {
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getAPIKeyByIDResult: testAPIKey,
+			updateAPIKeyErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testUser
+		testRepo.ArgsOut[GetAPIKeyByIDMethod][0] = testcase.getAPIKeyByIDResult
+		testRepo.SpecialFuncs[UpdateAPIKeyMethod] = func(apiKey APIKey) (*APIKey, error) {
+			if testcase.updateAPIKeyErr != nil {
+				return nil, testcase.updateAPIKeyErr
+			}
+			return &apiKey, nil
+		}
+		apiKey, err := testAPI.RotateAPIKey(RequestInfo{Identifier: "123456", Admin: true}, "service", "KEY-ID", testcase.expireAt)
+		if testcase.wantError != nil {
+			checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", x, err)
+			continue
+		}
+
+		// Secret is replaced, other fields are kept
+		parts := strings.SplitN(apiKey.Key, API_KEY_SEPARATOR, 2)
+		if len(parts) != 2 || parts[0] != testAPIKey.ID || hashAPIKeySecret(parts[1]) != apiKey.Hash || apiKey.Hash == testAPIKey.Hash {
+			t.Errorf("Test %v failed. Received key %v doesn't match rotated key %v", x, apiKey.Key, apiKey.APIKey)
+			continue
+		}
+		expected := *testAPIKey
+		expected.Hash = apiKey.Hash
+		expected.RotateAt = apiKey.RotateAt
+		expected.ExpireAt = testcase.expectedExpireAt
+		if diff := pretty.Compare(apiKey.APIKey, expected); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", x, diff)
+		}
+		if apiKey.RotateAt.Before(now) {
+			t.Errorf("Test %v failed. Rotation date %v not updated", x, apiKey.RotateAt)
+		}
+	}
+}
+
+func TestAuthAPI_RevokeAPIKey(t *testing.T) {
+	now := time.Now().UTC()
+	testUser := &User{
+		ID:         "USER-ID",
+		ExternalID: "service",
+		Path:       "/service-accounts/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/service-accounts/", "service"),
+	}
+	testcases := map[string]struct {
+		wantError error
+
+		getAPIKeyByIDResult *APIKey
+		updateAPIKeyErr     error
+	}{
+		"OKCase": {
+			getAPIKeyByIDResult: &APIKey{
+				ID:     "KEY-ID",
+				UserID: "USER-ID",
+			},
+		},
+		"ErrorCaseAlreadyRevoked": {
+			wantError: &Error{
+				Code:    API_KEY_REVOKED,
+				Message: "Unable to revoke API key, API key with id KEY-ID is already revoked",
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:       "KEY-ID",
+				UserID:   "USER-ID",
+				RevokeAt: &now,
+			},
+		},
+		"ErrorCaseUpdateAPIKeyDBErr": {
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:     "KEY-ID",
+				UserID: "USER-ID",
+			},
+			updateAPIKeyErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testUser
+		testRepo.ArgsOut[GetAPIKeyByIDMethod][0] = testcase.getAPIKeyByIDResult
+		testRepo.SpecialFuncs[UpdateAPIKeyMethod] = func(apiKey APIKey) (*APIKey, error) {
+			if testcase.updateAPIKeyErr != nil {
+				return nil, testcase.updateAPIKeyErr
+			}
+			return &apiKey, nil
+		}
+		apiKey, err := testAPI.RevokeAPIKey(RequestInfo{Identifier: "123456", Admin: true}, "service", "KEY-ID")
+		if testcase.wantError != nil {
+			checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", x, err)
+			continue
+		}
+		if apiKey.RevokeAt == nil || apiKey.RevokeAt.Before(now) {
+			t.Errorf("Test %v failed. Revocation date %v not set", x, apiKey.RevokeAt)
+		}
+	}
+}
+
+func TestAuthAPI_AuthenticateAPIKey(t *testing.T) {
+	now := time.Now().UTC()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+	secret := "secret"
+	testcases := map[string]struct {
+		key string
+
+		expectedResponse string
+		wantError        error
+
+		getAPIKeyByIDResult *APIKey
+		getAPIKeyByIDErr    error
+	}{
+		"OKCase": {
+			key:              "KEY-ID.secret",
+			expectedResponse: "service",
+			getAPIKeyByIDResult: &APIKey{
+				ID:         "KEY-ID",
+				ExternalID: "service",
+				Hash:       hashAPIKeySecret(secret),
+			},
+		},
+		"OKCaseNotExpired": {
+			key:              "KEY-ID.secret",
+			expectedResponse: "service",
+			getAPIKeyByIDResult: &APIKey{
+				ID:         "KEY-ID",
+				ExternalID: "service",
+				Hash:       hashAPIKeySecret(secret),
+				ExpireAt:   &future,
+			},
+		},
+		"ErrorCaseInvalidFormat": {
+			key: "KEY-ID",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Invalid API key format",
+			},
+		},
+		"ErrorCaseAPIKeyNotFound": {
+			key: "KEY-ID.secret",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "API key with id KEY-ID not found",
+			},
+			getAPIKeyByIDErr: &database.Error{
+				Code:    database.API_KEY_NOT_FOUND,
+				Message: "API key with id KEY-ID not found",
+			},
+		},
+		"ErrorCaseInvalidSecret": {
+			key: "KEY-ID.other",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "Invalid secret for API key with id KEY-ID",
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:   "KEY-ID",
+				Hash: hashAPIKeySecret(secret),
+			},
+		},
+		"ErrorCaseRevoked": {
+			key: "KEY-ID.secret",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "API key with id KEY-ID is revoked",
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:       "KEY-ID",
+				Hash:     hashAPIKeySecret(secret),
+				RevokeAt: &past,
+			},
+		},
+		"ErrorCaseExpired": {
+			key: "KEY-ID.secret",
+			wantError: &Error{
+				Code:    INVALID_API_KEY,
+				Message: "API key with id KEY-ID is expired",
+			},
+			getAPIKeyByIDResult: &APIKey{
+				ID:       "KEY-ID",
+				Hash:     hashAPIKeySecret(secret),
+				ExpireAt: &past,
+			},
+		},
+		"ErrorCaseGetAPIKeyDBErr": {
+			key: "KEY-ID.secret",
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getAPIKeyByIDErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetAPIKeyByIDMethod][0] = testcase.getAPIKeyByIDResult
+		testRepo.ArgsOut[GetAPIKeyByIDMethod][1] = testcase.getAPIKeyByIDErr
+		externalID, err := testAPI.AuthenticateAPIKey(testcase.key)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResponse, externalID)
+	}
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/satori/go.uuid"
+)
+
+// TYPE DEFINITIONS
+
+// Audit event recorded for every successful change done through the API
+type AuditEvent struct {
+	ID        string          `json:"id, omitempty"`
+	Actor     string          `json:"actor, omitempty"`
+	RequestID string          `json:"requestId, omitempty"`
+	Action    string          `json:"action, omitempty"`
+	Urn       string          `json:"urn, omitempty"`
+	Before    json.RawMessage `json:"before, omitempty"`
+	After     json.RawMessage `json:"after, omitempty"`
+	CreateAt  time.Time       `json:"createAt, omitempty"`
+}
+
+func (e AuditEvent) String() string {
+	return fmt.Sprintf("[id: %v, actor: %v, requestId: %v, action: %v, urn: %v, createAt: %v]",
+		e.ID, e.Actor, e.RequestID, e.Action, e.Urn, e.CreateAt.Format("2006-01-02 15:04:05 MST"))
+}
+
+func (e AuditEvent) GetUrn() string {
+	return e.Urn
+}
+
+// Filter properties for audit events search. Empty properties don't filter.
+type AuditFilter struct {
+	Actor     string
+	Action    string
+	UrnPrefix string
+	From      time.Time
+	To        time.Time
+	// Pagination
+	Offset int
+	Limit  int
+}
+
+// Snapshot of a relation between resources, used in audit events
+type auditRelation struct {
+	User   *User   `json:"user,omitempty"`
+	Group  *Group  `json:"group,omitempty"`
+	Policy *Policy `json:"policy,omitempty"`
+}
+
+// AUDIT API IMPLEMENTATION
+
+func (api AuthAPI) ListAuditEvents(requestInfo RequestInfo, filter *AuditFilter) ([]AuditEvent, int, error) {
+	// Check parameters
+	var total int
+	if len(filter.Action) > 0 && !rAction.MatchString(filter.Action) {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Action %v", filter.Action),
+		}
+	}
+
+	if !filter.From.IsZero() && !filter.To.IsZero() && filter.To.Before(filter.From) {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: To %v is before From %v", filter.To.Format(time.RFC3339), filter.From.Format(time.RFC3339)),
+		}
+	}
+
+	if filter.Limit > MAX_LIMIT_SIZE {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DEFAULT_LIMIT_SIZE
+	}
+
+	// Retrieve audit events
+	events, total, err := api.AuditRepo.GetAuditEventsFiltered(filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, total, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions
+	resourcesToAuthorize := []Resource{}
+	for _, e := range events {
+		resourcesToAuthorize = append(resourcesToAuthorize, e)
+	}
+	resources, err := api.getAuthorizedResources(requestInfo, "urn:*", AUDIT_ACTION_LIST_AUDIT_EVENTS, resourcesToAuthorize)
+	if err != nil {
+		return nil, total, err
+	}
+
+	eventsFiltered := []AuditEvent{}
+	for _, res := range resources {
+		eventsFiltered = append(eventsFiltered, res.(AuditEvent))
+	}
+
+	return eventsFiltered, total, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Store audit event for a successful change with snapshots of the resource before and after it.
+// Errors are only logged because the change is already done.
+func (api AuthAPI) recordAuditEvent(requestInfo RequestInfo, action string, urn string, before interface{}, after interface{}) {
+	if api.AuditRepo == nil {
+		return
+	}
+
+	event := AuditEvent{
+		ID:        uuid.NewV4().String(),
+		Actor:     requestInfo.Identifier,
+		RequestID: requestInfo.RequestID,
+		Action:    action,
+		Urn:       urn,
+		Before:    getSnapshot(before),
+		After:     getSnapshot(after),
+		CreateAt:  time.Now().UTC(),
+	}
+	if err := api.AuditRepo.AddAuditEvent(event); err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		LogErrorMessage(api.Logger, requestInfo, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: fmt.Sprintf("Unable to store audit event %v: %v", event, dbError.Message),
+		})
+	}
+}
+
+// Serialize resource to store it in an audit event, nil if there isn't resource
+func getSnapshot(resource interface{}) json.RawMessage {
+	if resource == nil {
+		return nil
+	}
+	snapshot, err := json.Marshal(resource)
+	if err != nil {
+		return nil
+	}
+	return snapshot
+}
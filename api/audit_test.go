@@ -0,0 +1,309 @@
+package api
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestAuthAPI_ListAuditEvents(t *testing.T) {
+	now := time.Now().UTC()
+	testEvents := []AuditEvent{
+		{
+			ID:       "EVENT-ID",
+			Actor:    "123456",
+			Action:   USER_ACTION_CREATE_USER,
+			Urn:      CreateUrn("", RESOURCE_USER, "/example/test/", "123"),
+			After:    json.RawMessage(`{"id":"123"}`),
+			CreateAt: now,
+		},
+		{
+			ID:       "EVENT-ID2",
+			Actor:    "123456",
+			Action:   GROUP_ACTION_DELETE_GROUP,
+			Urn:      CreateUrn("example", RESOURCE_GROUP, "/example/test/", "group"),
+			Before:   json.RawMessage(`{"id":"GROUP-ID"}`),
+			CreateAt: now.Add(-time.Minute),
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		filter      *AuditFilter
+		// Expected result
+		expectedResult []AuditEvent
+		expectedLimit  int
+		totalResult    int
+		wantError      error
+		// Manager Results
+		getAuditEventsFilteredMethodResult []AuditEvent
+		getGroupsByUserIDMethodResult      []Group
+		getAttachedPoliciesMethodResult    []Policy
+		getUserByExternalIDMethodResult    *User
+		// API Errors
+		getAuditEventsFilteredMethodErr error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter:                             &AuditFilter{},
+			expectedResult:                     testEvents,
+			expectedLimit:                      DEFAULT_LIMIT_SIZE,
+			totalResult:                        2,
+			getAuditEventsFilteredMethodResult: testEvents,
+		},
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			filter: &AuditFilter{
+				Actor: "123456",
+				Limit: 20,
+			},
+			expectedResult:                     []AuditEvent{testEvents[0]},
+			expectedLimit:                      20,
+			totalResult:                        2,
+			getAuditEventsFilteredMethodResult: testEvents,
+			getUserByExternalIDMethodResult: &User{
+				ID:         "000",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDMethodResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Path: "/path/",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								AUDIT_ACTION_LIST_AUDIT_EVENTS,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/example/"),
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseInvalidAction": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &AuditFilter{
+				Action: "iam:*~#",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Action iam:*~#",
+			},
+		},
+		"ErrorCaseInvalidDates": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &AuditFilter{
+				From: time.Date(2016, 2, 1, 0, 0, 0, 0, time.UTC),
+				To:   time.Date(2016, 1, 1, 0, 0, 0, 0, time.UTC),
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: To 2016-01-01T00:00:00Z is before From 2016-02-01T00:00:00Z",
+			},
+		},
+		"ErrorCaseMaxLimitSize": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &AuditFilter{
+				Limit: 10000,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 10000, max limit allowed: 1000",
+			},
+		},
+		"ErrorCaseNoPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			filter: &AuditFilter{},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:*",
+			},
+			getAuditEventsFilteredMethodResult: testEvents,
+			getUserByExternalIDMethodResult: &User{
+				ID:         "000",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDMethodResult: []Group{},
+		},
+		"ErrorCaseGetAuditEventsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			filter: &AuditFilter{},
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getAuditEventsFilteredMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDMethodResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][0] = testcase.getAuditEventsFilteredMethodResult
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][1] = testcase.totalResult
+		testRepo.ArgsOut[GetAuditEventsFilteredMethod][2] = testcase.getAuditEventsFilteredMethodErr
+		events, total, err := testAPI.ListAuditEvents(testcase.requestInfo, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, events)
+		if testcase.totalResult != total {
+			t.Errorf("Test case %v. Received different total (wanted:%v / received:%v)", x, testcase.totalResult, total)
+		}
+		if testcase.wantError == nil && testcase.filter.Limit != testcase.expectedLimit {
+			t.Errorf("Test case %v. Received different limit (wanted:%v / received:%v)", x, testcase.expectedLimit, testcase.filter.Limit)
+		}
+	}
+}
+
+func TestAuthAPI_RecordAuditEvent(t *testing.T) {
+	testcases := map[string]struct {
+		// Method args
+		requestInfo RequestInfo
+		action      string
+		urn         string
+		before      interface{}
+		after       interface{}
+		// Expected result
+		expectedEvent AuditEvent
+		// Manager Errors
+		addAuditEventMethodErr error
+	}{
+		"OKCaseCreate": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			action: USER_ACTION_CREATE_USER,
+			urn:    CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			after: &User{
+				ID:         "USER-ID",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			expectedEvent: AuditEvent{
+				Actor:     "123456",
+				RequestID: "REQUEST-ID",
+				Action:    USER_ACTION_CREATE_USER,
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+				After: json.RawMessage(`{"id":"USER-ID","externalId":"1234","path":"/path/",` +
+					`"urn":"urn:iws:iam::user/path/1234","createAt":"0001-01-01T00:00:00Z"}`),
+			},
+		},
+		"OKCaseRelation": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			action: GROUP_ACTION_REMOVE_MEMBER,
+			urn:    CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+			before: auditRelation{
+				Group: &Group{ID: "GROUP-ID"},
+				User:  &User{ID: "USER-ID"},
+			},
+			expectedEvent: AuditEvent{
+				Actor:     "123456",
+				RequestID: "REQUEST-ID",
+				Action:    GROUP_ACTION_REMOVE_MEMBER,
+				Urn:       CreateUrn("example", RESOURCE_GROUP, "/path/", "group"),
+				Before: json.RawMessage(`{"user":{"id":"USER-ID","externalId":"","path":"","urn":"","createAt":"0001-01-01T00:00:00Z"},` +
+					`"group":{"id":"GROUP-ID","name":"","path":"","org":"","urn":"","createAt":"0001-01-01T00:00:00Z"}}`),
+			},
+		},
+		"ErrorCaseAddAuditEventDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				RequestID:  "REQUEST-ID",
+			},
+			action: USER_ACTION_DELETE_USER,
+			urn:    CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			expectedEvent: AuditEvent{
+				Actor:     "123456",
+				RequestID: "REQUEST-ID",
+				Action:    USER_ACTION_DELETE_USER,
+				Urn:       CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			addAuditEventMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[AddAuditEventMethod][0] = testcase.addAuditEventMethodErr
+		testAPI.recordAuditEvent(testcase.requestInfo, testcase.action, testcase.urn, testcase.before, testcase.after)
+		event, ok := testRepo.ArgsIn[AddAuditEventMethod][0].(AuditEvent)
+		if !ok {
+			t.Errorf("Test case %v. Audit event not stored", x)
+			continue
+		}
+		if event.ID == "" || event.CreateAt.IsZero() {
+			t.Errorf("Test case %v. Audit event without id or date: %v", x, event)
+		}
+		event.ID = ""
+		event.CreateAt = time.Time{}
+		if diff := pretty.Compare(string(event.Before), string(testcase.expectedEvent.Before)); diff != "" {
+			t.Errorf("Test case %v. Received different before snapshot (received/wanted) %v", x, diff)
+		}
+		if diff := pretty.Compare(string(event.After), string(testcase.expectedEvent.After)); diff != "" {
+			t.Errorf("Test case %v. Received different after snapshot (received/wanted) %v", x, diff)
+		}
+		event.Before, event.After = nil, nil
+		testcase.expectedEvent.Before, testcase.expectedEvent.After = nil, nil
+		if diff := pretty.Compare(event, testcase.expectedEvent); diff != "" {
+			t.Errorf("Test case %v. Received different audit event (received/wanted) %v", x, diff)
+		}
+	}
+
+	// Audit disabled
+	testAPI := makeTestAPI(makeTestRepo())
+	testAPI.AuditRepo = nil
+	testAPI.recordAuditEvent(RequestInfo{Identifier: "123456"}, USER_ACTION_DELETE_USER, "urn", nil, nil)
+}
@@ -2,18 +2,54 @@ package api
 
 import (
 	"fmt"
+	"net"
+	"regexp"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/Tecsisa/foulkon/database"
+	"github.com/Tecsisa/foulkon/metrics"
 )
 
+const (
+	// Authorization decision types
+	DECISION_DENIED_URN_PREFIX  = "DeniedUrnPrefix"
+	DECISION_DENIED_FULL_URN    = "DeniedFullUrn"
+	DECISION_ALLOWED_URN_PREFIX = "AllowedUrnPrefix"
+	DECISION_ALLOWED_FULL_URN   = "AllowedFullUrn"
+	DECISION_IMPLICIT_DENY      = "ImplicitDeny"
+
+	// Authorization decision metric results
+	METRIC_DECISION_ALLOWED = "allowed"
+	METRIC_DECISION_DENIED  = "denied"
+	METRIC_DECISION_ERROR   = "error"
+
+	// Authorization decision metric action for actions that aren't IAM actions
+	METRIC_ACTION_OTHER = "other"
+
+	// Max action and resources pairs authorized in a batch
+	MAX_AUTHORIZATION_CHECKS = 100
+)
+
+// Authorization decisions by action and result. Use metricAction to label actions.
+var authorizationDecisions = metrics.NewCounterVec("foulkon_authorization_decisions_total",
+	"Authorization decisions by action and result (allowed, denied or error).", "action", "decision")
+
 // TYPE DEFINITIONS
 
 type RequestInfo struct {
 	Identifier string
 	Admin      bool
 	RequestID  string
+	Context    RequestContext
+}
+
+// Request values used to evaluate statement conditions
+type RequestContext struct {
+	SourceIP    string
+	CurrentTime time.Time
+	Attributes  map[string]string
 }
 
 type EffectRestriction struct {
@@ -36,6 +72,61 @@ func (e ExternalResource) GetUrn() string {
 	return e.Urn
 }
 
+// Action and resources to authorize in a batch
+type AuthorizationCheck struct {
+	Action    string   `json:"action, omitempty"`
+	Resources []string `json:"resources, omitempty"`
+}
+
+// Resources allowed for an action authorized in a batch
+type AuthorizationCheckResult struct {
+	Action           string   `json:"action, omitempty"`
+	ResourcesAllowed []string `json:"resourcesAllowed, omitempty"`
+}
+
+// Explanation of the authorization decisions taken for a user, an action and a list of resources
+type AuthorizationExplanation struct {
+	ExternalID   string             `json:"externalId, omitempty"`
+	Action       string             `json:"action, omitempty"`
+	Groups       []GroupIdentity    `json:"groups, omitempty"`
+	Restrictions *Restrictions      `json:"restrictions, omitempty"`
+	Decisions    []ResourceDecision `json:"decisions, omitempty"`
+}
+
+// Decision taken for a resource, with the restriction that decided it and the statements that contain the resource
+type ResourceDecision struct {
+	Urn                 string                `json:"urn, omitempty"`
+	Allowed             bool                  `json:"allowed, omitempty"`
+	DecisionType        string                `json:"decisionType, omitempty"`
+	DecisionRestriction string                `json:"decisionRestriction, omitempty"`
+	Statements          []StatementEvaluation `json:"statements, omitempty"`
+}
+
+// Statement that applies to the requested action, with the group and policy it comes from.
+// Group is nil when the policy is attached directly to the user.
+type StatementEvaluation struct {
+	Group            *GroupIdentity `json:"group, omitempty"`
+	Policy           PolicyIdentity `json:"policy, omitempty"`
+	Statement        Statement      `json:"statement, omitempty"`
+	MatchedResources []string       `json:"matchedResources, omitempty"`
+}
+
+// Statement of a policy attached to a user, with the policy and the group it comes from.
+// Group is nil when the policy is attached directly to the user.
+type EffectiveStatement struct {
+	Group     *GroupIdentity
+	Policy    PolicyIdentity
+	Statement Statement
+}
+
+// Users and groups allowed to do an action over a resource. Users are identified by their externalId.
+type AuthorizedPrincipals struct {
+	Action   string          `json:"action, omitempty"`
+	Resource string          `json:"resource, omitempty"`
+	Users    []string        `json:"users, omitempty"`
+	Groups   []GroupIdentity `json:"groups, omitempty"`
+}
+
 // AUTHZ API IMPLEMENTATION
 
 // GetAuthorizedUsers returns authorized users for specified resource+action
@@ -92,53 +183,395 @@ func (api AuthAPI) GetAuthorizedPolicies(requestInfo RequestInfo, resourceUrn st
 // GetAuthorizedExternalResources returns the resources where the specified user has the action granted
 func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string) ([]string, error) {
 	// Validate parameters
-	if err := AreValidActions([]string{action}); err != nil {
-		// Transform to API error
-		apiError := err.(*Error)
+	if err := validateExternalResources(action, resources); err != nil {
+		return nil, err
+	}
+	externalResources := []Resource{}
+	for _, res := range resources {
+		externalResources = append(externalResources, ExternalResource{Urn: res})
+	}
+
+	allowedUrns, err := api.getAuthorizedResources(requestInfo, "urn:*", action, externalResources)
+	if err != nil {
+		return nil, err
+	}
+
+	response := []string{}
+	for _, res := range allowedUrns {
+		response = append(response, res.GetUrn())
+	}
+
+	return response, nil
+}
+
+// GetAuthorizedRestrictions returns the restrictions that apply to the authenticated user for the action over the
+// resources contained in the urn prefix, so they can be used to filter resources without listing them
+func (api AuthAPI) GetAuthorizedRestrictions(requestInfo RequestInfo, action string, urnPrefix string) (*Restrictions, error) {
+	// Validate parameters
+	if err := validateRestrictionsRequest(action, urnPrefix); err != nil {
+		return nil, err
+	}
+
+	// Admin has no restrictions
+	if requestInfo.Admin {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_ALLOWED)
+		restrictions := &Restrictions{
+			AllowedUrnPrefixes: []string{},
+			AllowedFullUrns:    []string{},
+			DeniedUrnPrefixes:  []string{},
+			DeniedFullUrns:     []string{},
+		}
+		if isFullUrn(urnPrefix) {
+			restrictions.AllowedFullUrns = append(restrictions.AllowedFullUrns, urnPrefix)
+		} else {
+			restrictions.AllowedUrnPrefixes = append(restrictions.AllowedUrnPrefixes, urnPrefix)
+		}
+		return restrictions, nil
+	}
+
+	restrictions, err := api.getRestrictions(requestInfo.Identifier, action, urnPrefix, requestInfo.Context)
+	if err != nil {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_ERROR)
+		return nil, err
+	}
+	if len(restrictions.AllowedFullUrns) > 0 || len(restrictions.AllowedUrnPrefixes) > 0 {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_ALLOWED)
+	} else {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_DENIED)
+	}
+
+	return restrictions, nil
+}
+
+// GetAuthorizedExternalResourcesForUser returns the resources where the user with this externalID has the action
+// granted, evaluated with the context of the request. The authenticated user needs permission to check authorization
+// of that user.
+func (api AuthAPI) GetAuthorizedExternalResourcesForUser(requestInfo RequestInfo, externalID string, action string, resources []string) ([]string, error) {
+	// Validate parameters
+	if err := validateExternalResources(action, resources); err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_CHECK_AUTHORIZATION, []User{*user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
 		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: apiError.Message,
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
 		}
 	}
-	if len(resources) < 1 {
+
+	// Authorize resources for the user, that is never an admin
+	userRequestInfo := RequestInfo{
+		Identifier: user.ExternalID,
+		RequestID:  requestInfo.RequestID,
+		Context:    requestInfo.Context,
+	}
+	allowedResources, err := api.GetAuthorizedExternalResources(userRequestInfo, action, resources)
+	if err != nil {
+		// User without any permission for this action isn't an error for the authenticated user
+		if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR {
+			return []string{}, nil
+		}
+		return nil, err
+	}
+
+	return allowedResources, nil
+}
+
+// GetAuthorizedExternalResourcesBatch returns the resources where the specified user has the action granted for
+// every check, in the same order. User statements are retrieved once for all checks.
+func (api AuthAPI) GetAuthorizedExternalResourcesBatch(requestInfo RequestInfo, checks []AuthorizationCheck) ([]AuthorizationCheckResult, error) {
+	// Validate parameters
+	if len(checks) < 1 || len(checks) > MAX_AUTHORIZATION_CHECKS {
 		return nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
-			Message: "Invalid parameter Resources %v. Resources can't be empty",
+			Message: fmt.Sprintf("Invalid parameter checks, number of checks must be between 1 and %v", MAX_AUTHORIZATION_CHECKS),
 		}
 	}
-	externalResources := []Resource{}
-	for _, res := range resources {
-		if !isFullUrn(res) {
-			return nil, &Error{
-				Code:    INVALID_PARAMETER_ERROR,
-				Message: fmt.Sprintf("Invalid parameter resource %v. Urn prefixes are not allowed here", res),
+	for _, check := range checks {
+		if err := validateExternalResources(check.Action, check.Resources); err != nil {
+			return nil, err
+		}
+	}
+
+	// Retrieve statements for all checks, admin doesn't need them
+	var effectiveStatements []Statement
+	if !requestInfo.Admin {
+		var err error
+		effectiveStatements, err = api.getEffectiveStatements(requestInfo.Identifier)
+		if err != nil {
+			for _, check := range checks {
+				authorizationDecisions.Inc(metricAction(check.Action), METRIC_DECISION_ERROR)
 			}
+			return nil, err
 		}
-		if err := AreValidResources([]string{res}); err != nil {
-			// Transform to API error
-			apiError := err.(*Error)
-			return nil, &Error{
-				Code:    INVALID_PARAMETER_ERROR,
-				Message: apiError.Message,
+	}
+
+	response := []AuthorizationCheckResult{}
+	for _, check := range checks {
+		resourcesAllowed := []string{}
+		if requestInfo.Admin {
+			resourcesAllowed = append(resourcesAllowed, check.Resources...)
+		} else {
+			restrictions := getRestrictionsByAction(effectiveStatements, check.Action, "urn:*", requestInfo.Context)
+			externalResources := []Resource{}
+			for _, res := range check.Resources {
+				externalResources = append(externalResources, ExternalResource{Urn: res})
+			}
+			for _, res := range filterResources(externalResources, restrictions) {
+				resourcesAllowed = append(resourcesAllowed, res.GetUrn())
 			}
 		}
-		externalResources = append(externalResources, ExternalResource{Urn: res})
+
+		if len(resourcesAllowed) > 0 {
+			authorizationDecisions.Inc(metricAction(check.Action), METRIC_DECISION_ALLOWED)
+		} else {
+			authorizationDecisions.Inc(metricAction(check.Action), METRIC_DECISION_DENIED)
+		}
+		response = append(response, AuthorizationCheckResult{
+			Action:           check.Action,
+			ResourcesAllowed: resourcesAllowed,
+		})
 	}
-	if strings.Contains(action, "*") {
+
+	return response, nil
+}
+
+// ExplainAuthorization returns the decision taken for every resource when the specified user requests the action,
+// with the groups, policies, statements and restrictions that contributed to it
+func (api AuthAPI) ExplainAuthorization(requestInfo RequestInfo, externalID string, action string, resources []string) (*AuthorizationExplanation, error) {
+	// Validate parameters
+	if err := validateExternalResources(action, resources); err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_EXPLAIN_AUTHORIZATION, []User{*user})
+	if err != nil {
+		return nil, err
+	}
+	if len(usersFiltered) < 1 {
 		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter action %v. Action parameter can't be a prefix", action),
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
 		}
 	}
 
-	allowedUrns, err := api.getAuthorizedResources(requestInfo, "urn:*", action, externalResources)
+	// Retrieve all groups of the user, also the ones without policies
+	groups, err := api.getGroupsByUser(user.ID)
 	if err != nil {
 		return nil, err
 	}
+	groupIDs := []GroupIdentity{}
+	for _, g := range groups {
+		groupIDs = append(groupIDs, GroupIdentity{
+			Org:  g.Org,
+			Name: g.Name,
+		})
+	}
 
-	response := []string{}
-	for _, res := range allowedUrns {
-		response = append(response, res.GetUrn())
+	// Retrieve the same effective statements used to authorize, with every group or user attachment they come from
+	effectiveStatements, err := api.getEffectiveStatementOrigins(user.ExternalID)
+	if err != nil {
+		return nil, err
+	}
+	evaluations := []StatementEvaluation{}
+	for _, effectiveStatement := range effectiveStatements {
+		if isActionContained(action, effectiveStatement.Statement.Actions) {
+			evaluations = append(evaluations, StatementEvaluation{
+				Group:     effectiveStatement.Group,
+				Policy:    effectiveStatement.Policy,
+				Statement: effectiveStatement.Statement,
+			})
+		}
+	}
+
+	// Discard statements whose conditions are not satisfied by this request
+	statements := []Statement{}
+	evaluationsByConditions := []StatementEvaluation{}
+	for _, evaluation := range evaluations {
+		if areConditionsSatisfied(evaluation.Statement.Conditions, requestInfo.Context) {
+			statements = append(statements, evaluation.Statement)
+			evaluationsByConditions = append(evaluationsByConditions, evaluation)
+		}
+	}
+	evaluations = evaluationsByConditions
+
+	// Retrieve restrictions the same way that authorization does
+	restrictions := getRestrictions(statements, "urn:*", false)
+
+	decisions := []ResourceDecision{}
+	for _, urn := range resources {
+		allowed, decisionType, decisionRestriction := getResourceDecision(urn, *restrictions)
+		decision := ResourceDecision{
+			Urn:                 urn,
+			Allowed:             allowed,
+			DecisionType:        decisionType,
+			DecisionRestriction: decisionRestriction,
+			Statements:          []StatementEvaluation{},
+		}
+		// Add statements whose resources contain the urn
+		for _, evaluation := range evaluations {
+			matchedResources := []string{}
+			for _, statementResource := range evaluation.Statement.Resources {
+				if isContainedOrEqual(urn, statementResource) {
+					matchedResources = append(matchedResources, statementResource)
+				}
+			}
+			if len(matchedResources) > 0 {
+				evaluation.MatchedResources = matchedResources
+				decision.Statements = append(decision.Statements, evaluation)
+			}
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return &AuthorizationExplanation{
+		ExternalID:   user.ExternalID,
+		Action:       action,
+		Groups:       groupIDs,
+		Restrictions: restrictions,
+		Decisions:    decisions,
+	}, nil
+}
+
+// GetAuthorizedPrincipals returns the users and groups allowed to do the action over the resource, evaluated with the
+// context of the request. Policies that allow it are scanned to find their groups, members and users, and then deny
+// statements are applied. A group is allowed if its own policies allow it, and a user if its effective statements
+// allow it. Only admin users can retrieve them.
+func (api AuthAPI) GetAuthorizedPrincipals(requestInfo RequestInfo, action string, resource string) (*AuthorizedPrincipals, error) {
+	// Validate parameters
+	if err := validateExternalResources(action, []string{resource}); err != nil {
+		return nil, err
+	}
+
+	if !requestInfo.Admin {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to retrieve authorized principals. Only admin users can do it",
+				requestInfo.Identifier),
+		}
+	}
+
+	// Retrieve all policies
+	policies, _, err := api.PolicyRepo.GetPoliciesFiltered("", &Filter{})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Retrieve groups and users attached to policies that allow the action. A policy that denies it
+	// can't grant it to anyone.
+	groups := []Group{}
+	users := []User{}
+	groupIDs := map[string]bool{}
+	userIDs := map[string]bool{}
+	for _, policy := range policies {
+		if !isActionAllowed(getStatementsByRequestedAction([]Policy{policy}, action), action, resource, requestInfo.Context) {
+			continue
+		}
+
+		attachedGroups, _, err := api.PolicyRepo.GetAttachedGroups(policy.ID, &Filter{})
+		if err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+		for _, group := range attachedGroups {
+			if !groupIDs[group.ID] {
+				groupIDs[group.ID] = true
+				groups = append(groups, group)
+			}
+		}
+
+		attachedUsers, _, err := api.PolicyRepo.GetAttachedUsers(policy.ID, &Filter{})
+		if err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+		for _, user := range attachedUsers {
+			if !userIDs[user.ID] {
+				userIDs[user.ID] = true
+				users = append(users, user)
+			}
+		}
+	}
+
+	response := &AuthorizedPrincipals{
+		Action:   action,
+		Resource: resource,
+		Users:    []string{},
+		Groups:   []GroupIdentity{},
+	}
+
+	// Check groups with all their policies, and add their members
+	for _, group := range groups {
+		groupPolicies, err := api.getPoliciesByGroups([]Group{group})
+		if err != nil {
+			return nil, err
+		}
+		if isActionAllowed(getStatementsByRequestedAction(groupPolicies, action), action, resource, requestInfo.Context) {
+			response.Groups = append(response.Groups, GroupIdentity{
+				Org:  group.Org,
+				Name: group.Name,
+			})
+		}
+
+		members, _, err := api.GroupRepo.GetGroupMembers(group.ID, &Filter{})
+		if err != nil {
+			//Transform to DB error
+			dbError := err.(*database.Error)
+			return nil, &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: dbError.Message,
+			}
+		}
+		for _, member := range members {
+			if !userIDs[member.ID] {
+				userIDs[member.ID] = true
+				users = append(users, member)
+			}
+		}
+	}
+
+	// Check users with their effective statements, that include deny statements of their other groups and policies
+	for _, user := range users {
+		statements, err := api.getEffectiveStatements(user.ExternalID)
+		if err != nil {
+			return nil, err
+		}
+		if isActionAllowed(statements, action, resource, requestInfo.Context) {
+			response.Users = append(response.Users, user.ExternalID)
+		}
 	}
 
 	return response, nil
@@ -150,12 +583,14 @@ func (api AuthAPI) GetAuthorizedExternalResources(requestInfo RequestInfo, actio
 func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn string, action string, resources []Resource) ([]Resource, error) {
 	// If user is an admin return all resources without restriction
 	if requestInfo.Admin {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_ALLOWED)
 		return resources, nil
 	}
 
 	// Check authorization for this user
-	restrictions, err := api.getRestrictions(requestInfo.Identifier, action, resourceUrn)
+	restrictions, err := api.getRestrictions(requestInfo.Identifier, action, resourceUrn, requestInfo.Context)
 	if err != nil {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_ERROR)
 		return nil, err
 	}
 
@@ -163,6 +598,7 @@ func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn s
 
 	// Check if there are some restrictions for this urn resource
 	if len(restrictions.AllowedFullUrns) < 1 && len(restrictions.AllowedUrnPrefixes) < 1 {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_DENIED)
 		return nil, &Error{
 			Code:    UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v", requestInfo.Identifier, resourceUrn),
@@ -171,14 +607,67 @@ func (api AuthAPI) getAuthorizedResources(requestInfo RequestInfo, resourceUrn s
 
 	// Filter resources
 	resourcesFiltered := filterResources(resources, restrictions)
+	if len(resourcesFiltered) > 0 || len(resources) == 0 {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_ALLOWED)
+	} else {
+		authorizationDecisions.Inc(metricAction(action), METRIC_DECISION_DENIED)
+	}
 
 	return resourcesFiltered, nil
 }
 
 // Get restrictions for this action and full resource or prefix resource, attached to this authenticated user
-func (api AuthAPI) getRestrictions(externalID string, action string, resource string) (*Restrictions, error) {
-	// Get user if exists
-	user, err := api.UserRepo.GetUserByExternalID(externalID)
+func (api AuthAPI) getRestrictions(externalID string, action string, resource string, context RequestContext) (*Restrictions, error) {
+	effectiveStatements, err := api.getEffectiveStatements(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getRestrictionsByAction(effectiveStatements, action, resource, context), nil
+}
+
+// Get restrictions for this action and full resource or prefix resource from user effective statements
+func getRestrictionsByAction(effectiveStatements []Statement, action string, resource string, context RequestContext) *Restrictions {
+	// Retrieve valid statements
+	statements := []Statement{}
+	for _, statement := range effectiveStatements {
+		if isActionContained(action, statement.Actions) {
+			statements = append(statements, statement)
+		}
+	}
+
+	// Discard statements whose conditions are not satisfied by this request
+	statements = getStatementsByConditions(statements, context)
+
+	// Retrieve restrictions
+	return getRestrictions(statements, resource, isFullUrn(resource))
+}
+
+// Get all statements from policies attached to this authenticated user and its groups, using cache if enabled.
+// Statements of a policy attached several times are only returned once.
+func (api AuthAPI) getEffectiveStatements(externalID string) ([]Statement, error) {
+	effectiveStatements, err := api.getEffectiveStatementOrigins(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	statements := []Statement{}
+	for _, effectiveStatement := range getUniqueEffectiveStatements(effectiveStatements) {
+		statements = append(statements, effectiveStatement.Statement)
+	}
+	return statements, nil
+}
+
+// Get all statements from policies attached to this authenticated user and its groups with the policy and group
+// they come from, using cache if enabled
+func (api AuthAPI) getEffectiveStatementOrigins(externalID string) ([]EffectiveStatement, error) {
+	statements, generation, ok := api.Cache.get(externalID)
+	if ok {
+		return statements, nil
+	}
+
+	// Retrieve statements from all policies of the user
+	statements, err := api.AuthzRepo.GetEffectiveStatements(externalID)
 
 	// Error handling
 	if err != nil {
@@ -197,25 +686,34 @@ func (api AuthAPI) getRestrictions(externalID string, action string, resource st
 			}
 		}
 	}
+	api.Cache.set(externalID, statements, generation)
 
-	groups, err := api.getGroupsByUser(user.ID)
-	if err != nil {
-		return nil, err
-	}
+	return statements, nil
+}
 
-	policies, err := api.getPoliciesByGroups(groups)
-	if err != nil {
-		return nil, err
+// Keep statements of every policy from the first group or user attachment found, like a single attachment
+// of each policy. Repositories return all statements of an attachment together.
+func getUniqueEffectiveStatements(effectiveStatements []EffectiveStatement) []EffectiveStatement {
+	unique := []EffectiveStatement{}
+	policyOrigins := map[PolicyIdentity]*GroupIdentity{}
+	for _, effectiveStatement := range effectiveStatements {
+		origin, ok := policyOrigins[effectiveStatement.Policy]
+		if !ok {
+			origin = effectiveStatement.Group
+			policyOrigins[effectiveStatement.Policy] = origin
+		}
+		if isSameGroupIdentity(origin, effectiveStatement.Group) {
+			unique = append(unique, effectiveStatement)
+		}
 	}
+	return unique
+}
 
-	// Retrieve valid statements
-	statements := getStatementsByRequestedAction(policies, action)
-
-	// Retrieve restrictions
-	var authResources *Restrictions
-	authResources = getRestrictions(statements, resource, isFullUrn(resource))
-
-	return authResources, nil
+func isSameGroupIdentity(a *GroupIdentity, b *GroupIdentity) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
 }
 
 func (api AuthAPI) getGroupsByUser(userID string) ([]Group, error) {
@@ -262,6 +760,74 @@ func (api AuthAPI) getPoliciesByGroups(groups []Group) ([]Policy, error) {
 	return policies, nil
 }
 
+// Validate action and resources received to authorize external resources
+func validateExternalResources(action string, resources []string) error {
+	if err := AreValidActions([]string{action}); err != nil {
+		// Transform to API error
+		apiError := err.(*Error)
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: apiError.Message,
+		}
+	}
+	if len(resources) < 1 {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: "Invalid parameter Resources %v. Resources can't be empty",
+		}
+	}
+	for _, res := range resources {
+		if !isFullUrn(res) {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter resource %v. Urn prefixes are not allowed here", res),
+			}
+		}
+		if err := AreValidResources([]string{res}); err != nil {
+			// Transform to API error
+			apiError := err.(*Error)
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: apiError.Message,
+			}
+		}
+	}
+	if strings.Contains(action, "*") {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter action %v. Action parameter can't be a prefix", action),
+		}
+	}
+	return nil
+}
+
+// Validate action and urn prefix received to retrieve restrictions
+func validateRestrictionsRequest(action string, urnPrefix string) error {
+	if err := AreValidActions([]string{action}); err != nil {
+		// Transform to API error
+		apiError := err.(*Error)
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: apiError.Message,
+		}
+	}
+	if strings.Contains(action, "*") {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter action %v. Action parameter can't be a prefix", action),
+		}
+	}
+	if err := AreValidResources([]string{urnPrefix}); err != nil {
+		// Transform to API error
+		apiError := err.(*Error)
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: apiError.Message,
+		}
+	}
+	return nil
+}
+
 // Filter a slice of statements for a specified action
 func getStatementsByRequestedAction(policies []Policy, requestedAction string) []Statement {
 	// Check received policies
@@ -281,6 +847,126 @@ func getStatementsByRequestedAction(policies []Policy, requestedAction string) [
 	return statements
 }
 
+// Filter a slice of statements keeping the ones whose conditions are satisfied by the request context
+func getStatementsByConditions(statements []Statement, context RequestContext) []Statement {
+	// Check received statements
+	if statements == nil || len(statements) < 1 {
+		return nil
+	}
+
+	statementsFiltered := []Statement{}
+	for _, statement := range statements {
+		if areConditionsSatisfied(statement.Conditions, context) {
+			statementsFiltered = append(statementsFiltered, statement)
+		}
+	}
+
+	return statementsFiltered
+}
+
+// Returns true if all conditions are satisfied by the request context
+func areConditionsSatisfied(conditions []Condition, context RequestContext) bool {
+	for _, condition := range conditions {
+		if !isConditionSatisfied(condition, context) {
+			return false
+		}
+	}
+	return true
+}
+
+// Returns true if the context value for the condition key matches any of the condition values.
+// Conditions over keys missing in the request context are never satisfied.
+func isConditionSatisfied(condition Condition, context RequestContext) bool {
+	value, ok := context.getValue(condition.Key)
+	if !ok {
+		return false
+	}
+	for _, conditionValue := range condition.Values {
+		if isConditionValueMatched(condition.Operator, value, conditionValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// Returns true if the context value matches the condition value using the operator
+func isConditionValueMatched(operator string, value string, conditionValue string) bool {
+	switch operator {
+	case CONDITION_OPERATOR_IP_ADDRESS:
+		ip := net.ParseIP(value)
+		if ip == nil {
+			return false
+		}
+		if _, ipNet, err := net.ParseCIDR(conditionValue); err == nil {
+			return ipNet.Contains(ip)
+		}
+		return ip.Equal(net.ParseIP(conditionValue))
+	case CONDITION_OPERATOR_DATE_BEFORE, CONDITION_OPERATOR_DATE_AFTER:
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false
+		}
+		limit, err := time.Parse(time.RFC3339, conditionValue)
+		if err != nil {
+			return false
+		}
+		if operator == CONDITION_OPERATOR_DATE_BEFORE {
+			return date.Before(limit)
+		}
+		return date.After(limit)
+	case CONDITION_OPERATOR_TIME_OF_DAY:
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false
+		}
+		start, end, err := parseTimeOfDayRange(conditionValue)
+		if err != nil {
+			return false
+		}
+		// Start is included and end excluded, ranges that cross midnight include both days
+		date = date.UTC()
+		minutes := date.Hour()*60 + date.Minute()
+		if start < end {
+			return minutes >= start && minutes < end
+		}
+		return minutes >= start || minutes < end
+	case CONDITION_OPERATOR_WEEKDAY:
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return false
+		}
+		day, err := parseWeekday(conditionValue)
+		return err == nil && date.UTC().Weekday() == day
+	case CONDITION_OPERATOR_STRING_EQUALS:
+		return value == conditionValue
+	case CONDITION_OPERATOR_STRING_LIKE:
+		// Only '*' wildcard is supported, it matches any sequence of characters
+		pattern := "^" + strings.Replace(regexp.QuoteMeta(conditionValue), `\*`, ".*", -1) + "$"
+		match, err := regexp.MatchString(pattern, value)
+		return err == nil && match
+	default:
+		return false
+	}
+}
+
+// Retrieve value for a condition key. Source IP and current time are filled from the request,
+// any other key is looked up in the request attributes.
+func (c RequestContext) getValue(key string) (string, bool) {
+	switch key {
+	case CONDITION_KEY_SOURCE_IP:
+		return c.SourceIP, c.SourceIP != ""
+	case CONDITION_KEY_CURRENT_TIME:
+		currentTime := c.CurrentTime
+		if currentTime.IsZero() {
+			currentTime = time.Now()
+		}
+		return currentTime.UTC().Format(time.RFC3339), true
+	default:
+		value, ok := c.Attributes[key]
+		return value, ok
+	}
+}
+
 // Returns true if an action is contained inside a slice of statements
 func isActionContained(actionRequested string, statementActions []string) bool {
 	match := false
@@ -474,6 +1160,24 @@ func getRestrictions(statements []Statement, resource string, resourceIsFullUrn
 	return restrictions
 }
 
+// Check if statements allow the action over a full urn, the same way that authorization does
+func isActionAllowed(statements []Statement, action string, resource string, context RequestContext) bool {
+	restrictions := getRestrictionsByAction(statements, action, resource, context)
+	allowed, _, _ := getResourceDecision(resource, *restrictions)
+	return allowed
+}
+
+// Action label for authorization decisions metric. Actions are sent by callers, so only IAM actions
+// keep their name to keep metric labels bounded.
+func metricAction(action string) string {
+	for _, a := range iamActions {
+		if a == action {
+			return action
+		}
+	}
+	return METRIC_ACTION_OTHER
+}
+
 // Remove resources that are not allowed by the restrictions
 func filterResources(resources []Resource, restrictions *Restrictions) []Resource {
 	filteredResource := []Resource{}
@@ -488,43 +1192,35 @@ func filterResources(resources []Resource, restrictions *Restrictions) []Resourc
 
 // Check if resource is allowed or not
 func isAllowedResource(resource Resource, restrictions Restrictions) bool {
-	allowed := false
-	denied := false
+	allowed, _, _ := getResourceDecision(resource.GetUrn(), restrictions)
+	return allowed
+}
+
+// Retrieve if an urn is allowed by the restrictions, with the type of restriction and the restriction value that decided it
+func getResourceDecision(urn string, restrictions Restrictions) (bool, string, string) {
 	// Check deny restrictions
-	if len(restrictions.DeniedUrnPrefixes) > 0 {
-		for _, restriction := range restrictions.DeniedUrnPrefixes {
-			if isContainedOrEqual(resource.GetUrn(), restriction) {
-				denied = true
-				break
-			}
+	for _, restriction := range restrictions.DeniedUrnPrefixes {
+		if isContainedOrEqual(urn, restriction) {
+			return false, DECISION_DENIED_URN_PREFIX, restriction
 		}
 	}
-	if len(restrictions.DeniedFullUrns) > 0 && !denied {
-		for _, restriction := range restrictions.DeniedFullUrns {
-			if resource.GetUrn() == restriction {
-				denied = true
-				break
-			}
+	for _, restriction := range restrictions.DeniedFullUrns {
+		if urn == restriction {
+			return false, DECISION_DENIED_FULL_URN, restriction
 		}
 	}
 
 	// Check allow restrictions
-	if len(restrictions.AllowedUrnPrefixes) > 0 && !denied {
-		for _, restriction := range restrictions.AllowedUrnPrefixes {
-			if isContainedOrEqual(resource.GetUrn(), restriction) {
-				allowed = true
-				break
-			}
+	for _, restriction := range restrictions.AllowedUrnPrefixes {
+		if isContainedOrEqual(urn, restriction) {
+			return true, DECISION_ALLOWED_URN_PREFIX, restriction
 		}
 	}
-	if len(restrictions.AllowedFullUrns) > 0 && !denied && !allowed {
-		for _, restriction := range restrictions.AllowedFullUrns {
-			if resource.GetUrn() == restriction {
-				allowed = true
-				break
-			}
+	for _, restriction := range restrictions.AllowedFullUrns {
+		if urn == restriction {
+			return true, DECISION_ALLOWED_FULL_URN, restriction
 		}
 	}
 
-	return allowed && !denied
+	return false, DECISION_IMPLICIT_DENY, ""
 }
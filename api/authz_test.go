@@ -1,7 +1,9 @@
 package api
 
 import (
+	"fmt"
 	"testing"
+	"time"
 
 	"github.com/Tecsisa/foulkon/database"
 )
@@ -547,6 +549,781 @@ func TestGetAuthorizedExternalResources(t *testing.T) {
 	}
 }
 
+func TestGetAuthorizedRestrictions(t *testing.T) {
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// Urn prefix to retrieve restrictions
+		urnPrefix string
+		// Action to do
+		action string
+		// Expected restrictions
+		expectedRestrictions *Restrictions
+		// Error to compare when we expect an error
+		wantError error
+		// GetUserByExternalID Method Out Arguments
+		getUserByExternalIDResult *User
+		getUserByExternalIDError  error
+		// GetAttachedUserPolicies Method Out Arguments
+		getAttachedUserPoliciesResult []Policy
+	}{
+		"OktestCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			urnPrefix: "urn:ews:product:instance:resource/*",
+			action:    "product:DoAction",
+			expectedRestrictions: &Restrictions{
+				AllowedUrnPrefixes: []string{"urn:ews:product:instance:resource/*"},
+				AllowedFullUrns:    []string{},
+				DeniedUrnPrefixes:  []string{},
+				DeniedFullUrns:     []string{},
+			},
+		},
+		"OktestCaseAdminFullUrn": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			urnPrefix: "urn:ews:product:instance:resource/resource1",
+			action:    "product:DoAction",
+			expectedRestrictions: &Restrictions{
+				AllowedUrnPrefixes: []string{},
+				AllowedFullUrns:    []string{"urn:ews:product:instance:resource/resource1"},
+				DeniedUrnPrefixes:  []string{},
+				DeniedFullUrns:     []string{},
+			},
+		},
+		"OktestCaseRestrictions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			urnPrefix: "urn:ews:product:instance:resource/path1/*",
+			action:    "product:DoAction",
+			expectedRestrictions: &Restrictions{
+				AllowedUrnPrefixes: []string{"urn:ews:product:instance:resource/*"},
+				AllowedFullUrns:    []string{},
+				DeniedUrnPrefixes:  []string{"urn:ews:product:instance:resource/path1/private/*"},
+				DeniedFullUrns:     []string{"urn:ews:product:instance:resource/path1/resourceDeny"},
+			},
+			getUserByExternalIDResult: &User{
+				ID:  "123456",
+				Urn: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			getAttachedUserPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect:  "allow",
+							Actions: []string{"product:DoAction"},
+							Resources: []string{
+								"urn:ews:product:instance:resource/*",
+								"urn:ews:product:instance:resource/path2/resourceAllow",
+							},
+						},
+						{
+							Effect:  "deny",
+							Actions: []string{"product:DoAction"},
+							Resources: []string{
+								"urn:ews:product:instance:resource/path1/private/*",
+								"urn:ews:product:instance:resource/path1/resourceDeny",
+								"urn:ews:product:instance:resource/path2/*",
+							},
+						},
+						{
+							Effect:  "allow",
+							Actions: []string{"product:OtherAction"},
+							Resources: []string{
+								"urn:ews:product:instance:resource/path1/*",
+							},
+						},
+					},
+				},
+			},
+		},
+		"OktestCaseWithoutPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			urnPrefix: "urn:ews:product:instance:resource/path1/*",
+			action:    "product:DoAction",
+			expectedRestrictions: &Restrictions{
+				AllowedUrnPrefixes: []string{},
+				AllowedFullUrns:    []string{},
+				DeniedUrnPrefixes:  []string{},
+				DeniedFullUrns:     []string{},
+			},
+			getUserByExternalIDResult: &User{
+				ID:  "123456",
+				Urn: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+		},
+		"ErrortestCaseInvalidAction": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			urnPrefix: "urn:ews:product:instance:resource/*",
+			action:    "valid::Action",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "No regex match in action: valid::Action",
+			},
+		},
+		"ErrortestCaseActionPrefix": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			urnPrefix: "urn:ews:product:instance:resource/*",
+			action:    "product:Do*",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter action product:Do*. Action parameter can't be a prefix",
+			},
+		},
+		"ErrortestCaseInvalidUrnPrefix": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			urnPrefix: "urn:invalid/resource:resource",
+			action:    "product:DoAction",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "No regex match in resource: urn:invalid/resource:resource",
+			},
+		},
+		"ErrortestCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			urnPrefix: "urn:ews:product:instance:resource/*",
+			action:    "product:DoAction",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
+			},
+			getUserByExternalIDError: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
+
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = test.getAttachedUserPoliciesResult
+
+		restrictions, err := testAPI.GetAuthorizedRestrictions(test.requestInfo, test.action, test.urnPrefix)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedRestrictions, restrictions)
+	}
+}
+
+func TestGetAuthorizedPrincipals(t *testing.T) {
+	allowPolicy := Policy{
+		ID:   "POLICY-ALLOW-ID",
+		Name: "policyAllow",
+		Org:  "example",
+		Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyAllow"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{"product:*"},
+				Resources: []string{"urn:ews:product:instance:resource/*"},
+			},
+		},
+	}
+	denyPolicy := Policy{
+		ID:   "POLICY-DENY-ID",
+		Name: "policyDeny",
+		Org:  "example",
+		Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyDeny"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{"product:DoAction"},
+				Resources: []string{"urn:ews:product:instance:resource/*"},
+			},
+			{
+				Effect:    "deny",
+				Actions:   []string{"product:DoAction"},
+				Resources: []string{"urn:ews:product:instance:resource/resource1"},
+			},
+		},
+	}
+	otherActionPolicy := Policy{
+		ID:   "POLICY-OTHER-ID",
+		Name: "policyOther",
+		Org:  "example",
+		Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policyOther"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{"product:OtherAction"},
+				Resources: []string{"urn:ews:product:instance:resource/*"},
+			},
+		},
+	}
+	group := Group{
+		ID:   "GROUP-ID",
+		Name: "group1",
+		Org:  "example",
+		Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+	}
+	user1 := User{
+		ID:         "USER1-ID",
+		ExternalID: "user1",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+	}
+	user2 := User{
+		ID:         "USER2-ID",
+		ExternalID: "user2",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user2"),
+	}
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// Action and resource requested
+		action   string
+		resource string
+		// Expected result
+		expectedPrincipals *AuthorizedPrincipals
+		// Error to compare when we expect an error
+		wantError error
+		// Repository results
+		getPoliciesFilteredResult   []Policy
+		getPoliciesFilteredError    error
+		getAttachedGroupsResult     []Group
+		getAttachedUsersResult      []User
+		getAttachedPoliciesResult   []Policy
+		getGroupMembersResult       []User
+		effectiveStatementsByUser   map[string][]Statement
+		getEffectiveStatementsError error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			action:   "product:DoAction",
+			resource: "urn:ews:product:instance:resource/resource1",
+			expectedPrincipals: &AuthorizedPrincipals{
+				Action:   "product:DoAction",
+				Resource: "urn:ews:product:instance:resource/resource1",
+				Users:    []string{"user2"},
+				Groups: []GroupIdentity{
+					{
+						Org:  "example",
+						Name: "group1",
+					},
+				},
+			},
+			getPoliciesFilteredResult: []Policy{allowPolicy, denyPolicy, otherActionPolicy},
+			getAttachedGroupsResult:   []Group{group},
+			getAttachedUsersResult:    []User{user2},
+			getAttachedPoliciesResult: []Policy{allowPolicy},
+			getGroupMembersResult:     []User{user1, user2},
+			effectiveStatementsByUser: map[string][]Statement{
+				"user1": append(append([]Statement{}, *allowPolicy.Statements...), *denyPolicy.Statements...),
+				"user2": *allowPolicy.Statements,
+			},
+		},
+		"OkCaseNoPolicies": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			action:   "product:DoAction",
+			resource: "urn:ews:product:instance:resource/resource1",
+			expectedPrincipals: &AuthorizedPrincipals{
+				Action:   "product:DoAction",
+				Resource: "urn:ews:product:instance:resource/resource1",
+				Users:    []string{},
+				Groups:   []GroupIdentity{},
+			},
+			getPoliciesFilteredResult: []Policy{otherActionPolicy},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			action:   "product:DoAction",
+			resource: "urn:ews:product:instance:resource/resource1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to retrieve authorized principals. Only admin users can do it",
+			},
+		},
+		"ErrorCaseInvalidAction": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			action:   "product:Do*",
+			resource: "urn:ews:product:instance:resource/resource1",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter action product:Do*. Action parameter can't be a prefix",
+			},
+		},
+		"ErrorCaseResourcePrefix": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			action:   "product:DoAction",
+			resource: "urn:ews:product:instance:resource/*",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter resource urn:ews:product:instance:resource/*. Urn prefixes are not allowed here",
+			},
+		},
+		"ErrorCaseGetPoliciesFilteredDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			action:   "product:DoAction",
+			resource: "urn:ews:product:instance:resource/resource1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getPoliciesFilteredError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseGetEffectiveStatementsDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			action:   "product:DoAction",
+			resource: "urn:ews:product:instance:resource/resource1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getPoliciesFilteredResult: []Policy{allowPolicy},
+			getAttachedUsersResult:    []User{user1},
+			getEffectiveStatementsError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = test.getPoliciesFilteredResult
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][2] = test.getPoliciesFilteredError
+		testRepo.ArgsOut[GetAttachedGroupsMethod][0] = test.getAttachedGroupsResult
+		testRepo.ArgsOut[GetAttachedUsersMethod][0] = test.getAttachedUsersResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = test.getGroupMembersResult
+		effectiveStatementsByUser := test.effectiveStatementsByUser
+		effectiveStatementsError := test.getEffectiveStatementsError
+		testRepo.SpecialFuncs[GetEffectiveStatementsMethod] = func(externalID string) ([]EffectiveStatement, error) {
+			if effectiveStatementsError != nil {
+				return nil, effectiveStatementsError
+			}
+			statements := []EffectiveStatement{}
+			for _, statement := range effectiveStatementsByUser[externalID] {
+				statements = append(statements, EffectiveStatement{Statement: statement})
+			}
+			return statements, nil
+		}
+
+		principals, err := testAPI.GetAuthorizedPrincipals(test.requestInfo, test.action, test.resource)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedPrincipals, principals)
+	}
+}
+
+func TestGetAuthorizedExternalResourcesForUser(t *testing.T) {
+	users := map[string]*User{
+		"service1": {
+			ID:         "SERVICE-ID",
+			ExternalID: "service1",
+			Urn:        CreateUrn("", RESOURCE_USER, "/services/", "service1"),
+		},
+		"user1": {
+			ID:         "USER-ID",
+			ExternalID: "user1",
+			Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+		},
+	}
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// User to check
+		externalID string
+		// Resource urns that user wants to access
+		resourceUrns []string
+		// Action to do
+		action string
+		// Expected allowed resources
+		expectedResources []string
+		// Error to compare when we expect an error
+		wantError error
+		// Statements of all users
+		statements []Statement
+	}{
+		"OktestCaseAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "service1",
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+				"urn:ews:product:instance:resource/path2/resource",
+			},
+			expectedResources: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{USER_ACTION_GET_USER, USER_ACTION_CHECK_AUTHORIZATION},
+					Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+				},
+				{
+					Effect:    "allow",
+					Actions:   []string{"product:DoAction"},
+					Resources: []string{"urn:ews:product:instance:resource/path1/*"},
+				},
+			},
+		},
+		"OktestCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+				"urn:ews:product:instance:resource/path2/resource",
+			},
+			expectedResources: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"product:DoAction"},
+					Resources: []string{"urn:ews:product:instance:resource/path1/*"},
+				},
+			},
+		},
+		"OktestCaseUserWithoutPermissions": {
+			requestInfo: RequestInfo{
+				Identifier: "service1",
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			expectedResources: []string{},
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{USER_ACTION_GET_USER, USER_ACTION_CHECK_AUTHORIZATION},
+					Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+				},
+			},
+		},
+		"ErrortestCaseCheckNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "service1",
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			wantError: &Error{
+				Code: UNAUTHORIZED_RESOURCES_ERROR,
+				Message: fmt.Sprintf("User with externalId service1 is not allowed to access to resource %v",
+					CreateUrn("", RESOURCE_USER, "/path/", "user1")),
+			},
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{USER_ACTION_GET_USER, "product:DoAction"},
+					Resources: []string{"urn:*"},
+				},
+			},
+		},
+		"ErrortestCaseInvalidResourceWithPrefix": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:*",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter resource urn:*. Urn prefixes are not allowed here",
+			},
+		},
+		"ErrortestCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user2",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			if user, ok := users[id]; ok {
+				return user, nil
+			}
+			return nil, &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			}
+		}
+		statements := test.statements
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = []Policy{
+			{
+				ID:         "POLICY-USER-ID",
+				Urn:        CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+				Statements: &statements,
+			},
+		}
+
+		resources, err := testAPI.GetAuthorizedExternalResourcesForUser(test.requestInfo, test.externalID, test.action, test.resourceUrns)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResources, resources)
+		if err == nil && testRepo.ArgsIn[GetEffectiveStatementsMethod][0] != test.externalID {
+			t.Errorf("Test %v failed. Received different user identifiers (wanted:%v / received:%v)",
+				n, test.externalID, testRepo.ArgsIn[GetEffectiveStatementsMethod][0])
+		}
+	}
+}
+
+func TestGetAuthorizedExternalResourcesBatch(t *testing.T) {
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// Actions and resources that user wants to access
+		checks []AuthorizationCheck
+		// Expected allowed resources
+		expectedResults []AuthorizationCheckResult
+		// Error to compare when we expect an error
+		wantError error
+		// GetUserByExternalID Method Out Arguments
+		getUserByExternalIDResult *User
+		getUserByExternalIDError  error
+		// GetGroupsByUserID Method Out Arguments
+		getGroupsByUserIDResult []Group
+		getGroupsByUserIDError  error
+		// GetAttachedPolicies Method Out Arguments
+		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesError  error
+	}{
+		"OktestCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "admin",
+				Admin:      true,
+			},
+			checks: []AuthorizationCheck{
+				{
+					Action:    "product:View",
+					Resources: []string{"urn:ews:product:instance:resource/res1"},
+				},
+				{
+					Action:    "product:Delete",
+					Resources: []string{"urn:ews:product:instance:resource/res1", "urn:ews:product:instance:resource/res2"},
+				},
+			},
+			expectedResults: []AuthorizationCheckResult{
+				{
+					Action:           "product:View",
+					ResourcesAllowed: []string{"urn:ews:product:instance:resource/res1"},
+				},
+				{
+					Action:           "product:Delete",
+					ResourcesAllowed: []string{"urn:ews:product:instance:resource/res1", "urn:ews:product:instance:resource/res2"},
+				},
+			},
+		},
+		"OktestCaseSeveralActions": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			checks: []AuthorizationCheck{
+				{
+					Action:    "product:View",
+					Resources: []string{"urn:ews:product:instance:resource/res1", "urn:ews:product:instance:resource/res2"},
+				},
+				{
+					Action:    "product:Edit",
+					Resources: []string{"urn:ews:product:instance:resource/res1", "urn:ews:product:instance:resource/res2"},
+				},
+				{
+					Action:    "product:Delete",
+					Resources: []string{"urn:ews:product:instance:resource/res1", "urn:ews:product:instance:resource/res2"},
+				},
+			},
+			expectedResults: []AuthorizationCheckResult{
+				{
+					Action:           "product:View",
+					ResourcesAllowed: []string{"urn:ews:product:instance:resource/res1", "urn:ews:product:instance:resource/res2"},
+				},
+				{
+					Action:           "product:Edit",
+					ResourcesAllowed: []string{"urn:ews:product:instance:resource/res1"},
+				},
+				{
+					Action:           "product:Delete",
+					ResourcesAllowed: []string{},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:  "123456",
+				Urn: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:  "GROUP-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								"product:View",
+								"product:Edit",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/*",
+							},
+						},
+						{
+							Effect: "deny",
+							Actions: []string{
+								"product:Edit",
+							},
+							Resources: []string{
+								"urn:ews:product:instance:resource/res2",
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrortestCaseEmptyChecks": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter checks, number of checks must be between 1 and 100",
+			},
+		},
+		"ErrortestCaseInvalidCheck": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			checks: []AuthorizationCheck{
+				{
+					Action:    "product:View",
+					Resources: []string{"urn:ews:product:instance:resource/res1"},
+				},
+				{
+					Action:    "product:View",
+					Resources: []string{"urn:*"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter resource urn:*. Urn prefixes are not allowed here",
+			},
+		},
+		"ErrortestCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			checks: []AuthorizationCheck{
+				{
+					Action:    "product:View",
+					Resources: []string{"urn:ews:product:instance:resource/res1"},
+				},
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Authenticated user with externalId 123456 not found. Unable to retrieve permissions.",
+			},
+			getUserByExternalIDError: &database.Error{
+				Code: database.USER_NOT_FOUND,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		// Count how many times user statements are retrieved
+		statementLoads := 0
+		testRepo.SpecialFuncs[GetUserByExternalIDMethod] = func(id string) (*User, error) {
+			statementLoads++
+			if test.getUserByExternalIDError != nil {
+				return nil, test.getUserByExternalIDError
+			}
+			return test.getUserByExternalIDResult, nil
+		}
+
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][1] = test.getGroupsByUserIDError
+
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
+
+		results, err := testAPI.GetAuthorizedExternalResourcesBatch(test.requestInfo, test.checks)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResults, results)
+		if err == nil && !test.requestInfo.Admin && statementLoads != 1 {
+			t.Errorf("Test %v failed. User statements retrieved %v times, wanted 1", n, statementLoads)
+		}
+	}
+}
+
 // Test for aux methods of Foulkon
 
 func TestGetAuthorizedResources(t *testing.T) {
@@ -563,6 +1340,8 @@ func TestGetAuthorizedResources(t *testing.T) {
 		resourcesAuthorized []Resource
 		// Error to compare when we expect an error
 		wantError error
+		// Decision counted in authorization metrics
+		expectedDecision string
 		// GetUserByExternalID Method Out Arguments
 		getUserByExternalIDResult *User
 		getUserByExternalIDError  error
@@ -574,6 +1353,7 @@ func TestGetAuthorizedResources(t *testing.T) {
 		getAttachedPoliciesError  error
 	}{
 		"OKtestCaseAdmin": {
+			expectedDecision: METRIC_DECISION_ALLOWED,
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      true,
@@ -594,6 +1374,7 @@ func TestGetAuthorizedResources(t *testing.T) {
 			},
 		},
 		"ErrortestCaseGetRestrictions": {
+			expectedDecision: METRIC_DECISION_ERROR,
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
@@ -622,6 +1403,7 @@ func TestGetAuthorizedResources(t *testing.T) {
 			},
 		},
 		"ErrortestCaseNotAllowedResources": {
+			expectedDecision: METRIC_DECISION_DENIED,
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
@@ -650,6 +1432,7 @@ func TestGetAuthorizedResources(t *testing.T) {
 			},
 		},
 		"OKtestCaseResourcesFiltered": {
+			expectedDecision: METRIC_DECISION_ALLOWED,
 			requestInfo: RequestInfo{
 				Identifier: "123456",
 				Admin:      false,
@@ -701,6 +1484,7 @@ func TestGetAuthorizedResources(t *testing.T) {
 			},
 		},
 		"OKtestCaseResourcesFilteredReturnEmpty": {
+			expectedDecision: METRIC_DECISION_DENIED,
 			// This test case checks if user has access to groups in /path2/ prefix, but there are groups
 			// only in /path/, so we expect a empty slice of groups authorized
 			requestInfo: RequestInfo{
@@ -764,8 +1548,13 @@ func TestGetAuthorizedResources(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][1] = test.getAttachedPoliciesError
 
+		decisions := authorizationDecisions.Value(test.action, test.expectedDecision)
 		authorizedResources, err := testAPI.getAuthorizedResources(test.requestInfo, test.resourceUrn, test.action, test.resourcesToAuthorize)
 		checkMethodResponse(t, n, test.wantError, err, test.resourcesAuthorized, authorizedResources)
+		if received := authorizationDecisions.Value(test.action, test.expectedDecision) - decisions; received != 1 {
+			t.Errorf("Test %v failed. Received different %v decisions counted (wanted:1 / received:%v)",
+				n, test.expectedDecision, received)
+		}
 		if !test.requestInfo.Admin {
 			// Check received authenticated user in method GetUserByExternalID
 			if testRepo.ArgsIn[GetUserByExternalIDMethod][0] != test.requestInfo.Identifier {
@@ -785,6 +1574,8 @@ func TestGetRestrictions(t *testing.T) {
 		resourceUrn string
 		// Action to do
 		action string
+		// Request context to evaluate conditions
+		context RequestContext
 		// Expected Restrictions
 		expectedRestrictions *Restrictions
 		// Error to compare when we expect an error
@@ -993,6 +1784,86 @@ func TestGetRestrictions(t *testing.T) {
 					},
 				},
 			}},
+		"OkTestCaseConditions": {
+			authUserID:  "AuthUserID",
+			resourceUrn: GetUrnPrefix("example", RESOURCE_GROUP, "/path"),
+			action:      GROUP_ACTION_GET_GROUP,
+			context: RequestContext{
+				SourceIP: "10.1.2.3",
+				Attributes: map[string]string{
+					"Department": "dev",
+				},
+			},
+			expectedRestrictions: &Restrictions{
+				AllowedUrnPrefixes: []string{
+					GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID: "AuthUserID",
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID: "GROUP-USER-ID",
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:  "POLICY-USER-ID",
+					Urn: CreateUrn("example", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
+							},
+							Conditions: []Condition{
+								{
+									Operator: CONDITION_OPERATOR_IP_ADDRESS,
+									Key:      CONDITION_KEY_SOURCE_IP,
+									Values:   []string{"10.0.0.0/8"},
+								},
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_GROUP, "/path2/"),
+							},
+							Conditions: []Condition{
+								{
+									Operator: CONDITION_OPERATOR_STRING_EQUALS,
+									Key:      "Department",
+									Values:   []string{"sales"},
+								},
+							},
+						},
+						{
+							Effect: "deny",
+							Actions: []string{
+								GROUP_ACTION_GET_GROUP,
+							},
+							Resources: []string{
+								GetUrnPrefix("example", RESOURCE_GROUP, "/path1/"),
+							},
+							Conditions: []Condition{
+								{
+									Operator: CONDITION_OPERATOR_DATE_BEFORE,
+									Key:      CONDITION_KEY_CURRENT_TIME,
+									Values:   []string{"2000-01-01T00:00:00Z"},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for n, test := range testcases {
@@ -1009,8 +1880,13 @@ func TestGetRestrictions(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = test.getAttachedPoliciesError
 
-		restrictions, err := testAPI.getRestrictions(test.authUserID, test.action, test.resourceUrn)
+		restrictions, err := testAPI.getRestrictions(test.authUserID, test.action, test.resourceUrn, test.context)
 		checkMethodResponse(t, n, test.wantError, err, test.expectedRestrictions, restrictions)
+		if test.wantError == nil && testRepo.ArgsIn[GetEffectiveStatementsMethod][0] != test.authUserID {
+			t.Errorf("Test %v failed. Received different user identifiers (wanted:%v / received:%v)",
+				n, test.authUserID, testRepo.ArgsIn[GetEffectiveStatementsMethod][0])
+			continue
+		}
 		if test.wantError == nil && testRepo.ArgsIn[GetUserByExternalIDMethod][0] != test.authUserID {
 			t.Errorf("Test %v failed. Received different user identifiers (wanted:%v / received:%v)",
 				n, test.authUserID, testRepo.ArgsIn[GetUserByExternalIDMethod][0])
@@ -1340,6 +2216,201 @@ func TestIsActionContained(t *testing.T) {
 	}
 }
 
+func TestIsConditionSatisfied(t *testing.T) {
+	testcases := map[string]struct {
+		condition        Condition
+		context          RequestContext
+		expectedResponse bool
+	}{
+		"OkCaseIPInRange": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_IP_ADDRESS,
+				Key:      CONDITION_KEY_SOURCE_IP,
+				Values:   []string{"192.168.1.1", "10.0.0.0/8"},
+			},
+			context: RequestContext{
+				SourceIP: "10.1.2.3",
+			},
+			expectedResponse: true,
+		},
+		"OkCaseIPOutOfRange": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_IP_ADDRESS,
+				Key:      CONDITION_KEY_SOURCE_IP,
+				Values:   []string{"10.0.0.0/8"},
+			},
+			context: RequestContext{
+				SourceIP: "11.1.2.3",
+			},
+			expectedResponse: false,
+		},
+		"OkCaseIPMissing": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_IP_ADDRESS,
+				Key:      CONDITION_KEY_SOURCE_IP,
+				Values:   []string{"10.0.0.0/8"},
+			},
+			expectedResponse: false,
+		},
+		"OkCaseDateBefore": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_DATE_BEFORE,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"2016-06-01T00:00:00Z"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: true,
+		},
+		"OkCaseDateAfter": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_DATE_AFTER,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"2016-06-01T00:00:00Z"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 0, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: false,
+		},
+		"OkCaseTimeOfDay": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"08:00-18:00"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 8, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: true,
+		},
+		"OkCaseTimeOfDayEndExcluded": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"08:00-18:00"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 18, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: false,
+		},
+		"OkCaseTimeOfDayOtherTimeZone": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"08:00-18:00"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 9, 0, 0, 0, time.FixedZone("UTC+2", 2*60*60)),
+			},
+			expectedResponse: false,
+		},
+		"OkCaseTimeOfDayCrossingMidnight": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"22:00-06:00"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 2, 30, 0, 0, time.UTC),
+			},
+			expectedResponse: true,
+		},
+		"OkCaseTimeOfDayOutsideCrossingMidnight": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"22:00-06:00"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: false,
+		},
+		"OkCaseWeekday": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_WEEKDAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"Saturday", "Sunday"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: true,
+		},
+		"OkCaseWeekdayNoMatch": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_WEEKDAY,
+				Key:      CONDITION_KEY_CURRENT_TIME,
+				Values:   []string{"Monday"},
+			},
+			context: RequestContext{
+				CurrentTime: time.Date(2016, 5, 1, 12, 0, 0, 0, time.UTC),
+			},
+			expectedResponse: false,
+		},
+		"OkCaseStringEquals": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_STRING_EQUALS,
+				Key:      "Department",
+				Values:   []string{"sales", "dev"},
+			},
+			context: RequestContext{
+				Attributes: map[string]string{
+					"Department": "dev",
+				},
+			},
+			expectedResponse: true,
+		},
+		"OkCaseStringLike": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_STRING_LIKE,
+				Key:      "Department",
+				Values:   []string{"dev*.team"},
+			},
+			context: RequestContext{
+				Attributes: map[string]string{
+					"Department": "devops.team",
+				},
+			},
+			expectedResponse: true,
+		},
+		"OkCaseStringLikeNoMatch": {
+			condition: Condition{
+				Operator: CONDITION_OPERATOR_STRING_LIKE,
+				Key:      "Department",
+				Values:   []string{"dev*.team"},
+			},
+			context: RequestContext{
+				Attributes: map[string]string{
+					"Department": "devops-team",
+				},
+			},
+			expectedResponse: false,
+		},
+		"OkCaseUnknownOperator": {
+			condition: Condition{
+				Operator: "Other",
+				Key:      "Department",
+				Values:   []string{"dev"},
+			},
+			context: RequestContext{
+				Attributes: map[string]string{
+					"Department": "dev",
+				},
+			},
+			expectedResponse: false,
+		},
+	}
+
+	for n, test := range testcases {
+		isSatisfied := isConditionSatisfied(test.condition, test.context)
+		checkMethodResponse(t, n, nil, nil, test.expectedResponse, isSatisfied)
+	}
+}
+
 func TestIsResourceContained(t *testing.T) {
 	testcases := map[string]struct {
 		resource         string
@@ -1390,6 +2461,61 @@ func TestIsFullUrn(t *testing.T) {
 	}
 }
 
+func TestGetUniqueEffectiveStatements(t *testing.T) {
+	group := &GroupIdentity{Org: "example", Name: "group1"}
+	group2 := &GroupIdentity{Org: "example", Name: "group2"}
+	policy := PolicyIdentity{Org: "example", Name: "policy1"}
+	policy2 := PolicyIdentity{Org: "example", Name: "policy2"}
+	allowStatement := Statement{
+		Effect:    "allow",
+		Actions:   []string{"product:DoAction"},
+		Resources: []string{"urn:ews:product:instance:resource/*"},
+	}
+	denyStatement := Statement{
+		Effect:    "deny",
+		Actions:   []string{"product:DoAction"},
+		Resources: []string{"urn:ews:product:instance:resource/private/*"},
+	}
+	testcases := map[string]struct {
+		effectiveStatements []EffectiveStatement
+		expectedResponse    []EffectiveStatement
+	}{
+		"OktestCaseDifferentPolicies": {
+			effectiveStatements: []EffectiveStatement{
+				{Group: group, Policy: policy, Statement: allowStatement},
+				{Policy: policy2, Statement: denyStatement},
+			},
+			expectedResponse: []EffectiveStatement{
+				{Group: group, Policy: policy, Statement: allowStatement},
+				{Policy: policy2, Statement: denyStatement},
+			},
+		},
+		"OktestCasePolicyAttachedSeveralTimes": {
+			effectiveStatements: []EffectiveStatement{
+				{Group: group, Policy: policy, Statement: allowStatement},
+				{Group: group, Policy: policy, Statement: denyStatement},
+				{Group: group2, Policy: policy, Statement: allowStatement},
+				{Group: group2, Policy: policy, Statement: denyStatement},
+				{Policy: policy, Statement: allowStatement},
+				{Policy: policy, Statement: denyStatement},
+			},
+			expectedResponse: []EffectiveStatement{
+				{Group: group, Policy: policy, Statement: allowStatement},
+				{Group: group, Policy: policy, Statement: denyStatement},
+			},
+		},
+		"OktestCaseEmpty": {
+			effectiveStatements: []EffectiveStatement{},
+			expectedResponse:    []EffectiveStatement{},
+		},
+	}
+
+	for n, test := range testcases {
+		unique := getUniqueEffectiveStatements(test.effectiveStatements)
+		checkMethodResponse(t, n, nil, nil, test.expectedResponse, unique)
+	}
+}
+
 func TestInsertRestriction(t *testing.T) {
 	testcases := map[string]struct {
 		resource struct {
@@ -2135,6 +3261,34 @@ func TestFilterResources(t *testing.T) {
 	}
 }
 
+func TestMetricAction(t *testing.T) {
+	testcases := map[string]struct {
+		action           string
+		expectedResponse string
+	}{
+		"OkCaseIAMAction": {
+			action:           USER_ACTION_GET_USER,
+			expectedResponse: USER_ACTION_GET_USER,
+		},
+		"OkCaseExternalAction": {
+			action:           "example:get",
+			expectedResponse: METRIC_ACTION_OTHER,
+		},
+		"OkCaseIAMActionPrefix": {
+			action:           "iam:*",
+			expectedResponse: METRIC_ACTION_OTHER,
+		},
+		"OkCaseInvalidAction": {
+			action:           "&%",
+			expectedResponse: METRIC_ACTION_OTHER,
+		},
+	}
+
+	for n, test := range testcases {
+		checkMethodResponse(t, n, nil, nil, test.expectedResponse, metricAction(test.action))
+	}
+}
+
 func TestIsAllowedResource(t *testing.T) {
 	testcases := map[string]struct {
 		resource     Resource
@@ -2248,3 +3402,326 @@ func TestIsAllowedResource(t *testing.T) {
 		checkMethodResponse(t, n, nil, nil, test.expectedData, response)
 	}
 }
+
+func TestExplainAuthorization(t *testing.T) {
+	testcases := map[string]struct {
+		// Authenticated user
+		requestInfo RequestInfo
+		// User to explain
+		externalID string
+		// Resource urns that user wants to access
+		resourceUrns []string
+		// Action to do
+		action string
+		// Expected explanation
+		expectedExplanation *AuthorizationExplanation
+		// Error to compare when we expect an error
+		wantError error
+		// GetUserByExternalID Method Out Arguments
+		getUserByExternalIDResult *User
+		getUserByExternalIDError  error
+		// GetGroupsByUserID Method Out Arguments
+		getGroupsByUserIDResult []Group
+		getGroupsByUserIDError  error
+		// GetAttachedPolicies Method Out Arguments
+		getAttachedPoliciesResult []Policy
+		getAttachedPoliciesError  error
+		// GetAttachedUserPolicies Method Out Arguments
+		getAttachedUserPoliciesResult []Policy
+	}{
+		"OktestCaseUserPolicy": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			expectedExplanation: &AuthorizationExplanation{
+				ExternalID: "user1",
+				Action:     "product:DoAction",
+				Groups:     []GroupIdentity{},
+				Restrictions: &Restrictions{
+					AllowedUrnPrefixes: []string{},
+					AllowedFullUrns:    []string{"urn:ews:product:instance:resource/path1/resource"},
+					DeniedUrnPrefixes:  []string{},
+					DeniedFullUrns:     []string{},
+				},
+				Decisions: []ResourceDecision{
+					{
+						Urn:                 "urn:ews:product:instance:resource/path1/resource",
+						Allowed:             true,
+						DecisionType:        DECISION_ALLOWED_FULL_URN,
+						DecisionRestriction: "urn:ews:product:instance:resource/path1/resource",
+						Statements: []StatementEvaluation{
+							{
+								Policy: PolicyIdentity{
+									Org:  "example",
+									Name: "policy2",
+								},
+								Statement: Statement{
+									Effect:    "allow",
+									Actions:   []string{"product:DoAction"},
+									Resources: []string{"urn:ews:product:instance:resource/path1/resource"},
+								},
+								MatchedResources: []string{"urn:ews:product:instance:resource/path1/resource"},
+							},
+						},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "user1",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			getAttachedUserPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Org:  "example",
+					Name: "policy2",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policy2"),
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{"product:DoAction"},
+							Resources: []string{"urn:ews:product:instance:resource/path1/resource"},
+						},
+					},
+				},
+			},
+		},
+		"ErrortestCaseInvalidAction": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "valid::Action",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "No regex match in action: valid::Action",
+			},
+		},
+		"ErrortestCaseInvalidResourceWithPrefix": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "product:DoSomething",
+			resourceUrns: []string{
+				"urn:*",
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter resource urn:*. Urn prefixes are not allowed here",
+			},
+		},
+		"ErrortestCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "product:DoSomething",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			getUserByExternalIDError: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrortestCaseGetGroupsByUserID": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "product:DoSomething",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resource",
+			},
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "user1",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			getGroupsByUserIDError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"OktestCase": {
+			requestInfo: RequestInfo{
+				Admin: true,
+			},
+			externalID: "user1",
+			action:     "product:DoAction",
+			resourceUrns: []string{
+				"urn:ews:product:instance:resource/path1/resourceAllow",
+				"urn:ews:product:instance:resource/path1/resourceDeny",
+				"urn:ews:product:instance:resource/path2/resource",
+			},
+			expectedExplanation: &AuthorizationExplanation{
+				ExternalID: "user1",
+				Action:     "product:DoAction",
+				Groups: []GroupIdentity{
+					{
+						Org:  "example",
+						Name: "group1",
+					},
+				},
+				Restrictions: &Restrictions{
+					AllowedUrnPrefixes: []string{"urn:ews:product:instance:resource/path1*"},
+					AllowedFullUrns:    []string{},
+					DeniedUrnPrefixes:  []string{},
+					DeniedFullUrns:     []string{"urn:ews:product:instance:resource/path1/resourceDeny"},
+				},
+				Decisions: []ResourceDecision{
+					{
+						Urn:                 "urn:ews:product:instance:resource/path1/resourceAllow",
+						Allowed:             true,
+						DecisionType:        DECISION_ALLOWED_URN_PREFIX,
+						DecisionRestriction: "urn:ews:product:instance:resource/path1*",
+						Statements: []StatementEvaluation{
+							{
+								Group: &GroupIdentity{
+									Org:  "example",
+									Name: "group1",
+								},
+								Policy: PolicyIdentity{
+									Org:  "example",
+									Name: "policy1",
+								},
+								Statement: Statement{
+									Effect:    "allow",
+									Actions:   []string{"product:DoAction"},
+									Resources: []string{"urn:ews:product:instance:resource/path1*"},
+								},
+								MatchedResources: []string{"urn:ews:product:instance:resource/path1*"},
+							},
+						},
+					},
+					{
+						Urn:                 "urn:ews:product:instance:resource/path1/resourceDeny",
+						Allowed:             false,
+						DecisionType:        DECISION_DENIED_FULL_URN,
+						DecisionRestriction: "urn:ews:product:instance:resource/path1/resourceDeny",
+						Statements: []StatementEvaluation{
+							{
+								Group: &GroupIdentity{
+									Org:  "example",
+									Name: "group1",
+								},
+								Policy: PolicyIdentity{
+									Org:  "example",
+									Name: "policy1",
+								},
+								Statement: Statement{
+									Effect:    "allow",
+									Actions:   []string{"product:DoAction"},
+									Resources: []string{"urn:ews:product:instance:resource/path1*"},
+								},
+								MatchedResources: []string{"urn:ews:product:instance:resource/path1*"},
+							},
+							{
+								Group: &GroupIdentity{
+									Org:  "example",
+									Name: "group1",
+								},
+								Policy: PolicyIdentity{
+									Org:  "example",
+									Name: "policy1",
+								},
+								Statement: Statement{
+									Effect:    "deny",
+									Actions:   []string{"product:*"},
+									Resources: []string{"urn:ews:product:instance:resource/path1/resourceDeny"},
+								},
+								MatchedResources: []string{"urn:ews:product:instance:resource/path1/resourceDeny"},
+							},
+						},
+					},
+					{
+						Urn:                 "urn:ews:product:instance:resource/path2/resource",
+						Allowed:             false,
+						DecisionType:        DECISION_IMPLICIT_DENY,
+						DecisionRestriction: "",
+						Statements:          []StatementEvaluation{},
+					},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "123456",
+				ExternalID: "user1",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Org:  "example",
+					Name: "group1",
+					Urn:  CreateUrn("example", RESOURCE_GROUP, "/path/", "group1"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Org:  "example",
+					Name: "policy1",
+					Urn:  CreateUrn("example", RESOURCE_POLICY, "/path/", "policy1"),
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{"product:DoAction"},
+							Resources: []string{"urn:ews:product:instance:resource/path1*"},
+						},
+						{
+							Effect:    "deny",
+							Actions:   []string{"product:*"},
+							Resources: []string{"urn:ews:product:instance:resource/path1/resourceDeny"},
+						},
+						{
+							Effect:    "allow",
+							Actions:   []string{"product:OtherAction"},
+							Resources: []string{"urn:ews:product:instance:resource/path2*"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = test.getUserByExternalIDError
+
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][2] = test.getGroupsByUserIDError
+
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][2] = test.getAttachedPoliciesError
+
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = test.getAttachedUserPoliciesResult
+
+		explanation, err := testAPI.ExplainAuthorization(test.requestInfo, test.externalID, test.action, test.resourceUrns)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedExplanation, explanation)
+
+		// Statements must be the effective statements used to authorize
+		if err == nil && testRepo.ArgsIn[GetEffectiveStatementsMethod][0] != test.externalID {
+			t.Errorf("Test %v failed. Received different effective statements user (wanted:%v / received:%v)",
+				n, test.externalID, testRepo.ArgsIn[GetEffectiveStatementsMethod][0])
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package api
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// TYPE DEFINITIONS
+
+// Cache of effective statements by user external identifier. A nil cache is disabled,
+// so nothing is stored and counters stay at zero.
+type StatementCache struct {
+	// Counters first to keep them 64-bit aligned for atomic operations
+	hits    uint64
+	misses  uint64
+	ttl     time.Duration
+	mutex   sync.RWMutex
+	entries map[string]statementCacheEntry
+	// Incremented on every invalidation to discard values retrieved before it
+	generation uint64
+}
+
+type statementCacheEntry struct {
+	statements []EffectiveStatement
+	expiration time.Time
+}
+
+// NewStatementCache returns a cache whose entries expire after ttl, or nil (disabled cache) if ttl isn't positive
+func NewStatementCache(ttl time.Duration) *StatementCache {
+	if ttl <= 0 {
+		return nil
+	}
+	return &StatementCache{
+		ttl:     ttl,
+		entries: make(map[string]statementCacheEntry),
+	}
+}
+
+// Hits returns the number of lookups served from cache
+func (c *StatementCache) Hits() uint64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.hits)
+}
+
+// Misses returns the number of lookups not found in cache or expired
+func (c *StatementCache) Misses() uint64 {
+	if c == nil {
+		return 0
+	}
+	return atomic.LoadUint64(&c.misses)
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve statements for user if they are cached and not expired. When they aren't, it returns
+// the generation that must be used to store them.
+func (c *StatementCache) get(externalID string) ([]EffectiveStatement, uint64, bool) {
+	if c == nil {
+		return nil, 0, false
+	}
+	c.mutex.RLock()
+	entry, ok := c.entries[externalID]
+	generation := c.generation
+	c.mutex.RUnlock()
+
+	if ok && time.Now().Before(entry.expiration) {
+		atomic.AddUint64(&c.hits, 1)
+		return entry.statements, generation, true
+	}
+	atomic.AddUint64(&c.misses, 1)
+	return nil, generation, false
+}
+
+// Store statements for user, unless the cache was invalidated after the generation was retrieved
+func (c *StatementCache) set(externalID string, statements []EffectiveStatement, generation uint64) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if generation != c.generation {
+		return
+	}
+	c.entries[externalID] = statementCacheEntry{
+		statements: statements,
+		expiration: time.Now().Add(c.ttl),
+	}
+}
+
+// Remove statements cached for user
+func (c *StatementCache) invalidate(externalID string) {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	delete(c.entries, externalID)
+}
+
+// Remove all cached statements
+func (c *StatementCache) invalidateAll() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.generation++
+	c.entries = make(map[string]statementCacheEntry)
+}
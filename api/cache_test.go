@@ -0,0 +1,150 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+func TestStatementCache(t *testing.T) {
+	statements := []EffectiveStatement{
+		{
+			Policy: PolicyIdentity{
+				Org:  "example",
+				Name: "policy1",
+			},
+			Statement: Statement{
+				Effect: "allow",
+				Actions: []string{
+					USER_ACTION_GET_USER,
+				},
+				Resources: []string{
+					GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// Operations over a new cache
+		operations func(c *StatementCache)
+		// Expected results
+		expectedStatements []EffectiveStatement
+		expectedFound      bool
+		expectedHits       uint64
+		expectedMisses     uint64
+	}{
+		"OkCaseHit": {
+			operations: func(c *StatementCache) {
+				_, generation, _ := c.get("user1")
+				c.set("user1", statements, generation)
+			},
+			expectedStatements: statements,
+			expectedFound:      true,
+			expectedHits:       1,
+			expectedMisses:     1,
+		},
+		"OkCaseMiss": {
+			operations:     func(c *StatementCache) {},
+			expectedMisses: 1,
+		},
+		"OkCaseInvalidated": {
+			operations: func(c *StatementCache) {
+				_, generation, _ := c.get("user1")
+				c.set("user1", statements, generation)
+				c.invalidate("user1")
+			},
+			expectedMisses: 2,
+		},
+		"OkCaseAllInvalidated": {
+			operations: func(c *StatementCache) {
+				_, generation, _ := c.get("user1")
+				c.set("user1", statements, generation)
+				c.invalidateAll()
+			},
+			expectedMisses: 2,
+		},
+		"OkCaseInvalidatedBeforeSet": {
+			operations: func(c *StatementCache) {
+				_, generation, _ := c.get("user1")
+				c.invalidate("user2")
+				c.set("user1", statements, generation)
+			},
+			expectedMisses: 2,
+		},
+	}
+
+	for n, test := range testcases {
+		cache := NewStatementCache(time.Minute)
+		test.operations(cache)
+		received, _, found := cache.get("user1")
+		checkMethodResponse(t, n, nil, nil, test.expectedFound, found)
+		checkMethodResponse(t, n, nil, nil, test.expectedStatements, received)
+		checkMethodResponse(t, n, nil, nil, test.expectedHits, cache.Hits())
+		checkMethodResponse(t, n, nil, nil, test.expectedMisses, cache.Misses())
+	}
+}
+
+func TestStatementCacheExpiration(t *testing.T) {
+	cache := NewStatementCache(time.Millisecond)
+	_, generation, _ := cache.get("user1")
+	cache.set("user1", []EffectiveStatement{}, generation)
+	time.Sleep(5 * time.Millisecond)
+	if _, _, found := cache.get("user1"); found {
+		t.Error("Test failed. Expired statements were found in cache")
+	}
+}
+
+func TestStatementCacheDisabled(t *testing.T) {
+	cache := NewStatementCache(0)
+	if cache != nil {
+		t.Fatal("Test failed. Cache with no TTL must be disabled")
+	}
+	_, generation, _ := cache.get("user1")
+	cache.set("user1", []EffectiveStatement{}, generation)
+	if _, _, found := cache.get("user1"); found {
+		t.Error("Test failed. Disabled cache returned statements")
+	}
+	checkMethodResponse(t, "Disabled", nil, nil, uint64(0), cache.Misses())
+}
+
+func TestGetEffectiveStatementsWithCache(t *testing.T) {
+	testRepo := makeTestRepo()
+	testAPI := makeTestAPI(testRepo)
+	testAPI.Cache = NewStatementCache(time.Minute)
+
+	policies := []Policy{
+		{
+			ID: "PolicyID",
+			Statements: &[]Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+			},
+		},
+	}
+	testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{ID: "UserID"}
+	testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{{ID: "GroupID"}}
+	testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = policies
+
+	statements, err := testAPI.getEffectiveStatements("user1")
+	checkMethodResponse(t, "FirstCall", nil, err, *policies[0].Statements, statements)
+
+	// Repository errors aren't reached while statements are cached
+	testRepo.ArgsOut[GetUserByExternalIDMethod][1] = &database.Error{
+		Code: database.INTERNAL_ERROR,
+	}
+	statements, err = testAPI.getEffectiveStatements("user1")
+	checkMethodResponse(t, "CachedCall", nil, err, *policies[0].Statements, statements)
+	checkMethodResponse(t, "CachedCall", nil, nil, uint64(1), testAPI.Cache.Hits())
+
+	testAPI.Cache.invalidate("user1")
+	_, err = testAPI.getEffectiveStatements("user1")
+	checkMethodResponse(t, "InvalidatedCall", &Error{Code: UNKNOWN_API_ERROR}, err, nil, nil)
+}
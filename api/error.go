@@ -28,9 +28,19 @@ const (
 	POLICY_IS_ALREADY_ATTACHED_TO_GROUP = "PolicyIsAlreadyAttachedToGroup"
 	POLICY_IS_NOT_ATTACHED_TO_GROUP     = "PolicyIsNotAttachedToGroup"
 
+	// UserPolicies error codes
+	POLICY_IS_ALREADY_ATTACHED_TO_USER = "PolicyIsAlreadyAttachedToUser"
+	POLICY_IS_NOT_ATTACHED_TO_USER     = "PolicyIsNotAttachedToUser"
+
 	// Policy API error codes
 	POLICY_ALREADY_EXIST             = "PolicyAlreadyExist"
 	POLICY_BY_ORG_AND_NAME_NOT_FOUND = "PolicyWithOrgAndNameNotFound"
+	POLICY_VERSION_NOT_FOUND         = "PolicyVersionNotFound"
+
+	// API key error codes
+	API_KEY_NOT_FOUND = "APIKeyNotFound"
+	API_KEY_REVOKED   = "APIKeyRevoked"
+	INVALID_API_KEY   = "InvalidAPIKey"
 
 	// Regex error
 	REGEX_NO_MATCH = "RegexNoMatch"
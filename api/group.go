@@ -100,6 +100,7 @@ func (api AuthAPI) AddGroup(requestInfo RequestInfo, org string, name string, pa
 				}
 			}
 			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group created %+v", createdGroup))
+			api.recordAuditEvent(requestInfo, GROUP_ACTION_CREATE_GROUP, createdGroup.Urn, nil, createdGroup)
 			return createdGroup, nil
 		default: // Unexpected error
 			return nil, &Error{
@@ -321,6 +322,7 @@ func (api AuthAPI) UpdateGroup(requestInfo RequestInfo, org string, name string,
 	}
 
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group updated from %+v to %+v", oldGroup, group))
+	api.recordAuditEvent(requestInfo, GROUP_ACTION_UPDATE_GROUP, group.Urn, oldGroup, group)
 	return group, nil
 
 }
@@ -359,7 +361,11 @@ func (api AuthAPI) RemoveGroup(requestInfo RequestInfo, org string, name string)
 		}
 	}
 
+	// Members of this group may have different statements now
+	api.Cache.invalidateAll()
+
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Group deleted %+v", group))
+	api.recordAuditEvent(requestInfo, GROUP_ACTION_DELETE_GROUP, group.Urn, group, nil)
 	return nil
 }
 
@@ -421,7 +427,11 @@ func (api AuthAPI) AddMember(requestInfo RequestInfo, externalId string, name st
 			Message: dbError.Message,
 		}
 	}
+
+	api.Cache.invalidate(userDB.ExternalID)
+
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v added to group %+v", userDB, groupDB))
+	api.recordAuditEvent(requestInfo, GROUP_ACTION_ADD_MEMBER, groupDB.Urn, nil, auditRelation{Group: groupDB, User: userDB})
 	return nil
 }
 
@@ -484,7 +494,10 @@ func (api AuthAPI) RemoveMember(requestInfo RequestInfo, externalId string, name
 		}
 	}
 
+	api.Cache.invalidate(userDB.ExternalID)
+
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Member %+v removed from group %+v", userDB, groupDB))
+	api.recordAuditEvent(requestInfo, GROUP_ACTION_REMOVE_MEMBER, groupDB.Urn, auditRelation{Group: groupDB, User: userDB}, nil)
 	return nil
 }
 
@@ -543,50 +556,11 @@ func (api AuthAPI) ListMembers(requestInfo RequestInfo, org string, name string,
 }
 
 func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string) error {
-
-	// Check if group exists
-	group, err := api.GetGroupByName(requestInfo, org, name)
-	if err != nil {
-		return err
-	}
-
-	// Check restrictions
-	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_ATTACH_GROUP_POLICY, []Group{*group})
-	if err != nil {
-		return err
-	}
-	if len(groupsFiltered) < 1 {
-		return &Error{
-			Code: UNAUTHORIZED_RESOURCES_ERROR,
-			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, group.Urn),
-		}
-	}
-
-	// Check if policy exists
-	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	group, policy, err := api.checkAttachPolicyToGroup(requestInfo, org, name, policyName)
 	if err != nil {
 		return err
 	}
 
-	// Check existing relationship
-	isAttached, err := api.GroupRepo.IsAttachedToGroup(group.ID, policy.ID)
-	if err != nil {
-		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
-	}
-
-	if isAttached {
-		// Unexpected error
-		return &Error{
-			Code:    POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
-			Message: fmt.Sprintf("Policy: %v is already attached to Group: %v", policy.Name, group.Name),
-		}
-	}
-
 	// Attach Policy to Group
 	err = api.GroupRepo.AttachPolicy(group.ID, policy.ID)
 
@@ -598,56 +572,20 @@ func (api AuthAPI) AttachPolicyToGroup(requestInfo RequestInfo, org string, name
 		}
 	}
 
+	// Members of this group may have different statements now
+	api.Cache.invalidateAll()
+
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v attached to group %+v", policy, group))
+	api.recordAuditEvent(requestInfo, GROUP_ACTION_ATTACH_GROUP_POLICY, group.Urn, nil, auditRelation{Group: group, Policy: policy})
 	return nil
 }
 
 func (api AuthAPI) DetachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string) error {
-
-	// Check if group exists
-	group, err := api.GetGroupByName(requestInfo, org, name)
+	group, policy, err := api.checkDetachPolicyToGroup(requestInfo, org, name, policyName)
 	if err != nil {
 		return err
 	}
 
-	// Check restrictions
-	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_DETACH_GROUP_POLICY, []Group{*group})
-	if err != nil {
-		return err
-	}
-	if len(groupsFiltered) < 1 {
-		return &Error{
-			Code: UNAUTHORIZED_RESOURCES_ERROR,
-			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, group.Urn),
-		}
-	}
-
-	// Check if policy exists
-	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
-	if err != nil {
-		return err
-	}
-
-	// Check existing relationship
-	isAttached, err := api.GroupRepo.IsAttachedToGroup(group.ID, policy.ID)
-	if err != nil {
-		dbError := err.(*database.Error)
-		return &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
-		}
-	}
-
-	if !isAttached {
-		return &Error{
-			Code: POLICY_IS_NOT_ATTACHED_TO_GROUP,
-			Message: fmt.Sprintf("Policy with org %v and name %v is not attached to group with org %v and name %v",
-				policy.Org, policy.Name, group.Org, group.Name),
-		}
-
-	}
-
 	// Detach Policy to Group
 	err = api.GroupRepo.DetachPolicy(group.ID, policy.ID)
 
@@ -659,7 +597,11 @@ func (api AuthAPI) DetachPolicyToGroup(requestInfo RequestInfo, org string, name
 		}
 	}
 
+	// Members of this group may have different statements now
+	api.Cache.invalidateAll()
+
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v detached from group %+v", policy, group))
+	api.recordAuditEvent(requestInfo, GROUP_ACTION_DETACH_GROUP_POLICY, group.Urn, auditRelation{Group: group, Policy: policy}, nil)
 	return nil
 }
 
@@ -731,3 +673,100 @@ func createGroup(org string, name string, path string) Group {
 
 	return group
 }
+
+// Check that requestInfo can attach the policy to the group, returning both
+func (api AuthAPI) checkAttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string) (*Group, *Policy, error) {
+	// Check if group exists
+	group, err := api.GetGroupByName(requestInfo, org, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_ATTACH_GROUP_POLICY, []Group{*group})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	// Check if policy exists
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check existing relationship
+	isAttached, err := api.GroupRepo.IsAttachedToGroup(group.ID, policy.ID)
+	if err != nil {
+		dbError := err.(*database.Error)
+		return nil, nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if isAttached {
+		// Unexpected error
+		return nil, nil, &Error{
+			Code:    POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+			Message: fmt.Sprintf("Policy: %v is already attached to Group: %v", policy.Name, group.Name),
+		}
+	}
+
+	return group, policy, nil
+}
+
+// Check that requestInfo can detach the policy from the group, returning both
+func (api AuthAPI) checkDetachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string) (*Group, *Policy, error) {
+	// Check if group exists
+	group, err := api.GetGroupByName(requestInfo, org, name)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check restrictions
+	groupsFiltered, err := api.GetAuthorizedGroups(requestInfo, group.Urn, GROUP_ACTION_DETACH_GROUP_POLICY, []Group{*group})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(groupsFiltered) < 1 {
+		return nil, nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, group.Urn),
+		}
+	}
+
+	// Check if policy exists
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check existing relationship
+	isAttached, err := api.GroupRepo.IsAttachedToGroup(group.ID, policy.ID)
+	if err != nil {
+		dbError := err.(*database.Error)
+		return nil, nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if !isAttached {
+		return nil, nil, &Error{
+			Code: POLICY_IS_NOT_ATTACHED_TO_GROUP,
+			Message: fmt.Sprintf("Policy with org %v and name %v is not attached to group with org %v and name %v",
+				policy.Org, policy.Name, group.Org, group.Name),
+		}
+
+	}
+
+	return group, policy, nil
+}
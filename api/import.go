@@ -0,0 +1,806 @@
+package api
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Users, groups and policies with their relations, exported from an organization or from all of them if org
+// is empty. Users don't belong to organizations, so an organization document only has the users that are
+// members of its groups or attached to its policies.
+type ExportDocument struct {
+	Org      string         `json:"org, omitempty"`
+	Users    []ExportUser   `json:"users, omitempty"`
+	Groups   []ExportGroup  `json:"groups, omitempty"`
+	Policies []ExportPolicy `json:"policies, omitempty"`
+}
+
+// User with the policies attached to it
+type ExportUser struct {
+	ExternalID string           `json:"externalId, omitempty"`
+	Path       string           `json:"path, omitempty"`
+	Policies   []PolicyIdentity `json:"policies, omitempty"`
+}
+
+// Group with the external identifiers of its members and the names of the policies attached to it,
+// that belong to the organization of the group
+type ExportGroup struct {
+	Org      string   `json:"org, omitempty"`
+	Name     string   `json:"name, omitempty"`
+	Path     string   `json:"path, omitempty"`
+	Members  []string `json:"members, omitempty"`
+	Policies []string `json:"policies, omitempty"`
+}
+
+type ExportPolicy struct {
+	Org        string      `json:"org, omitempty"`
+	Name       string      `json:"name, omitempty"`
+	Path       string      `json:"path, omitempty"`
+	Statements []Statement `json:"statements, omitempty"`
+}
+
+// Number of elements of a type by what an import did with them
+type ImportCounts struct {
+	Created   int `json:"created, omitempty"`
+	Updated   int `json:"updated, omitempty"`
+	Unchanged int `json:"unchanged, omitempty"`
+	Removed   int `json:"removed, omitempty"`
+	Conflicts int `json:"conflicts, omitempty"`
+}
+
+// Result of an import. Elements of the document in conflict are invalid, duplicated or reference
+// elements that don't exist, and they are skipped with a message that explains why.
+type ImportResult struct {
+	Users         ImportCounts `json:"users, omitempty"`
+	Groups        ImportCounts `json:"groups, omitempty"`
+	Policies      ImportCounts `json:"policies, omitempty"`
+	Members       ImportCounts `json:"members, omitempty"`
+	GroupPolicies ImportCounts `json:"groupPolicies, omitempty"`
+	UserPolicies  ImportCounts `json:"userPolicies, omitempty"`
+	Conflicts     []string     `json:"conflicts, omitempty"`
+}
+
+// Changes done by an import. Repository applies all of them or none.
+type ImportChanges struct {
+	AddedUsers           []User
+	UpdatedUsers         []User
+	RemovedUsers         []User
+	AddedGroups          []Group
+	UpdatedGroups        []Group
+	RemovedGroups        []Group
+	AddedPolicies        []Policy
+	UpdatedPolicies      []Policy
+	RemovedPolicies      []Policy
+	AddedMembers         []ImportMember
+	RemovedMembers       []ImportMember
+	AddedGroupPolicies   []ImportGroupPolicy
+	RemovedGroupPolicies []ImportGroupPolicy
+	AddedUserPolicies    []ImportUserPolicy
+	RemovedUserPolicies  []ImportUserPolicy
+	PolicyVersions       []ImportPolicyVersion
+}
+
+func (c ImportChanges) isEmpty() bool {
+	return len(c.AddedUsers)+len(c.UpdatedUsers)+len(c.RemovedUsers)+
+		len(c.AddedGroups)+len(c.UpdatedGroups)+len(c.RemovedGroups)+
+		len(c.AddedPolicies)+len(c.UpdatedPolicies)+len(c.RemovedPolicies)+
+		len(c.AddedMembers)+len(c.RemovedMembers)+
+		len(c.AddedGroupPolicies)+len(c.RemovedGroupPolicies)+
+		len(c.AddedUserPolicies)+len(c.RemovedUserPolicies) == 0
+}
+
+// Group-Users Relationship
+type ImportMember struct {
+	User  *User
+	Group *Group
+}
+
+// Group-Policies Relationship
+type ImportGroupPolicy struct {
+	Group  *Group
+	Policy *Policy
+}
+
+// User-Policies Relationship
+type ImportUserPolicy struct {
+	User   *User
+	Policy *Policy
+}
+
+// Version of a created or updated policy, stored with the next version number. Previous is the stored
+// policy of updated policies, stored as a version first if the policy doesn't have versions.
+type ImportPolicyVersion struct {
+	Previous *Policy
+	Version  PolicyVersion
+}
+
+// Audit event to record when import changes are applied
+type importEvent struct {
+	action string
+	urn    string
+	before interface{}
+	after  interface{}
+}
+
+// Current state and changes of an import
+type importPlan struct {
+	org     string
+	prune   bool
+	changes ImportChanges
+	result  ImportResult
+	events  []importEvent
+
+	// Author and date of policy versions
+	author   string
+	createAt time.Time
+
+	// Elements after the import, by external id for users and by org and name for groups and policies
+	users    map[string]*User
+	groups   map[string]*Group
+	policies map[string]*Policy
+}
+
+// IMPORT API IMPLEMENTATION
+
+func (api AuthAPI) Export(requestInfo RequestInfo, org string) (*ExportDocument, error) {
+	if err := checkImportParameters(requestInfo, org); err != nil {
+		return nil, err
+	}
+
+	users, _, err := api.UserRepo.GetUsersFiltered(&Filter{})
+	if err != nil {
+		return nil, unknownDBError(err)
+	}
+	groups, _, err := api.GroupRepo.GetGroupsFiltered(org, &Filter{})
+	if err != nil {
+		return nil, unknownDBError(err)
+	}
+	policies, _, err := api.PolicyRepo.GetPoliciesFiltered(org, &Filter{})
+	if err != nil {
+		return nil, unknownDBError(err)
+	}
+
+	document := &ExportDocument{
+		Org:      org,
+		Users:    []ExportUser{},
+		Groups:   []ExportGroup{},
+		Policies: []ExportPolicy{},
+	}
+
+	for _, p := range policies {
+		document.Policies = append(document.Policies, ExportPolicy{
+			Org:        p.Org,
+			Name:       p.Name,
+			Path:       p.Path,
+			Statements: *p.Statements,
+		})
+	}
+
+	// Users related to groups of the organization
+	related := map[string]bool{}
+	for _, g := range groups {
+		members, _, err := api.GroupRepo.GetGroupMembers(g.ID, &Filter{})
+		if err != nil {
+			return nil, unknownDBError(err)
+		}
+		attached, _, err := api.GroupRepo.GetAttachedPolicies(g.ID, &Filter{})
+		if err != nil {
+			return nil, unknownDBError(err)
+		}
+		group := ExportGroup{
+			Org:      g.Org,
+			Name:     g.Name,
+			Path:     g.Path,
+			Members:  []string{},
+			Policies: []string{},
+		}
+		for _, m := range members {
+			group.Members = append(group.Members, m.ExternalID)
+			related[m.ExternalID] = true
+		}
+		for _, p := range attached {
+			group.Policies = append(group.Policies, p.Name)
+		}
+		sort.Strings(group.Members)
+		sort.Strings(group.Policies)
+		document.Groups = append(document.Groups, group)
+	}
+
+	for _, u := range users {
+		attached, _, err := api.UserRepo.GetAttachedUserPolicies(u.ID, &Filter{})
+		if err != nil {
+			return nil, unknownDBError(err)
+		}
+		user := ExportUser{
+			ExternalID: u.ExternalID,
+			Path:       u.Path,
+			Policies:   []PolicyIdentity{},
+		}
+		for _, p := range attached {
+			if len(org) < 1 || p.Org == org {
+				user.Policies = append(user.Policies, PolicyIdentity{Org: p.Org, Name: p.Name})
+			}
+		}
+		if len(org) > 0 && !related[u.ExternalID] && len(user.Policies) < 1 {
+			continue
+		}
+		sort.Sort(policyIdentities(user.Policies))
+		document.Users = append(document.Users, user)
+	}
+
+	// Same order for the same state, to compare documents of different workers
+	sort.Sort(exportUsers(document.Users))
+	sort.Sort(exportGroups(document.Groups))
+	sort.Sort(exportPolicies(document.Policies))
+
+	return document, nil
+}
+
+func (api AuthAPI) Import(requestInfo RequestInfo, org string, document *ExportDocument, prune bool) (*ImportResult, error) {
+	if err := checkImportParameters(requestInfo, org); err != nil {
+		return nil, err
+	}
+
+	plan := &importPlan{
+		org:      org,
+		prune:    prune,
+		author:   requestInfo.Identifier,
+		createAt: time.Now().UTC(),
+		users:    map[string]*User{},
+		groups:   map[string]*Group{},
+		policies: map[string]*Policy{},
+	}
+	plan.result.Conflicts = []string{}
+
+	// Elements are planned before relations, which reference them
+	if err := api.planImportPolicies(plan, document.Policies); err != nil {
+		return nil, err
+	}
+	if err := api.planImportGroups(plan, document.Groups); err != nil {
+		return nil, err
+	}
+	if err := api.planImportUsers(plan, document.Users); err != nil {
+		return nil, err
+	}
+	if err := api.planImportGroupRelations(plan, document.Groups); err != nil {
+		return nil, err
+	}
+	if err := api.planImportUserPolicies(plan, document.Users); err != nil {
+		return nil, err
+	}
+
+	if plan.changes.isEmpty() {
+		return &plan.result, nil
+	}
+
+	// Apply all changes in a single transaction
+	if err := api.ImportRepo.ApplyImportChanges(plan.changes); err != nil {
+		return nil, unknownDBError(err)
+	}
+
+	for _, e := range plan.events {
+		api.recordAuditEvent(requestInfo, e.action, e.urn, e.before, e.after)
+	}
+	api.Cache.invalidateAll()
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Import done in organization %v: %+v", org, plan.result))
+
+	return &plan.result, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Plan policies of the document. Policies not in the document are removed only if prune is enabled.
+func (api AuthAPI) planImportPolicies(plan *importPlan, policies []ExportPolicy) error {
+	current, _, err := api.PolicyRepo.GetPoliciesFiltered(plan.org, &Filter{})
+	if err != nil {
+		return unknownDBError(err)
+	}
+	for i, p := range current {
+		plan.policies[orgKey(p.Org, p.Name)] = &current[i]
+	}
+
+	seen := map[string]bool{}
+	for _, p := range policies {
+		org := p.Org
+		if len(org) < 1 {
+			org = plan.org
+		}
+		key := orgKey(org, p.Name)
+		if seen[key] {
+			plan.conflict(&plan.result.Policies, "Duplicated policy %v in organization %v", p.Name, org)
+			continue
+		}
+		seen[key] = true
+		if msg := validateImportElement(plan.org, org, p.Name, p.Path); len(msg) > 0 {
+			plan.conflict(&plan.result.Policies, "Policy %v in organization %v: %v", p.Name, org, msg)
+			continue
+		}
+		statements := p.Statements
+		if statements == nil {
+			statements = []Statement{}
+		}
+		if err := AreValidStatements(&statements); err != nil {
+			plan.conflict(&plan.result.Policies, "Policy %v in organization %v: %v", p.Name, org, err.(*Error).Message)
+			continue
+		}
+
+		policyDB, ok := plan.policies[key]
+		switch {
+		case !ok:
+			policy := createPolicy(p.Name, p.Path, org, &statements)
+			plan.policies[key] = &policy
+			plan.changes.AddedPolicies = append(plan.changes.AddedPolicies, policy)
+			plan.changes.PolicyVersions = append(plan.changes.PolicyVersions, ImportPolicyVersion{
+				Version: createPolicyVersion(&policy, plan.author, plan.createAt),
+			})
+			plan.event(POLICY_ACTION_CREATE_POLICY, policy.Urn, nil, &policy)
+			plan.result.Policies.Created++
+		case policyDB.Path != p.Path || !isSameStatements(*policyDB.Statements, statements):
+			policy := *policyDB
+			policy.Path = p.Path
+			policy.Urn = CreateUrn(org, RESOURCE_POLICY, p.Path, p.Name)
+			policy.Statements = &statements
+			plan.policies[key] = &policy
+			plan.changes.UpdatedPolicies = append(plan.changes.UpdatedPolicies, policy)
+			plan.changes.PolicyVersions = append(plan.changes.PolicyVersions, ImportPolicyVersion{
+				Previous: policyDB,
+				Version:  createPolicyVersion(&policy, plan.author, plan.createAt),
+			})
+			plan.event(POLICY_ACTION_UPDATE_POLICY, policy.Urn, policyDB, &policy)
+			plan.result.Policies.Updated++
+		default:
+			plan.result.Policies.Unchanged++
+		}
+	}
+
+	if plan.prune {
+		for _, p := range current {
+			if !seen[orgKey(p.Org, p.Name)] {
+				policy := p
+				delete(plan.policies, orgKey(p.Org, p.Name))
+				plan.changes.RemovedPolicies = append(plan.changes.RemovedPolicies, policy)
+				plan.event(POLICY_ACTION_DELETE_POLICY, policy.Urn, &policy, nil)
+				plan.result.Policies.Removed++
+			}
+		}
+	}
+	return nil
+}
+
+// Plan groups of the document. Groups not in the document are removed only if prune is enabled.
+func (api AuthAPI) planImportGroups(plan *importPlan, groups []ExportGroup) error {
+	current, _, err := api.GroupRepo.GetGroupsFiltered(plan.org, &Filter{})
+	if err != nil {
+		return unknownDBError(err)
+	}
+	for i, g := range current {
+		plan.groups[orgKey(g.Org, g.Name)] = &current[i]
+	}
+
+	seen := map[string]bool{}
+	for _, g := range groups {
+		org := g.Org
+		if len(org) < 1 {
+			org = plan.org
+		}
+		key := orgKey(org, g.Name)
+		if seen[key] {
+			plan.conflict(&plan.result.Groups, "Duplicated group %v in organization %v", g.Name, org)
+			continue
+		}
+		seen[key] = true
+		if msg := validateImportElement(plan.org, org, g.Name, g.Path); len(msg) > 0 {
+			plan.conflict(&plan.result.Groups, "Group %v in organization %v: %v", g.Name, org, msg)
+			continue
+		}
+
+		groupDB, ok := plan.groups[key]
+		switch {
+		case !ok:
+			group := createGroup(org, g.Name, g.Path)
+			plan.groups[key] = &group
+			plan.changes.AddedGroups = append(plan.changes.AddedGroups, group)
+			plan.event(GROUP_ACTION_CREATE_GROUP, group.Urn, nil, &group)
+			plan.result.Groups.Created++
+		case groupDB.Path != g.Path:
+			group := *groupDB
+			group.Path = g.Path
+			group.Urn = CreateUrn(org, RESOURCE_GROUP, g.Path, g.Name)
+			plan.groups[key] = &group
+			plan.changes.UpdatedGroups = append(plan.changes.UpdatedGroups, group)
+			plan.event(GROUP_ACTION_UPDATE_GROUP, group.Urn, groupDB, &group)
+			plan.result.Groups.Updated++
+		default:
+			plan.result.Groups.Unchanged++
+		}
+	}
+
+	if plan.prune {
+		for _, g := range current {
+			if !seen[orgKey(g.Org, g.Name)] {
+				group := g
+				delete(plan.groups, orgKey(g.Org, g.Name))
+				plan.changes.RemovedGroups = append(plan.changes.RemovedGroups, group)
+				plan.event(GROUP_ACTION_DELETE_GROUP, group.Urn, &group, nil)
+				plan.result.Groups.Removed++
+			}
+		}
+	}
+	return nil
+}
+
+// Plan users of the document. Users don't belong to organizations, so users not in the document
+// are removed only if prune is enabled in an import of all organizations.
+func (api AuthAPI) planImportUsers(plan *importPlan, users []ExportUser) error {
+	current, _, err := api.UserRepo.GetUsersFiltered(&Filter{})
+	if err != nil {
+		return unknownDBError(err)
+	}
+	for i, u := range current {
+		plan.users[u.ExternalID] = &current[i]
+	}
+
+	seen := map[string]bool{}
+	for _, u := range users {
+		if seen[u.ExternalID] {
+			plan.conflict(&plan.result.Users, "Duplicated user %v", u.ExternalID)
+			continue
+		}
+		seen[u.ExternalID] = true
+		if !IsValidUserExternalID(u.ExternalID) {
+			plan.conflict(&plan.result.Users, "Invalid parameter: externalId %v", u.ExternalID)
+			continue
+		}
+		if !IsValidPath(u.Path) {
+			plan.conflict(&plan.result.Users, "User %v: Invalid parameter: path %v", u.ExternalID, u.Path)
+			continue
+		}
+
+		userDB, ok := plan.users[u.ExternalID]
+		switch {
+		case !ok:
+			user := createUser(u.ExternalID, u.Path)
+			plan.users[u.ExternalID] = &user
+			plan.changes.AddedUsers = append(plan.changes.AddedUsers, user)
+			plan.event(USER_ACTION_CREATE_USER, user.Urn, nil, &user)
+			plan.result.Users.Created++
+		case userDB.Path != u.Path:
+			user := *userDB
+			user.Path = u.Path
+			user.Urn = CreateUrn("", RESOURCE_USER, u.Path, u.ExternalID)
+			plan.users[u.ExternalID] = &user
+			plan.changes.UpdatedUsers = append(plan.changes.UpdatedUsers, user)
+			plan.event(USER_ACTION_UPDATE_USER, user.Urn, userDB, &user)
+			plan.result.Users.Updated++
+		default:
+			plan.result.Users.Unchanged++
+		}
+	}
+
+	if plan.prune && len(plan.org) < 1 {
+		for _, u := range current {
+			if !seen[u.ExternalID] {
+				user := u
+				delete(plan.users, u.ExternalID)
+				plan.changes.RemovedUsers = append(plan.changes.RemovedUsers, user)
+				plan.event(USER_ACTION_DELETE_USER, user.Urn, &user, nil)
+				plan.result.Users.Removed++
+			}
+		}
+	}
+	return nil
+}
+
+// Plan members and policies of the groups in the document. Relations of groups that aren't in
+// the document don't change, unless groups are removed.
+func (api AuthAPI) planImportGroupRelations(plan *importPlan, groups []ExportGroup) error {
+	planned := map[string]bool{}
+	for _, g := range groups {
+		org := g.Org
+		if len(org) < 1 {
+			org = plan.org
+		}
+		key := orgKey(org, g.Name)
+		group, ok := plan.groups[key]
+		// Skip groups in conflict
+		if !ok || planned[key] || (len(plan.org) > 0 && org != plan.org) || !IsValidPath(g.Path) {
+			continue
+		}
+		planned[key] = true
+
+		// Current relations, new groups don't have any
+		members := []User{}
+		policies := []Policy{}
+		if !isAddedGroup(plan, group.ID) {
+			var err error
+			members, _, err = api.GroupRepo.GetGroupMembers(group.ID, &Filter{})
+			if err != nil {
+				return unknownDBError(err)
+			}
+			policies, _, err = api.GroupRepo.GetAttachedPolicies(group.ID, &Filter{})
+			if err != nil {
+				return unknownDBError(err)
+			}
+		}
+		currentMembers := map[string]*User{}
+		for i, m := range members {
+			currentMembers[m.ExternalID] = &members[i]
+		}
+		currentPolicies := map[string]*Policy{}
+		for i, p := range policies {
+			currentPolicies[p.Name] = &policies[i]
+		}
+
+		// Members
+		wanted := map[string]bool{}
+		for _, externalID := range g.Members {
+			if wanted[externalID] {
+				continue
+			}
+			wanted[externalID] = true
+			user, ok := plan.users[externalID]
+			switch {
+			case !ok:
+				plan.conflict(&plan.result.Members, "Member %v of group %v in organization %v not found", externalID, g.Name, org)
+			case currentMembers[externalID] != nil:
+				plan.result.Members.Unchanged++
+			default:
+				plan.changes.AddedMembers = append(plan.changes.AddedMembers, ImportMember{User: user, Group: group})
+				plan.event(GROUP_ACTION_ADD_MEMBER, group.Urn, nil, auditRelation{Group: group, User: user})
+				plan.result.Members.Created++
+			}
+		}
+		if plan.prune {
+			for i, m := range members {
+				user := &members[i]
+				// Relations of removed users are removed with them
+				if _, ok := plan.users[m.ExternalID]; ok && !wanted[m.ExternalID] {
+					plan.changes.RemovedMembers = append(plan.changes.RemovedMembers, ImportMember{User: user, Group: group})
+					plan.event(GROUP_ACTION_REMOVE_MEMBER, group.Urn, auditRelation{Group: group, User: user}, nil)
+					plan.result.Members.Removed++
+				}
+			}
+		}
+
+		// Policies of the organization of the group
+		wanted = map[string]bool{}
+		for _, name := range g.Policies {
+			if wanted[name] {
+				continue
+			}
+			wanted[name] = true
+			policy, ok := plan.policies[orgKey(org, name)]
+			switch {
+			case !ok:
+				plan.conflict(&plan.result.GroupPolicies, "Policy %v of group %v in organization %v not found", name, g.Name, org)
+			case currentPolicies[name] != nil:
+				plan.result.GroupPolicies.Unchanged++
+			default:
+				plan.changes.AddedGroupPolicies = append(plan.changes.AddedGroupPolicies, ImportGroupPolicy{Group: group, Policy: policy})
+				plan.event(GROUP_ACTION_ATTACH_GROUP_POLICY, group.Urn, nil, auditRelation{Group: group, Policy: policy})
+				plan.result.GroupPolicies.Created++
+			}
+		}
+		if plan.prune {
+			for i, p := range policies {
+				policy := &policies[i]
+				if _, ok := plan.policies[orgKey(org, p.Name)]; ok && !wanted[p.Name] {
+					plan.changes.RemovedGroupPolicies = append(plan.changes.RemovedGroupPolicies, ImportGroupPolicy{Group: group, Policy: policy})
+					plan.event(GROUP_ACTION_DETACH_GROUP_POLICY, group.Urn, auditRelation{Group: group, Policy: policy}, nil)
+					plan.result.GroupPolicies.Removed++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Plan policies attached to users. If prune is enabled, policies of the organization attached
+// to users that aren't in the document are detached too.
+func (api AuthAPI) planImportUserPolicies(plan *importPlan, users []ExportUser) error {
+	wanted := map[string][]PolicyIdentity{}
+	for _, u := range users {
+		if _, ok := wanted[u.ExternalID]; ok {
+			continue
+		}
+		wanted[u.ExternalID] = u.Policies
+	}
+
+	// Sorted to plan changes in the same order every time
+	externalIDs := []string{}
+	for externalID := range plan.users {
+		externalIDs = append(externalIDs, externalID)
+	}
+	sort.Strings(externalIDs)
+
+	for _, externalID := range externalIDs {
+		user := plan.users[externalID]
+		identities, inDocument := wanted[externalID]
+		if !inDocument && !plan.prune {
+			continue
+		}
+
+		// Current policies of the organization, new users don't have any
+		policies := []Policy{}
+		if !isAddedUser(plan, user.ID) {
+			attachedPolicies, _, err := api.UserRepo.GetAttachedUserPolicies(user.ID, &Filter{})
+			if err != nil {
+				return unknownDBError(err)
+			}
+			for _, p := range attachedPolicies {
+				if len(plan.org) < 1 || p.Org == plan.org {
+					policies = append(policies, p)
+				}
+			}
+		}
+		currentPolicies := map[string]*Policy{}
+		for i, p := range policies {
+			currentPolicies[orgKey(p.Org, p.Name)] = &policies[i]
+		}
+
+		attached := map[string]bool{}
+		for _, identity := range identities {
+			org := identity.Org
+			if len(org) < 1 {
+				org = plan.org
+			}
+			key := orgKey(org, identity.Name)
+			if attached[key] {
+				continue
+			}
+			attached[key] = true
+			policy, ok := plan.policies[key]
+			switch {
+			case !ok:
+				plan.conflict(&plan.result.UserPolicies, "Policy %v in organization %v of user %v not found", identity.Name, org, externalID)
+			case currentPolicies[key] != nil:
+				plan.result.UserPolicies.Unchanged++
+			default:
+				plan.changes.AddedUserPolicies = append(plan.changes.AddedUserPolicies, ImportUserPolicy{User: user, Policy: policy})
+				plan.event(USER_ACTION_ATTACH_USER_POLICY, user.Urn, nil, auditRelation{User: user, Policy: policy})
+				plan.result.UserPolicies.Created++
+			}
+		}
+		if plan.prune {
+			for i, p := range policies {
+				policy := &policies[i]
+				key := orgKey(p.Org, p.Name)
+				// Relations of removed policies are removed with them
+				if _, ok := plan.policies[key]; ok && !attached[key] {
+					plan.changes.RemovedUserPolicies = append(plan.changes.RemovedUserPolicies, ImportUserPolicy{User: user, Policy: policy})
+					plan.event(USER_ACTION_DETACH_USER_POLICY, user.Urn, auditRelation{User: user, Policy: policy}, nil)
+					plan.result.UserPolicies.Removed++
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (plan *importPlan) conflict(counts *ImportCounts, format string, args ...interface{}) {
+	counts.Conflicts++
+	plan.result.Conflicts = append(plan.result.Conflicts, fmt.Sprintf(format, args...))
+}
+
+func (plan *importPlan) event(action string, urn string, before interface{}, after interface{}) {
+	plan.events = append(plan.events, importEvent{
+		action: action,
+		urn:    urn,
+		before: before,
+		after:  after,
+	})
+}
+
+func isAddedUser(plan *importPlan, id string) bool {
+	for _, u := range plan.changes.AddedUsers {
+		if u.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func isAddedGroup(plan *importPlan, id string) bool {
+	for _, g := range plan.changes.AddedGroups {
+		if g.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// Only admin can export and import, because they change resources of all users
+func checkImportParameters(requestInfo RequestInfo, org string) error {
+	if len(org) > 0 && !IsValidOrg(org) {
+		return &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if !requestInfo.Admin {
+		return &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to export or import. Only admin users can do it",
+				requestInfo.Identifier),
+		}
+	}
+	return nil
+}
+
+// Validate organization, name and path of a group or policy of a document for org, returning why it's invalid
+func validateImportElement(importOrg string, org string, name string, path string) string {
+	switch {
+	case len(importOrg) > 0 && org != importOrg:
+		return fmt.Sprintf("Organization doesn't match imported organization %v", importOrg)
+	case !IsValidOrg(org):
+		return fmt.Sprintf("Invalid parameter: org %v", org)
+	case !IsValidName(name):
+		return fmt.Sprintf("Invalid parameter: name %v", name)
+	case !IsValidPath(path):
+		return fmt.Sprintf("Invalid parameter: path %v", path)
+	}
+	return ""
+}
+
+func orgKey(org string, name string) string {
+	return org + "/" + name
+}
+
+// Compare statements in order
+func isSameStatements(a []Statement, b []Statement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+func unknownDBError(err error) error {
+	//Transform to DB error
+	dbError := err.(*database.Error)
+	return &Error{
+		Code:    UNKNOWN_API_ERROR,
+		Message: dbError.Message,
+	}
+}
+
+// Sort export elements by organization and name, or by external id for users
+
+type exportUsers []ExportUser
+
+func (u exportUsers) Len() int           { return len(u) }
+func (u exportUsers) Swap(i, j int)      { u[i], u[j] = u[j], u[i] }
+func (u exportUsers) Less(i, j int) bool { return u[i].ExternalID < u[j].ExternalID }
+
+type exportGroups []ExportGroup
+
+func (g exportGroups) Len() int      { return len(g) }
+func (g exportGroups) Swap(i, j int) { g[i], g[j] = g[j], g[i] }
+func (g exportGroups) Less(i, j int) bool {
+	return orgKey(g[i].Org, g[i].Name) < orgKey(g[j].Org, g[j].Name)
+}
+
+type exportPolicies []ExportPolicy
+
+func (p exportPolicies) Len() int      { return len(p) }
+func (p exportPolicies) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p exportPolicies) Less(i, j int) bool {
+	return orgKey(p[i].Org, p[i].Name) < orgKey(p[j].Org, p[j].Name)
+}
+
+type policyIdentities []PolicyIdentity
+
+func (p policyIdentities) Len() int      { return len(p) }
+func (p policyIdentities) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p policyIdentities) Less(i, j int) bool {
+	return orgKey(p[i].Org, p[i].Name) < orgKey(p[j].Org, p[j].Name)
+}
@@ -0,0 +1,501 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_Export(t *testing.T) {
+	statements := []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{USER_ACTION_GET_USER},
+			Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		// Expected result
+		expectedResult *ExportDocument
+		wantError      error
+		// Manager Results
+		getUsersFilteredMethodResult        []User
+		getGroupsFilteredMethodResult       []Group
+		getPoliciesFilteredMethodResult     []Policy
+		getGroupMembersMethodResult         []User
+		getAttachedPoliciesMethodResult     []Policy
+		getAttachedUserPoliciesMethodResult []Policy
+		// Manager Errors
+		getUsersFilteredMethodErr error
+	}{
+		"OKCaseOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "123",
+			getUsersFilteredMethodResult: []User{
+				{
+					ID:         "UserID2",
+					ExternalID: "user2",
+					Path:       "/path/",
+				},
+				{
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+				},
+			},
+			getGroupsFilteredMethodResult: []Group{
+				{
+					ID:   "GroupID",
+					Name: "group",
+					Path: "/path/",
+					Org:  "123",
+				},
+			},
+			getPoliciesFilteredMethodResult: []Policy{
+				{
+					ID:         "PolicyID",
+					Name:       "policy",
+					Path:       "/path/",
+					Org:        "123",
+					Statements: &statements,
+				},
+			},
+			getGroupMembersMethodResult: []User{
+				{
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+				},
+			},
+			getAttachedPoliciesMethodResult: []Policy{
+				{
+					ID:   "PolicyID",
+					Name: "policy",
+					Org:  "123",
+				},
+			},
+			getAttachedUserPoliciesMethodResult: []Policy{
+				{
+					ID:   "PolicyID2",
+					Name: "policy",
+					Org:  "456",
+				},
+			},
+			expectedResult: &ExportDocument{
+				Org: "123",
+				Users: []ExportUser{
+					{
+						ExternalID: "user1",
+						Path:       "/path/",
+						Policies:   []PolicyIdentity{},
+					},
+				},
+				Groups: []ExportGroup{
+					{
+						Org:      "123",
+						Name:     "group",
+						Path:     "/path/",
+						Members:  []string{"user1"},
+						Policies: []string{"policy"},
+					},
+				},
+				Policies: []ExportPolicy{
+					{
+						Org:        "123",
+						Name:       "policy",
+						Path:       "/path/",
+						Statements: statements,
+					},
+				},
+			},
+		},
+		"OKCaseAllOrgs": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			getUsersFilteredMethodResult: []User{
+				{
+					ID:         "UserID2",
+					ExternalID: "user2",
+					Path:       "/path/",
+				},
+				{
+					ID:         "UserID1",
+					ExternalID: "user1",
+					Path:       "/path/",
+				},
+			},
+			getAttachedUserPoliciesMethodResult: []Policy{
+				{
+					ID:   "PolicyID2",
+					Name: "policy",
+					Org:  "456",
+				},
+			},
+			expectedResult: &ExportDocument{
+				Users: []ExportUser{
+					{
+						ExternalID: "user1",
+						Path:       "/path/",
+						Policies: []PolicyIdentity{
+							{
+								Org:  "456",
+								Name: "policy",
+							},
+						},
+					},
+					{
+						ExternalID: "user2",
+						Path:       "/path/",
+						Policies: []PolicyIdentity{
+							{
+								Org:  "456",
+								Name: "policy",
+							},
+						},
+					},
+				},
+				Groups:   []ExportGroup{},
+				Policies: []ExportPolicy{},
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "!*^**~$%&/()(",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !*^**~$%&/()(",
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org: "123",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to export or import. Only admin users can do it",
+			},
+		},
+		"ErrorCaseGetUsersFilteredDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "123",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUsersFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUsersFilteredMethod][0] = test.getUsersFilteredMethodResult
+		testRepo.ArgsOut[GetUsersFilteredMethod][2] = test.getUsersFilteredMethodErr
+		testRepo.ArgsOut[GetGroupsFilteredMethod][0] = test.getGroupsFilteredMethodResult
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = test.getPoliciesFilteredMethodResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = test.getGroupMembersMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = test.getAttachedUserPoliciesMethodResult
+
+		document, err := testAPI.Export(test.requestInfo, test.org)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, document)
+	}
+}
+
+func TestAuthAPI_Import(t *testing.T) {
+	statements := []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{USER_ACTION_GET_USER},
+			Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+		},
+	}
+	user := User{
+		ID:         "UserID",
+		ExternalID: "user1",
+		Path:       "/path/",
+		Urn:        CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+	}
+	group := Group{
+		ID:   "GroupID",
+		Name: "group",
+		Path: "/path/",
+		Org:  "123",
+		Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group"),
+	}
+	policy := Policy{
+		ID:         "PolicyID",
+		Name:       "policy",
+		Path:       "/path/",
+		Org:        "123",
+		Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "policy"),
+		Statements: &statements,
+	}
+	document := &ExportDocument{
+		Org: "123",
+		Users: []ExportUser{
+			{
+				ExternalID: "user1",
+				Path:       "/path/",
+				Policies: []PolicyIdentity{
+					{
+						Org:  "123",
+						Name: "policy",
+					},
+				},
+			},
+		},
+		Groups: []ExportGroup{
+			{
+				Org:      "123",
+				Name:     "group",
+				Path:     "/path/",
+				Members:  []string{"user1"},
+				Policies: []string{"policy"},
+			},
+		},
+		Policies: []ExportPolicy{
+			{
+				Org:        "123",
+				Name:       "policy",
+				Path:       "/path/",
+				Statements: statements,
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		document    *ExportDocument
+		prune       bool
+		// Expected result
+		expectedResult  *ImportResult
+		expectedApplied bool
+		wantError       error
+		// Manager Results
+		getUsersFilteredMethodResult        []User
+		getGroupsFilteredMethodResult       []Group
+		getPoliciesFilteredMethodResult     []Policy
+		getGroupMembersMethodResult         []User
+		getAttachedPoliciesMethodResult     []Policy
+		getAttachedUserPoliciesMethodResult []Policy
+		// Manager Errors
+		applyImportChangesMethodErr error
+	}{
+		"OKCaseCreated": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "123",
+			document: document,
+			expectedResult: &ImportResult{
+				Users:         ImportCounts{Created: 1},
+				Groups:        ImportCounts{Created: 1},
+				Policies:      ImportCounts{Created: 1},
+				Members:       ImportCounts{Created: 1},
+				GroupPolicies: ImportCounts{Created: 1},
+				UserPolicies:  ImportCounts{Created: 1},
+				Conflicts:     []string{},
+			},
+			expectedApplied: true,
+		},
+		"OKCaseUnchanged": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                                 "123",
+			document:                            document,
+			getUsersFilteredMethodResult:        []User{user},
+			getGroupsFilteredMethodResult:       []Group{group},
+			getPoliciesFilteredMethodResult:     []Policy{policy},
+			getGroupMembersMethodResult:         []User{user},
+			getAttachedPoliciesMethodResult:     []Policy{policy},
+			getAttachedUserPoliciesMethodResult: []Policy{policy},
+			expectedResult: &ImportResult{
+				Users:         ImportCounts{Unchanged: 1},
+				Groups:        ImportCounts{Unchanged: 1},
+				Policies:      ImportCounts{Unchanged: 1},
+				Members:       ImportCounts{Unchanged: 1},
+				GroupPolicies: ImportCounts{Unchanged: 1},
+				UserPolicies:  ImportCounts{Unchanged: 1},
+				Conflicts:     []string{},
+			},
+		},
+		"OKCasePrune": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "123",
+			document: &ExportDocument{
+				Policies: []ExportPolicy{
+					{
+						Name:       "policy",
+						Path:       "/newpath/",
+						Statements: statements,
+					},
+				},
+			},
+			prune:                         true,
+			getUsersFilteredMethodResult:  []User{user},
+			getGroupsFilteredMethodResult: []Group{group},
+			getPoliciesFilteredMethodResult: []Policy{
+				policy,
+				{
+					ID:         "PolicyID2",
+					Name:       "policy2",
+					Path:       "/path/",
+					Org:        "123",
+					Statements: &statements,
+				},
+			},
+			getAttachedUserPoliciesMethodResult: []Policy{policy},
+			expectedResult: &ImportResult{
+				Groups:       ImportCounts{Removed: 1},
+				Policies:     ImportCounts{Updated: 1, Removed: 1},
+				UserPolicies: ImportCounts{Removed: 1},
+				Conflicts:    []string{},
+			},
+			expectedApplied: true,
+		},
+		"OKCaseConflicts": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "123",
+			document: &ExportDocument{
+				Users: []ExportUser{
+					{
+						ExternalID: "user1",
+						Path:       "/path",
+					},
+				},
+				Groups: []ExportGroup{
+					{
+						Org:     "456",
+						Name:    "group",
+						Path:    "/path/",
+						Members: []string{"user1"},
+					},
+				},
+				Policies: []ExportPolicy{
+					{
+						Name:       "policy",
+						Path:       "/path/",
+						Statements: statements,
+					},
+					{
+						Name:       "policy",
+						Path:       "/path/",
+						Statements: statements,
+					},
+					{
+						Name: "policy2",
+						Path: "/path/",
+						Statements: []Statement{
+							{
+								Effect: "allow",
+							},
+						},
+					},
+				},
+			},
+			getPoliciesFilteredMethodResult: []Policy{policy},
+			expectedResult: &ImportResult{
+				Users:    ImportCounts{Conflicts: 1},
+				Groups:   ImportCounts{Conflicts: 1},
+				Policies: ImportCounts{Unchanged: 1, Conflicts: 2},
+				Conflicts: []string{
+					"Duplicated policy policy in organization 123",
+					"Policy policy2 in organization 123: Empty actions",
+					"Group group in organization 456: Organization doesn't match imported organization 123",
+					"User user1: Invalid parameter: path /path",
+				},
+			},
+		},
+		"ErrorCaseNotAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org:      "123",
+			document: document,
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to export or import. Only admin users can do it",
+			},
+		},
+		"ErrorCaseApplyImportChangesDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:      "123",
+			document: document,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			expectedApplied: true,
+			applyImportChangesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUsersFilteredMethod][0] = test.getUsersFilteredMethodResult
+		testRepo.ArgsOut[GetGroupsFilteredMethod][0] = test.getGroupsFilteredMethodResult
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = test.getPoliciesFilteredMethodResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = test.getGroupMembersMethodResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesMethodResult
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = test.getAttachedUserPoliciesMethodResult
+		testRepo.ArgsOut[ApplyImportChangesMethod][0] = test.applyImportChangesMethodErr
+
+		result, err := testAPI.Import(test.requestInfo, test.org, test.document, test.prune)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, result)
+
+		// Check that changes are applied only if there are any
+		if applied := testRepo.ArgsIn[ApplyImportChangesMethod][0] != nil; applied != test.expectedApplied {
+			t.Errorf("Test %v failed. Received different applied changes (wanted:%v / received:%v)", n, test.expectedApplied, applied)
+		}
+
+		// Check that created and updated policies are versioned with the same changes
+		if changes, ok := testRepo.ArgsIn[ApplyImportChangesMethod][0].(ImportChanges); ok {
+			if len(changes.PolicyVersions) != len(changes.AddedPolicies)+len(changes.UpdatedPolicies) {
+				t.Errorf("Test %v failed. Received different policy versions %v", n, changes.PolicyVersions)
+			}
+			for _, v := range changes.PolicyVersions {
+				if v.Version.Author != test.requestInfo.Identifier {
+					t.Errorf("Test %v failed. Received different policy version author %v", n, v.Version.Author)
+				}
+			}
+		}
+	}
+}
@@ -1,6 +1,10 @@
 package api
 
-import log "github.com/Sirupsen/logrus"
+import (
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+)
 
 // TYPE DEFINITIONS
 
@@ -15,7 +19,13 @@ type AuthAPI struct {
 	UserRepo   UserRepo
 	GroupRepo  GroupRepo
 	PolicyRepo PolicyRepo
+	AuthzRepo  AuthzRepo
+	AuditRepo  AuditRepo
+	ImportRepo ImportRepo
+	APIKeyRepo APIKeyRepo
 	Logger     *log.Logger
+	// Effective statements cache used to authorize, nil if disabled
+	Cache *StatementCache
 }
 
 // Filter properties for database search
@@ -45,13 +55,25 @@ type UserAPI interface {
 	// are invalid, user doesn't exist or unexpected error happen.
 	UpdateUser(requestInfo RequestInfo, externalId string, newPath string) (*User, error)
 
-	// Remove user stored in database with its group relationships.
+	// Remove user stored in database with its group and policy relationships, and its API keys.
 	// Throw error if externalId parameter is invalid, user doesn't exist or unexpected error happen.
 	RemoveUser(requestInfo RequestInfo, externalId string) error
 
 	// Retrieve groups that belongs to the user. Throw error if externalId parameter is invalid, user
 	// doesn't exist or unexpected error happen.
 	ListGroupsByUser(requestInfo RequestInfo, externalId string, filter *Filter) ([]GroupIdentity, int, error)
+
+	// Attach policy directly to user. Throw error if the input parameters are invalid, policy doesn't exist,
+	// user doesn't exist, policy is already attached to the user or unexpected error happen.
+	AttachPolicyToUser(requestInfo RequestInfo, externalId string, org string, policyName string) error
+
+	// Detach policy from user. Throw error if the input parameters are invalid, policy doesn't exist,
+	// user doesn't exist, policy isn't attached to the user or unexpected error happen.
+	DetachPolicyFromUser(requestInfo RequestInfo, externalId string, org string, policyName string) error
+
+	// Retrieve policies that are attached directly to the user. Throw error if the input parameters are invalid,
+	// user doesn't exist or unexpected error happen.
+	ListAttachedUserPolicies(requestInfo RequestInfo, externalId string, filter *Filter) ([]PolicyIdentity, int, error)
 }
 
 type GroupAPI interface {
@@ -96,6 +118,16 @@ type GroupAPI interface {
 	// group doesn't exist, policy isn't attached to the group or unexpected error happen.
 	DetachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string) error
 
+	// Check the same as AttachPolicyToGroup, without attaching the policy, and compare restrictions of group members
+	// before and after attaching it, over the checks received or sampled from policy statements.
+	DryRunAttachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string,
+		checks []PermissionCheck) (*PermissionImpact, error)
+
+	// Check the same as DetachPolicyToGroup, without detaching the policy, and compare restrictions of group members
+	// before and after detaching it, over the checks received or sampled from policy statements.
+	DryRunDetachPolicyToGroup(requestInfo RequestInfo, org string, groupName string, policyName string,
+		checks []PermissionCheck) (*PermissionImpact, error)
+
 	// Retrieve name of policies that are attached to the group. Throw error if the input parameters are invalid,
 	// group doesn't exist or unexpected error happen.
 	ListAttachedGroupPolicies(requestInfo RequestInfo, org string, groupName string, filter *Filter) ([]string, int, error)
@@ -106,6 +138,11 @@ type PolicyAPI interface {
 	// the policy already exist or unexpected error happen.
 	AddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error)
 
+	// Check the same as AddPolicy, without storing the policy. As a new policy isn't attached to anyone,
+	// it only returns the checks received or sampled from statements.
+	DryRunAddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement,
+		checks []PermissionCheck) (*PermissionImpact, error)
+
 	// Retrieve policy from database. Throw error when the input parameters are invalid,
 	// policy doesn't exist or unexpected error happen.
 	GetPolicyByName(requestInfo RequestInfo, org string, name string) (*Policy, error)
@@ -120,13 +157,43 @@ type PolicyAPI interface {
 	UpdatePolicy(requestInfo RequestInfo, org string, name string, newName string, newPath string,
 		newStatements []Statement) (*Policy, error)
 
-	// Remove policy stored in database with its groups relationships.
+	// Check the same as UpdatePolicy, without updating the policy, and compare restrictions of users attached to the
+	// policy, directly or by groups, before and after the update, over the checks received or sampled from old and new statements.
+	DryRunUpdatePolicy(requestInfo RequestInfo, org string, name string, newName string, newPath string,
+		newStatements []Statement, checks []PermissionCheck) (*PermissionImpact, error)
+
+	// Remove policy stored in database with its groups and users relationships.
 	// Throw error if the input parameters are invalid, the policy doesn't exist or unexpected error happen.
 	RemovePolicy(requestInfo RequestInfo, org string, name string) error
 
 	// Retrieve name of groups that are attached to the policy. Throw error if the input parameters are invalid,
 	// policy doesn't exist or unexpected error happen.
 	ListAttachedGroups(requestInfo RequestInfo, org string, name string, filter *Filter) ([]string, int, error)
+
+	// Retrieve versions of the policy, oldest first. Throw error if the input parameters are invalid,
+	// policy doesn't exist or unexpected error happen.
+	ListPolicyVersions(requestInfo RequestInfo, org string, name string, filter *Filter) ([]PolicyVersion, int, error)
+
+	// Retrieve a version of the policy. Throw error if the input parameters are invalid,
+	// policy or version don't exist or unexpected error happen.
+	GetPolicyVersion(requestInfo RequestInfo, org string, name string, version int) (*PolicyVersion, error)
+
+	// Compare two versions of the policy. Throw error if the input parameters are invalid,
+	// policy or versions don't exist or unexpected error happen.
+	DiffPolicyVersions(requestInfo RequestInfo, org string, name string, fromVersion int, toVersion int) (*PolicyVersionDiff, error)
+
+	// Update policy with name, path and statements of a previous version, creating a new version.
+	// Throw error if the input parameters are invalid, policy or version don't exist,
+	// target policy already exist or unexpected error happen.
+	RollbackPolicy(requestInfo RequestInfo, org string, name string, version int) (*Policy, error)
+
+	// Analyze statements of the policy looking for problems, checking actions against iam actions and known actions.
+	// Throw error if the input parameters are invalid, policy doesn't exist or unexpected error happen.
+	LintPolicy(requestInfo RequestInfo, org string, name string, knownActions []string) (*PolicyLintReport, error)
+
+	// Analyze statements of all policies in the organization that requestInfo is allowed to retrieve.
+	// Throw error if the input parameters are invalid or unexpected error happen.
+	LintPolicies(requestInfo RequestInfo, org string, knownActions []string) ([]PolicyLintReport, error)
 }
 
 type AuthzAPI interface {
@@ -145,6 +212,76 @@ type AuthzAPI interface {
 	// Retrieve list of authorized external resources filtered according to the input parameters. Throw error
 	// if requestInfo doesn't exist, requestInfo doesn't have access to any resources or unexpected error happen.
 	GetAuthorizedExternalResources(requestInfo RequestInfo, action string, resources []string) ([]string, error)
+
+	// Retrieve allowed and denied urns and urn prefixes for the action, that contain or are contained in the urn
+	// prefix. Throw error if the input parameters are invalid, requestInfo doesn't exist or unexpected error happen.
+	GetAuthorizedRestrictions(requestInfo RequestInfo, action string, urnPrefix string) (*Restrictions, error)
+
+	// Retrieve list of external resources where the user with this externalID has the action granted. Throw error
+	// if the input parameters are invalid, user doesn't exist, requestInfo doesn't have access to check authorization
+	// of the user or unexpected error happen.
+	GetAuthorizedExternalResourcesForUser(requestInfo RequestInfo, externalID string, action string, resources []string) ([]string, error)
+
+	// Retrieve list of authorized external resources for every action and resources check, loading user
+	// statements once. Throw error if the input parameters are invalid, requestInfo doesn't exist or
+	// unexpected error happen.
+	GetAuthorizedExternalResourcesBatch(requestInfo RequestInfo, checks []AuthorizationCheck) ([]AuthorizationCheckResult, error)
+
+	// Retrieve users and groups allowed to do the action over the resource. Throw error if the input parameters
+	// are invalid, requestInfo isn't an admin or unexpected error happen.
+	GetAuthorizedPrincipals(requestInfo RequestInfo, action string, resource string) (*AuthorizedPrincipals, error)
+
+	// Retrieve the decision taken for every resource when the user requests the action, with the groups,
+	// policies and statements that contributed to it. Throw error if the input parameters are invalid, user
+	// doesn't exist, requestInfo doesn't have access to the user or unexpected error happen.
+	ExplainAuthorization(requestInfo RequestInfo, externalID string, action string, resources []string) (*AuthorizationExplanation, error)
+}
+
+type AuditAPI interface {
+	// Retrieve audit events from database filtered by actor, action, urn prefix and dates, newest first.
+	// Only events of resources that requestInfo is allowed to see are returned. Throw error if the input
+	// parameters are invalid, requestInfo doesn't have access to any resources or unexpected error happen.
+	ListAuditEvents(requestInfo RequestInfo, filter *AuditFilter) ([]AuditEvent, int, error)
+}
+
+type ImportAPI interface {
+	// Retrieve users, groups and policies of the organization with their relations, or of all organizations
+	// if org is empty. Throw error if org is invalid, requestInfo isn't admin or unexpected error happen.
+	Export(requestInfo RequestInfo, org string) (*ExportDocument, error)
+
+	// Create and update users, groups, policies and relations of the document in a single transaction, so
+	// importing it again doesn't change anything. If prune is true, elements not in the document are removed,
+	// users only if org is empty. Invalid elements are skipped and reported as conflicts. Throw error if org
+	// is invalid, requestInfo isn't admin or unexpected error happen.
+	Import(requestInfo RequestInfo, org string, document *ExportDocument, prune bool) (*ImportResult, error)
+}
+
+type APIKeyAPI interface {
+	// Create an API key for the user, with an optional expiration date. The key with its secret is only
+	// returned here. Throw error if the input parameters are invalid, user doesn't exist, requestInfo
+	// isn't allowed to create keys of the user or unexpected error happen.
+	CreateAPIKey(requestInfo RequestInfo, externalID string, name string, expireAt *time.Time) (*APIKeySecret, error)
+
+	// Retrieve an API key of the user, without its secret. Throw error if the input parameters are invalid,
+	// user or key don't exist or unexpected error happen.
+	GetAPIKey(requestInfo RequestInfo, externalID string, id string) (*APIKey, error)
+
+	// Retrieve API keys of the user, including expired and revoked ones. Throw error if the input parameters
+	// are invalid, user doesn't exist or unexpected error happen.
+	ListAPIKeys(requestInfo RequestInfo, externalID string, filter *Filter) ([]APIKey, int, error)
+
+	// Replace the secret of an API key, so previous secret stops working, and optionally its expiration date.
+	// Throw error if the input parameters are invalid, user or key don't exist, key is revoked or
+	// unexpected error happen.
+	RotateAPIKey(requestInfo RequestInfo, externalID string, id string, expireAt *time.Time) (*APIKeySecret, error)
+
+	// Revoke an API key, so it can't be used anymore. Throw error if the input parameters are invalid,
+	// user or key don't exist, key is already revoked or unexpected error happen.
+	RevokeAPIKey(requestInfo RequestInfo, externalID string, id string) (*APIKey, error)
+
+	// Retrieve external id of the user of a valid API key. Throw error INVALID_API_KEY if the key doesn't
+	// exist, its secret is wrong, it is expired or revoked, or unexpected error happen.
+	AuthenticateAPIKey(key string) (string, error)
 }
 
 // REPOSITORY INTERFACES
@@ -165,13 +302,28 @@ type UserRepo interface {
 	// are not satisfied or unexpected error happen.
 	UpdateUser(user User, newPath string, newUrn string) (*User, error)
 
-	// Remove user stored in database with its group relationships.
+	// Remove user stored in database with its group and policy relationships, and its API keys.
 	// Throw error if there are problems during transactions.
 	RemoveUser(id string) error
 
 	// Retrieve groups that belong to the user. Throw error
 	// if there are problems with database.
 	GetGroupsByUserID(id string, filter *Filter) ([]Group, int, error)
+
+	// Attach policy to user. It doesn't check restrictions about existence of user or policy. It throws
+	// errors if there are problems with database.
+	AttachPolicyToUser(userID string, policyID string) error
+
+	// Detach policy from user. It doesn't check restrictions about existence of user or policy. It throws
+	// errors if there are problems with database.
+	DetachPolicyFromUser(userID string, policyID string) error
+
+	// Check if policy is attached to user. It returns true if at least one relation exists. It throws
+	// errors if there are problems with database.
+	IsAttachedToUser(userID string, policyID string) (bool, error)
+
+	// Retrieve policies that are attached directly to the user. Throw error if there are problems with database.
+	GetAttachedUserPolicies(userID string, filter *Filter) ([]Policy, int, error)
 }
 
 // GroupRepo contains all database operations
@@ -227,8 +379,8 @@ type GroupRepo interface {
 
 // PolicyRepo contains all database operations
 type PolicyRepo interface {
-	// Store policy in database if there aren't errors.
-	AddPolicy(policy Policy) (*Policy, error)
+	// Store policy in database with its first version, in the same transaction, if there aren't errors.
+	AddPolicy(policy Policy, version PolicyVersion) (*Policy, error)
 
 	// Retrieve policy from database if it exists. Otherwise it throws an error.
 	GetPolicyByName(org string, name string) (*Policy, error)
@@ -237,14 +389,69 @@ type PolicyRepo interface {
 	// if there are problems with database.
 	GetPoliciesFiltered(org string, filter *Filter) ([]Policy, int, error)
 
-	// Update policy stored in database with new name and pathPrefix. Also it overrides statements.
+	// Update policy stored in database with new name and pathPrefix. Also it overrides statements and stores
+	// its new version with the next version number, in the same transaction. Policies created before versioning
+	// don't have versions, so the stored policy is stored as a version first.
 	// Throw error if there are problems with database.
-	UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement) (*Policy, error)
+	UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement,
+		version PolicyVersion) (*Policy, error)
 
-	// Remove policy stored in database with its groups relationships.
+	// Remove policy stored in database with its groups and users relationships, and its versions.
 	// Throw error if there are problems during transactions.
 	RemovePolicy(id string) error
 
 	// Retrieve groups that are attached to the policy. Throw error if there are problems with database.
 	GetAttachedGroups(policyID string, filter *Filter) ([]Group, int, error)
+
+	// Retrieve users that have the policy attached directly. Throw error if there are problems with database.
+	GetAttachedUsers(policyID string, filter *Filter) ([]User, int, error)
+
+	// Retrieve versions of the policy, oldest first. Throw error if there are problems with database.
+	GetPolicyVersions(policyID string, filter *Filter) ([]PolicyVersion, int, error)
+
+	// Retrieve a version of the policy. Throw error POLICY_VERSION_NOT_FOUND if it doesn't exist,
+	// or if there are problems with database.
+	GetPolicyVersion(policyID string, version int) (*PolicyVersion, error)
+}
+
+// AuthzRepo contains database operations to retrieve permissions
+type AuthzRepo interface {
+	// Retrieve statements of policies attached to the user with this externalId, directly or through its groups,
+	// in a single query. Every statement has the policy and the group it comes from, and it's repeated for every
+	// attachment of its policy. Statements of the same attachment are returned together. Throw error USER_NOT_FOUND
+	// if the user doesn't exist, or if there are problems with database.
+	GetEffectiveStatements(externalID string) ([]EffectiveStatement, error)
+}
+
+// AuditRepo contains database operations for audit events
+type AuditRepo interface {
+	// Store audit event in database if there aren't errors.
+	AddAuditEvent(event AuditEvent) error
+
+	// Retrieve audit events from database filtered by optional filter properties, newest first.
+	// Throw error if there are problems with database.
+	GetAuditEventsFiltered(filter *AuditFilter) ([]AuditEvent, int, error)
+}
+
+// ImportRepo contains database operations for imports
+type ImportRepo interface {
+	// Apply all changes of an import in a single transaction. If there are errors, no change is applied.
+	ApplyImportChanges(changes ImportChanges) error
+}
+
+// APIKeyRepo contains database operations for API keys
+type APIKeyRepo interface {
+	// Store API key in database if there aren't errors.
+	AddAPIKey(apiKey APIKey) (*APIKey, error)
+
+	// Retrieve API key from database. Throw error API_KEY_NOT_FOUND if it doesn't exist,
+	// or if there are problems with database.
+	GetAPIKeyByID(id string) (*APIKey, error)
+
+	// Retrieve API keys of the user, oldest first. Throw error if there are problems with database.
+	GetAPIKeysByUserID(userID string, filter *Filter) ([]APIKey, int, error)
+
+	// Update hash, rotation, expiration and revocation dates of an API key stored in database.
+	// Throw error if there are problems with database.
+	UpdateAPIKey(apiKey APIKey) (*APIKey, error)
 }
@@ -37,100 +37,52 @@ type PolicyIdentity struct {
 }
 
 type Statement struct {
-	Effect    string   `json:"effect, omitempty"`
-	Actions   []string `json:"actions, omitempty"`
-	Resources []string `json:"resources, omitempty"`
+	Effect     string      `json:"effect, omitempty"`
+	Actions    []string    `json:"actions, omitempty"`
+	Resources  []string    `json:"resources, omitempty"`
+	Conditions []Condition `json:"conditions, omitempty"`
 }
 
 func (s Statement) String() string {
-	return fmt.Sprintf("[effect: %v, actions: %v, resources: %v]", s.Effect, s.Actions, s.Resources)
+	return fmt.Sprintf("[effect: %v, actions: %v, resources: %v, conditions: %v]", s.Effect, s.Actions, s.Resources, s.Conditions)
 }
 
-// POLICY API IMPLEMENTATION
-
-func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error) {
-	// Validate fields
-	if !IsValidName(name) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: name %v", name),
-		}
-	}
-	if !IsValidOrg(org) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: org %v", org),
-		}
-	}
-	if !IsValidPath(path) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: path %v", path),
-		}
-
-	}
-	err := AreValidStatements(&statements)
-	if err != nil {
-		apiError := err.(*Error)
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: apiError.Message,
-		}
+// Condition that the request context must satisfy for the statement to apply.
+// The value of the context key must match any of the condition values using the operator.
+type Condition struct {
+	Operator string   `json:"operator, omitempty"`
+	Key      string   `json:"key, omitempty"`
+	Values   []string `json:"values, omitempty"`
+}
 
-	}
+func (c Condition) String() string {
+	return fmt.Sprintf("[operator: %v, key: %v, values: %v]", c.Operator, c.Key, c.Values)
+}
 
-	policy := createPolicy(name, path, org, &statements)
+// POLICY API IMPLEMENTATION
 
-	// Check restrictions
-	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_CREATE_POLICY, []Policy{policy})
+func (api AuthAPI) AddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error) {
+	policy, err := api.checkAddPolicy(requestInfo, name, path, org, statements)
 	if err != nil {
 		return nil, err
 	}
-	if len(policiesFiltered) < 1 {
-		return nil, &Error{
-			Code: UNAUTHORIZED_RESOURCES_ERROR,
-			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, policy.Urn),
-		}
-	}
 
-	// Check if policy already exists
-	_, err = api.PolicyRepo.GetPolicyByName(org, name)
+	// Create policy with its first version
+	createdPolicy, err := api.PolicyRepo.AddPolicy(*policy, createPolicyVersion(policy, requestInfo.Identifier, time.Now().UTC()))
 
-	// Check if policy could be retrieved
+	// Check if there is an unexpected error in DB
 	if err != nil {
-		// Transform to DB error
+		//Transform to DB error
 		dbError := err.(*database.Error)
-		switch dbError.Code {
-		// Policy doesn't exist in DB
-		case database.POLICY_NOT_FOUND:
-			// Create policy
-			createdPolicy, err := api.PolicyRepo.AddPolicy(policy)
-
-			// Check if there is an unexpected error in DB
-			if err != nil {
-				//Transform to DB error
-				dbError := err.(*database.Error)
-				return nil, &Error{
-					Code:    UNKNOWN_API_ERROR,
-					Message: dbError.Message,
-				}
-			}
-
-			LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy created %+v", createdPolicy))
-			return createdPolicy, nil
-		default: // Unexpected error
-			return nil, &Error{
-				Code:    UNKNOWN_API_ERROR,
-				Message: dbError.Message,
-			}
-		}
-	} else { // Fail if policy exists
 		return nil, &Error{
-			Code:    POLICY_ALREADY_EXIST,
-			Message: fmt.Sprintf("Unable to create policy, policy with org %v and name %v already exist", org, name),
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
 		}
 	}
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy created %+v", createdPolicy))
+	api.recordAuditEvent(requestInfo, POLICY_ACTION_CREATE_POLICY, createdPolicy.Urn, nil, createdPolicy)
+	return createdPolicy, nil
 }
 
 func (api AuthAPI) GetPolicyByName(requestInfo RequestInfo, org string, policyName string) (*Policy, error) {
@@ -254,189 +206,287 @@ func (api AuthAPI) ListPolicies(requestInfo RequestInfo, org string, filter *Fil
 
 func (api AuthAPI) UpdatePolicy(requestInfo RequestInfo, org string, policyName string, newName string, newPath string,
 	newStatements []Statement) (*Policy, error) {
-	// Validate fields
-	if !IsValidName(newName) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: new name %v", newName),
-		}
-	}
-	if !IsValidPath(newPath) {
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: new path %v", newPath),
-		}
-
-	}
-	err := AreValidStatements(&newStatements)
-	if err != nil {
-		apiError := err.(*Error)
-		return nil, &Error{
-			Code:    INVALID_PARAMETER_ERROR,
-			Message: apiError.Message,
-		}
+	return api.updatePolicy(requestInfo, POLICY_ACTION_UPDATE_POLICY, org, policyName, newName, newPath, newStatements)
+}
 
-	}
+func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string) error {
 
 	// Call repo to retrieve the policy
-	policyDB, err := api.GetPolicyByName(requestInfo, org, policyName)
+	policy, err := api.GetPolicyByName(requestInfo, org, name)
 	if err != nil {
-		return nil, err
+		return err
 	}
 
 	// Check restrictions
-	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policyDB.Urn, POLICY_ACTION_UPDATE_POLICY, []Policy{*policyDB})
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_DELETE_POLICY, []Policy{*policy})
 	if err != nil {
-		return nil, err
+		return err
 	}
 	if len(policiesFiltered) < 1 {
-		return nil, &Error{
+		return &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, policyDB.Urn),
+				requestInfo.Identifier, policy.Urn),
 		}
 	}
 
-	// Check if policy with "newName" exists
-	targetPolicy, err := api.GetPolicyByName(requestInfo, org, newName)
-
-	if err == nil && targetPolicy.ID != policyDB.ID {
-		// Policy already exists
-		return nil, &Error{
-			Code:    POLICY_ALREADY_EXIST,
-			Message: fmt.Sprintf("Policy name: %v already exists", newName),
+	err = api.PolicyRepo.RemovePolicy(policy.ID)
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
 		}
 	}
-	if err != nil {
-		if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR || apiError.Code == UNKNOWN_API_ERROR {
-			return nil, err
+
+	// Users with this policy attached may have different statements now
+	api.Cache.invalidateAll()
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy deleted %+v", policy))
+	api.recordAuditEvent(requestInfo, POLICY_ACTION_DELETE_POLICY, policy.Urn, policy, nil)
+	return nil
+}
+
+func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name string, filter *Filter) ([]string, int, error) {
+	// Validate fields
+	var total int
+	if filter.Limit > MAX_LIMIT_SIZE {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
 		}
 	}
 
-	// Get Policy Updated
-	policyToUpdate := createPolicy(newName, newPath, org, &newStatements)
+	if filter.Limit == 0 {
+		filter.Limit = DEFAULT_LIMIT_SIZE
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, name)
+	if err != nil {
+		return nil, total, err
+	}
 
 	// Check restrictions
-	policiesFiltered, err = api.GetAuthorizedPolicies(requestInfo, policyToUpdate.Urn, POLICY_ACTION_UPDATE_POLICY, []Policy{policyToUpdate})
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_LIST_ATTACHED_GROUPS, []Policy{*policy})
 	if err != nil {
-		return nil, err
+		return nil, total, err
 	}
 	if len(policiesFiltered) < 1 {
-		return nil, &Error{
+		return nil, total, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, policyToUpdate.Urn),
+				requestInfo.Identifier, policy.Urn),
 		}
 	}
 
-	// Update policy
-	policy, err := api.PolicyRepo.UpdatePolicy(*policyDB, newName, newPath, policyToUpdate.Urn, newStatements)
+	// Call repo to retrieve the attached groups
+	groups, total, err := api.PolicyRepo.GetAttachedGroups(policy.ID, filter)
 
-	// Check unexpected DB error
+	// Error handling
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return nil, &Error{
+		return nil, total, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
 	}
 
-	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy updated from %+v to %+v", policyDB, policy))
-	return policy, nil
+	groupNames := []string{}
+	for _, g := range groups {
+		groupNames = append(groupNames, g.Name)
+	}
+
+	return groupNames, total, nil
 }
 
-func (api AuthAPI) RemovePolicy(requestInfo RequestInfo, org string, name string) error {
+// PRIVATE HELPER METHODS
 
-	// Call repo to retrieve the policy
-	policy, err := api.GetPolicyByName(requestInfo, org, name)
+// Check that requestInfo can create the policy, returning the policy to create
+func (api AuthAPI) checkAddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement) (*Policy, error) {
+	// Validate fields
+	if !IsValidName(name) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: name %v", name),
+		}
+	}
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+	if !IsValidPath(path) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: path %v", path),
+		}
+
+	}
+	err := AreValidStatements(&statements)
 	if err != nil {
-		return err
+		apiError := err.(*Error)
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: apiError.Message,
+		}
+
 	}
 
+	policy := createPolicy(name, path, org, &statements)
+
 	// Check restrictions
-	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_DELETE_POLICY, []Policy{*policy})
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_CREATE_POLICY, []Policy{policy})
 	if err != nil {
-		return err
+		return nil, err
 	}
 	if len(policiesFiltered) < 1 {
-		return &Error{
+		return nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
 				requestInfo.Identifier, policy.Urn),
 		}
 	}
 
-	err = api.PolicyRepo.RemovePolicy(policy.ID)
+	// Check if policy already exists
+	_, err = api.PolicyRepo.GetPolicyByName(org, name)
+
+	// Fail if policy exists
+	if err == nil {
+		return nil, &Error{
+			Code:    POLICY_ALREADY_EXIST,
+			Message: fmt.Sprintf("Unable to create policy, policy with org %v and name %v already exist", org, name),
+		}
+	}
+
+	// Transform to DB error
+	dbError := err.(*database.Error)
+	if dbError.Code != database.POLICY_NOT_FOUND {
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return &policy, nil
+}
+
+// Update policy checking that requestInfo is allowed to do the action over the policy, before and after the change
+func (api AuthAPI) updatePolicy(requestInfo RequestInfo, action string, org string, policyName string, newName string, newPath string,
+	newStatements []Statement) (*Policy, error) {
+	policyDB, policyToUpdate, err := api.checkUpdatePolicy(requestInfo, action, org, policyName, newName, newPath, newStatements)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update policy with its new version
+	policy, err := api.PolicyRepo.UpdatePolicy(*policyDB, newName, newPath, policyToUpdate.Urn, newStatements,
+		createPolicyVersion(&Policy{ID: policyDB.ID, Name: newName, Path: newPath, Statements: &newStatements},
+			requestInfo.Identifier, time.Now().UTC()))
+
+	// Check unexpected DB error
 	if err != nil {
 		//Transform to DB error
 		dbError := err.(*database.Error)
-		return &Error{
+		return nil, &Error{
 			Code:    UNKNOWN_API_ERROR,
 			Message: dbError.Message,
 		}
 	}
 
-	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy deleted %+v", policy))
-	return nil
+	// Users with this policy attached may have different statements now
+	api.Cache.invalidateAll()
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy updated from %+v to %+v", policyDB, policy))
+	api.recordAuditEvent(requestInfo, action, policy.Urn, policyDB, policy)
+	return policy, nil
 }
 
-func (api AuthAPI) ListAttachedGroups(requestInfo RequestInfo, org string, name string, filter *Filter) ([]string, int, error) {
+// Check that requestInfo is allowed to do the action over the policy, before and after the change, returning
+// the stored policy and the updated one
+func (api AuthAPI) checkUpdatePolicy(requestInfo RequestInfo, action string, org string, policyName string, newName string, newPath string,
+	newStatements []Statement) (*Policy, *Policy, error) {
 	// Validate fields
-	var total int
-	if filter.Limit > MAX_LIMIT_SIZE {
-		return nil, total, &Error{
+	if !IsValidName(newName) {
+		return nil, nil, &Error{
 			Code:    INVALID_PARAMETER_ERROR,
-			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
+			Message: fmt.Sprintf("Invalid parameter: new name %v", newName),
 		}
 	}
+	if !IsValidPath(newPath) {
+		return nil, nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: new path %v", newPath),
+		}
+
+	}
+	err := AreValidStatements(&newStatements)
+	if err != nil {
+		apiError := err.(*Error)
+		return nil, nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: apiError.Message,
+		}
 
-	if filter.Limit == 0 {
-		filter.Limit = DEFAULT_LIMIT_SIZE
 	}
 
 	// Call repo to retrieve the policy
-	policy, err := api.GetPolicyByName(requestInfo, org, name)
+	policyDB, err := api.GetPolicyByName(requestInfo, org, policyName)
 	if err != nil {
-		return nil, total, err
+		return nil, nil, err
 	}
 
 	// Check restrictions
-	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, POLICY_ACTION_LIST_ATTACHED_GROUPS, []Policy{*policy})
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policyDB.Urn, action, []Policy{*policyDB})
 	if err != nil {
-		return nil, total, err
+		return nil, nil, err
 	}
 	if len(policiesFiltered) < 1 {
-		return nil, total, &Error{
+		return nil, nil, &Error{
 			Code: UNAUTHORIZED_RESOURCES_ERROR,
 			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
-				requestInfo.Identifier, policy.Urn),
+				requestInfo.Identifier, policyDB.Urn),
 		}
 	}
 
-	// Call repo to retrieve the attached groups
-	groups, total, err := api.PolicyRepo.GetAttachedGroups(policy.ID, filter)
+	// Check if policy with "newName" exists
+	targetPolicy, err := api.GetPolicyByName(requestInfo, org, newName)
 
-	// Error handling
+	if err == nil && targetPolicy.ID != policyDB.ID {
+		// Policy already exists
+		return nil, nil, &Error{
+			Code:    POLICY_ALREADY_EXIST,
+			Message: fmt.Sprintf("Policy name: %v already exists", newName),
+		}
+	}
 	if err != nil {
-		//Transform to DB error
-		dbError := err.(*database.Error)
-		return nil, total, &Error{
-			Code:    UNKNOWN_API_ERROR,
-			Message: dbError.Message,
+		if apiError := err.(*Error); apiError.Code == UNAUTHORIZED_RESOURCES_ERROR || apiError.Code == UNKNOWN_API_ERROR {
+			return nil, nil, err
 		}
 	}
 
-	groupNames := []string{}
-	for _, g := range groups {
-		groupNames = append(groupNames, g.Name)
+	// Get Policy Updated
+	policyToUpdate := createPolicy(newName, newPath, org, &newStatements)
+
+	// Check restrictions
+	policiesFiltered, err = api.GetAuthorizedPolicies(requestInfo, policyToUpdate.Urn, action, []Policy{policyToUpdate})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(policiesFiltered) < 1 {
+		return nil, nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, policyToUpdate.Urn),
+		}
 	}
 
-	return groupNames, total, nil
+	return policyDB, &policyToUpdate, nil
 }
 
-// PRIVATE HELPER METHODS
-
 func createPolicy(name string, path string, org string, statements *[]Statement) Policy {
 	urn := CreateUrn(org, RESOURCE_POLICY, path, name)
 	policy := Policy{
@@ -0,0 +1,352 @@
+package api
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Action and resource whose restrictions are compared in a dry run
+type PermissionCheck struct {
+	Action   string `json:"action, omitempty"`
+	Resource string `json:"resource, omitempty"`
+}
+
+// Restrictions of a user for an action and resource that are different after a change
+type PermissionChange struct {
+	User     string        `json:"user, omitempty"`
+	Action   string        `json:"action, omitempty"`
+	Resource string        `json:"resource, omitempty"`
+	Before   *Restrictions `json:"before, omitempty"`
+	After    *Restrictions `json:"after, omitempty"`
+}
+
+// Effect of a change over permissions of the users affected by it. If no checks are received,
+// they are sampled from actions and resources of the statements that change.
+type PermissionImpact struct {
+	Checks  []PermissionCheck  `json:"checks, omitempty"`
+	Users   []string           `json:"users, omitempty"`
+	Changes []PermissionChange `json:"changes, omitempty"`
+}
+
+// DRY RUN API IMPLEMENTATION
+
+func (api AuthAPI) DryRunAddPolicy(requestInfo RequestInfo, name string, path string, org string, statements []Statement,
+	checks []PermissionCheck) (*PermissionImpact, error) {
+	if err := validatePermissionChecks(checks); err != nil {
+		return nil, err
+	}
+
+	if _, err := api.checkAddPolicy(requestInfo, name, path, org, statements); err != nil {
+		return nil, err
+	}
+
+	// A new policy isn't attached to anyone yet
+	if len(checks) < 1 {
+		checks = samplePermissionChecks(statements)
+	}
+	return &PermissionImpact{
+		Checks:  checks,
+		Users:   []string{},
+		Changes: []PermissionChange{},
+	}, nil
+}
+
+func (api AuthAPI) DryRunUpdatePolicy(requestInfo RequestInfo, org string, policyName string, newName string, newPath string,
+	newStatements []Statement, checks []PermissionCheck) (*PermissionImpact, error) {
+	if err := validatePermissionChecks(checks); err != nil {
+		return nil, err
+	}
+
+	policyDB, _, err := api.checkUpdatePolicy(requestInfo, POLICY_ACTION_UPDATE_POLICY, org, policyName, newName, newPath, newStatements)
+	if err != nil {
+		return nil, err
+	}
+
+	// Users affected are the ones attached to the policy and members of groups attached to it
+	users, err := api.getPolicyUsers(policyDB.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checks) < 1 {
+		checks = samplePermissionChecks(append(append([]Statement{}, *policyDB.Statements...), newStatements...))
+	}
+	policyID := PolicyIdentity{Org: policyDB.Org, Name: policyDB.Name}
+	return api.getPermissionImpact(requestInfo, users, checks, func(_ *GroupIdentity, p PolicyIdentity, statements []Statement) []Statement {
+		if p == policyID {
+			return newStatements
+		}
+		return statements
+	}, nil)
+}
+
+func (api AuthAPI) DryRunAttachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string,
+	checks []PermissionCheck) (*PermissionImpact, error) {
+	if err := validatePermissionChecks(checks); err != nil {
+		return nil, err
+	}
+
+	group, policy, err := api.checkAttachPolicyToGroup(requestInfo, org, name, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := api.getGroupUsers(group.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checks) < 1 {
+		checks = samplePermissionChecks(*policy.Statements)
+	}
+	return api.getPermissionImpact(requestInfo, users, checks, func(_ *GroupIdentity, _ PolicyIdentity, statements []Statement) []Statement {
+		return statements
+	}, *policy.Statements)
+}
+
+func (api AuthAPI) DryRunDetachPolicyToGroup(requestInfo RequestInfo, org string, name string, policyName string,
+	checks []PermissionCheck) (*PermissionImpact, error) {
+	if err := validatePermissionChecks(checks); err != nil {
+		return nil, err
+	}
+
+	groupDB, policyDB, err := api.checkDetachPolicyToGroup(requestInfo, org, name, policyName)
+	if err != nil {
+		return nil, err
+	}
+
+	users, err := api.getGroupUsers(groupDB.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(checks) < 1 {
+		checks = samplePermissionChecks(*policyDB.Statements)
+	}
+	// Policy is only removed from this group, users may have it from other groups or attached directly
+	groupID := GroupIdentity{Org: groupDB.Org, Name: groupDB.Name}
+	policyID := PolicyIdentity{Org: policyDB.Org, Name: policyDB.Name}
+	return api.getPermissionImpact(requestInfo, users, checks, func(g *GroupIdentity, p PolicyIdentity, statements []Statement) []Statement {
+		if g != nil && *g == groupID && p == policyID {
+			return nil
+		}
+		return statements
+	}, nil)
+}
+
+// PRIVATE HELPER METHODS
+
+// Compare restrictions of the users before and after the change. change receives the statements of a policy
+// attached to the group, or directly to the user if group is nil, and returns the ones that the attachment will have.
+// added are new statements of all users.
+func (api AuthAPI) getPermissionImpact(requestInfo RequestInfo, users []User, checks []PermissionCheck,
+	change func(group *GroupIdentity, policy PolicyIdentity, statements []Statement) []Statement, added []Statement) (*PermissionImpact, error) {
+	impact := &PermissionImpact{
+		Checks:  checks,
+		Users:   []string{},
+		Changes: []PermissionChange{},
+	}
+	for _, user := range users {
+		before, after, err := api.getUserStatements(user.ExternalID, change)
+		if err != nil {
+			return nil, err
+		}
+		after = append(after, added...)
+
+		impact.Users = append(impact.Users, user.ExternalID)
+		for _, check := range checks {
+			beforeRestrictions := getRestrictionsByAction(before, check.Action, check.Resource, requestInfo.Context)
+			afterRestrictions := getRestrictionsByAction(after, check.Action, check.Resource, requestInfo.Context)
+			if !isSameRestrictions(beforeRestrictions, afterRestrictions) {
+				impact.Changes = append(impact.Changes, PermissionChange{
+					User:     user.ExternalID,
+					Action:   check.Action,
+					Resource: check.Resource,
+					Before:   beforeRestrictions,
+					After:    afterRestrictions,
+				})
+			}
+		}
+	}
+	return impact, nil
+}
+
+// Retrieve effective statements of the user, currently and after the change of every policy attachment.
+// Both come from the same effective statements used to authorize.
+func (api AuthAPI) getUserStatements(externalID string,
+	change func(group *GroupIdentity, policy PolicyIdentity, statements []Statement) []Statement) ([]Statement, []Statement, error) {
+	effectiveStatements, err := api.getEffectiveStatementOrigins(externalID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	before := []Statement{}
+	for _, effectiveStatement := range getUniqueEffectiveStatements(effectiveStatements) {
+		before = append(before, effectiveStatement.Statement)
+	}
+
+	// Statements of an attachment are together, apply the change to each attachment
+	after := []Statement{}
+	for i := 0; i < len(effectiveStatements); {
+		group := effectiveStatements[i].Group
+		policy := effectiveStatements[i].Policy
+		statements := []Statement{}
+		for ; i < len(effectiveStatements) && isSameGroupIdentity(group, effectiveStatements[i].Group) &&
+			effectiveStatements[i].Policy == policy; i++ {
+			statements = append(statements, effectiveStatements[i].Statement)
+		}
+		after = append(after, change(group, policy, statements)...)
+	}
+
+	return before, after, nil
+}
+
+// Retrieve users attached to the policy and members of groups attached to it
+func (api AuthAPI) getPolicyUsers(policyID string) ([]User, error) {
+	users, _, err := api.PolicyRepo.GetAttachedUsers(policyID, &Filter{})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	groups, _, err := api.PolicyRepo.GetAttachedGroups(policyID, &Filter{})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	for _, group := range groups {
+		members, err := api.getGroupUsers(group.ID)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, members...)
+	}
+
+	// Remove duplicated users
+	userIDs := map[string]bool{}
+	uniqueUsers := []User{}
+	for _, user := range users {
+		if !userIDs[user.ID] {
+			userIDs[user.ID] = true
+			uniqueUsers = append(uniqueUsers, user)
+		}
+	}
+	return uniqueUsers, nil
+}
+
+// Retrieve members of the group
+func (api AuthAPI) getGroupUsers(groupID string) ([]User, error) {
+	members, _, err := api.GroupRepo.GetGroupMembers(groupID, &Filter{})
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+	return members, nil
+}
+
+func validatePermissionChecks(checks []PermissionCheck) error {
+	for _, check := range checks {
+		if err := AreValidActions([]string{check.Action}); err != nil {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: action %v", check.Action),
+			}
+		}
+		// Authorization only matches action prefixes of statements with full actions
+		if strings.ContainsAny(check.Action, "*") {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: action %v. Action prefixes are not allowed here", check.Action),
+			}
+		}
+		if err := AreValidResources([]string{check.Resource}); err != nil {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: resource %v", check.Resource),
+			}
+		}
+	}
+	return nil
+}
+
+// Every action with every resource of each statement, without duplicates. Action prefixes are replaced by
+// the full actions of the statements that they match, or skipped if they don't match any.
+func samplePermissionChecks(statements []Statement) []PermissionCheck {
+	fullActions := []string{}
+	for _, statement := range statements {
+		for _, action := range statement.Actions {
+			if !strings.ContainsAny(action, "*") {
+				fullActions = append(fullActions, action)
+			}
+		}
+	}
+
+	checks := []PermissionCheck{}
+	sampled := map[PermissionCheck]bool{}
+	for _, statement := range statements {
+		for _, action := range statement.Actions {
+			for _, fullAction := range getFullActions(action, fullActions) {
+				for _, resource := range statement.Resources {
+					check := PermissionCheck{
+						Action:   fullAction,
+						Resource: resource,
+					}
+					if !sampled[check] {
+						sampled[check] = true
+						checks = append(checks, check)
+					}
+				}
+			}
+		}
+	}
+	return checks
+}
+
+// Full actions matched by an action, that can be a prefix. IAM action prefixes match IAM actions too.
+func getFullActions(action string, fullActions []string) []string {
+	if !strings.ContainsAny(action, "*") {
+		return []string{action}
+	}
+	if strings.HasPrefix(action, IAM_ACTION_PREFIX) {
+		fullActions = append(append([]string{}, fullActions...), iamActions...)
+	}
+	matched := []string{}
+	for _, fullAction := range fullActions {
+		if isActionContained(fullAction, []string{action}) {
+			matched = append(matched, fullAction)
+		}
+	}
+	return matched
+}
+
+// Compare restrictions without taking into account the order of the urns
+func isSameRestrictions(a *Restrictions, b *Restrictions) bool {
+	return reflect.DeepEqual(sortedStrings(a.AllowedUrnPrefixes), sortedStrings(b.AllowedUrnPrefixes)) &&
+		reflect.DeepEqual(sortedStrings(a.AllowedFullUrns), sortedStrings(b.AllowedFullUrns)) &&
+		reflect.DeepEqual(sortedStrings(a.DeniedUrnPrefixes), sortedStrings(b.DeniedUrnPrefixes)) &&
+		reflect.DeepEqual(sortedStrings(a.DeniedFullUrns), sortedStrings(b.DeniedFullUrns))
+}
+
+func sortedStrings(values []string) []string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return sorted
+}
@@ -0,0 +1,725 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+func TestAuthAPI_DryRunAddPolicy(t *testing.T) {
+	statements := []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{USER_ACTION_GET_USER, USER_ACTION_LIST_USERS},
+			Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		name        string
+		path        string
+		org         string
+		statements  []Statement
+		checks      []PermissionCheck
+		// Expected result
+		expectedResult *PermissionImpact
+		wantError      error
+		// Manager Errors
+		getPolicyByNameMethodErr error
+	}{
+		"OKCaseSampledChecks": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:       "test",
+			path:       "/path/",
+			org:        "123",
+			statements: statements,
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+					{
+						Action:   USER_ACTION_LIST_USERS,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+				Users:   []string{},
+				Changes: []PermissionChange{},
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+		},
+		"OKCaseReceivedChecks": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:       "test",
+			path:       "/path/",
+			org:        "123",
+			statements: statements,
+			checks: []PermissionCheck{
+				{
+					Action:   USER_ACTION_GET_USER,
+					Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+					},
+				},
+				Users:   []string{},
+				Changes: []PermissionChange{},
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+		},
+		"ErrorCaseInvalidCheck": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:       "test",
+			path:       "/path/",
+			org:        "123",
+			statements: statements,
+			checks: []PermissionCheck{
+				{
+					Action:   "**",
+					Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: action **",
+			},
+		},
+		"ErrorCaseActionPrefixCheck": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:       "test",
+			path:       "/path/",
+			org:        "123",
+			statements: statements,
+			checks: []PermissionCheck{
+				{
+					Action:   "iam:Get*",
+					Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: action iam:Get*. Action prefixes are not allowed here",
+			},
+		},
+		"ErrorCasePolicyAlreadyExist": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			name:       "test",
+			path:       "/path/",
+			org:        "123",
+			statements: statements,
+			wantError: &Error{
+				Code:    POLICY_ALREADY_EXIST,
+				Message: "Unable to create policy, policy with org 123 and name test already exist",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = &Policy{}
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = test.getPolicyByNameMethodErr
+
+		impact, err := testAPI.DryRunAddPolicy(test.requestInfo, test.name, test.path, test.org, test.statements, test.checks)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, impact)
+
+		// Check that nothing is stored
+		if testRepo.ArgsIn[AddPolicyMethod][0] != nil {
+			t.Errorf("Test %v failed. Policy stored in dry run", n)
+		}
+	}
+}
+
+func TestAuthAPI_DryRunUpdatePolicy(t *testing.T) {
+	oldPolicy := &Policy{
+		ID:   "test1",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{USER_ACTION_GET_USER},
+				Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+			},
+		},
+	}
+	newStatements := []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{USER_ACTION_GET_USER},
+			Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path2/")},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		org         string
+		policyName  string
+		checks      []PermissionCheck
+		// Expected result
+		expectedResult *PermissionImpact
+		wantError      error
+		// Manager Results
+		getAttachedUsersResult        []User
+		getAttachedGroupsResult       []Group
+		getGroupMembersResult         []User
+		getAttachedUserPoliciesResult []Policy
+		// Manager Errors
+		getPolicyByNameMethodErr  error
+		getAttachedUsersMethodErr error
+	}{
+		"OKCaseUserAndGroupMember": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path2/"),
+					},
+				},
+				Users: []string{"user1", "user2"},
+				Changes: []PermissionChange{
+					{
+						User:     "user1",
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						Before: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+						After: &Restrictions{
+							AllowedUrnPrefixes: []string{},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+					},
+					{
+						User:     "user1",
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path2/"),
+						Before: &Restrictions{
+							AllowedUrnPrefixes: []string{},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+						After: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path2/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+					},
+					{
+						User:     "user2",
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						Before: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+						After: &Restrictions{
+							AllowedUrnPrefixes: []string{},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+					},
+					{
+						User:     "user2",
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path2/"),
+						Before: &Restrictions{
+							AllowedUrnPrefixes: []string{},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+						After: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path2/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+					},
+				},
+			},
+			getAttachedUsersResult: []User{
+				{
+					ID:         "1",
+					ExternalID: "user1",
+				},
+			},
+			getAttachedGroupsResult: []Group{
+				{
+					ID:   "G1",
+					Name: "group1",
+				},
+			},
+			getGroupMembersResult: []User{
+				{
+					ID:         "1",
+					ExternalID: "user1",
+				},
+				{
+					ID:         "2",
+					ExternalID: "user2",
+				},
+			},
+			getAttachedUserPoliciesResult: []Policy{*oldPolicy},
+		},
+		"OKCaseReceivedChecksWithoutChanges": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			checks: []PermissionCheck{
+				{
+					Action:   USER_ACTION_DELETE_USER,
+					Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+				},
+			},
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_DELETE_USER,
+						Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+					},
+				},
+				Users:   []string{"user1"},
+				Changes: []PermissionChange{},
+			},
+			getAttachedUsersResult: []User{
+				{
+					ID:         "1",
+					ExternalID: "user1",
+				},
+			},
+			getAttachedUserPoliciesResult: []Policy{*oldPolicy},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			wantError: &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy not found",
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: "Policy not found",
+			},
+		},
+		"ErrorCaseGetAttachedUsersDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getAttachedUsersMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{ID: "1", ExternalID: "user1"}
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = oldPolicy
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = test.getPolicyByNameMethodErr
+		testRepo.ArgsOut[GetAttachedUsersMethod][0] = test.getAttachedUsersResult
+		testRepo.ArgsOut[GetAttachedUsersMethod][2] = test.getAttachedUsersMethodErr
+		testRepo.ArgsOut[GetAttachedGroupsMethod][0] = test.getAttachedGroupsResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = test.getGroupMembersResult
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = test.getAttachedUserPoliciesResult
+
+		impact, err := testAPI.DryRunUpdatePolicy(test.requestInfo, test.org, test.policyName, "test", "/path/", newStatements, test.checks)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, impact)
+
+		// Check that nothing is stored
+		if testRepo.ArgsIn[UpdatePolicyMethod][0] != nil {
+			t.Errorf("Test %v failed. Policy updated in dry run", n)
+		}
+	}
+}
+
+func TestAuthAPI_DryRunAttachPolicyToGroup(t *testing.T) {
+	group := &Group{
+		ID:   "G1",
+		Name: "group1",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+	}
+	policy := &Policy{
+		ID:   "test1",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Effect:    "deny",
+				Actions:   []string{USER_ACTION_GET_USER},
+				Resources: []string{CreateUrn("", RESOURCE_USER, "/path/", "user1")},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		// Expected result
+		expectedResult *PermissionImpact
+		wantError      error
+		// Manager Results
+		isAttachedToGroupResult bool
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+					},
+				},
+				Users: []string{"user1"},
+				Changes: []PermissionChange{
+					{
+						User:     "user1",
+						Action:   USER_ACTION_GET_USER,
+						Resource: CreateUrn("", RESOURCE_USER, "/path/", "user1"),
+						Before: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+						After: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{CreateUrn("", RESOURCE_USER, "/path/", "user1")},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCasePolicyIsAlreadyAttached": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    POLICY_IS_ALREADY_ATTACHED_TO_GROUP,
+				Message: "Policy: test is already attached to Group: group1",
+			},
+			isAttachedToGroupResult: true,
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{ID: "1", ExternalID: "user1"}
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = group
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = policy
+		testRepo.ArgsOut[IsAttachedToGroupMethod][0] = test.isAttachedToGroupResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = []User{
+			{
+				ID:         "1",
+				ExternalID: "user1",
+			},
+		}
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{*group}
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = []Policy{
+			{
+				ID: "test2",
+				Statements: &[]Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{USER_ACTION_GET_USER},
+						Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+					},
+				},
+			},
+		}
+
+		impact, err := testAPI.DryRunAttachPolicyToGroup(test.requestInfo, "123", "group1", "test", nil)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, impact)
+
+		// Check that nothing is stored
+		if testRepo.ArgsIn[AttachPolicyMethod][0] != nil {
+			t.Errorf("Test %v failed. Policy attached in dry run", n)
+		}
+	}
+}
+
+func TestAuthAPI_DryRunDetachPolicyToGroup(t *testing.T) {
+	group := &Group{
+		ID:   "G1",
+		Name: "group1",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "group1"),
+	}
+	policy := &Policy{
+		ID:   "test1",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{USER_ACTION_GET_USER},
+				Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		requestInfo RequestInfo
+		// Expected result
+		expectedResult *PermissionImpact
+		wantError      error
+		// Manager Results
+		isAttachedToGroupResult       bool
+		getAttachedUserPoliciesResult []Policy
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+				Users: []string{"user1"},
+				Changes: []PermissionChange{
+					{
+						User:     "user1",
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+						Before: &Restrictions{
+							AllowedUrnPrefixes: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+						After: &Restrictions{
+							AllowedUrnPrefixes: []string{},
+							AllowedFullUrns:    []string{},
+							DeniedUrnPrefixes:  []string{},
+							DeniedFullUrns:     []string{},
+						},
+					},
+				},
+			},
+			isAttachedToGroupResult: true,
+		},
+		"OKCasePolicyAttachedToUser": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			expectedResult: &PermissionImpact{
+				Checks: []PermissionCheck{
+					{
+						Action:   USER_ACTION_GET_USER,
+						Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+					},
+				},
+				Users:   []string{"user1"},
+				Changes: []PermissionChange{},
+			},
+			isAttachedToGroupResult:       true,
+			getAttachedUserPoliciesResult: []Policy{*policy},
+		},
+		"ErrorCasePolicyIsNotAttached": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			wantError: &Error{
+				Code:    POLICY_IS_NOT_ATTACHED_TO_GROUP,
+				Message: "Policy with org 123 and name test is not attached to group with org 123 and name group1",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = &User{ID: "1", ExternalID: "user1"}
+
+		testRepo.ArgsOut[GetGroupByNameMethod][0] = group
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = policy
+		testRepo.ArgsOut[IsAttachedToGroupMethod][0] = test.isAttachedToGroupResult
+		testRepo.ArgsOut[GetGroupMembersMethod][0] = []User{
+			{
+				ID:         "1",
+				ExternalID: "user1",
+			},
+		}
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = test.getAttachedUserPoliciesResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = []Group{*group}
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = []Policy{*policy}
+
+		impact, err := testAPI.DryRunDetachPolicyToGroup(test.requestInfo, "123", "group1", "test", nil)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, impact)
+
+		// Check that nothing is removed
+		if testRepo.ArgsIn[DetachPolicyMethod][0] != nil {
+			t.Errorf("Test %v failed. Policy detached in dry run", n)
+		}
+	}
+}
+
+func TestSamplePermissionChecks(t *testing.T) {
+	testcases := map[string]struct {
+		statements     []Statement
+		expectedChecks []PermissionCheck
+	}{
+		"OkCaseFullActions": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:get", "example:list"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+				{
+					Effect:    "deny",
+					Actions:   []string{"example:get"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+			},
+			expectedChecks: []PermissionCheck{
+				{
+					Action:   "example:get",
+					Resource: "urn:ews:example:instance1:resource/*",
+				},
+				{
+					Action:   "example:list",
+					Resource: "urn:ews:example:instance1:resource/*",
+				},
+			},
+		},
+		"OkCaseActionPrefixes": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:*", "other:*"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+				{
+					Effect:    "deny",
+					Actions:   []string{"example:get"},
+					Resources: []string{"urn:ews:example:instance1:resource/private"},
+				},
+			},
+			expectedChecks: []PermissionCheck{
+				{
+					Action:   "example:get",
+					Resource: "urn:ews:example:instance1:resource/*",
+				},
+				{
+					Action:   "example:get",
+					Resource: "urn:ews:example:instance1:resource/private",
+				},
+			},
+		},
+		"OkCaseIAMActionPrefix": {
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"iam:ListAttached*"},
+					Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+				},
+			},
+			expectedChecks: []PermissionCheck{
+				{
+					Action:   USER_ACTION_LIST_ATTACHED_USER_POLICIES,
+					Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+				{
+					Action:   GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+					Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+				{
+					Action:   POLICY_ACTION_LIST_ATTACHED_GROUPS,
+					Resource: GetUrnPrefix("", RESOURCE_USER, "/path/"),
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		checks := samplePermissionChecks(test.statements)
+		checkMethodResponse(t, n, nil, nil, test.expectedChecks, checks)
+	}
+}
@@ -0,0 +1,342 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+const (
+	// Lint finding types
+	LINT_DUPLICATED_STATEMENT = "DuplicatedStatement"
+	LINT_SHADOWED_STATEMENT   = "ShadowedStatement"
+	LINT_REDUNDANT_RESOURCE   = "RedundantResource"
+	LINT_BROAD_WILDCARD       = "BroadWildcard"
+	LINT_UNKNOWN_ACTION       = "UnknownAction"
+	LINT_FOREIGN_ORG_URN      = "ForeignOrgUrn"
+
+	// Prefix of IAM actions and urns
+	IAM_ACTION_PREFIX = "iam:"
+	IAM_URN_PREFIX    = "urn:iws:iam:"
+
+	// Blocks of a complete urn, urn:namespace:product:instance:resource
+	URN_BLOCKS = 5
+)
+
+// Actions of the IAM API, used to know if an iam action in a statement exists
+var iamActions = []string{
+	USER_ACTION_CREATE_USER,
+	USER_ACTION_DELETE_USER,
+	USER_ACTION_GET_USER,
+	USER_ACTION_LIST_USERS,
+	USER_ACTION_UPDATE_USER,
+	USER_ACTION_LIST_GROUPS_FOR_USER,
+	USER_ACTION_EXPLAIN_AUTHORIZATION,
+	USER_ACTION_CHECK_AUTHORIZATION,
+	USER_ACTION_ATTACH_USER_POLICY,
+	USER_ACTION_DETACH_USER_POLICY,
+	USER_ACTION_LIST_ATTACHED_USER_POLICIES,
+	USER_ACTION_CREATE_API_KEY,
+	USER_ACTION_GET_API_KEY,
+	USER_ACTION_LIST_API_KEYS,
+	USER_ACTION_ROTATE_API_KEY,
+	USER_ACTION_REVOKE_API_KEY,
+	GROUP_ACTION_CREATE_GROUP,
+	GROUP_ACTION_DELETE_GROUP,
+	GROUP_ACTION_GET_GROUP,
+	GROUP_ACTION_LIST_GROUPS,
+	GROUP_ACTION_UPDATE_GROUP,
+	GROUP_ACTION_LIST_MEMBERS,
+	GROUP_ACTION_ADD_MEMBER,
+	GROUP_ACTION_REMOVE_MEMBER,
+	GROUP_ACTION_ATTACH_GROUP_POLICY,
+	GROUP_ACTION_DETACH_GROUP_POLICY,
+	GROUP_ACTION_LIST_ATTACHED_GROUP_POLICIES,
+	POLICY_ACTION_CREATE_POLICY,
+	POLICY_ACTION_DELETE_POLICY,
+	POLICY_ACTION_UPDATE_POLICY,
+	POLICY_ACTION_GET_POLICY,
+	POLICY_ACTION_LIST_ATTACHED_GROUPS,
+	POLICY_ACTION_LIST_POLICIES,
+	POLICY_ACTION_LIST_POLICY_VERSIONS,
+	POLICY_ACTION_GET_POLICY_VERSION,
+	POLICY_ACTION_ROLLBACK_POLICY,
+	AUDIT_ACTION_LIST_AUDIT_EVENTS,
+}
+
+// TYPE DEFINITIONS
+
+// Problem found in a statement of a policy. Statement is the position of the statement in the policy, starting at 0,
+// and Value is the action or resource affected, if any.
+type PolicyLintFinding struct {
+	Type      string `json:"type, omitempty"`
+	Statement int    `json:"statement, omitempty"`
+	Value     string `json:"value, omitempty"`
+	Message   string `json:"message, omitempty"`
+}
+
+// Findings of a policy analysis
+type PolicyLintReport struct {
+	Org      string              `json:"org, omitempty"`
+	Name     string              `json:"name, omitempty"`
+	Findings []PolicyLintFinding `json:"findings, omitempty"`
+}
+
+// POLICY LINT API IMPLEMENTATION
+
+func (api AuthAPI) LintPolicy(requestInfo RequestInfo, org string, name string, knownActions []string) (*PolicyLintReport, error) {
+	// Call repo to retrieve the policy
+	policy, err := api.GetPolicyByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PolicyLintReport{
+		Org:      policy.Org,
+		Name:     policy.Name,
+		Findings: LintStatements(policy.Org, *policy.Statements, knownActions),
+	}, nil
+}
+
+func (api AuthAPI) LintPolicies(requestInfo RequestInfo, org string, knownActions []string) ([]PolicyLintReport, error) {
+	// Validate fields
+	if !IsValidOrg(org) {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: org %v", org),
+		}
+	}
+
+	// Call repo to retrieve all policies of the organization
+	policies, _, err := api.PolicyRepo.GetPoliciesFiltered(org, &Filter{})
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Check restrictions to retrieve them
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, GetUrnPrefix(org, RESOURCE_POLICY, "/"), POLICY_ACTION_GET_POLICY, policies)
+	if err != nil {
+		return nil, err
+	}
+
+	reports := []PolicyLintReport{}
+	for _, policy := range policiesFiltered {
+		reports = append(reports, PolicyLintReport{
+			Org:      policy.Org,
+			Name:     policy.Name,
+			Findings: LintStatements(policy.Org, *policy.Statements, knownActions),
+		})
+	}
+
+	return reports, nil
+}
+
+// LintStatements analyzes the statements of a policy in the organization and returns the problems found.
+// Statements must be valid. Actions that don't start with iam: are only checked when known actions are received,
+// usually the actions configured in the proxy.
+func LintStatements(org string, statements []Statement, knownActions []string) []PolicyLintFinding {
+	findings := []PolicyLintFinding{}
+	for i, statement := range statements {
+		findings = append(findings, lintDuplicatedStatement(i, statements)...)
+		findings = append(findings, lintShadowedStatement(i, statements)...)
+		findings = append(findings, lintRedundantResources(i, statement)...)
+		findings = append(findings, lintBroadWildcards(i, statement)...)
+		findings = append(findings, lintUnknownActions(i, statement, knownActions)...)
+		findings = append(findings, lintForeignOrgUrns(i, statement, org)...)
+	}
+	return findings
+}
+
+// PRIVATE HELPER METHODS
+
+// Report a statement equal to a previous one
+func lintDuplicatedStatement(i int, statements []Statement) []PolicyLintFinding {
+	for j := 0; j < i; j++ {
+		if statements[j].String() == statements[i].String() {
+			return []PolicyLintFinding{
+				{
+					Type:      LINT_DUPLICATED_STATEMENT,
+					Statement: i,
+					Message:   fmt.Sprintf("Statement is a duplicate of statement %v", j),
+				},
+			}
+		}
+	}
+	return nil
+}
+
+// Report an allow statement whose actions and resources are all denied by statements without conditions,
+// so it never grants anything
+func lintShadowedStatement(i int, statements []Statement) []PolicyLintFinding {
+	if statements[i].Effect != "allow" {
+		return nil
+	}
+	for _, action := range statements[i].Actions {
+		for _, resource := range statements[i].Resources {
+			if !isDenied(action, resource, statements) {
+				return nil
+			}
+		}
+	}
+	return []PolicyLintFinding{
+		{
+			Type:      LINT_SHADOWED_STATEMENT,
+			Statement: i,
+			Message:   "Every action and resource of this statement is denied by deny statements",
+		},
+	}
+}
+
+// Report resources contained in other resources of the same statement
+func lintRedundantResources(i int, statement Statement) []PolicyLintFinding {
+	findings := []PolicyLintFinding{}
+	for j, resource := range statement.Resources {
+		for k, other := range statement.Resources {
+			// Equal resources are reported once, for the last one
+			if j == k || (resource == other && j < k) {
+				continue
+			}
+			if isCoveredBy(resource, other) {
+				findings = append(findings, PolicyLintFinding{
+					Type:      LINT_REDUNDANT_RESOURCE,
+					Statement: i,
+					Value:     resource,
+					Message:   fmt.Sprintf("Resource %v is contained in resource %v", resource, other),
+				})
+				break
+			}
+		}
+	}
+	return findings
+}
+
+// Report allowed actions with a wildcard in the service name and allowed resources with a wildcard
+// before the resource block, that give access to more than intended
+func lintBroadWildcards(i int, statement Statement) []PolicyLintFinding {
+	if statement.Effect != "allow" {
+		return nil
+	}
+	findings := []PolicyLintFinding{}
+	for _, action := range statement.Actions {
+		if isBroadAction(action) {
+			findings = append(findings, PolicyLintFinding{
+				Type:      LINT_BROAD_WILDCARD,
+				Statement: i,
+				Value:     action,
+				Message:   fmt.Sprintf("Action %v allows actions of several services", action),
+			})
+		}
+	}
+	for _, resource := range statement.Resources {
+		if !isFullUrn(resource) && len(strings.Split(resource, ":")) < URN_BLOCKS {
+			findings = append(findings, PolicyLintFinding{
+				Type:      LINT_BROAD_WILDCARD,
+				Statement: i,
+				Value:     resource,
+				Message:   fmt.Sprintf("Resource %v contains resources of several namespaces, products or instances", resource),
+			})
+		}
+	}
+	return findings
+}
+
+// Report actions that don't match any iam action or, if there are known actions, any of them
+func lintUnknownActions(i int, statement Statement, knownActions []string) []PolicyLintFinding {
+	findings := []PolicyLintFinding{}
+	for _, action := range statement.Actions {
+		// Broad actions are already reported
+		if isBroadAction(action) {
+			continue
+		}
+		actions := knownActions
+		if strings.HasPrefix(action, IAM_ACTION_PREFIX) {
+			actions = iamActions
+		} else if len(knownActions) < 1 {
+			continue
+		}
+		if !matchesAny(action, actions) {
+			findings = append(findings, PolicyLintFinding{
+				Type:      LINT_UNKNOWN_ACTION,
+				Statement: i,
+				Value:     action,
+				Message:   fmt.Sprintf("Action %v doesn't match any known action", action),
+			})
+		}
+	}
+	return findings
+}
+
+// Report IAM urns of other organizations. User urns don't have organization.
+func lintForeignOrgUrns(i int, statement Statement, org string) []PolicyLintFinding {
+	findings := []PolicyLintFinding{}
+	for _, resource := range statement.Resources {
+		if !strings.HasPrefix(resource, IAM_URN_PREFIX) {
+			continue
+		}
+		blocks := strings.Split(strings.TrimPrefix(resource, IAM_URN_PREFIX), ":")
+		if len(blocks) < 2 || blocks[0] == "" || blocks[0] == org {
+			continue
+		}
+		findings = append(findings, PolicyLintFinding{
+			Type:      LINT_FOREIGN_ORG_URN,
+			Statement: i,
+			Value:     resource,
+			Message:   fmt.Sprintf("Resource %v belongs to organization %v", resource, blocks[0]),
+		})
+	}
+	return findings
+}
+
+// Check if an action over a resource is denied by any statement without conditions
+func isDenied(action string, resource string, statements []Statement) bool {
+	for _, statement := range statements {
+		if statement.Effect != "deny" || len(statement.Conditions) > 0 {
+			continue
+		}
+		if isAnyCoveredBy(statement.Actions, action) && isAnyCoveredBy(statement.Resources, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the wildcard of an action is in the service name, before the ':' separator
+func isBroadAction(action string) bool {
+	return !isFullUrn(action) && !strings.Contains(strings.Trim(action, "*"), ":")
+}
+
+// Check if any of the patterns covers the value
+func isAnyCoveredBy(patterns []string, value string) bool {
+	for _, pattern := range patterns {
+		if isCoveredBy(value, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if the pattern matches any of the values
+func matchesAny(pattern string, values []string) bool {
+	for _, value := range values {
+		if isCoveredBy(value, pattern) {
+			return true
+		}
+	}
+	return false
+}
+
+// Check if every action or resource matched by value, that can be a prefix, is matched by pattern too
+func isCoveredBy(value string, pattern string) bool {
+	if isFullUrn(pattern) {
+		return value == pattern
+	}
+	return isContainedOrEqual(value, pattern)
+}
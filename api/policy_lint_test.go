@@ -0,0 +1,508 @@
+package api
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+func TestLintStatements(t *testing.T) {
+	testcases := map[string]struct {
+		org          string
+		statements   []Statement
+		knownActions []string
+
+		expectedFindings []PolicyLintFinding
+	}{
+		"OkCaseWithoutFindings": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Get*", USER_ACTION_GET_USER},
+					Resources: []string{"urn:ews:example:instance1:resource/*", GetUrnPrefix("", RESOURCE_USER, "/path/")},
+				},
+				{
+					Effect:    "deny",
+					Actions:   []string{"example:Get*"},
+					Resources: []string{"urn:ews:example:instance1:resource/private/*"},
+				},
+			},
+			knownActions:     []string{"example:GetResource"},
+			expectedFindings: []PolicyLintFinding{},
+		},
+		"OkCaseDuplicatedStatement": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Get"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Get"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+			},
+			expectedFindings: []PolicyLintFinding{
+				{
+					Type:      LINT_DUPLICATED_STATEMENT,
+					Statement: 1,
+					Message:   "Statement is a duplicate of statement 0",
+				},
+			},
+		},
+		"OkCaseShadowedStatement": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Get", "example:List"},
+					Resources: []string{"urn:ews:example:instance1:resource/path/*"},
+				},
+				{
+					Effect:    "deny",
+					Actions:   []string{"example:*"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Get"},
+					Resources: []string{"urn:ews:example:instance2:resource/*"},
+				},
+				{
+					Effect:    "deny",
+					Actions:   []string{"example:Get"},
+					Resources: []string{"urn:ews:example:instance2:resource/*"},
+					Conditions: []Condition{
+						{
+							Operator: CONDITION_OPERATOR_IP_ADDRESS,
+							Key:      CONDITION_KEY_SOURCE_IP,
+							Values:   []string{"10.0.0.0/8"},
+						},
+					},
+				},
+			},
+			expectedFindings: []PolicyLintFinding{
+				{
+					Type:      LINT_SHADOWED_STATEMENT,
+					Statement: 0,
+					Message:   "Every action and resource of this statement is denied by deny statements",
+				},
+			},
+		},
+		"OkCaseRedundantResources": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						"example:Get",
+					},
+					Resources: []string{
+						"urn:ews:example:instance1:resource/path/res1",
+						"urn:ews:example:instance1:resource/*",
+						"urn:ews:example:instance1:resource/*",
+						"urn:ews:example:instance1:resource/path/res2",
+					},
+				},
+			},
+			expectedFindings: []PolicyLintFinding{
+				{
+					Type:      LINT_REDUNDANT_RESOURCE,
+					Statement: 0,
+					Value:     "urn:ews:example:instance1:resource/path/res1",
+					Message:   "Resource urn:ews:example:instance1:resource/path/res1 is contained in resource urn:ews:example:instance1:resource/*",
+				},
+				{
+					Type:      LINT_REDUNDANT_RESOURCE,
+					Statement: 0,
+					Value:     "urn:ews:example:instance1:resource/*",
+					Message:   "Resource urn:ews:example:instance1:resource/* is contained in resource urn:ews:example:instance1:resource/*",
+				},
+				{
+					Type:      LINT_REDUNDANT_RESOURCE,
+					Statement: 0,
+					Value:     "urn:ews:example:instance1:resource/path/res2",
+					Message:   "Resource urn:ews:example:instance1:resource/path/res2 is contained in resource urn:ews:example:instance1:resource/*",
+				},
+			},
+		},
+		"OkCaseBroadWildcards": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"ex*"},
+					Resources: []string{"urn:ews:example:*"},
+				},
+				{
+					Effect:    "deny",
+					Actions:   []string{"ex*"},
+					Resources: []string{"*"},
+				},
+			},
+			expectedFindings: []PolicyLintFinding{
+				{
+					Type:      LINT_SHADOWED_STATEMENT,
+					Statement: 0,
+					Message:   "Every action and resource of this statement is denied by deny statements",
+				},
+				{
+					Type:      LINT_BROAD_WILDCARD,
+					Statement: 0,
+					Value:     "ex*",
+					Message:   "Action ex* allows actions of several services",
+				},
+				{
+					Type:      LINT_BROAD_WILDCARD,
+					Statement: 0,
+					Value:     "urn:ews:example:*",
+					Message:   "Resource urn:ews:example:* contains resources of several namespaces, products or instances",
+				},
+			},
+		},
+		"OkCaseUnknownActions": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"iam:GetUsers", "iam:List*", "iam:Fake*", "example:Get", "other:*"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+			},
+			knownActions: []string{"example:Get", "example:List"},
+			expectedFindings: []PolicyLintFinding{
+				{
+					Type:      LINT_UNKNOWN_ACTION,
+					Statement: 0,
+					Value:     "iam:GetUsers",
+					Message:   "Action iam:GetUsers doesn't match any known action",
+				},
+				{
+					Type:      LINT_UNKNOWN_ACTION,
+					Statement: 0,
+					Value:     "iam:Fake*",
+					Message:   "Action iam:Fake* doesn't match any known action",
+				},
+				{
+					Type:      LINT_UNKNOWN_ACTION,
+					Statement: 0,
+					Value:     "other:*",
+					Message:   "Action other:* doesn't match any known action",
+				},
+			},
+		},
+		"OkCaseForeignOrgUrns": {
+			org: "123",
+			statements: []Statement{
+				{
+					Effect:  "allow",
+					Actions: []string{GROUP_ACTION_GET_GROUP},
+					Resources: []string{
+						GetUrnPrefix("123", RESOURCE_GROUP, "/"),
+						GetUrnPrefix("456", RESOURCE_GROUP, "/"),
+						GetUrnPrefix("", RESOURCE_USER, "/"),
+					},
+				},
+			},
+			expectedFindings: []PolicyLintFinding{
+				{
+					Type:      LINT_FOREIGN_ORG_URN,
+					Statement: 0,
+					Value:     "urn:iws:iam:456:group/*",
+					Message:   "Resource urn:iws:iam:456:group/* belongs to organization 456",
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		findings := LintStatements(test.org, test.statements, test.knownActions)
+		checkMethodResponse(t, n, nil, nil, test.expectedFindings, findings)
+	}
+}
+
+func TestIAMActions(t *testing.T) {
+	// Every iam action constant declared in package files must be a known iam action
+	packages, err := parser.ParseDir(token.NewFileSet(), ".", nil, 0)
+	if err != nil {
+		t.Fatalf("Unexpected error parsing package: %v", err)
+	}
+	known := map[string]bool{}
+	for _, action := range iamActions {
+		known[action] = true
+	}
+	for _, file := range packages["api"].Files {
+		for _, decl := range file.Decls {
+			genDecl, ok := decl.(*ast.GenDecl)
+			if !ok || genDecl.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range genDecl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				for i, value := range valueSpec.Values {
+					literal, ok := value.(*ast.BasicLit)
+					if !ok || literal.Kind != token.STRING {
+						continue
+					}
+					action, _ := strconv.Unquote(literal.Value)
+					if !strings.HasPrefix(action, IAM_ACTION_PREFIX) || action == IAM_ACTION_PREFIX {
+						continue
+					}
+					if !known[action] {
+						t.Errorf("Action %v of constant %v not found in iam actions", action, valueSpec.Names[i])
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestAuthAPI_LintPolicy(t *testing.T) {
+	testPolicy := &Policy{
+		ID:   "POLICY-ID",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		Statements: &[]Statement{
+			{
+				Effect:    "allow",
+				Actions:   []string{"example:Get"},
+				Resources: []string{"urn:ews:example:instance1:resource/*"},
+			},
+			{
+				Effect:    "allow",
+				Actions:   []string{"example:Get"},
+				Resources: []string{"urn:ews:example:instance1:resource/*"},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		requestInfo  RequestInfo
+		org          string
+		policyName   string
+		knownActions []string
+
+		expectedResult *PolicyLintReport
+		wantError      error
+
+		getPolicyByNameMethodResult *Policy
+		getPolicyByNameMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "123",
+			policyName:   "test",
+			knownActions: []string{"example:Get"},
+			expectedResult: &PolicyLintReport{
+				Org:  "123",
+				Name: "test",
+				Findings: []PolicyLintFinding{
+					{
+						Type:      LINT_DUPLICATED_STATEMENT,
+						Statement: 1,
+						Message:   "Statement is a duplicate of statement 0",
+					},
+				},
+			},
+			getPolicyByNameMethodResult: testPolicy,
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			wantError: &Error{
+				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = test.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = test.getPolicyByNameMethodErr
+
+		report, err := testAPI.LintPolicy(test.requestInfo, test.org, test.policyName, test.knownActions)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, report)
+	}
+}
+
+func TestAuthAPI_LintPolicies(t *testing.T) {
+	testPolicies := []Policy{
+		{
+			ID:   "POLICY-ID",
+			Name: "test",
+			Org:  "123",
+			Path: "/path/",
+			Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+			Statements: &[]Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Get"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+			},
+		},
+		{
+			ID:   "POLICY-ID2",
+			Name: "test2",
+			Org:  "123",
+			Path: "/path2/",
+			Urn:  CreateUrn("123", RESOURCE_POLICY, "/path2/", "test2"),
+			Statements: &[]Statement{
+				{
+					Effect:    "allow",
+					Actions:   []string{"example:Delete"},
+					Resources: []string{"urn:ews:example:instance1:resource/*"},
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		requestInfo  RequestInfo
+		org          string
+		knownActions []string
+
+		expectedResult []PolicyLintReport
+		wantError      error
+
+		getUserByExternalIDResult *User
+		getAttachedPoliciesResult []Policy
+		getGroupsByUserIDResult   []Group
+
+		getPoliciesFilteredMethodResult []Policy
+		getPoliciesFilteredMethodErr    error
+	}{
+		"OKCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:          "123",
+			knownActions: []string{"example:Get"},
+			expectedResult: []PolicyLintReport{
+				{
+					Org:      "123",
+					Name:     "test",
+					Findings: []PolicyLintFinding{},
+				},
+				{
+					Org:  "123",
+					Name: "test2",
+					Findings: []PolicyLintFinding{
+						{
+							Type:      LINT_UNKNOWN_ACTION,
+							Statement: 0,
+							Value:     "example:Delete",
+							Message:   "Action example:Delete doesn't match any known action",
+						},
+					},
+				},
+			},
+			getPoliciesFilteredMethodResult: testPolicies,
+		},
+		"OKCaseRestricted": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+			},
+			org: "123",
+			expectedResult: []PolicyLintReport{
+				{
+					Org:      "123",
+					Name:     "test2",
+					Findings: []PolicyLintFinding{},
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect:    "allow",
+							Actions:   []string{POLICY_ACTION_GET_POLICY},
+							Resources: []string{GetUrnPrefix("123", RESOURCE_POLICY, "/path2/")},
+						},
+					},
+				},
+			},
+			getPoliciesFilteredMethodResult: testPolicies,
+		},
+		"ErrorCaseInvalidOrg": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "!*^**~$%&/()",
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org !*^**~$%&/()",
+			},
+		},
+		"ErrorCaseGetPoliciesFilteredDBError": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org: "123",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getPoliciesFilteredMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = test.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = test.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = test.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][0] = test.getPoliciesFilteredMethodResult
+		testRepo.ArgsOut[GetPoliciesFilteredMethod][2] = test.getPoliciesFilteredMethodErr
+
+		reports, err := testAPI.LintPolicies(test.requestInfo, test.org, test.knownActions)
+		checkMethodResponse(t, n, test.wantError, err, test.expectedResult, reports)
+	}
+}
@@ -426,6 +426,7 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 	testAPI := makeTestAPI(testRepo)
 
 	for x, testcase := range testcases {
+		testRepo.ArgsIn[AddPolicyMethod][0] = nil
 		testRepo.ArgsOut[AddPolicyMethod][0] = testcase.addPolicyMethodResult
 		testRepo.ArgsOut[AddPolicyMethod][1] = testcase.addPolicyMethodErr
 		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
@@ -435,6 +436,14 @@ func TestAuthAPI_AddPolicy(t *testing.T) {
 		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
 		policy, err := testAPI.AddPolicy(testcase.requestInfo, testcase.policyName, testcase.path, testcase.org, testcase.statements)
 		checkMethodResponse(t, x, testcase.wantError, err, testcase.addPolicyMethodResult, policy)
+
+		// Check that first version is stored with the policy
+		if stored, ok := testRepo.ArgsIn[AddPolicyMethod][0].(Policy); ok {
+			version := testRepo.ArgsIn[AddPolicyMethod][1].(PolicyVersion)
+			if version.PolicyID != stored.ID || version.Name != stored.Name || version.Author != testcase.requestInfo.Identifier {
+				t.Errorf("Test case %v. Received different policy version %v", x, version)
+			}
+		}
 	}
 }
 
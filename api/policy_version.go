@@ -0,0 +1,222 @@
+package api
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// TYPE DEFINITIONS
+
+// Policy version, a snapshot of the policy stored every time that it's created or updated
+type PolicyVersion struct {
+	PolicyID   string       `json:"policyId, omitempty"`
+	Version    int          `json:"version, omitempty"`
+	Name       string       `json:"name, omitempty"`
+	Path       string       `json:"path, omitempty"`
+	Statements *[]Statement `json:"statements, omitempty"`
+	Author     string       `json:"author, omitempty"`
+	CreateAt   time.Time    `json:"createAt, omitempty"`
+}
+
+func (v PolicyVersion) String() string {
+	return fmt.Sprintf("[policyId: %v, version: %v, name: %v, path: %v, author: %v, createAt: %v, statements: %v]",
+		v.PolicyID, v.Version, v.Name, v.Path, v.Author, v.CreateAt.Format("2006-01-02 15:04:05 MST"), v.Statements)
+}
+
+// Differences between two policy versions. Statements are compared as a whole,
+// so a modified statement appears as removed and added.
+type PolicyVersionDiff struct {
+	FromVersion       int         `json:"fromVersion, omitempty"`
+	ToVersion         int         `json:"toVersion, omitempty"`
+	FromName          string      `json:"fromName, omitempty"`
+	ToName            string      `json:"toName, omitempty"`
+	FromPath          string      `json:"fromPath, omitempty"`
+	ToPath            string      `json:"toPath, omitempty"`
+	AddedStatements   []Statement `json:"addedStatements, omitempty"`
+	RemovedStatements []Statement `json:"removedStatements, omitempty"`
+}
+
+// POLICY VERSION API IMPLEMENTATION
+
+func (api AuthAPI) ListPolicyVersions(requestInfo RequestInfo, org string, name string, filter *Filter) ([]PolicyVersion, int, error) {
+	// Validate fields
+	var total int
+	if filter.Limit > MAX_LIMIT_SIZE {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DEFAULT_LIMIT_SIZE
+	}
+
+	// Call repo to retrieve the policy
+	policy, err := api.getPolicyAuthorized(requestInfo, org, name, POLICY_ACTION_LIST_POLICY_VERSIONS)
+	if err != nil {
+		return nil, total, err
+	}
+
+	// Call repo to retrieve the versions
+	versions, total, err := api.PolicyRepo.GetPolicyVersions(policy.ID, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, total, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return versions, total, nil
+}
+
+func (api AuthAPI) GetPolicyVersion(requestInfo RequestInfo, org string, name string, version int) (*PolicyVersion, error) {
+	// Call repo to retrieve the policy
+	policy, err := api.getPolicyAuthorized(requestInfo, org, name, POLICY_ACTION_GET_POLICY_VERSION)
+	if err != nil {
+		return nil, err
+	}
+
+	return api.getPolicyVersion(policy, version)
+}
+
+func (api AuthAPI) DiffPolicyVersions(requestInfo RequestInfo, org string, name string, fromVersion int, toVersion int) (*PolicyVersionDiff, error) {
+	// Call repo to retrieve the policy
+	policy, err := api.getPolicyAuthorized(requestInfo, org, name, POLICY_ACTION_GET_POLICY_VERSION)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the versions
+	from, err := api.getPolicyVersion(policy, fromVersion)
+	if err != nil {
+		return nil, err
+	}
+	to, err := api.getPolicyVersion(policy, toVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &PolicyVersionDiff{
+		FromVersion:       from.Version,
+		ToVersion:         to.Version,
+		FromName:          from.Name,
+		ToName:            to.Name,
+		FromPath:          from.Path,
+		ToPath:            to.Path,
+		AddedStatements:   subtractStatements(*to.Statements, *from.Statements),
+		RemovedStatements: subtractStatements(*from.Statements, *to.Statements),
+	}, nil
+}
+
+func (api AuthAPI) RollbackPolicy(requestInfo RequestInfo, org string, name string, version int) (*Policy, error) {
+	// Call repo to retrieve the policy
+	policy, err := api.getPolicyAuthorized(requestInfo, org, name, POLICY_ACTION_ROLLBACK_POLICY)
+	if err != nil {
+		return nil, err
+	}
+
+	// Call repo to retrieve the version
+	policyVersion, err := api.getPolicyVersion(policy, version)
+	if err != nil {
+		return nil, err
+	}
+
+	// Restore version as a new one
+	return api.updatePolicy(requestInfo, POLICY_ACTION_ROLLBACK_POLICY, org, name, policyVersion.Name, policyVersion.Path,
+		*policyVersion.Statements)
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve policy checking that requestInfo is allowed to do the action over it
+func (api AuthAPI) getPolicyAuthorized(requestInfo RequestInfo, org string, name string, action string) (*Policy, error) {
+	policy, err := api.GetPolicyByName(requestInfo, org, name)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check restrictions
+	policiesFiltered, err := api.GetAuthorizedPolicies(requestInfo, policy.Urn, action, []Policy{*policy})
+	if err != nil {
+		return nil, err
+	}
+	if len(policiesFiltered) < 1 {
+		return nil, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, policy.Urn),
+		}
+	}
+
+	return policy, nil
+}
+
+func (api AuthAPI) getPolicyVersion(policy *Policy, version int) (*PolicyVersion, error) {
+	if version < 1 {
+		return nil, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: version %v", version),
+		}
+	}
+
+	policyVersion, err := api.PolicyRepo.GetPolicyVersion(policy.ID, version)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		// Version doesn't exist in DB
+		if dbError.Code == database.POLICY_VERSION_NOT_FOUND {
+			return nil, &Error{
+				Code:    POLICY_VERSION_NOT_FOUND,
+				Message: dbError.Message,
+			}
+		}
+		return nil, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	return policyVersion, nil
+}
+
+// Version of the policy with its current state, stored by repository with the next version number
+func createPolicyVersion(policy *Policy, author string, createAt time.Time) PolicyVersion {
+	statements := []Statement{}
+	if policy.Statements != nil {
+		statements = *policy.Statements
+	}
+	return PolicyVersion{
+		PolicyID:   policy.ID,
+		Name:       policy.Name,
+		Path:       policy.Path,
+		Statements: &statements,
+		Author:     author,
+		CreateAt:   createAt,
+	}
+}
+
+// Retrieve statements that are in a but not in b, considering repeated statements
+func subtractStatements(a []Statement, b []Statement) []Statement {
+	pending := map[string]int{}
+	for _, s := range b {
+		pending[s.String()]++
+	}
+	result := []Statement{}
+	for _, s := range a {
+		if pending[s.String()] > 0 {
+			pending[s.String()]--
+			continue
+		}
+		result = append(result, s)
+	}
+	return result
+}
@@ -0,0 +1,488 @@
+package api
+
+import (
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestAuthAPI_ListPolicyVersions(t *testing.T) {
+	now := time.Now().UTC()
+	testPolicy := &Policy{
+		ID:   "POLICY-ID",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+	}
+	testVersions := []PolicyVersion{
+		{
+			PolicyID:   "POLICY-ID",
+			Version:    1,
+			Name:       "test",
+			Path:       "/path/",
+			Statements: &[]Statement{},
+			Author:     "123456",
+			CreateAt:   now,
+		},
+	}
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		org         string
+		policyName  string
+		filter      *Filter
+
+		getGroupsByUserIDResult   []Group
+		getAttachedPoliciesResult []Policy
+		getUserByExternalIDResult *User
+
+		expectedResult []PolicyVersion
+		expectedLimit  int
+		totalResult    int
+		wantError      error
+
+		getPolicyByNameMethodResult   *Policy
+		getPolicyVersionsMethodResult []PolicyVersion
+		getPolicyByNameMethodErr      error
+		getPolicyVersionsMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                           "123",
+			policyName:                    "test",
+			filter:                        &Filter{},
+			expectedResult:                testVersions,
+			expectedLimit:                 DEFAULT_LIMIT_SIZE,
+			totalResult:                   1,
+			getPolicyByNameMethodResult:   testPolicy,
+			getPolicyVersionsMethodResult: testVersions,
+		},
+		"ErrorCaseMaxLimitSize": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			filter: &Filter{
+				Limit: 10000,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 10000, max limit allowed: 1000",
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:        "123",
+			policyName: "test",
+			filter:     &Filter{},
+			wantError: &Error{
+				Code: POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code: database.POLICY_NOT_FOUND,
+			},
+		},
+		"ErrorCaseNotAllowed": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      false,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			filter:                      &Filter{},
+			getPolicyByNameMethodResult: testPolicy,
+			getUserByExternalIDResult: &User{
+				ID:         "USER-ID",
+				ExternalID: "123456",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "123456"),
+			},
+			getGroupsByUserIDResult: []Group{
+				{
+					ID:   "GROUP-USER-ID",
+					Name: "groupUser",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_GROUP, "/path/", "groupUser"),
+				},
+			},
+			getAttachedPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
+				},
+			},
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 123456 is not allowed to access to resource urn:iws:iam:123:policy/path/test",
+			},
+		},
+		"ErrorCaseGetPolicyVersionsDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			org:                         "123",
+			policyName:                  "test",
+			filter:                      &Filter{},
+			getPolicyByNameMethodResult: testPolicy,
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getPolicyVersionsMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetGroupsByUserIDMethod][0] = testcase.getGroupsByUserIDResult
+		testRepo.ArgsOut[GetAttachedPoliciesMethod][0] = testcase.getAttachedPoliciesResult
+		testRepo.ArgsOut[GetPolicyVersionsMethod][0] = testcase.getPolicyVersionsMethodResult
+		testRepo.ArgsOut[GetPolicyVersionsMethod][1] = testcase.totalResult
+		testRepo.ArgsOut[GetPolicyVersionsMethod][2] = testcase.getPolicyVersionsMethodErr
+		versions, total, err := testAPI.ListPolicyVersions(testcase.requestInfo, testcase.org, testcase.policyName, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, versions)
+		if testcase.wantError == nil {
+			if testcase.totalResult != total {
+				t.Errorf("Test case %v. Received different total (wanted:%v / received:%v)", x, testcase.totalResult, total)
+			}
+			if testcase.filter.Limit != testcase.expectedLimit {
+				t.Errorf("Test case %v. Received different limit (wanted:%v / received:%v)", x, testcase.expectedLimit, testcase.filter.Limit)
+			}
+		}
+	}
+}
+
+func TestAuthAPI_GetPolicyVersion(t *testing.T) {
+	testPolicy := &Policy{
+		ID:   "POLICY-ID",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+	}
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		version     int
+
+		wantError error
+
+		getPolicyByNameMethodResult  *Policy
+		getPolicyVersionMethodResult *PolicyVersion
+		getPolicyVersionMethodErr    error
+	}{
+		"OKCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			version:                     2,
+			getPolicyByNameMethodResult: testPolicy,
+			getPolicyVersionMethodResult: &PolicyVersion{
+				PolicyID:   "POLICY-ID",
+				Version:    2,
+				Name:       "test",
+				Path:       "/path/",
+				Statements: &[]Statement{},
+			},
+		},
+		"ErrorCaseInvalidVersion": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			version:                     0,
+			getPolicyByNameMethodResult: testPolicy,
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: version 0",
+			},
+		},
+		"ErrorCaseVersionNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			version:                     3,
+			getPolicyByNameMethodResult: testPolicy,
+			wantError: &Error{
+				Code:    POLICY_VERSION_NOT_FOUND,
+				Message: "Version 3 of policy with id POLICY-ID not found",
+			},
+			getPolicyVersionMethodErr: &database.Error{
+				Code:    database.POLICY_VERSION_NOT_FOUND,
+				Message: "Version 3 of policy with id POLICY-ID not found",
+			},
+		},
+		"ErrorCaseGetPolicyVersionDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			version:                     1,
+			getPolicyByNameMethodResult: testPolicy,
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getPolicyVersionMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameMethodResult
+		testRepo.ArgsOut[GetPolicyVersionMethod][0] = testcase.getPolicyVersionMethodResult
+		testRepo.ArgsOut[GetPolicyVersionMethod][1] = testcase.getPolicyVersionMethodErr
+		policyVersion, err := testAPI.GetPolicyVersion(testcase.requestInfo, "123", "test", testcase.version)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.getPolicyVersionMethodResult, policyVersion)
+	}
+}
+
+func TestAuthAPI_DiffPolicyVersions(t *testing.T) {
+	testPolicy := &Policy{
+		ID:   "POLICY-ID",
+		Name: "test",
+		Org:  "123",
+		Path: "/path/",
+		Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+	}
+	getUser := Statement{
+		Effect:    "allow",
+		Actions:   []string{USER_ACTION_GET_USER},
+		Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+	}
+	listUsers := Statement{
+		Effect:    "allow",
+		Actions:   []string{USER_ACTION_LIST_USERS},
+		Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+	}
+	getGroup := Statement{
+		Effect:    "deny",
+		Actions:   []string{GROUP_ACTION_GET_GROUP},
+		Resources: []string{GetUrnPrefix("123", RESOURCE_GROUP, "/path/")},
+	}
+	testVersions := map[int]*PolicyVersion{
+		1: {
+			PolicyID:   "POLICY-ID",
+			Version:    1,
+			Name:       "test",
+			Path:       "/path/",
+			Statements: &[]Statement{getUser, listUsers},
+		},
+		2: {
+			PolicyID:   "POLICY-ID",
+			Version:    2,
+			Name:       "test2",
+			Path:       "/path2/",
+			Statements: &[]Statement{listUsers, getGroup},
+		},
+	}
+	testcases := map[string]struct {
+		fromVersion int
+		toVersion   int
+
+		expectedResult *PolicyVersionDiff
+		wantError      error
+	}{
+		"OKCase": {
+			fromVersion: 1,
+			toVersion:   2,
+			expectedResult: &PolicyVersionDiff{
+				FromVersion:       1,
+				ToVersion:         2,
+				FromName:          "test",
+				ToName:            "test2",
+				FromPath:          "/path/",
+				ToPath:            "/path2/",
+				AddedStatements:   []Statement{getGroup},
+				RemovedStatements: []Statement{getUser},
+			},
+		},
+		"OKCaseSameVersion": {
+			fromVersion: 2,
+			toVersion:   2,
+			expectedResult: &PolicyVersionDiff{
+				FromVersion:       2,
+				ToVersion:         2,
+				FromName:          "test2",
+				ToName:            "test2",
+				FromPath:          "/path2/",
+				ToPath:            "/path2/",
+				AddedStatements:   []Statement{},
+				RemovedStatements: []Statement{},
+			},
+		},
+		"ErrorCaseToVersionNotFound": {
+			fromVersion: 1,
+			toVersion:   3,
+			wantError: &Error{
+				Code:    POLICY_VERSION_NOT_FOUND,
+				Message: "Version 3 of policy with id POLICY-ID not found",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testPolicy
+		testRepo.SpecialFuncs[GetPolicyVersionMethod] = func(policyID string, version int) (*PolicyVersion, error) {
+			if v, ok := testVersions[version]; ok {
+				return v, nil
+			}
+			return nil, &database.Error{
+				Code:    database.POLICY_VERSION_NOT_FOUND,
+				Message: "Version 3 of policy with id POLICY-ID not found",
+			}
+		}
+		diff, err := testAPI.DiffPolicyVersions(RequestInfo{Identifier: "123456", Admin: true}, "123", "test",
+			testcase.fromVersion, testcase.toVersion)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, diff)
+	}
+}
+
+func TestAuthAPI_RollbackPolicy(t *testing.T) {
+	now := time.Now().UTC()
+	statements := []Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{USER_ACTION_GET_USER},
+			Resources: []string{GetUrnPrefix("", RESOURCE_USER, "/path/")},
+		},
+	}
+	testPolicy := &Policy{
+		ID:         "POLICY-ID",
+		Name:       "test",
+		Org:        "123",
+		Path:       "/path/",
+		Urn:        CreateUrn("123", RESOURCE_POLICY, "/path/", "test"),
+		CreateAt:   now,
+		Statements: &[]Statement{},
+	}
+	rolledBackPolicy := &Policy{
+		ID:         "POLICY-ID",
+		Name:       "test",
+		Org:        "123",
+		Path:       "/path2/",
+		Urn:        CreateUrn("123", RESOURCE_POLICY, "/path2/", "test"),
+		CreateAt:   now,
+		Statements: &statements,
+	}
+	testcases := map[string]struct {
+		version int
+
+		expectedResult     *Policy
+		expectedNewVersion *PolicyVersion
+		wantError          error
+
+		getPolicyVersionMethodResult *PolicyVersion
+		getPolicyVersionMethodErr    error
+		updatePolicyMethodErr        error
+	}{
+		"OKCase": {
+			version:        1,
+			expectedResult: rolledBackPolicy,
+			expectedNewVersion: &PolicyVersion{
+				PolicyID:   "POLICY-ID",
+				Name:       "test",
+				Path:       "/path2/",
+				Statements: &statements,
+				Author:     "123456",
+			},
+			getPolicyVersionMethodResult: &PolicyVersion{
+				PolicyID:   "POLICY-ID",
+				Version:    1,
+				Name:       "test",
+				Path:       "/path2/",
+				Statements: &statements,
+			},
+		},
+		"ErrorCaseVersionNotFound": {
+			version: 5,
+			wantError: &Error{
+				Code:    POLICY_VERSION_NOT_FOUND,
+				Message: "Version 5 of policy with id POLICY-ID not found",
+			},
+			getPolicyVersionMethodErr: &database.Error{
+				Code:    database.POLICY_VERSION_NOT_FOUND,
+				Message: "Version 5 of policy with id POLICY-ID not found",
+			},
+		},
+		"ErrorCaseUpdatePolicyDBErr": {
+			version: 1,
+			wantError: &Error{
+				Code: UNKNOWN_API_ERROR,
+			},
+			getPolicyVersionMethodResult: &PolicyVersion{
+				PolicyID:   "POLICY-ID",
+				Version:    1,
+				Name:       "test",
+				Path:       "/path2/",
+				Statements: &statements,
+			},
+			updatePolicyMethodErr: &database.Error{
+				Code: database.INTERNAL_ERROR,
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testPolicy
+		testRepo.ArgsOut[GetPolicyVersionMethod][0] = testcase.getPolicyVersionMethodResult
+		testRepo.ArgsOut[GetPolicyVersionMethod][1] = testcase.getPolicyVersionMethodErr
+		testRepo.ArgsOut[GetPolicyVersionsMethod][1] = 1
+		testRepo.ArgsOut[UpdatePolicyMethod][0] = testcase.expectedResult
+		testRepo.ArgsOut[UpdatePolicyMethod][1] = testcase.updatePolicyMethodErr
+		policy, err := testAPI.RollbackPolicy(RequestInfo{Identifier: "123456", Admin: true}, "123", "test", testcase.version)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedResult, policy)
+		if testcase.expectedNewVersion != nil {
+			newVersion, ok := testRepo.ArgsIn[UpdatePolicyMethod][5].(PolicyVersion)
+			if !ok {
+				t.Errorf("Test case %v. Policy version not stored", x)
+				continue
+			}
+			newVersion.CreateAt = time.Time{}
+			if diff := pretty.Compare(newVersion, testcase.expectedNewVersion); diff != "" {
+				t.Errorf("Test case %v. Received different policy version (received/wanted) %v", x, diff)
+			}
+		}
+	}
+}
@@ -9,31 +9,46 @@ import (
 )
 
 const (
-	GetUserByExternalIDMethod = "GetUserByExternalID"
-	AddUserMethod             = "AddUser"
-	UpdateUserMethod          = "UpdateUser"
-	GetUsersFilteredMethod    = "GetUsersFiltered"
-	GetGroupsByUserIDMethod   = "GetGroupsByUserID"
-	RemoveUserMethod          = "RemoveUser"
-	GetGroupByNameMethod      = "GetGroupByName"
-	IsMemberOfGroupMethod     = "IsMemberOfGroup"
-	GetGroupMembersMethod     = "GetGroupMembers"
-	IsAttachedToGroupMethod   = "IsAttachedToGroup"
-	GetAttachedPoliciesMethod = "GetAttachedPolicies"
-	GetGroupsFilteredMethod   = "GetGroupsFiltered"
-	RemoveGroupMethod         = "RemoveGroup"
-	AddGroupMethod            = "AddGroup"
-	AddMemberMethod           = "AddMember"
-	RemoveMemberMethod        = "RemoveMember"
-	UpdateGroupMethod         = "UpdateGroup"
-	AttachPolicyMethod        = "AttachPolicy"
-	DetachPolicyMethod        = "DetachPolicy"
-	GetPolicyByNameMethod     = "GetPolicyByName"
-	AddPolicyMethod           = "AddPolicy"
-	UpdatePolicyMethod        = "UpdatePolicy"
-	RemovePolicyMethod        = "RemovePolicy"
-	GetPoliciesFilteredMethod = "GetPoliciesFiltered"
-	GetAttachedGroupsMethod   = "GetAttachedGroups"
+	GetUserByExternalIDMethod     = "GetUserByExternalID"
+	AddUserMethod                 = "AddUser"
+	UpdateUserMethod              = "UpdateUser"
+	GetUsersFilteredMethod        = "GetUsersFiltered"
+	GetGroupsByUserIDMethod       = "GetGroupsByUserID"
+	RemoveUserMethod              = "RemoveUser"
+	GetGroupByNameMethod          = "GetGroupByName"
+	IsMemberOfGroupMethod         = "IsMemberOfGroup"
+	GetGroupMembersMethod         = "GetGroupMembers"
+	IsAttachedToGroupMethod       = "IsAttachedToGroup"
+	GetAttachedPoliciesMethod     = "GetAttachedPolicies"
+	GetGroupsFilteredMethod       = "GetGroupsFiltered"
+	RemoveGroupMethod             = "RemoveGroup"
+	AddGroupMethod                = "AddGroup"
+	AddMemberMethod               = "AddMember"
+	RemoveMemberMethod            = "RemoveMember"
+	UpdateGroupMethod             = "UpdateGroup"
+	AttachPolicyMethod            = "AttachPolicy"
+	DetachPolicyMethod            = "DetachPolicy"
+	GetPolicyByNameMethod         = "GetPolicyByName"
+	AddPolicyMethod               = "AddPolicy"
+	UpdatePolicyMethod            = "UpdatePolicy"
+	RemovePolicyMethod            = "RemovePolicy"
+	GetPoliciesFilteredMethod     = "GetPoliciesFiltered"
+	GetAttachedGroupsMethod       = "GetAttachedGroups"
+	GetAttachedUsersMethod        = "GetAttachedUsers"
+	AttachPolicyToUserMethod      = "AttachPolicyToUser"
+	DetachPolicyFromUserMethod    = "DetachPolicyFromUser"
+	IsAttachedToUserMethod        = "IsAttachedToUser"
+	GetAttachedUserPoliciesMethod = "GetAttachedUserPolicies"
+	GetEffectiveStatementsMethod  = "GetEffectiveStatements"
+	GetPolicyVersionsMethod       = "GetPolicyVersions"
+	GetPolicyVersionMethod        = "GetPolicyVersion"
+	AddAuditEventMethod           = "AddAuditEvent"
+	GetAuditEventsFilteredMethod  = "GetAuditEventsFiltered"
+	ApplyImportChangesMethod      = "ApplyImportChanges"
+	AddAPIKeyMethod               = "AddAPIKey"
+	GetAPIKeyByIDMethod           = "GetAPIKeyByID"
+	GetAPIKeysByUserIDMethod      = "GetAPIKeysByUserID"
+	UpdateAPIKeyMethod            = "UpdateAPIKey"
 )
 
 // TestRepo that implements all repo manager interfaces
@@ -76,11 +91,26 @@ func makeTestRepo() *TestRepo {
 	testRepo.ArgsIn[AttachPolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[DetachPolicyMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetPolicyByNameMethod] = make([]interface{}, 2)
-	testRepo.ArgsIn[AddPolicyMethod] = make([]interface{}, 1)
-	testRepo.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 5)
+	testRepo.ArgsIn[AddPolicyMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 6)
 	testRepo.ArgsIn[RemovePolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsIn[GetPoliciesFilteredMethod] = make([]interface{}, 2)
 	testRepo.ArgsIn[GetAttachedGroupsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetAttachedUsersMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AttachPolicyToUserMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[DetachPolicyFromUserMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[IsAttachedToUserMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetAttachedUserPoliciesMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetEffectiveStatementsMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetPolicyVersionsMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[GetPolicyVersionMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[AddAuditEventMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAuditEventsFilteredMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[ApplyImportChangesMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[AddAPIKeyMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAPIKeyByIDMethod] = make([]interface{}, 1)
+	testRepo.ArgsIn[GetAPIKeysByUserIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsIn[UpdateAPIKeyMethod] = make([]interface{}, 1)
 
 	testRepo.ArgsOut[GetUserByExternalIDMethod] = make([]interface{}, 2)
 	testRepo.ArgsOut[AddUserMethod] = make([]interface{}, 2)
@@ -107,6 +137,20 @@ func makeTestRepo() *TestRepo {
 	testRepo.ArgsOut[RemovePolicyMethod] = make([]interface{}, 1)
 	testRepo.ArgsOut[GetPoliciesFilteredMethod] = make([]interface{}, 3)
 	testRepo.ArgsOut[GetAttachedGroupsMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetAttachedUsersMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[AttachPolicyToUserMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[DetachPolicyFromUserMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[IsAttachedToUserMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAttachedUserPoliciesMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetPolicyVersionsMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[GetPolicyVersionMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[AddAuditEventMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[GetAuditEventsFilteredMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[ApplyImportChangesMethod] = make([]interface{}, 1)
+	testRepo.ArgsOut[AddAPIKeyMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAPIKeyByIDMethod] = make([]interface{}, 2)
+	testRepo.ArgsOut[GetAPIKeysByUserIDMethod] = make([]interface{}, 3)
+	testRepo.ArgsOut[UpdateAPIKeyMethod] = make([]interface{}, 2)
 
 	return testRepo
 }
@@ -116,6 +160,10 @@ func makeTestAPI(testRepo *TestRepo) *AuthAPI {
 		UserRepo:   testRepo,
 		GroupRepo:  testRepo,
 		PolicyRepo: testRepo,
+		AuthzRepo:  testRepo,
+		AuditRepo:  testRepo,
+		ImportRepo: testRepo,
+		APIKeyRepo: testRepo,
 		Logger: &log.Logger{
 			Out:       bytes.NewBuffer([]byte{}),
 			Formatter: &log.TextFormatter{},
@@ -210,6 +258,58 @@ func (t TestRepo) GetGroupsByUserID(id string, filter *Filter) ([]Group, int, er
 	return groups, total, err
 }
 
+func (t TestRepo) AttachPolicyToUser(userID string, policyID string) error {
+	t.ArgsIn[AttachPolicyToUserMethod][0] = userID
+	t.ArgsIn[AttachPolicyToUserMethod][1] = policyID
+	var err error
+	if t.ArgsOut[AttachPolicyToUserMethod][0] != nil {
+		err = t.ArgsOut[AttachPolicyToUserMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) DetachPolicyFromUser(userID string, policyID string) error {
+	t.ArgsIn[DetachPolicyFromUserMethod][0] = userID
+	t.ArgsIn[DetachPolicyFromUserMethod][1] = policyID
+	var err error
+	if t.ArgsOut[DetachPolicyFromUserMethod][0] != nil {
+		err = t.ArgsOut[DetachPolicyFromUserMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) IsAttachedToUser(userID string, policyID string) (bool, error) {
+	t.ArgsIn[IsAttachedToUserMethod][0] = userID
+	t.ArgsIn[IsAttachedToUserMethod][1] = policyID
+	isAttached := false
+	if t.ArgsOut[IsAttachedToUserMethod][0] != nil {
+		isAttached = t.ArgsOut[IsAttachedToUserMethod][0].(bool)
+	}
+	var err error
+	if t.ArgsOut[IsAttachedToUserMethod][1] != nil {
+		err = t.ArgsOut[IsAttachedToUserMethod][1].(error)
+	}
+	return isAttached, err
+}
+
+func (t TestRepo) GetAttachedUserPolicies(userID string, filter *Filter) ([]Policy, int, error) {
+	t.ArgsIn[GetAttachedUserPoliciesMethod][0] = userID
+	t.ArgsIn[GetAttachedUserPoliciesMethod][1] = filter
+	var policies []Policy
+	if t.ArgsOut[GetAttachedUserPoliciesMethod][0] != nil {
+		policies = t.ArgsOut[GetAttachedUserPoliciesMethod][0].([]Policy)
+	}
+	var total int
+	if t.ArgsOut[GetAttachedUserPoliciesMethod][1] != nil {
+		total = t.ArgsOut[GetAttachedUserPoliciesMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetAttachedUserPoliciesMethod][2] != nil {
+		err = t.ArgsOut[GetAttachedUserPoliciesMethod][2].(error)
+	}
+	return policies, total, err
+}
+
 func (t TestRepo) RemoveUser(id string) error {
 	t.ArgsIn[RemoveUserMethod][0] = id
 	var err error
@@ -420,8 +520,9 @@ func (t TestRepo) GetPolicyByName(org string, name string) (*Policy, error) {
 	return policy, err
 }
 
-func (t TestRepo) AddPolicy(policy Policy) (*Policy, error) {
+func (t TestRepo) AddPolicy(policy Policy, version PolicyVersion) (*Policy, error) {
 	t.ArgsIn[AddPolicyMethod][0] = policy
+	t.ArgsIn[AddPolicyMethod][1] = version
 	var created *Policy
 	if t.ArgsOut[AddPolicyMethod][0] != nil {
 		created = t.ArgsOut[AddPolicyMethod][0].(*Policy)
@@ -433,12 +534,14 @@ func (t TestRepo) AddPolicy(policy Policy) (*Policy, error) {
 	return created, err
 }
 
-func (t TestRepo) UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement) (*Policy, error) {
+func (t TestRepo) UpdatePolicy(policy Policy, newName string, newPath string, newUrn string, newStatements []Statement,
+	version PolicyVersion) (*Policy, error) {
 	t.ArgsIn[UpdatePolicyMethod][0] = policy
 	t.ArgsIn[UpdatePolicyMethod][1] = newName
 	t.ArgsIn[UpdatePolicyMethod][2] = newPath
 	t.ArgsIn[UpdatePolicyMethod][3] = newUrn
 	t.ArgsIn[UpdatePolicyMethod][4] = newStatements
+	t.ArgsIn[UpdatePolicyMethod][5] = version
 
 	var updated *Policy
 	if t.ArgsOut[UpdatePolicyMethod][0] != nil {
@@ -497,6 +600,222 @@ func (t TestRepo) GetAttachedGroups(policyID string, filter *Filter) ([]Group, i
 	return groups, total, err
 }
 
+func (t TestRepo) GetAttachedUsers(policyID string, filter *Filter) ([]User, int, error) {
+	t.ArgsIn[GetAttachedUsersMethod][0] = policyID
+	t.ArgsIn[GetAttachedUsersMethod][1] = filter
+	var users []User
+	if t.ArgsOut[GetAttachedUsersMethod][0] != nil {
+		users = t.ArgsOut[GetAttachedUsersMethod][0].([]User)
+	}
+	var total int
+	if t.ArgsOut[GetAttachedUsersMethod][1] != nil {
+		total = t.ArgsOut[GetAttachedUsersMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetAttachedUsersMethod][2] != nil {
+		err = t.ArgsOut[GetAttachedUsersMethod][2].(error)
+	}
+	return users, total, err
+}
+
+func (t TestRepo) GetPolicyVersions(policyID string, filter *Filter) ([]PolicyVersion, int, error) {
+	t.ArgsIn[GetPolicyVersionsMethod][0] = policyID
+	t.ArgsIn[GetPolicyVersionsMethod][1] = filter
+	var versions []PolicyVersion
+	if t.ArgsOut[GetPolicyVersionsMethod][0] != nil {
+		versions = t.ArgsOut[GetPolicyVersionsMethod][0].([]PolicyVersion)
+	}
+
+	var total int
+	if t.ArgsOut[GetPolicyVersionsMethod][1] != nil {
+		total = t.ArgsOut[GetPolicyVersionsMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetPolicyVersionsMethod][2] != nil {
+		err = t.ArgsOut[GetPolicyVersionsMethod][2].(error)
+	}
+	return versions, total, err
+}
+
+func (t TestRepo) GetPolicyVersion(policyID string, version int) (*PolicyVersion, error) {
+	t.ArgsIn[GetPolicyVersionMethod][0] = policyID
+	t.ArgsIn[GetPolicyVersionMethod][1] = version
+	if specialFunc, ok := t.SpecialFuncs[GetPolicyVersionMethod].(func(policyID string, version int) (*PolicyVersion, error)); ok && specialFunc != nil {
+		return specialFunc(policyID, version)
+	}
+	var policyVersion *PolicyVersion
+	if t.ArgsOut[GetPolicyVersionMethod][0] != nil {
+		policyVersion = t.ArgsOut[GetPolicyVersionMethod][0].(*PolicyVersion)
+	}
+	var err error
+	if t.ArgsOut[GetPolicyVersionMethod][1] != nil {
+		err = t.ArgsOut[GetPolicyVersionMethod][1].(error)
+	}
+	return policyVersion, err
+}
+
+// GetEffectiveStatements joins the results of user, group and policy methods like database does,
+// so tests can define permissions with them
+func (t TestRepo) GetEffectiveStatements(externalID string) ([]EffectiveStatement, error) {
+	t.ArgsIn[GetEffectiveStatementsMethod][0] = externalID
+	if specialFunc, ok := t.SpecialFuncs[GetEffectiveStatementsMethod].(func(externalID string) ([]EffectiveStatement, error)); ok && specialFunc != nil {
+		return specialFunc(externalID)
+	}
+
+	user, err := t.GetUserByExternalID(externalID)
+	if err != nil {
+		return nil, err
+	}
+	statements := []EffectiveStatement{}
+	addStatements := func(group *GroupIdentity, policies []Policy) {
+		for _, policy := range policies {
+			for _, statement := range *policy.Statements {
+				statements = append(statements, EffectiveStatement{
+					Group: group,
+					Policy: PolicyIdentity{
+						Org:  policy.Org,
+						Name: policy.Name,
+					},
+					Statement: statement,
+				})
+			}
+		}
+	}
+	groups, _, err := t.GetGroupsByUserID(user.ID, &Filter{})
+	if err != nil {
+		return nil, err
+	}
+	for _, group := range groups {
+		groupPolicies, _, err := t.GetAttachedPolicies(group.ID, &Filter{})
+		if err != nil {
+			return nil, err
+		}
+		addStatements(&GroupIdentity{
+			Org:  group.Org,
+			Name: group.Name,
+		}, groupPolicies)
+	}
+	userPolicies, _, err := t.GetAttachedUserPolicies(user.ID, &Filter{})
+	if err != nil {
+		return nil, err
+	}
+	addStatements(nil, userPolicies)
+
+	return statements, nil
+}
+
+//////////////////
+// Audit repo
+//////////////////
+
+func (t TestRepo) AddAuditEvent(event AuditEvent) error {
+	t.ArgsIn[AddAuditEventMethod][0] = event
+	var err error
+	if t.ArgsOut[AddAuditEventMethod][0] != nil {
+		err = t.ArgsOut[AddAuditEventMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestRepo) GetAuditEventsFiltered(filter *AuditFilter) ([]AuditEvent, int, error) {
+	t.ArgsIn[GetAuditEventsFilteredMethod][0] = filter
+	var events []AuditEvent
+	if t.ArgsOut[GetAuditEventsFilteredMethod][0] != nil {
+		events = t.ArgsOut[GetAuditEventsFilteredMethod][0].([]AuditEvent)
+	}
+
+	var total int
+	if t.ArgsOut[GetAuditEventsFilteredMethod][1] != nil {
+		total = t.ArgsOut[GetAuditEventsFilteredMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetAuditEventsFilteredMethod][2] != nil {
+		err = t.ArgsOut[GetAuditEventsFilteredMethod][2].(error)
+	}
+	return events, total, err
+}
+
+//////////////////
+// Import repo
+//////////////////
+
+func (t TestRepo) ApplyImportChanges(changes ImportChanges) error {
+	t.ArgsIn[ApplyImportChangesMethod][0] = changes
+	var err error
+	if t.ArgsOut[ApplyImportChangesMethod][0] != nil {
+		err = t.ArgsOut[ApplyImportChangesMethod][0].(error)
+	}
+	return err
+}
+
+//////////////////
+// API key repo
+//////////////////
+
+func (t TestRepo) AddAPIKey(apiKey APIKey) (*APIKey, error) {
+	t.ArgsIn[AddAPIKeyMethod][0] = apiKey
+	if specialFunc, ok := t.SpecialFuncs[AddAPIKeyMethod].(func(apiKey APIKey) (*APIKey, error)); ok && specialFunc != nil {
+		return specialFunc(apiKey)
+	}
+	var added *APIKey
+	if t.ArgsOut[AddAPIKeyMethod][0] != nil {
+		added = t.ArgsOut[AddAPIKeyMethod][0].(*APIKey)
+	}
+	var err error
+	if t.ArgsOut[AddAPIKeyMethod][1] != nil {
+		err = t.ArgsOut[AddAPIKeyMethod][1].(error)
+	}
+	return added, err
+}
+
+func (t TestRepo) GetAPIKeyByID(id string) (*APIKey, error) {
+	t.ArgsIn[GetAPIKeyByIDMethod][0] = id
+	var apiKey *APIKey
+	if t.ArgsOut[GetAPIKeyByIDMethod][0] != nil {
+		apiKey = t.ArgsOut[GetAPIKeyByIDMethod][0].(*APIKey)
+	}
+	var err error
+	if t.ArgsOut[GetAPIKeyByIDMethod][1] != nil {
+		err = t.ArgsOut[GetAPIKeyByIDMethod][1].(error)
+	}
+	return apiKey, err
+}
+
+func (t TestRepo) GetAPIKeysByUserID(userID string, filter *Filter) ([]APIKey, int, error) {
+	t.ArgsIn[GetAPIKeysByUserIDMethod][0] = userID
+	t.ArgsIn[GetAPIKeysByUserIDMethod][1] = filter
+	var apiKeys []APIKey
+	if t.ArgsOut[GetAPIKeysByUserIDMethod][0] != nil {
+		apiKeys = t.ArgsOut[GetAPIKeysByUserIDMethod][0].([]APIKey)
+	}
+
+	var total int
+	if t.ArgsOut[GetAPIKeysByUserIDMethod][1] != nil {
+		total = t.ArgsOut[GetAPIKeysByUserIDMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[GetAPIKeysByUserIDMethod][2] != nil {
+		err = t.ArgsOut[GetAPIKeysByUserIDMethod][2].(error)
+	}
+	return apiKeys, total, err
+}
+
+func (t TestRepo) UpdateAPIKey(apiKey APIKey) (*APIKey, error) {
+	t.ArgsIn[UpdateAPIKeyMethod][0] = apiKey
+	if specialFunc, ok := t.SpecialFuncs[UpdateAPIKeyMethod].(func(apiKey APIKey) (*APIKey, error)); ok && specialFunc != nil {
+		return specialFunc(apiKey)
+	}
+	var updated *APIKey
+	if t.ArgsOut[UpdateAPIKeyMethod][0] != nil {
+		updated = t.ArgsOut[UpdateAPIKeyMethod][0].(*APIKey)
+	}
+	var err error
+	if t.ArgsOut[UpdateAPIKeyMethod][1] != nil {
+		err = t.ArgsOut[UpdateAPIKeyMethod][1].(error)
+	}
+	return updated, err
+}
+
 // Private helper methods
 
 func GetRandomString(runeValue []rune, n int) string {
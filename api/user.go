@@ -82,6 +82,7 @@ func (api AuthAPI) AddUser(requestInfo RequestInfo, externalId string, path stri
 				}
 			}
 			LogOperation(api.Logger, requestInfo, fmt.Sprintf("User created %+v", createdUser))
+			api.recordAuditEvent(requestInfo, USER_ACTION_CREATE_USER, createdUser.Urn, nil, createdUser)
 			return createdUser, nil
 		default: // Unexpected error
 			return nil, &Error{
@@ -249,6 +250,7 @@ func (api AuthAPI) UpdateUser(requestInfo RequestInfo, externalId string, newPat
 	}
 
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User updated from %+v to %+v", userDB, user))
+	api.recordAuditEvent(requestInfo, USER_ACTION_UPDATE_USER, user.Urn, userDB, user)
 	return user, nil
 
 }
@@ -284,7 +286,11 @@ func (api AuthAPI) RemoveUser(requestInfo RequestInfo, externalId string) error
 			Message: dbError.Message,
 		}
 	}
+
+	api.Cache.invalidate(user.ExternalID)
+
 	LogOperation(api.Logger, requestInfo, fmt.Sprintf("User deleted %+v", user))
+	api.recordAuditEvent(requestInfo, USER_ACTION_DELETE_USER, user.Urn, user, nil)
 	return nil
 }
 
@@ -346,6 +352,187 @@ func (api AuthAPI) ListGroupsByUser(requestInfo RequestInfo, externalId string,
 	return groupIDs, total, nil
 }
 
+func (api AuthAPI) AttachPolicyToUser(requestInfo RequestInfo, externalId string, org string, policyName string) error {
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_ATTACH_USER_POLICY, []User{*user})
+	if err != nil {
+		return err
+	}
+	if len(usersFiltered) < 1 {
+		return &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	// Check if policy exists
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return err
+	}
+
+	// Check existing relationship
+	isAttached, err := api.UserRepo.IsAttachedToUser(user.ID, policy.ID)
+	if err != nil {
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if isAttached {
+		return &Error{
+			Code:    POLICY_IS_ALREADY_ATTACHED_TO_USER,
+			Message: fmt.Sprintf("Policy: %v is already attached to User: %v", policy.Name, user.ExternalID),
+		}
+	}
+
+	// Attach Policy to User
+	err = api.UserRepo.AttachPolicyToUser(user.ID, policy.ID)
+
+	if err != nil {
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	api.Cache.invalidate(user.ExternalID)
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v attached to user %+v", policy, user))
+	api.recordAuditEvent(requestInfo, USER_ACTION_ATTACH_USER_POLICY, user.Urn, nil, auditRelation{User: user, Policy: policy})
+	return nil
+}
+
+func (api AuthAPI) DetachPolicyFromUser(requestInfo RequestInfo, externalId string, org string, policyName string) error {
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_DETACH_USER_POLICY, []User{*user})
+	if err != nil {
+		return err
+	}
+	if len(usersFiltered) < 1 {
+		return &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	// Check if policy exists
+	policy, err := api.GetPolicyByName(requestInfo, org, policyName)
+	if err != nil {
+		return err
+	}
+
+	// Check existing relationship
+	isAttached, err := api.UserRepo.IsAttachedToUser(user.ID, policy.ID)
+	if err != nil {
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	if !isAttached {
+		return &Error{
+			Code: POLICY_IS_NOT_ATTACHED_TO_USER,
+			Message: fmt.Sprintf("Policy with org %v and name %v is not attached to user with externalId %v",
+				policy.Org, policy.Name, user.ExternalID),
+		}
+	}
+
+	// Detach Policy from User
+	err = api.UserRepo.DetachPolicyFromUser(user.ID, policy.ID)
+
+	if err != nil {
+		dbError := err.(*database.Error)
+		return &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	api.Cache.invalidate(user.ExternalID)
+
+	LogOperation(api.Logger, requestInfo, fmt.Sprintf("Policy %+v detached from user %+v", policy, user))
+	api.recordAuditEvent(requestInfo, USER_ACTION_DETACH_USER_POLICY, user.Urn, auditRelation{User: user, Policy: policy}, nil)
+	return nil
+}
+
+func (api AuthAPI) ListAttachedUserPolicies(requestInfo RequestInfo, externalId string, filter *Filter) ([]PolicyIdentity, int, error) {
+	// Check parameters
+	var total int
+	if filter.Limit > MAX_LIMIT_SIZE {
+		return nil, total, &Error{
+			Code:    INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: Limit %v, max limit allowed: %v", filter.Limit, MAX_LIMIT_SIZE),
+		}
+	}
+
+	if filter.Limit == 0 {
+		filter.Limit = DEFAULT_LIMIT_SIZE
+	}
+
+	// Call repo to retrieve the user
+	user, err := api.GetUserByExternalID(requestInfo, externalId)
+	if err != nil {
+		return nil, total, err
+	}
+
+	// Check restrictions
+	usersFiltered, err := api.GetAuthorizedUsers(requestInfo, user.Urn, USER_ACTION_LIST_ATTACHED_USER_POLICIES, []User{*user})
+	if err != nil {
+		return nil, total, err
+	}
+	if len(usersFiltered) < 1 {
+		return nil, total, &Error{
+			Code: UNAUTHORIZED_RESOURCES_ERROR,
+			Message: fmt.Sprintf("User with externalId %v is not allowed to access to resource %v",
+				requestInfo.Identifier, user.Urn),
+		}
+	}
+
+	// Call repo to retrieve the UserPolicyRelations
+	attachedPolicies, total, err := api.UserRepo.GetAttachedUserPolicies(user.ID, filter)
+
+	// Error handling
+	if err != nil {
+		//Transform to DB error
+		dbError := err.(*database.Error)
+		return nil, total, &Error{
+			Code:    UNKNOWN_API_ERROR,
+			Message: dbError.Message,
+		}
+	}
+
+	// Transform to identifiers
+	policyIDs := []PolicyIdentity{}
+	for _, p := range attachedPolicies {
+		policyIDs = append(policyIDs, PolicyIdentity{
+			Org:  p.Org,
+			Name: p.Name,
+		})
+	}
+
+	return policyIDs, total, nil
+}
+
 // PRIVATE HELPER METHODS
 
 func createUser(externalId string, path string) User {
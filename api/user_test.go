@@ -1963,3 +1963,653 @@ func TestAuthAPI_ListGroupsByUser(t *testing.T) {
 	}
 
 }
+
+func TestAuthAPI_AttachPolicyToUser(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		externalID  string
+		org         string
+		policyName  string
+		// Expected result
+		wantError error
+		// Manager Results
+		getUserByExternalIDResult     *User
+		getPolicyByNameResult         *Policy
+		getAttachedUserPoliciesResult []Policy
+		isAttachedToUserResult        bool
+		// API Errors
+		getUserByExternalIDMethodErr error
+		getPolicyByNameMethodErr     error
+		isAttachedToUserMethodErr    error
+		attachPolicyToUserMethodErr  error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserResult: false,
+		},
+		"OkCaseWithUserPolicies": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			getAttachedUserPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+								USER_ACTION_ATTACH_USER_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
+				},
+			},
+			isAttachedToUserResult: false,
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource " + CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy not found",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: "Policy not found",
+			},
+		},
+		"ErrorCaseIsAttachedToUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCasePolicyIsAlreadyAttached": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    POLICY_IS_ALREADY_ATTACHED_TO_USER,
+				Message: "Policy: policy1 is already attached to User: 1234",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserResult: true,
+		},
+		"ErrorCaseAttachPolicyToUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			attachPolicyToUserMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = testcase.getAttachedUserPoliciesResult
+		testRepo.ArgsOut[IsAttachedToUserMethod][0] = testcase.isAttachedToUserResult
+		testRepo.ArgsOut[IsAttachedToUserMethod][1] = testcase.isAttachedToUserMethodErr
+		testRepo.ArgsOut[AttachPolicyToUserMethod][0] = testcase.attachPolicyToUserMethodErr
+
+		err := testAPI.AttachPolicyToUser(testcase.requestInfo, testcase.externalID, testcase.org, testcase.policyName)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_DetachPolicyFromUser(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		externalID  string
+		org         string
+		policyName  string
+		// Expected result
+		wantError error
+		// Manager Results
+		getUserByExternalIDResult     *User
+		getPolicyByNameResult         *Policy
+		getAttachedUserPoliciesResult []Policy
+		isAttachedToUserResult        bool
+		// API Errors
+		getUserByExternalIDMethodErr  error
+		getPolicyByNameMethodErr      error
+		isAttachedToUserMethodErr     error
+		detachPolicyFromUserMethodErr error
+	}{
+		"OkCaseAdmin": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserResult: true,
+		},
+		"OkCaseWithUserPolicies": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			getAttachedUserPoliciesResult: []Policy{
+				{
+					ID:   "POLICY-USER-ID",
+					Name: "policyUser",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policyUser"),
+					Statements: &[]Statement{
+						{
+							Effect: "allow",
+							Actions: []string{
+								USER_ACTION_GET_USER,
+								USER_ACTION_DETACH_USER_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("", RESOURCE_USER, "/path/"),
+							},
+						},
+						{
+							Effect: "allow",
+							Actions: []string{
+								POLICY_ACTION_GET_POLICY,
+							},
+							Resources: []string{
+								GetUrnPrefix("123", RESOURCE_POLICY, "/path/"),
+							},
+						},
+					},
+				},
+			},
+			isAttachedToUserResult: true,
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			requestInfo: RequestInfo{
+				Identifier: "1234",
+				Admin:      false,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "User with externalId 1234 is not allowed to access to resource " + CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Policy not found",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameMethodErr: &database.Error{
+				Code:    database.POLICY_NOT_FOUND,
+				Message: "Policy not found",
+			},
+		},
+		"ErrorCaseIsAttachedToUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCasePolicyIsNotAttached": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    POLICY_IS_NOT_ATTACHED_TO_USER,
+				Message: "Policy with org 123 and name policy1 is not attached to user with externalId 1234",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserResult: false,
+		},
+		"ErrorCaseDetachPolicyFromUserDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			org:        "123",
+			policyName: "policy1",
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getPolicyByNameResult: &Policy{
+				ID:   "test1",
+				Name: "policy1",
+				Org:  "123",
+				Path: "/path/",
+				Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+			},
+			isAttachedToUserResult: true,
+			detachPolicyFromUserMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetPolicyByNameMethod][0] = testcase.getPolicyByNameResult
+		testRepo.ArgsOut[GetPolicyByNameMethod][1] = testcase.getPolicyByNameMethodErr
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = testcase.getAttachedUserPoliciesResult
+		testRepo.ArgsOut[IsAttachedToUserMethod][0] = testcase.isAttachedToUserResult
+		testRepo.ArgsOut[IsAttachedToUserMethod][1] = testcase.isAttachedToUserMethodErr
+		testRepo.ArgsOut[DetachPolicyFromUserMethod][0] = testcase.detachPolicyFromUserMethodErr
+
+		err := testAPI.DetachPolicyFromUser(testcase.requestInfo, testcase.externalID, testcase.org, testcase.policyName)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
+func TestAuthAPI_ListAttachedUserPolicies(t *testing.T) {
+	testcases := map[string]struct {
+		requestInfo RequestInfo
+		externalID  string
+		filter      *Filter
+		// Expected result
+		expectedPolicies []PolicyIdentity
+		wantError        error
+		// Manager Results
+		getUserByExternalIDResult     *User
+		getAttachedUserPoliciesResult []Policy
+		// API Errors
+		getUserByExternalIDMethodErr     error
+		getAttachedUserPoliciesMethodErr error
+	}{
+		"OkCase": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			filter:     &testFilter,
+			expectedPolicies: []PolicyIdentity{
+				{
+					Org:  "123",
+					Name: "policy1",
+				},
+				{
+					Org:  "456",
+					Name: "policy2",
+				},
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getAttachedUserPoliciesResult: []Policy{
+				{
+					ID:   "POLICY1",
+					Name: "policy1",
+					Org:  "123",
+					Path: "/path/",
+					Urn:  CreateUrn("123", RESOURCE_POLICY, "/path/", "policy1"),
+				},
+				{
+					ID:   "POLICY2",
+					Name: "policy2",
+					Org:  "456",
+					Path: "/path/",
+					Urn:  CreateUrn("456", RESOURCE_POLICY, "/path/", "policy2"),
+				},
+			},
+		},
+		"ErrorCaseMaxLimitSize": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			filter: &Filter{
+				Limit: 10000,
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit 10000, max limit allowed: 1000",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			filter:     &testFilter,
+			wantError: &Error{
+				Code:    USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User not found",
+			},
+			getUserByExternalIDMethodErr: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User not found",
+			},
+		},
+		"ErrorCaseGetAttachedUserPoliciesDBErr": {
+			requestInfo: RequestInfo{
+				Identifier: "123456",
+				Admin:      true,
+			},
+			externalID: "1234",
+			filter:     &testFilter,
+			wantError: &Error{
+				Code:    UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+			getUserByExternalIDResult: &User{
+				ID:         "543210",
+				ExternalID: "1234",
+				Path:       "/path/",
+				Urn:        CreateUrn("", RESOURCE_USER, "/path/", "1234"),
+			},
+			getAttachedUserPoliciesMethodErr: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		testRepo := makeTestRepo()
+		testAPI := makeTestAPI(testRepo)
+
+		testRepo.ArgsOut[GetUserByExternalIDMethod][0] = testcase.getUserByExternalIDResult
+		testRepo.ArgsOut[GetUserByExternalIDMethod][1] = testcase.getUserByExternalIDMethodErr
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][0] = testcase.getAttachedUserPoliciesResult
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][1] = len(testcase.getAttachedUserPoliciesResult)
+		testRepo.ArgsOut[GetAttachedUserPoliciesMethod][2] = testcase.getAttachedUserPoliciesMethodErr
+
+		policies, total, err := testAPI.ListAttachedUserPolicies(testcase.requestInfo, testcase.externalID, testcase.filter)
+		checkMethodResponse(t, x, testcase.wantError, err, testcase.expectedPolicies, policies)
+		if testcase.wantError == nil && total != len(testcase.expectedPolicies) {
+			t.Errorf("Test %v failed. Received different total elements: %v", x, total)
+		}
+	}
+}
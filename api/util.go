@@ -4,8 +4,10 @@ import (
 	"fmt"
 	//"github.com/Sirupsen/logrus"
 	"github.com/Sirupsen/logrus"
+	"net"
 	"regexp"
 	"strings"
+	"time"
 )
 
 const (
@@ -25,12 +27,22 @@ const (
 	// Actions
 
 	// User actions
-	USER_ACTION_CREATE_USER          = "iam:CreateUser"
-	USER_ACTION_DELETE_USER          = "iam:DeleteUser"
-	USER_ACTION_GET_USER             = "iam:GetUser"
-	USER_ACTION_LIST_USERS           = "iam:ListUsers"
-	USER_ACTION_UPDATE_USER          = "iam:UpdateUser"
-	USER_ACTION_LIST_GROUPS_FOR_USER = "iam:ListGroupsForUser"
+	USER_ACTION_CREATE_USER                 = "iam:CreateUser"
+	USER_ACTION_DELETE_USER                 = "iam:DeleteUser"
+	USER_ACTION_GET_USER                    = "iam:GetUser"
+	USER_ACTION_LIST_USERS                  = "iam:ListUsers"
+	USER_ACTION_UPDATE_USER                 = "iam:UpdateUser"
+	USER_ACTION_LIST_GROUPS_FOR_USER        = "iam:ListGroupsForUser"
+	USER_ACTION_EXPLAIN_AUTHORIZATION       = "iam:ExplainAuthorization"
+	USER_ACTION_CHECK_AUTHORIZATION         = "iam:CheckAuthorization"
+	USER_ACTION_ATTACH_USER_POLICY          = "iam:AttachUserPolicy"
+	USER_ACTION_DETACH_USER_POLICY          = "iam:DetachUserPolicy"
+	USER_ACTION_LIST_ATTACHED_USER_POLICIES = "iam:ListAttachedUserPolicies"
+	USER_ACTION_CREATE_API_KEY              = "iam:CreateAPIKey"
+	USER_ACTION_GET_API_KEY                 = "iam:GetAPIKey"
+	USER_ACTION_LIST_API_KEYS               = "iam:ListAPIKeys"
+	USER_ACTION_ROTATE_API_KEY              = "iam:RotateAPIKey"
+	USER_ACTION_REVOKE_API_KEY              = "iam:RevokeAPIKey"
 
 	// Group actions
 	GROUP_ACTION_CREATE_GROUP                 = "iam:CreateGroup"
@@ -52,6 +64,25 @@ const (
 	POLICY_ACTION_GET_POLICY           = "iam:GetPolicy"
 	POLICY_ACTION_LIST_ATTACHED_GROUPS = "iam:ListAttachedGroups"
 	POLICY_ACTION_LIST_POLICIES        = "iam:ListPolicies"
+	POLICY_ACTION_LIST_POLICY_VERSIONS = "iam:ListPolicyVersions"
+	POLICY_ACTION_GET_POLICY_VERSION   = "iam:GetPolicyVersion"
+	POLICY_ACTION_ROLLBACK_POLICY      = "iam:RollbackPolicy"
+
+	// Audit actions
+	AUDIT_ACTION_LIST_AUDIT_EVENTS = "iam:ListAuditEvents"
+
+	// Condition operators
+	CONDITION_OPERATOR_IP_ADDRESS    = "IpAddress"
+	CONDITION_OPERATOR_DATE_BEFORE   = "DateBefore"
+	CONDITION_OPERATOR_DATE_AFTER    = "DateAfter"
+	CONDITION_OPERATOR_STRING_EQUALS = "StringEquals"
+	CONDITION_OPERATOR_STRING_LIKE   = "StringLike"
+	CONDITION_OPERATOR_TIME_OF_DAY   = "TimeOfDay"
+	CONDITION_OPERATOR_WEEKDAY       = "Weekday"
+
+	// Condition keys filled from request
+	CONDITION_KEY_SOURCE_IP    = "SourceIp"
+	CONDITION_KEY_CURRENT_TIME = "CurrentTime"
 )
 
 var (
@@ -66,6 +97,7 @@ var (
 	rWordResourcePrefix, _ = regexp.Compile(`^[\w+\-_.@]+\*$`)
 	rUrn, _                = regexp.Compile(`^\*$|^[\w+\-@.]+\*?$|^[\w+\-@.]+\*?$|^[\w+\-@.]+(/?([\w+\-@.]+/)*([\w+\-@.]|[*])+)?$`)
 	rUrnExclude, _         = regexp.Compile(`[/]{2,}|[:]{2,}|[*]{2,}`)
+	rConditionKey, _       = regexp.Compile(`^[\w\-_:.]+$`)
 )
 
 func CreateUrn(org string, resource string, path string, name string) string {
@@ -225,10 +257,120 @@ func AreValidStatements(statements *[]Statement) error {
 		if err != nil {
 			return err
 		}
+
+		// check conditions
+		err = AreValidConditions(statement.Conditions)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func AreValidConditions(conditions []Condition) error {
+	for _, condition := range conditions {
+		if len(condition.Key) < 1 {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Empty condition key",
+			}
+		}
+		if !rConditionKey.MatchString(condition.Key) {
+			return &Error{
+				Code:    REGEX_NO_MATCH,
+				Message: fmt.Sprintf("No regex match in condition key: %v", condition.Key),
+			}
+		}
+		if len(condition.Values) < 1 {
+			return &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Empty values in condition with key %v", condition.Key),
+			}
+		}
+
+		switch condition.Operator {
+		case CONDITION_OPERATOR_IP_ADDRESS:
+			for _, value := range condition.Values {
+				if _, _, err := net.ParseCIDR(value); err != nil && net.ParseIP(value) == nil {
+					return &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid IP address or CIDR range in condition: %v", value),
+					}
+				}
+			}
+		case CONDITION_OPERATOR_DATE_BEFORE, CONDITION_OPERATOR_DATE_AFTER:
+			for _, value := range condition.Values {
+				if _, err := time.Parse(time.RFC3339, value); err != nil {
+					return &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid date in condition: %v - RFC 3339 format expected", value),
+					}
+				}
+			}
+		case CONDITION_OPERATOR_TIME_OF_DAY:
+			for _, value := range condition.Values {
+				if _, _, err := parseTimeOfDayRange(value); err != nil {
+					return &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid time of day range in condition: %v - HH:MM-HH:MM format expected", value),
+					}
+				}
+			}
+		case CONDITION_OPERATOR_WEEKDAY:
+			for _, value := range condition.Values {
+				if _, err := parseWeekday(value); err != nil {
+					return &Error{
+						Code:    INVALID_PARAMETER_ERROR,
+						Message: fmt.Sprintf("Invalid weekday in condition: %v - English day name expected", value),
+					}
+				}
+			}
+		case CONDITION_OPERATOR_STRING_EQUALS, CONDITION_OPERATOR_STRING_LIKE:
+			// Any string value is accepted
+		default:
+			return &Error{
+				Code: INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid condition operator: %v - Only '%v', '%v', '%v', '%v', '%v', '%v' and '%v' accepted",
+					condition.Operator, CONDITION_OPERATOR_IP_ADDRESS, CONDITION_OPERATOR_DATE_BEFORE,
+					CONDITION_OPERATOR_DATE_AFTER, CONDITION_OPERATOR_TIME_OF_DAY, CONDITION_OPERATOR_WEEKDAY,
+					CONDITION_OPERATOR_STRING_EQUALS, CONDITION_OPERATOR_STRING_LIKE),
+			}
+		}
 	}
 	return nil
 }
 
+// Parse a time of day range in format HH:MM-HH:MM, returning start and end as minutes from midnight.
+// Start after end is a range that crosses midnight.
+func parseTimeOfDayRange(value string) (int, int, error) {
+	limits := strings.Split(value, "-")
+	if len(limits) != 2 {
+		return 0, 0, fmt.Errorf("Invalid time of day range %v", value)
+	}
+	minutes := make([]int, 2, 2)
+	for i, limit := range limits {
+		t, err := time.Parse("15:04", limit)
+		if err != nil {
+			return 0, 0, err
+		}
+		minutes[i] = t.Hour()*60 + t.Minute()
+	}
+	if minutes[0] == minutes[1] {
+		return 0, 0, fmt.Errorf("Empty time of day range %v", value)
+	}
+	return minutes[0], minutes[1], nil
+}
+
+// Parse an English day name, like Monday
+func parseWeekday(value string) (time.Weekday, error) {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		if day.String() == value {
+			return day, nil
+		}
+	}
+	return time.Sunday, fmt.Errorf("Invalid weekday %v", value)
+}
+
 func LogOperation(logger *logrus.Logger, requestInfo RequestInfo, message string) {
 	logger.WithFields(logrus.Fields{
 		"requestID": requestInfo.RequestID,
@@ -447,6 +447,166 @@ func TestAreValidStatements(t *testing.T) {
 	}
 }
 
+func TestAreValidConditions(t *testing.T) {
+	testcases := map[string]struct {
+		// Method args
+		conditions []Condition
+		// Expected results
+		wantError error
+	}{
+		"OKCase": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_IP_ADDRESS,
+					Key:      CONDITION_KEY_SOURCE_IP,
+					Values:   []string{"10.0.0.0/8", "192.168.1.1"},
+				},
+				{
+					Operator: CONDITION_OPERATOR_DATE_AFTER,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"2016-01-01T00:00:00Z"},
+				},
+				{
+					Operator: CONDITION_OPERATOR_STRING_LIKE,
+					Key:      "Department",
+					Values:   []string{"dev*"},
+				},
+				{
+					Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"08:00-18:30", "22:00-06:00"},
+				},
+				{
+					Operator: CONDITION_OPERATOR_WEEKDAY,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"Monday", "Friday"},
+				},
+			},
+		},
+		"OKCaseEmpty": {},
+		"ErrorCaseEmptyKey": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_STRING_EQUALS,
+					Values:   []string{"value"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Empty condition key",
+			},
+		},
+		"ErrorCaseInvalidKey": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_STRING_EQUALS,
+					Key:      "key*",
+					Values:   []string{"value"},
+				},
+			},
+			wantError: &Error{
+				Code:    REGEX_NO_MATCH,
+				Message: "No regex match in condition key: key*",
+			},
+		},
+		"ErrorCaseEmptyValues": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_STRING_EQUALS,
+					Key:      "key",
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Empty values in condition with key key",
+			},
+		},
+		"ErrorCaseInvalidIP": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_IP_ADDRESS,
+					Key:      CONDITION_KEY_SOURCE_IP,
+					Values:   []string{"10.0.0.0/99"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid IP address or CIDR range in condition: 10.0.0.0/99",
+			},
+		},
+		"ErrorCaseInvalidDate": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_DATE_BEFORE,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"2016-01-01"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid date in condition: 2016-01-01 - RFC 3339 format expected",
+			},
+		},
+		"ErrorCaseInvalidTimeOfDay": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"8:00-25:00"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid time of day range in condition: 8:00-25:00 - HH:MM-HH:MM format expected",
+			},
+		},
+		"ErrorCaseEmptyTimeOfDay": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_TIME_OF_DAY,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"08:00-08:00"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid time of day range in condition: 08:00-08:00 - HH:MM-HH:MM format expected",
+			},
+		},
+		"ErrorCaseInvalidWeekday": {
+			conditions: []Condition{
+				{
+					Operator: CONDITION_OPERATOR_WEEKDAY,
+					Key:      CONDITION_KEY_CURRENT_TIME,
+					Values:   []string{"monday"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid weekday in condition: monday - English day name expected",
+			},
+		},
+		"ErrorCaseInvalidOperator": {
+			conditions: []Condition{
+				{
+					Operator: "Other",
+					Key:      "key",
+					Values:   []string{"value"},
+				},
+			},
+			wantError: &Error{
+				Code:    INVALID_PARAMETER_ERROR,
+				Message: "Invalid condition operator: Other - Only 'IpAddress', 'DateBefore', 'DateAfter', 'TimeOfDay', 'Weekday', 'StringEquals' and 'StringLike' accepted",
+			},
+		},
+	}
+
+	for x, testcase := range testcases {
+		err := AreValidConditions(testcase.conditions)
+		checkMethodResponse(t, x, testcase.wantError, err, nil, nil)
+	}
+}
+
 func TestAreValidResources(t *testing.T) {
 	testcases := map[string]struct {
 		// Method args
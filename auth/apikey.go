@@ -0,0 +1,68 @@
+package auth
+
+import (
+	"net/http"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+const (
+	API_KEY_HEADER = "X-FOULKON-API-KEY"
+)
+
+// APIKeyAuthenticator retrieves the user of an API key, implemented by API keys API
+type APIKeyAuthenticator interface {
+	// AuthenticateAPIKey returns external id of the user of key, or an error if key isn't valid
+	AuthenticateAPIKey(key string) (string, error)
+}
+
+// APIKeyAuthConnector represents an API key connector that implements interface of auth connector
+type APIKeyAuthConnector struct {
+	logger        *log.Logger
+	authenticator APIKeyAuthenticator
+}
+
+func NewAPIKeyConnector(logger *log.Logger, authenticator APIKeyAuthenticator) AuthConnector {
+	return &APIKeyAuthConnector{
+		logger:        logger,
+		authenticator: authenticator,
+	}
+}
+
+// This method retrieves API key from request and checks if it is valid
+func (c APIKeyAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := r.Header.Get("Request-ID")
+		key := r.Header.Get(API_KEY_HEADER)
+		if key == "" {
+			c.logger.WithFields(log.Fields{
+				"requestID": requestID,
+			}).Error("API key not found in request")
+			http.Error(w, "Error API key not found in request", http.StatusUnauthorized)
+			return
+		}
+
+		externalID, err := c.authenticator.AuthenticateAPIKey(key)
+		if err != nil {
+			c.logger.WithFields(log.Fields{
+				"requestID": requestID,
+			}).Error(err.Error())
+			http.Error(w, "Error invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		// Replace user sent by client, if any
+		r.Header.Set(USER_ID_HEADER, externalID)
+		h.ServeHTTP(w, r)
+	})
+}
+
+// Decline requests without API key header
+func (c APIKeyAuthConnector) Accepts(r *http.Request) bool {
+	return r.Header.Get(API_KEY_HEADER) != ""
+}
+
+// Retrieve user of API key
+func (c APIKeyAuthConnector) RetrieveUserID(r http.Request) string {
+	return r.Header.Get(USER_ID_HEADER)
+}
@@ -0,0 +1,76 @@
+package auth
+
+import (
+	"net/http"
+)
+
+const (
+	AUTH_CONNECTOR_HEADER = "X-FOULKON-AUTH-CONNECTOR"
+)
+
+// Interface for connectors that can decline requests without their credentials, so next connector of
+// a chain is tried. Connectors that don't implement it accept all requests.
+type DeclinableAuthConnector interface {
+	AuthConnector
+	// Accepts returns true if request has credentials handled by connector
+	Accepts(r *http.Request) bool
+}
+
+// Connector with the name used in logs, e.g. its authenticator type
+type NamedAuthConnector struct {
+	Name      string
+	Connector AuthConnector
+}
+
+// ChainAuthConnector represents a list of connectors tried in order that implements interface of auth connector
+type ChainAuthConnector struct {
+	connectors []NamedAuthConnector
+}
+
+func NewChainConnector(connectors []NamedAuthConnector) AuthConnector {
+	return &ChainAuthConnector{
+		connectors: connectors,
+	}
+}
+
+// This method authenticates request with first connector that doesn't decline it. If all connectors
+// decline it, last connector authenticates it to respond with its error.
+func (c ChainAuthConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		connector := c.selectConnector(r)
+		if connector == nil {
+			http.Error(w, "Error no authentication connector configured", http.StatusUnauthorized)
+			return
+		}
+
+		// Replace connector sent by client, if any
+		r.Header.Set(AUTH_CONNECTOR_HEADER, connector.Name)
+		connector.Connector.Authenticate(h).ServeHTTP(w, r)
+	})
+}
+
+// Retrieve user from connector that authenticated request
+func (c ChainAuthConnector) RetrieveUserID(r http.Request) string {
+	name := r.Header.Get(AUTH_CONNECTOR_HEADER)
+	for _, connector := range c.connectors {
+		if connector.Name == name {
+			return connector.Connector.RetrieveUserID(r)
+		}
+	}
+	return ""
+}
+
+// PRIVATE HELPER METHODS
+
+func (c ChainAuthConnector) selectConnector(r *http.Request) *NamedAuthConnector {
+	for i, connector := range c.connectors {
+		if declinable, ok := connector.Connector.(DeclinableAuthConnector); ok && !declinable.Accepts(r) {
+			continue
+		}
+		return &c.connectors[i]
+	}
+	if len(c.connectors) == 0 {
+		return nil
+	}
+	return &c.connectors[len(c.connectors)-1]
+}
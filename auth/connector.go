@@ -2,11 +2,15 @@ package auth
 
 import (
 	"net/http"
+	"sync"
 )
 
 // Authenticator system, with connector and basic admin authentication
 type Authenticator struct {
-	Connector     AuthConnector
+	Connector AuthConnector
+
+	// Admin credentials can be changed while requests are served
+	lock          sync.RWMutex
 	adminUser     string
 	adminPassword string
 }
@@ -26,10 +30,21 @@ type AuthConnector interface {
 	RetrieveUserID(r http.Request) string
 }
 
+// SetAdminCredentials replaces admin user and password used in basic authentication
+func (a *Authenticator) SetAdminCredentials(adminUser string, adminPassword string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	a.adminUser = adminUser
+	a.adminPassword = adminPassword
+}
+
 func (a *Authenticator) Authenticate(h http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var handler http.Handler
-		if isAdmin(r, a.adminUser, a.adminPassword) {
+		// Connector is only set by chain connectors
+		r.Header.Del(AUTH_CONNECTOR_HEADER)
+		adminUser, adminPassword := a.getAdminCredentials()
+		if isAdmin(r, adminUser, adminPassword) {
 			// Admin check
 			handler = h
 
@@ -44,12 +59,29 @@ func (a *Authenticator) Authenticate(h http.Handler) http.Handler {
 
 // GetAuthenticatedUser retrieves user from request
 func (a *Authenticator) GetAuthenticatedUser(r *http.Request) (string, bool) {
-	if isAdmin(r, a.adminUser, a.adminPassword) {
-		return a.adminUser, true
+	adminUser, adminPassword := a.getAdminCredentials()
+	if isAdmin(r, adminUser, adminPassword) {
+		return adminUser, true
 	}
 	return a.Connector.RetrieveUserID(*r), false
 }
 
+// GetAuthConnector retrieves name of connector that authenticated request, admin for basic
+// admin authentication, or empty if it isn't known
+func (a *Authenticator) GetAuthConnector(r *http.Request) string {
+	adminUser, adminPassword := a.getAdminCredentials()
+	if isAdmin(r, adminUser, adminPassword) {
+		return "admin"
+	}
+	return r.Header.Get(AUTH_CONNECTOR_HEADER)
+}
+
+func (a *Authenticator) getAdminCredentials() (string, string) {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+	return a.adminUser, a.adminPassword
+}
+
 func isAdmin(r *http.Request, adminUser string, adminPassword string) bool {
 	username, password, ok := r.BasicAuth()
 	// Password is never stored in DB
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"net/http"
+	"strings"
 
 	"fmt"
 
@@ -60,6 +61,11 @@ func (c OIDCAuthConnector) Authenticate(h http.Handler) http.Handler {
 	return openid.AuthenticateUser(&c.configuration, openid.UserHandlerFunc(userHandler))
 }
 
+// Decline requests without a bearer token in Authorization header
+func (c OIDCAuthConnector) Accepts(r *http.Request) bool {
+	return strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
 // Retrieve user from OIDC token
 func (c OIDCAuthConnector) RetrieveUserID(r http.Request) string {
 	userID := r.Header.Get(USER_ID_HEADER)
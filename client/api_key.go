@@ -0,0 +1,78 @@
+package client
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// API KEY API
+
+// CreateAPIKey creates an API key for the user, with an optional expiration date. The returned key is the
+// only time its secret is received.
+func (c *Client) CreateAPIKey(externalID string, name string, expireAt *time.Time) (*api.APIKeySecret, error) {
+	request := &internalhttp.CreateAPIKeyRequest{
+		Name:     name,
+		ExpireAt: expireAt,
+	}
+	apiKey := &api.APIKeySecret{}
+	if err := c.do(http.MethodPost, userPath(externalID, "api-keys"), nil, request, apiKey); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+// GetAPIKey returns an API key of the user, without its secret
+func (c *Client) GetAPIKey(externalID string, id string) (*api.APIKey, error) {
+	apiKey := &api.APIKey{}
+	if err := c.do(http.MethodGet, userPath(externalID, "api-keys", id), nil, nil, apiKey); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+// ListAPIKeys returns a page of API keys of the user
+func (c *Client) ListAPIKeys(externalID string, filter *api.Filter) (*internalhttp.ListAPIKeysResponse, error) {
+	response := &internalhttp.ListAPIKeysResponse{}
+	if err := c.do(http.MethodGet, userPath(externalID, "api-keys"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// APIKeys iterates over all API keys of the user, including expired and revoked ones
+func (c *Client) APIKeys(externalID string) *APIKeyIterator {
+	it := &APIKeyIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListAPIKeys(externalID, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.APIKeys
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// RotateAPIKey replaces the secret of an API key, and its expiration date if expireAt isn't nil
+func (c *Client) RotateAPIKey(externalID string, id string, expireAt *time.Time) (*api.APIKeySecret, error) {
+	request := &internalhttp.RotateAPIKeyRequest{
+		ExpireAt: expireAt,
+	}
+	apiKey := &api.APIKeySecret{}
+	if err := c.do(http.MethodPost, userPath(externalID, "api-keys", id, "rotate"), nil, request, apiKey); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
+
+// RevokeAPIKey revokes an API key, so it can't be used anymore
+func (c *Client) RevokeAPIKey(externalID string, id string) (*api.APIKey, error) {
+	apiKey := &api.APIKey{}
+	if err := c.do(http.MethodPost, userPath(externalID, "api-keys", id, "revoke"), nil, nil, apiKey); err != nil {
+		return nil, err
+	}
+	return apiKey, nil
+}
@@ -0,0 +1,133 @@
+package client
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+)
+
+func TestClient_APIKeys(t *testing.T) {
+	c := adminClient
+
+	// Service account allowed to retrieve service accounts
+	if _, err := c.AddUser("api-keys-service", "/service-accounts/"); err != nil {
+		t.Fatalf("Unexpected error adding user %v", err)
+	}
+	if _, err := c.AddPolicy("api-keys", "policy1", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{api.USER_ACTION_GET_USER},
+			Resources: []string{"urn:iws:iam::user/service-accounts/*"},
+		},
+	}); err != nil {
+		t.Fatalf("Unexpected error adding policy %v", err)
+	}
+	if err := c.AttachPolicyToUser("api-keys-service", "api-keys", "policy1"); err != nil {
+		t.Fatalf("Unexpected error attaching policy %v", err)
+	}
+
+	expireAt := time.Now().UTC().Add(time.Hour)
+	apiKey, err := c.CreateAPIKey("api-keys-service", "deploy", &expireAt)
+	if err != nil {
+		t.Fatalf("Unexpected error creating API key %v", err)
+	}
+	if apiKey.Name != "deploy" || apiKey.ExternalID != "api-keys-service" || apiKey.Key == "" || apiKey.Hash != "" {
+		t.Errorf("Received different API key %v", apiKey)
+	}
+
+	// Authenticate with the key
+	keyClient := NewClient(apiKeyServer.URL, NewAPIKeyCredentials(apiKey.Key), nil)
+	if _, err := keyClient.GetUser("api-keys-service"); err != nil {
+		t.Errorf("Unexpected error authenticated with API key %v", err)
+	}
+	if _, err := NewClient(apiKeyServer.URL, NewAPIKeyCredentials(apiKey.ID+".invalid"), nil).GetUser("api-keys-service"); !IsUnauthenticated(err) {
+		t.Errorf("Received different error with invalid API key (wanted unauthenticated / received:%v)", err)
+	}
+
+	// List and get
+	it := c.APIKeys("api-keys-service")
+	if !it.Next() || it.APIKey().ID != apiKey.ID || it.Next() || it.Err() != nil {
+		t.Errorf("Received different API keys, error %v", it.Err())
+	}
+	received, err := c.GetAPIKey("api-keys-service", apiKey.ID)
+	if err != nil || received.ID != apiKey.ID || received.ExpireAt == nil {
+		t.Errorf("Received different API key %v, error %v", received, err)
+	}
+	if _, err := c.GetAPIKey("api-keys-service", "unknown"); !IsNotFound(err) {
+		t.Errorf("Received different error (wanted not found / received:%v)", err)
+	}
+
+	// Previous secret stops working after rotation
+	rotated, err := c.RotateAPIKey("api-keys-service", apiKey.ID, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error rotating API key %v", err)
+	}
+	if rotated.ID != apiKey.ID || rotated.Key == apiKey.Key || rotated.ExpireAt == nil {
+		t.Errorf("Received different rotated API key %v", rotated)
+	}
+	if _, err := keyClient.GetUser("api-keys-service"); !IsUnauthenticated(err) {
+		t.Errorf("Received different error with rotated API key (wanted unauthenticated / received:%v)", err)
+	}
+	keyClient = NewClient(apiKeyServer.URL, NewAPIKeyCredentials(rotated.Key), nil)
+	if _, err := keyClient.GetUser("api-keys-service"); err != nil {
+		t.Errorf("Unexpected error authenticated with rotated API key %v", err)
+	}
+
+	// Revoked key can't be used
+	revoked, err := c.RevokeAPIKey("api-keys-service", apiKey.ID)
+	if err != nil || revoked.RevokeAt == nil {
+		t.Fatalf("Received different revoked API key %v, error %v", revoked, err)
+	}
+	if _, err := keyClient.GetUser("api-keys-service"); !IsUnauthenticated(err) {
+		t.Errorf("Received different error with revoked API key (wanted unauthenticated / received:%v)", err)
+	}
+	if _, err := c.RevokeAPIKey("api-keys-service", apiKey.ID); !hasErrorCode(err, api.API_KEY_REVOKED) {
+		t.Errorf("Received different error (wanted %v / received:%v)", api.API_KEY_REVOKED, err)
+	}
+}
+
+func TestClient_ChainedConnectors(t *testing.T) {
+	c := adminClient
+
+	if _, err := c.AddUser("chained-service", "/service-accounts/"); err != nil {
+		t.Fatalf("Unexpected error adding user %v", err)
+	}
+	apiKey, err := c.CreateAPIKey("chained-service", "chained", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error creating API key %v", err)
+	}
+
+	// API key connector authenticates requests with API key
+	workerLog.Reset()
+	if _, err := NewClient(apiKeyServer.URL, NewAPIKeyCredentials(apiKey.Key), nil).GetUser("chained-service"); IsUnauthenticated(err) {
+		t.Errorf("Unexpected error authenticated with API key %v", err)
+	}
+	if log := workerLog.String(); !strings.Contains(log, "connector=apikey") || !strings.Contains(log, "user=chained-service") {
+		t.Errorf("Transaction log without API key connector: %v", log)
+	}
+
+	// API key connector declines requests without API key, so next connector authenticates them
+	workerLog.Reset()
+	if _, err := NewClient(apiKeyServer.URL, NewBearerCredentials(userToken), nil).GetUser("chained-service"); IsUnauthenticated(err) {
+		t.Errorf("Unexpected error authenticated with bearer token %v", err)
+	}
+	if log := workerLog.String(); !strings.Contains(log, "connector=test") || !strings.Contains(log, "user=user1") {
+		t.Errorf("Transaction log without test connector: %v", log)
+	}
+
+	// Invalid API key isn't authenticated by next connector
+	if _, err := NewClient(apiKeyServer.URL, NewAPIKeyCredentials(apiKey.ID+".invalid"), nil).GetUser("chained-service"); !IsUnauthenticated(err) {
+		t.Errorf("Received different error with invalid API key (wanted unauthenticated / received:%v)", err)
+	}
+
+	// Admin requests are logged with admin connector
+	workerLog.Reset()
+	if _, err := NewClient(apiKeyServer.URL, NewBasicCredentials(adminUser, adminPassword), nil).GetUser("chained-service"); err != nil {
+		t.Errorf("Unexpected error authenticated as admin %v", err)
+	}
+	if log := workerLog.String(); !strings.Contains(log, "connector=admin") {
+		t.Errorf("Transaction log without admin connector: %v", log)
+	}
+}
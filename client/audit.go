@@ -0,0 +1,69 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// AUDIT API
+
+// ListAuditEvents returns a page of audit events, newest first, filtered by actor, action, urn prefix and dates
+func (c *Client) ListAuditEvents(filter *api.AuditFilter) (*internalhttp.ListAuditEventsResponse, error) {
+	response := &internalhttp.ListAuditEventsResponse{}
+	if err := c.do(http.MethodGet, internalhttp.AUDIT_URL, auditFilterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AuditEvents iterates over all audit events of filter, newest first. Offset and Limit of filter are ignored.
+func (c *Client) AuditEvents(filter api.AuditFilter) *AuditEventIterator {
+	it := &AuditEventIterator{}
+	it.fetch = func(pageFilter *api.Filter) (int, int, error) {
+		filter.Offset = pageFilter.Offset
+		filter.Limit = pageFilter.Limit
+		response, err := c.ListAuditEvents(&filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.AuditEvents
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// Query params of an audit filter
+func auditFilterQuery(filter *api.AuditFilter) url.Values {
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+	params := map[string]string{
+		"Actor":     filter.Actor,
+		"Action":    filter.Action,
+		"UrnPrefix": filter.UrnPrefix,
+	}
+	if !filter.From.IsZero() {
+		params["From"] = filter.From.Format(time.RFC3339)
+	}
+	if !filter.To.IsZero() {
+		params["To"] = filter.To.Format(time.RFC3339)
+	}
+	if filter.Offset != 0 {
+		params["Offset"] = strconv.Itoa(filter.Offset)
+	}
+	if filter.Limit != 0 {
+		params["Limit"] = strconv.Itoa(filter.Limit)
+	}
+	for param, value := range params {
+		if value != "" {
+			query.Set(param, value)
+		}
+	}
+	return query
+}
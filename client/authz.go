@@ -0,0 +1,89 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// AUTHORIZATION API
+
+// GetAuthorizedExternalResources returns the resources that authenticated user is allowed to do the action over
+func (c *Client) GetAuthorizedExternalResources(action string, resources []string) ([]string, error) {
+	request := &internalhttp.AuthorizeResourcesRequest{
+		Action:    action,
+		Resources: resources,
+	}
+	response := &internalhttp.AuthorizeResourcesResponse{}
+	if err := c.do(http.MethodPost, internalhttp.RESOURCE_URL, nil, request, response); err != nil {
+		return nil, err
+	}
+	return response.ResourcesAllowed, nil
+}
+
+// GetAuthorizedExternalResourcesBatch checks several actions and resources for authenticated user in one request
+func (c *Client) GetAuthorizedExternalResourcesBatch(checks []api.AuthorizationCheck) ([]api.AuthorizationCheckResult, error) {
+	request := &internalhttp.AuthorizeResourcesBatchRequest{
+		Checks: checks,
+	}
+	response := &internalhttp.AuthorizeResourcesBatchResponse{}
+	if err := c.do(http.MethodPost, internalhttp.RESOURCE_BATCH_URL, nil, request, response); err != nil {
+		return nil, err
+	}
+	return response.Results, nil
+}
+
+// GetAuthorizedExternalResourcesForUser returns the resources that another user is allowed to do the action over
+func (c *Client) GetAuthorizedExternalResourcesForUser(externalID string, action string, resources []string) ([]string, error) {
+	request := &internalhttp.AuthorizeResourcesForUserRequest{
+		ExternalID: externalID,
+		Action:     action,
+		Resources:  resources,
+	}
+	response := &internalhttp.AuthorizeResourcesResponse{}
+	if err := c.do(http.MethodPost, internalhttp.RESOURCE_CHECK_URL, nil, request, response); err != nil {
+		return nil, err
+	}
+	return response.ResourcesAllowed, nil
+}
+
+// ExplainAuthorization returns the statements that allow or deny the action over each resource for a user
+func (c *Client) ExplainAuthorization(externalID string, action string, resources []string) (*api.AuthorizationExplanation, error) {
+	request := &internalhttp.ExplainAuthorizationRequest{
+		ExternalID: externalID,
+		Action:     action,
+		Resources:  resources,
+	}
+	explanation := &api.AuthorizationExplanation{}
+	if err := c.do(http.MethodPost, internalhttp.RESOURCE_EXPLAIN_URL, nil, request, explanation); err != nil {
+		return nil, err
+	}
+	return explanation, nil
+}
+
+// GetAuthorizedRestrictions returns the urns allowed and denied to authenticated user for the action under urn prefix
+func (c *Client) GetAuthorizedRestrictions(action string, urnPrefix string) (*api.Restrictions, error) {
+	request := &internalhttp.AuthorizeRestrictionsRequest{
+		Action:    action,
+		UrnPrefix: urnPrefix,
+	}
+	restrictions := &api.Restrictions{}
+	if err := c.do(http.MethodPost, internalhttp.RESOURCE_RESTRICTIONS_URL, nil, request, restrictions); err != nil {
+		return nil, err
+	}
+	return restrictions, nil
+}
+
+// GetAuthorizedPrincipals returns the users and groups allowed to do the action over the resource
+func (c *Client) GetAuthorizedPrincipals(action string, resource string) (*api.AuthorizedPrincipals, error) {
+	request := &internalhttp.AuthorizePrincipalsRequest{
+		Action:   action,
+		Resource: resource,
+	}
+	principals := &api.AuthorizedPrincipals{}
+	if err := c.do(http.MethodPost, internalhttp.RESOURCE_PRINCIPALS_URL, nil, request, principals); err != nil {
+		return nil, err
+	}
+	return principals, nil
+}
@@ -0,0 +1,291 @@
+// Package client is a Go client for the worker REST API, with the request and response types of
+// the worker http package.
+package client
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/auth"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+const (
+	// Timeout of requests if no http client is received
+	DEFAULT_TIMEOUT = 30 * time.Second
+)
+
+// Client calls worker API with its credentials
+type Client struct {
+	workerURL   string
+	credentials Credentials
+	httpClient  *http.Client
+
+	// Request id sent to worker, empty to let worker create one
+	requestID string
+}
+
+// Credentials authenticate requests sent to worker
+type Credentials interface {
+	// SetCredentials adds authentication to a request before sending it
+	SetCredentials(r *http.Request) error
+}
+
+// Error is an error response of worker, with the API error code and message, or UNKNOWN_API_ERROR code if
+// the response doesn't have a body.
+type Error struct {
+	StatusCode int
+	Code       string
+	Message    string
+	RequestID  string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("Status: %v, Code: %v, Message: %v, RequestID: %v", e.StatusCode, e.Code, e.Message, e.RequestID)
+}
+
+// NewClient returns a client for the worker in workerURL, e.g. https://foulkon.example.com. If httpClient
+// is nil, a client with DEFAULT_TIMEOUT is used.
+func NewClient(workerURL string, credentials Credentials, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: DEFAULT_TIMEOUT}
+	}
+	return &Client{
+		workerURL:   workerURL,
+		credentials: credentials,
+		httpClient:  httpClient,
+	}
+}
+
+// WithRequestID returns a copy of client that sends requestID to worker, so worker logs and audit events
+// have the request id of the caller, e.g. the one received in Request-ID header of an incoming request.
+func (c *Client) WithRequestID(requestID string) *Client {
+	client := *c
+	client.requestID = requestID
+	return &client
+}
+
+// CREDENTIALS
+
+type basicCredentials struct {
+	username string
+	password string
+}
+
+// NewBasicCredentials returns credentials of worker admin user
+func NewBasicCredentials(username string, password string) Credentials {
+	return &basicCredentials{
+		username: username,
+		password: password,
+	}
+}
+
+func (b *basicCredentials) SetCredentials(r *http.Request) error {
+	r.SetBasicAuth(b.username, b.password)
+	return nil
+}
+
+type bearerCredentials struct {
+	tokenSource func() (string, error)
+}
+
+// NewBearerCredentials returns credentials with an OIDC ID token
+func NewBearerCredentials(token string) Credentials {
+	return NewBearerTokenSource(func() (string, error) {
+		return token, nil
+	})
+}
+
+// NewBearerTokenSource returns credentials with the OIDC ID token returned by tokenSource before each
+// request, so tokens can be renewed when they expire.
+func NewBearerTokenSource(tokenSource func() (string, error)) Credentials {
+	return &bearerCredentials{
+		tokenSource: tokenSource,
+	}
+}
+
+func (b *bearerCredentials) SetCredentials(r *http.Request) error {
+	token, err := b.tokenSource()
+	if err != nil {
+		return err
+	}
+	r.Header.Set("Authorization", "Bearer "+token)
+	return nil
+}
+
+type apiKeyCredentials struct {
+	key string
+}
+
+// NewAPIKeyCredentials returns credentials with an API key, for workers with apikey authenticator
+func NewAPIKeyCredentials(key string) Credentials {
+	return &apiKeyCredentials{
+		key: key,
+	}
+}
+
+func (a *apiKeyCredentials) SetCredentials(r *http.Request) error {
+	r.Header.Set(auth.API_KEY_HEADER, a.key)
+	return nil
+}
+
+// ERRORS
+
+// IsNotFound returns true if err is a worker error because a user, group, policy, version or API key wasn't found
+func IsNotFound(err error) bool {
+	return hasErrorCode(err, api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.GROUP_BY_ORG_AND_NAME_NOT_FOUND,
+		api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.POLICY_VERSION_NOT_FOUND, api.API_KEY_NOT_FOUND)
+}
+
+// IsAlreadyExist returns true if err is a worker error because a user, group, policy or relation already exists
+func IsAlreadyExist(err error) bool {
+	return hasErrorCode(err, api.USER_ALREADY_EXIST, api.GROUP_ALREADY_EXIST, api.POLICY_ALREADY_EXIST,
+		api.USER_IS_ALREADY_A_MEMBER_OF_GROUP, api.POLICY_IS_ALREADY_ATTACHED_TO_GROUP, api.POLICY_IS_ALREADY_ATTACHED_TO_USER)
+}
+
+// IsNotRelated returns true if err is a worker error because a user isn't a member of a group, or a policy
+// isn't attached to a group or user
+func IsNotRelated(err error) bool {
+	return hasErrorCode(err, api.USER_IS_NOT_A_MEMBER_OF_GROUP, api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
+		api.POLICY_IS_NOT_ATTACHED_TO_USER)
+}
+
+// IsInvalidParameter returns true if err is a worker error because of an invalid parameter
+func IsInvalidParameter(err error) bool {
+	return hasErrorCode(err, api.INVALID_PARAMETER_ERROR)
+}
+
+// IsUnauthorized returns true if err is a worker error because authenticated user isn't allowed to do the request
+func IsUnauthorized(err error) bool {
+	return hasErrorCode(err, api.UNAUTHORIZED_RESOURCES_ERROR)
+}
+
+// IsUnauthenticated returns true if err is a worker error because credentials weren't valid
+func IsUnauthenticated(err error) bool {
+	clientError, ok := err.(*Error)
+	return ok && clientError.StatusCode == http.StatusUnauthorized
+}
+
+func hasErrorCode(err error, codes ...string) bool {
+	clientError, ok := err.(*Error)
+	if !ok {
+		return false
+	}
+	for _, code := range codes {
+		if clientError.Code == code {
+			return true
+		}
+	}
+	return false
+}
+
+// PRIVATE HELPER METHODS
+
+// This aux method sends request, marshalled as JSON if it isn't nil, and decodes the response in value,
+// if it isn't nil, or returns the error received.
+func (c *Client) do(method string, path string, query url.Values, request interface{}, value interface{}) error {
+	var body []byte
+	if request != nil {
+		var err error
+		body, err = json.Marshal(request)
+		if err != nil {
+			return err
+		}
+	}
+
+	reqURL := c.workerURL + path
+	if len(query) > 0 {
+		reqURL += "?" + query.Encode()
+	}
+	req, err := http.NewRequest(method, reqURL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if request != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.requestID != "" {
+		req.Header.Set(internalhttp.REQUEST_ID_HEADER, c.requestID)
+	}
+	if c.credentials != nil {
+		if err := c.credentials.SetCredentials(req); err != nil {
+			return err
+		}
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode >= http.StatusOK && res.StatusCode < http.StatusMultipleChoices {
+		if value == nil || res.StatusCode == http.StatusNoContent {
+			return nil
+		}
+		return json.NewDecoder(res.Body).Decode(value)
+	}
+
+	apiError := &api.Error{}
+	if err := json.NewDecoder(res.Body).Decode(apiError); err != nil || apiError.Code == "" {
+		apiError.Code = api.UNKNOWN_API_ERROR
+		apiError.Message = res.Status
+	}
+	return &Error{
+		StatusCode: res.StatusCode,
+		Code:       apiError.Code,
+		Message:    apiError.Message,
+		RequestID:  res.Header.Get(internalhttp.REQUEST_ID_HEADER),
+	}
+}
+
+// Query params of a filter
+func filterQuery(filter *api.Filter) url.Values {
+	query := url.Values{}
+	if filter == nil {
+		return query
+	}
+	if filter.PathPrefix != "" {
+		query.Set("PathPrefix", filter.PathPrefix)
+	}
+	if filter.Offset != 0 {
+		query.Set("Offset", strconv.Itoa(filter.Offset))
+	}
+	if filter.Limit != 0 {
+		query.Set("Limit", strconv.Itoa(filter.Limit))
+	}
+	return query
+}
+
+// Query params of dry run mode, to check each action over each resource
+func dryRunQuery(actions []string, resources []string) url.Values {
+	return url.Values{
+		"DryRun":   {"true"},
+		"Action":   actions,
+		"Resource": resources,
+	}
+}
+
+// URL path of an organization element, escaping names
+func orgPath(org string, elements ...string) string {
+	path := internalhttp.API_VERSION_1 + "/organizations/" + url.PathEscape(org)
+	for _, element := range elements {
+		path += "/" + url.PathEscape(element)
+	}
+	return path
+}
+
+// URL path of a user element, escaping names
+func userPath(externalID string, elements ...string) string {
+	path := internalhttp.USER_ROOT_URL + "/" + url.PathEscape(externalID)
+	for _, element := range elements {
+		path += "/" + url.PathEscape(element)
+	}
+	return path
+}
@@ -0,0 +1,305 @@
+package client
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/auth"
+	"github.com/Tecsisa/foulkon/database/memory"
+	"github.com/Tecsisa/foulkon/foulkon"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+const (
+	adminUser     = "admin"
+	adminPassword = "admin"
+	userToken     = "user1-token"
+)
+
+// Worker with in-memory database, to call real handlers
+var server *httptest.Server
+
+// Worker with API key and test connectors chained, and the same database
+var apiKeyServer *httptest.Server
+
+// Log of workers, with transaction logs
+var workerLog *bytes.Buffer
+
+// Client with admin credentials
+var adminClient *Client
+
+// Aux connector that authenticates user1 with a bearer token
+type TestConnector struct{}
+
+func (tc TestConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+userToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (tc TestConnector) RetrieveUserID(r http.Request) string {
+	return "user1"
+}
+
+// Main Test that executes at first time and create all necessary data to work
+func TestMain(m *testing.M) {
+	// Create logger
+	workerLog = bytes.NewBuffer([]byte{})
+	logger := &log.Logger{
+		Out:       workerLog,
+		Formatter: &log.TextFormatter{},
+		Hooks:     make(log.LevelHooks),
+		Level:     log.DebugLevel,
+	}
+
+	repo := memory.NewMemoryRepo()
+	authApi := api.AuthAPI{
+		UserRepo:   repo,
+		GroupRepo:  repo,
+		PolicyRepo: repo,
+		AuthzRepo:  repo,
+		AuditRepo:  repo,
+		ImportRepo: repo,
+		APIKeyRepo: repo,
+		Logger:     logger,
+	}
+
+	worker := &foulkon.Worker{
+		Logger:        logger,
+		Authenticator: auth.NewAuthenticator(TestConnector{}, adminUser, adminPassword),
+		UserApi:       authApi,
+		GroupApi:      authApi,
+		PolicyApi:     authApi,
+		AuthzApi:      authApi,
+		AuditApi:      authApi,
+		ImportApi:     authApi,
+		APIKeyApi:     authApi,
+	}
+
+	server = httptest.NewServer(internalhttp.WorkerHandlerRouter(worker))
+	apiKeyWorker := *worker
+	apiKeyWorker.Authenticator = auth.NewAuthenticator(auth.NewChainConnector([]auth.NamedAuthConnector{
+		{Name: "apikey", Connector: auth.NewAPIKeyConnector(logger, authApi)},
+		{Name: "test", Connector: TestConnector{}},
+	}), adminUser, adminPassword)
+	apiKeyServer = httptest.NewServer(internalhttp.WorkerHandlerRouter(&apiKeyWorker))
+	adminClient = NewClient(server.URL, NewBasicCredentials(adminUser, adminPassword), nil)
+
+	result := m.Run()
+	server.Close()
+	apiKeyServer.Close()
+	os.Exit(result)
+}
+
+func TestClient_Errors(t *testing.T) {
+	testcases := map[string]struct {
+		client *Client
+		call   func(c *Client) error
+		// Expected result
+		expectedStatusCode int
+		expectedCode       string
+		notFound           bool
+		alreadyExist       bool
+		notRelated         bool
+		invalidParameter   bool
+		unauthorized       bool
+		unauthenticated    bool
+	}{
+		"ErrorCaseNotFound": {
+			client: adminClient,
+			call: func(c *Client) error {
+				_, err := c.GetUser("notfound")
+				return err
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+			notFound:           true,
+		},
+		"ErrorCaseAlreadyExist": {
+			client: adminClient,
+			call: func(c *Client) error {
+				if _, err := c.AddGroup("errors", "group1", "/path/"); err != nil {
+					return err
+				}
+				_, err := c.AddGroup("errors", "group1", "/path/")
+				return err
+			},
+			expectedStatusCode: http.StatusConflict,
+			expectedCode:       api.GROUP_ALREADY_EXIST,
+			alreadyExist:       true,
+		},
+		"ErrorCaseNotRelated": {
+			client: adminClient,
+			call: func(c *Client) error {
+				if _, err := c.AddUser("errors-user", "/path/"); err != nil {
+					return err
+				}
+				if _, err := c.AddGroup("errors", "group2", "/path/"); err != nil {
+					return err
+				}
+				return c.RemoveMember("errors", "group2", "errors-user")
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedCode:       api.USER_IS_NOT_A_MEMBER_OF_GROUP,
+			notRelated:         true,
+		},
+		"ErrorCaseInvalidParameter": {
+			client: adminClient,
+			call: func(c *Client) error {
+				_, err := c.AddUser("user", "invalid")
+				return err
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedCode:       api.INVALID_PARAMETER_ERROR,
+			invalidParameter:   true,
+		},
+		"ErrorCaseUnauthorized": {
+			client: NewClient(server.URL, NewBearerCredentials(userToken), nil),
+			call: func(c *Client) error {
+				_, err := c.AddUser("user2", "/path/")
+				return err
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedCode:       api.UNAUTHORIZED_RESOURCES_ERROR,
+			unauthorized:       true,
+		},
+		"ErrorCaseUnauthenticated": {
+			client: NewClient(server.URL, NewBearerCredentials("invalid"), nil),
+			call: func(c *Client) error {
+				_, err := c.GetUser("user1")
+				return err
+			},
+			expectedStatusCode: http.StatusUnauthorized,
+			expectedCode:       api.UNKNOWN_API_ERROR,
+			unauthenticated:    true,
+		},
+	}
+
+	for n, test := range testcases {
+		err := test.call(test.client)
+		clientError, ok := err.(*Error)
+		if !ok {
+			t.Errorf("Test case %v. Received different error type (wanted:*Error / received:%T %v)", n, err, err)
+			continue
+		}
+		if clientError.StatusCode != test.expectedStatusCode {
+			t.Errorf("Test case %v. Received different status code (wanted:%v / received:%v)", n, test.expectedStatusCode, clientError.StatusCode)
+			continue
+		}
+		if clientError.Code != test.expectedCode {
+			t.Errorf("Test case %v. Received different error code (wanted:%v / received:%v)", n, test.expectedCode, clientError.Code)
+			continue
+		}
+		if clientError.RequestID == "" {
+			t.Errorf("Test case %v. Request id not received", n)
+			continue
+		}
+		for name, check := range map[string]struct {
+			expected bool
+			received bool
+		}{
+			"IsNotFound":         {test.notFound, IsNotFound(err)},
+			"IsAlreadyExist":     {test.alreadyExist, IsAlreadyExist(err)},
+			"IsNotRelated":       {test.notRelated, IsNotRelated(err)},
+			"IsInvalidParameter": {test.invalidParameter, IsInvalidParameter(err)},
+			"IsUnauthorized":     {test.unauthorized, IsUnauthorized(err)},
+			"IsUnauthenticated":  {test.unauthenticated, IsUnauthenticated(err)},
+		} {
+			if check.expected != check.received {
+				t.Errorf("Test case %v. Received different %v (wanted:%v / received:%v)", n, name, check.expected, check.received)
+			}
+		}
+	}
+}
+
+func TestClient_Credentials(t *testing.T) {
+	testcases := map[string]struct {
+		credentials Credentials
+		// Expected result
+		expectedHeader string
+		expectedError  string
+	}{
+		"OkCaseBasic": {
+			credentials:    NewBasicCredentials("admin", "secret"),
+			expectedHeader: "Basic YWRtaW46c2VjcmV0",
+		},
+		"OkCaseBearer": {
+			credentials:    NewBearerCredentials("token"),
+			expectedHeader: "Bearer token",
+		},
+		"OkCaseBearerTokenSource": {
+			credentials: NewBearerTokenSource(func() (string, error) {
+				return "renewed", nil
+			}),
+			expectedHeader: "Bearer renewed",
+		},
+		"OkCaseNoCredentials": {},
+		"ErrorCaseTokenSource": {
+			credentials: NewBearerTokenSource(func() (string, error) {
+				return "", &api.Error{Code: "TokenError", Message: "Expired"}
+			}),
+			expectedError: "Code: TokenError, Message: Expired",
+		},
+	}
+
+	for n, test := range testcases {
+		header := ""
+		fakeWorker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			header = r.Header.Get("Authorization")
+			w.WriteHeader(http.StatusNoContent)
+		}))
+
+		err := NewClient(fakeWorker.URL, test.credentials, nil).RemoveUser("user1")
+		fakeWorker.Close()
+		if test.expectedError != "" {
+			if err == nil || err.Error() != test.expectedError {
+				t.Errorf("Test case %v. Received different error (wanted:%v / received:%v)", n, test.expectedError, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error %v", n, err)
+			continue
+		}
+		if header != test.expectedHeader {
+			t.Errorf("Test case %v. Received different authorization header (wanted:%v / received:%v)", n, test.expectedHeader, header)
+		}
+	}
+}
+
+func TestClient_WithRequestID(t *testing.T) {
+	requestID := "76543210-89ab-cdef-0123-456789abcdef"
+	c := adminClient.WithRequestID(requestID)
+	if _, err := c.AddUser("request-id-user", "/path/"); err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+
+	// Audit event has the request id of caller
+	response, err := adminClient.ListAuditEvents(&api.AuditFilter{UrnPrefix: "urn:iws:iam::user/path/request-id-user"})
+	if err != nil {
+		t.Fatalf("Unexpected error %v", err)
+	}
+	if len(response.AuditEvents) != 1 || response.AuditEvents[0].RequestID != requestID {
+		t.Errorf("Received different audit events (wanted request id:%v / received:%v)", requestID, response.AuditEvents)
+	}
+
+	// Error has the request id of caller, and original client doesn't send it
+	_, err = c.GetUser("notfound")
+	if clientError, ok := err.(*Error); !ok || clientError.RequestID != requestID {
+		t.Errorf("Received different error (wanted request id:%v / received:%v)", requestID, err)
+	}
+	_, err = adminClient.GetUser("notfound")
+	if clientError, ok := err.(*Error); !ok || clientError.RequestID == requestID || !strings.Contains(clientError.Error(), "RequestID") {
+		t.Errorf("Received different error (wanted other request id / received:%v)", err)
+	}
+}
@@ -0,0 +1,186 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// GROUP API
+
+// AddGroup creates a group in the organization
+func (c *Client) AddGroup(org string, name string, path string) (*api.Group, error) {
+	request := &internalhttp.CreateGroupRequest{
+		Name: name,
+		Path: path,
+	}
+	group := &api.Group{}
+	if err := c.do(http.MethodPost, orgPath(org, "groups"), nil, request, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// GetGroup returns the group of the organization with this name
+func (c *Client) GetGroup(org string, name string) (*api.Group, error) {
+	group := &api.Group{}
+	if err := c.do(http.MethodGet, orgPath(org, "groups", name), nil, nil, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// ListGroups returns a page of group names of the organization filtered by path prefix
+func (c *Client) ListGroups(org string, filter *api.Filter) (*internalhttp.ListGroupsResponse, error) {
+	response := &internalhttp.ListGroupsResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "groups"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Groups iterates over names of all groups of the organization with path prefix
+func (c *Client) Groups(org string, pathPrefix string) *NameIterator {
+	it := &NameIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		filter.PathPrefix = pathPrefix
+		response, err := c.ListGroups(org, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Groups
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// ListAllGroups returns a page of groups of all organizations filtered by path prefix
+func (c *Client) ListAllGroups(filter *api.Filter) (*internalhttp.ListAllGroupsResponse, error) {
+	response := &internalhttp.ListAllGroupsResponse{}
+	if err := c.do(http.MethodGet, internalhttp.API_VERSION_1+"/groups", filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AllGroups iterates over groups of all organizations with path prefix
+func (c *Client) AllGroups(pathPrefix string) *GroupIdentityIterator {
+	it := &GroupIdentityIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		filter.PathPrefix = pathPrefix
+		response, err := c.ListAllGroups(filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Groups
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// UpdateGroup changes name and path of a group
+func (c *Client) UpdateGroup(org string, name string, newName string, newPath string) (*api.Group, error) {
+	request := &internalhttp.UpdateGroupRequest{
+		Name: newName,
+		Path: newPath,
+	}
+	group := &api.Group{}
+	if err := c.do(http.MethodPut, orgPath(org, "groups", name), nil, request, group); err != nil {
+		return nil, err
+	}
+	return group, nil
+}
+
+// RemoveGroup deletes a group
+func (c *Client) RemoveGroup(org string, name string) error {
+	return c.do(http.MethodDelete, orgPath(org, "groups", name), nil, nil, nil)
+}
+
+// AddMember adds the user to the group
+func (c *Client) AddMember(org string, name string, externalID string) error {
+	return c.do(http.MethodPost, orgPath(org, "groups", name, "users", externalID), nil, nil, nil)
+}
+
+// RemoveMember removes the user from the group
+func (c *Client) RemoveMember(org string, name string, externalID string) error {
+	return c.do(http.MethodDelete, orgPath(org, "groups", name, "users", externalID), nil, nil, nil)
+}
+
+// ListMembers returns a page of external ids of group members
+func (c *Client) ListMembers(org string, name string, filter *api.Filter) (*internalhttp.ListMembersResponse, error) {
+	response := &internalhttp.ListMembersResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "groups", name, "users"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Members iterates over external ids of all group members
+func (c *Client) Members(org string, name string) *NameIterator {
+	it := &NameIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListMembers(org, name, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Members
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// AttachPolicyToGroup attaches a policy of the organization to the group
+func (c *Client) AttachPolicyToGroup(org string, name string, policyName string) error {
+	return c.do(http.MethodPost, orgPath(org, "groups", name, "policies", policyName), nil, nil, nil)
+}
+
+// DryRunAttachPolicyToGroup returns the permission impact of attaching the policy to the group, for each
+// action over each resource, without changing anything. Without actions and resources, the ones in policy
+// statements are checked.
+func (c *Client) DryRunAttachPolicyToGroup(org string, name string, policyName string, actions []string, resources []string) (*api.PermissionImpact, error) {
+	impact := &api.PermissionImpact{}
+	if err := c.do(http.MethodPost, orgPath(org, "groups", name, "policies", policyName), dryRunQuery(actions, resources), nil, impact); err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// DetachPolicyToGroup detaches a policy of the organization from the group
+func (c *Client) DetachPolicyToGroup(org string, name string, policyName string) error {
+	return c.do(http.MethodDelete, orgPath(org, "groups", name, "policies", policyName), nil, nil, nil)
+}
+
+// DryRunDetachPolicyToGroup returns the permission impact of detaching the policy from the group, for each
+// action over each resource, without changing anything. Without actions and resources, the ones in policy
+// statements are checked.
+func (c *Client) DryRunDetachPolicyToGroup(org string, name string, policyName string, actions []string, resources []string) (*api.PermissionImpact, error) {
+	impact := &api.PermissionImpact{}
+	if err := c.do(http.MethodDelete, orgPath(org, "groups", name, "policies", policyName), dryRunQuery(actions, resources), nil, impact); err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// ListAttachedGroupPolicies returns a page of names of policies attached to the group
+func (c *Client) ListAttachedGroupPolicies(org string, name string, filter *api.Filter) (*internalhttp.ListAttachedGroupPoliciesResponse, error) {
+	response := &internalhttp.ListAttachedGroupPoliciesResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "groups", name, "policies"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AttachedGroupPolicies iterates over names of all policies attached to the group
+func (c *Client) AttachedGroupPolicies(org string, name string) *NameIterator {
+	it := &NameIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListAttachedGroupPolicies(org, name, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.AttachedPolicies
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
@@ -0,0 +1,137 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestClient_Groups(t *testing.T) {
+	c := adminClient
+
+	group, err := c.AddGroup("groups", "group1", "/groups/")
+	if err != nil {
+		t.Fatalf("Unexpected error adding group %v", err)
+	}
+	if group.Org != "groups" || group.Name != "group1" || group.Path != "/groups/" {
+		t.Errorf("Received different group %v", group)
+	}
+	if _, err := c.AddGroup("groups", "group2", "/groups/"); err != nil {
+		t.Fatalf("Unexpected error adding group %v", err)
+	}
+
+	group, err = c.UpdateGroup("groups", "group2", "group3", "/groups/updated/")
+	if err != nil {
+		t.Fatalf("Unexpected error updating group %v", err)
+	}
+	if group.Name != "group3" || group.Path != "/groups/updated/" {
+		t.Errorf("Received different group %v", group)
+	}
+	if _, err := c.GetGroup("groups", "group2"); !IsNotFound(err) {
+		t.Errorf("Received different error (wanted:not found / received:%v)", err)
+	}
+
+	// List and iterate
+	names := []string{}
+	it := c.Groups("groups", "/groups/")
+	for it.Next() {
+		names = append(names, it.Name())
+	}
+	if diff := pretty.Compare(names, []string{"group1", "group3"}); diff != "" || it.Err() != nil {
+		t.Errorf("Received different groups (received/wanted) %v, error %v", diff, it.Err())
+	}
+	response, err := c.ListAllGroups(&api.Filter{PathPrefix: "/groups/updated/"})
+	if err != nil {
+		t.Fatalf("Unexpected error listing groups %v", err)
+	}
+	if diff := pretty.Compare(response.Groups, []api.GroupIdentity{{Org: "groups", Name: "group3"}}); diff != "" {
+		t.Errorf("Received different groups (received/wanted) %v", diff)
+	}
+	allGroups := c.AllGroups("/groups/")
+	for allGroups.Next() {
+	}
+	if allGroups.Total() != 2 || allGroups.Err() != nil {
+		t.Errorf("Received different total (wanted:2 / received:%v), error %v", allGroups.Total(), allGroups.Err())
+	}
+
+	// Members
+	if _, err := c.AddUser("groups-user1", "/groups/"); err != nil {
+		t.Fatalf("Unexpected error adding user %v", err)
+	}
+	if err := c.AddMember("groups", "group1", "groups-user1"); err != nil {
+		t.Fatalf("Unexpected error adding member %v", err)
+	}
+	if err := c.AddMember("groups", "group1", "groups-user1"); !IsAlreadyExist(err) {
+		t.Errorf("Received different error (wanted:already exist / received:%v)", err)
+	}
+	members, err := c.ListMembers("groups", "group1", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error listing members %v", err)
+	}
+	if diff := pretty.Compare(members.Members, []string{"groups-user1"}); diff != "" {
+		t.Errorf("Received different members (received/wanted) %v", diff)
+	}
+	if err := c.RemoveMember("groups", "group1", "groups-user1"); err != nil {
+		t.Fatalf("Unexpected error removing member %v", err)
+	}
+	memberIt := c.Members("groups", "group1")
+	if memberIt.Next() || memberIt.Err() != nil {
+		t.Errorf("Received different members, error %v", memberIt.Err())
+	}
+
+	// Policies with dry run
+	if err := c.AddMember("groups", "group1", "groups-user1"); err != nil {
+		t.Fatalf("Unexpected error adding member %v", err)
+	}
+	statements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{api.USER_ACTION_GET_USER},
+			Resources: []string{"urn:iws:iam::user/groups/*"},
+		},
+	}
+	if _, err := c.AddPolicy("groups", "policy1", "/path/", statements); err != nil {
+		t.Fatalf("Unexpected error adding policy %v", err)
+	}
+	impact, err := c.DryRunAttachPolicyToGroup("groups", "group1", "policy1", nil, nil)
+	if err != nil {
+		t.Fatalf("Unexpected error in dry run %v", err)
+	}
+	if len(impact.Changes) != 1 || impact.Changes[0].User != "groups-user1" {
+		t.Errorf("Received different impact %v", impact)
+	}
+	policyIt := c.AttachedGroupPolicies("groups", "group1")
+	if policyIt.Next() {
+		t.Errorf("Dry run attached policy %v", policyIt.Name())
+	}
+	if err := c.AttachPolicyToGroup("groups", "group1", "policy1"); err != nil {
+		t.Fatalf("Unexpected error attaching policy %v", err)
+	}
+	policies, err := c.ListAttachedGroupPolicies("groups", "group1", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error listing policies %v", err)
+	}
+	if diff := pretty.Compare(policies.AttachedPolicies, []string{"policy1"}); diff != "" {
+		t.Errorf("Received different policies (received/wanted) %v", diff)
+	}
+	impact, err = c.DryRunDetachPolicyToGroup("groups", "group1", "policy1", []string{api.USER_ACTION_GET_USER},
+		[]string{"urn:iws:iam::user/groups/groups-user1"})
+	if err != nil {
+		t.Fatalf("Unexpected error in dry run %v", err)
+	}
+	if len(impact.Changes) != 1 {
+		t.Errorf("Received different impact %v", impact)
+	}
+	if err := c.DetachPolicyToGroup("groups", "group1", "policy1"); err != nil {
+		t.Fatalf("Unexpected error detaching policy %v", err)
+	}
+
+	// Remove
+	if err := c.RemoveGroup("groups", "group3"); err != nil {
+		t.Fatalf("Unexpected error removing group %v", err)
+	}
+	if _, err := c.GetGroup("groups", "group3"); !IsNotFound(err) {
+		t.Errorf("Received different error (wanted:not found / received:%v)", err)
+	}
+}
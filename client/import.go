@@ -0,0 +1,40 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// IMPORT API
+
+// Export returns users, groups and policies of the organization, or of all organizations if org is empty
+func (c *Client) Export(org string) (*api.ExportDocument, error) {
+	document := &api.ExportDocument{}
+	if err := c.do(http.MethodGet, importPath(org, "export"), nil, nil, document); err != nil {
+		return nil, err
+	}
+	return document, nil
+}
+
+// Import applies a document to the organization, or to all organizations if org is empty. With prune,
+// elements that aren't in the document are removed.
+func (c *Client) Import(org string, document *api.ExportDocument, prune bool) (*api.ImportResult, error) {
+	result := &api.ImportResult{}
+	query := url.Values{"Prune": {strconv.FormatBool(prune)}}
+	if err := c.do(http.MethodPost, importPath(org, "import"), query, document, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// URL path of export and import endpoints
+func importPath(org string, endpoint string) string {
+	if org == "" {
+		return internalhttp.API_VERSION_1 + "/" + endpoint
+	}
+	return orgPath(org, endpoint)
+}
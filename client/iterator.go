@@ -0,0 +1,192 @@
+package client
+
+import (
+	"github.com/Tecsisa/foulkon/api"
+)
+
+// pager requests pages of a list with Offset and Limit until Total items are received
+type pager struct {
+	offset int
+	total  int
+	done   bool
+	err    error
+
+	// Requests the page of filter, returning its number of items and the total
+	fetch func(filter *api.Filter) (int, int, error)
+}
+
+// Err returns the error received requesting a page, if any. It must be checked when Next returns false.
+func (p *pager) Err() error {
+	return p.err
+}
+
+// Total returns the number of items of the list, received with the last page
+func (p *pager) Total() int {
+	return p.total
+}
+
+// This aux method requests next page, returning false if there are no more items or an error happened
+func (p *pager) nextPage() bool {
+	if p.done {
+		return false
+	}
+	items, total, err := p.fetch(&api.Filter{
+		Offset: p.offset,
+		Limit:  api.MAX_LIMIT_SIZE,
+	})
+	if err != nil {
+		p.err = err
+		p.done = true
+		return false
+	}
+	p.offset += items
+	p.total = total
+	if items == 0 || p.offset >= total {
+		p.done = true
+	}
+	return items > 0
+}
+
+// NameIterator iterates over names of a list: user external ids, group names, policy names or group members
+type NameIterator struct {
+	pager
+	items []string
+	next  int
+}
+
+// Next moves to the next name, requesting next page if needed. It returns false at the end or if an error happened.
+func (it *NameIterator) Next() bool {
+	if it.next >= len(it.items) {
+		if !it.nextPage() {
+			return false
+		}
+		it.next = 0
+	}
+	it.next++
+	return true
+}
+
+// Name returns current name
+func (it *NameIterator) Name() string {
+	return it.items[it.next-1]
+}
+
+// GroupIdentityIterator iterates over groups of all organizations or of a user
+type GroupIdentityIterator struct {
+	pager
+	items []api.GroupIdentity
+	next  int
+}
+
+// Next moves to the next group, requesting next page if needed. It returns false at the end or if an error happened.
+func (it *GroupIdentityIterator) Next() bool {
+	if it.next >= len(it.items) {
+		if !it.nextPage() {
+			return false
+		}
+		it.next = 0
+	}
+	it.next++
+	return true
+}
+
+// Group returns current group
+func (it *GroupIdentityIterator) Group() api.GroupIdentity {
+	return it.items[it.next-1]
+}
+
+// PolicyIdentityIterator iterates over policies of all organizations or attached to a user
+type PolicyIdentityIterator struct {
+	pager
+	items []api.PolicyIdentity
+	next  int
+}
+
+// Next moves to the next policy, requesting next page if needed. It returns false at the end or if an error happened.
+func (it *PolicyIdentityIterator) Next() bool {
+	if it.next >= len(it.items) {
+		if !it.nextPage() {
+			return false
+		}
+		it.next = 0
+	}
+	it.next++
+	return true
+}
+
+// Policy returns current policy
+func (it *PolicyIdentityIterator) Policy() api.PolicyIdentity {
+	return it.items[it.next-1]
+}
+
+// PolicyVersionIterator iterates over versions of a policy
+type PolicyVersionIterator struct {
+	pager
+	items []api.PolicyVersion
+	next  int
+}
+
+// Next moves to the next version, requesting next page if needed. It returns false at the end or if an error happened.
+func (it *PolicyVersionIterator) Next() bool {
+	if it.next >= len(it.items) {
+		if !it.nextPage() {
+			return false
+		}
+		it.next = 0
+	}
+	it.next++
+	return true
+}
+
+// Version returns current version
+func (it *PolicyVersionIterator) Version() api.PolicyVersion {
+	return it.items[it.next-1]
+}
+
+// AuditEventIterator iterates over audit events
+type AuditEventIterator struct {
+	pager
+	items []api.AuditEvent
+	next  int
+}
+
+// Next moves to the next event, requesting next page if needed. It returns false at the end or if an error happened.
+func (it *AuditEventIterator) Next() bool {
+	if it.next >= len(it.items) {
+		if !it.nextPage() {
+			return false
+		}
+		it.next = 0
+	}
+	it.next++
+	return true
+}
+
+// Event returns current event
+func (it *AuditEventIterator) Event() api.AuditEvent {
+	return it.items[it.next-1]
+}
+
+// APIKeyIterator iterates over API keys of a user
+type APIKeyIterator struct {
+	pager
+	items []api.APIKey
+	next  int
+}
+
+// Next moves to the next API key, requesting next page if needed. It returns false at the end or if an error happened.
+func (it *APIKeyIterator) Next() bool {
+	if it.next >= len(it.items) {
+		if !it.nextPage() {
+			return false
+		}
+		it.next = 0
+	}
+	it.next++
+	return true
+}
+
+// APIKey returns current API key
+func (it *APIKeyIterator) APIKey() api.APIKey {
+	return it.items[it.next-1]
+}
@@ -0,0 +1,106 @@
+package client
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestNameIterator(t *testing.T) {
+	testcases := map[string]struct {
+		// Pages returned by worker
+		pages [][]string
+		total int
+		// Error returned by worker, after all pages
+		errorStatus int
+		// Expected result
+		expectedNames    []string
+		expectedRequests int
+		expectedError    bool
+	}{
+		"OkCaseEmpty": {
+			pages:            [][]string{{}},
+			expectedNames:    []string{},
+			expectedRequests: 1,
+		},
+		"OkCaseOnePage": {
+			pages:            [][]string{{"user1", "user2"}},
+			total:            2,
+			expectedNames:    []string{"user1", "user2"},
+			expectedRequests: 1,
+		},
+		"OkCaseSeveralPages": {
+			pages:            [][]string{{"user1", "user2"}, {"user3"}, {"user4"}},
+			total:            4,
+			expectedNames:    []string{"user1", "user2", "user3", "user4"},
+			expectedRequests: 3,
+		},
+		"OkCaseRemovedWhileIterating": {
+			pages:            [][]string{{"user1", "user2"}, {}},
+			total:            3,
+			expectedNames:    []string{"user1", "user2"},
+			expectedRequests: 2,
+		},
+		"ErrorCaseSecondPage": {
+			pages:            [][]string{{"user1"}},
+			total:            2,
+			errorStatus:      http.StatusInternalServerError,
+			expectedNames:    []string{"user1"},
+			expectedRequests: 2,
+			expectedError:    true,
+		},
+	}
+
+	for n, test := range testcases {
+		requests := 0
+		offsets := []int{}
+		fakeWorker := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			offset, _ := strconv.Atoi(r.URL.Query().Get("Offset"))
+			offsets = append(offsets, offset)
+			if r.URL.Query().Get("Limit") != strconv.Itoa(api.MAX_LIMIT_SIZE) {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if requests >= len(test.pages) {
+				w.WriteHeader(test.errorStatus)
+				return
+			}
+			json.NewEncoder(w).Encode(internalhttp.GetUserExternalIDsResponse{
+				ExternalIDs: test.pages[requests],
+				Offset:      offset,
+				Limit:       api.MAX_LIMIT_SIZE,
+				Total:       test.total,
+			})
+			requests++
+		}))
+
+		names := []string{}
+		it := NewClient(fakeWorker.URL, nil, nil).Users("")
+		for it.Next() {
+			names = append(names, it.Name())
+		}
+		fakeWorker.Close()
+
+		if diff := pretty.Compare(names, test.expectedNames); diff != "" {
+			t.Errorf("Test case %v. Received different names (received/wanted) %v", n, diff)
+			continue
+		}
+		if len(offsets) != test.expectedRequests {
+			t.Errorf("Test case %v. Received different requests (wanted:%v / received:%v)", n, test.expectedRequests, len(offsets))
+			continue
+		}
+		if test.expectedError != (it.Err() != nil) {
+			t.Errorf("Test case %v. Received different error %v", n, it.Err())
+			continue
+		}
+		if it.Next() {
+			t.Errorf("Test case %v. Iterator continues after end", n)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package client
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// POLICY API
+
+// AddPolicy creates a policy in the organization
+func (c *Client) AddPolicy(org string, name string, path string, statements []api.Statement) (*api.Policy, error) {
+	request := &internalhttp.CreatePolicyRequest{
+		Name:       name,
+		Path:       path,
+		Statements: statements,
+	}
+	policy := &api.Policy{}
+	if err := c.do(http.MethodPost, orgPath(org, "policies"), nil, request, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DryRunAddPolicy validates a policy and returns the permission impact of creating it, for each action over
+// each resource, without changing anything. Without actions and resources, the ones in statements are checked.
+func (c *Client) DryRunAddPolicy(org string, name string, path string, statements []api.Statement, actions []string, resources []string) (*api.PermissionImpact, error) {
+	request := &internalhttp.CreatePolicyRequest{
+		Name:       name,
+		Path:       path,
+		Statements: statements,
+	}
+	impact := &api.PermissionImpact{}
+	if err := c.do(http.MethodPost, orgPath(org, "policies"), dryRunQuery(actions, resources), request, impact); err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// GetPolicy returns the policy of the organization with this name
+func (c *Client) GetPolicy(org string, name string) (*api.Policy, error) {
+	policy := &api.Policy{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies", name), nil, nil, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// ListPolicies returns a page of policy names of the organization filtered by path prefix
+func (c *Client) ListPolicies(org string, filter *api.Filter) (*internalhttp.ListPoliciesResponse, error) {
+	response := &internalhttp.ListPoliciesResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Policies iterates over names of all policies of the organization with path prefix
+func (c *Client) Policies(org string, pathPrefix string) *NameIterator {
+	it := &NameIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		filter.PathPrefix = pathPrefix
+		response, err := c.ListPolicies(org, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Policies
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// ListAllPolicies returns a page of policies of all organizations filtered by path prefix
+func (c *Client) ListAllPolicies(filter *api.Filter) (*internalhttp.ListAllPoliciesResponse, error) {
+	response := &internalhttp.ListAllPoliciesResponse{}
+	if err := c.do(http.MethodGet, internalhttp.API_VERSION_1+"/policies", filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AllPolicies iterates over policies of all organizations with path prefix
+func (c *Client) AllPolicies(pathPrefix string) *PolicyIdentityIterator {
+	it := &PolicyIdentityIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		filter.PathPrefix = pathPrefix
+		response, err := c.ListAllPolicies(filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Policies
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// UpdatePolicy changes name, path and statements of a policy
+func (c *Client) UpdatePolicy(org string, name string, newName string, newPath string, statements []api.Statement) (*api.Policy, error) {
+	request := &internalhttp.UpdatePolicyRequest{
+		Name:       newName,
+		Path:       newPath,
+		Statements: statements,
+	}
+	policy := &api.Policy{}
+	if err := c.do(http.MethodPut, orgPath(org, "policies", name), nil, request, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// DryRunUpdatePolicy validates a policy update and returns its permission impact, for each action over each
+// resource, without changing anything. Without actions and resources, the ones in changed statements are checked.
+func (c *Client) DryRunUpdatePolicy(org string, name string, newName string, newPath string, statements []api.Statement,
+	actions []string, resources []string) (*api.PermissionImpact, error) {
+	request := &internalhttp.UpdatePolicyRequest{
+		Name:       newName,
+		Path:       newPath,
+		Statements: statements,
+	}
+	impact := &api.PermissionImpact{}
+	if err := c.do(http.MethodPut, orgPath(org, "policies", name), dryRunQuery(actions, resources), request, impact); err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// RemovePolicy deletes a policy
+func (c *Client) RemovePolicy(org string, name string) error {
+	return c.do(http.MethodDelete, orgPath(org, "policies", name), nil, nil, nil)
+}
+
+// ListAttachedGroups returns a page of names of groups that have the policy attached
+func (c *Client) ListAttachedGroups(org string, name string, filter *api.Filter) (*internalhttp.ListAttachedGroupsResponse, error) {
+	response := &internalhttp.ListAttachedGroupsResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies", name, "groups"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AttachedGroups iterates over names of all groups that have the policy attached
+func (c *Client) AttachedGroups(org string, name string) *NameIterator {
+	it := &NameIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListAttachedGroups(org, name, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Groups
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// POLICY VERSIONS
+
+// ListPolicyVersions returns a page of versions of the policy
+func (c *Client) ListPolicyVersions(org string, name string, filter *api.Filter) (*internalhttp.ListPolicyVersionsResponse, error) {
+	response := &internalhttp.ListPolicyVersionsResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies", name, "versions"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// PolicyVersions iterates over all versions of the policy
+func (c *Client) PolicyVersions(org string, name string) *PolicyVersionIterator {
+	it := &PolicyVersionIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListPolicyVersions(org, name, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Versions
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// GetPolicyVersion returns a version of the policy
+func (c *Client) GetPolicyVersion(org string, name string, version int) (*api.PolicyVersion, error) {
+	policyVersion := &api.PolicyVersion{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies", name, "versions", strconv.Itoa(version)), nil, nil, policyVersion); err != nil {
+		return nil, err
+	}
+	return policyVersion, nil
+}
+
+// DiffPolicyVersions returns the differences between two versions of the policy
+func (c *Client) DiffPolicyVersions(org string, name string, fromVersion int, toVersion int) (*api.PolicyVersionDiff, error) {
+	query := url.Values{
+		"From": {strconv.Itoa(fromVersion)},
+		"To":   {strconv.Itoa(toVersion)},
+	}
+	diff := &api.PolicyVersionDiff{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies", name, "diff"), query, nil, diff); err != nil {
+		return nil, err
+	}
+	return diff, nil
+}
+
+// RollbackPolicy restores a version of the policy, creating a new version
+func (c *Client) RollbackPolicy(org string, name string, version int) (*api.Policy, error) {
+	policy := &api.Policy{}
+	if err := c.do(http.MethodPost, orgPath(org, "policies", name, "versions", strconv.Itoa(version), "rollback"), nil, nil, policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// POLICY LINT
+
+// LintPolicy analyzes statements of the policy. Actions without iam prefix are checked against known actions, if any.
+func (c *Client) LintPolicy(org string, name string, knownActions []string) (*api.PolicyLintReport, error) {
+	report := &api.PolicyLintReport{}
+	if err := c.do(http.MethodGet, orgPath(org, "policies", name, "lint"), url.Values{"Action": knownActions}, nil, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// LintPolicies analyzes all policies of the organization that the user can get
+func (c *Client) LintPolicies(org string, knownActions []string) (*internalhttp.LintPoliciesResponse, error) {
+	response := &internalhttp.LintPoliciesResponse{}
+	if err := c.do(http.MethodGet, orgPath(org, "lint"), url.Values{"Action": knownActions}, nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
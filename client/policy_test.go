@@ -0,0 +1,141 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestClient_Policies(t *testing.T) {
+	c := adminClient
+
+	statements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{api.USER_ACTION_GET_USER},
+			Resources: []string{"urn:iws:iam::user/policies/*"},
+		},
+	}
+	newStatements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{api.USER_ACTION_LIST_USERS},
+			Resources: []string{"urn:iws:iam::user/policies/*"},
+		},
+	}
+
+	impact, err := c.DryRunAddPolicy("policies", "policy1", "/policies/", statements, nil, nil)
+	if err != nil || impact == nil {
+		t.Fatalf("Unexpected error in dry run %v", err)
+	}
+	if _, err := c.GetPolicy("policies", "policy1"); !IsNotFound(err) {
+		t.Errorf("Dry run added policy, error %v", err)
+	}
+
+	policy, err := c.AddPolicy("policies", "policy1", "/policies/", statements)
+	if err != nil {
+		t.Fatalf("Unexpected error adding policy %v", err)
+	}
+	if policy.Name != "policy1" || policy.Path != "/policies/" {
+		t.Errorf("Received different policy %v", policy)
+	}
+
+	policy, err = c.UpdatePolicy("policies", "policy1", "policy1", "/policies/", newStatements)
+	if err != nil {
+		t.Fatalf("Unexpected error updating policy %v", err)
+	}
+	if diff := pretty.Compare(*policy.Statements, newStatements); diff != "" {
+		t.Errorf("Received different statements (received/wanted) %v", diff)
+	}
+
+	// List and iterate
+	names := []string{}
+	it := c.Policies("policies", "/policies/")
+	for it.Next() {
+		names = append(names, it.Name())
+	}
+	if diff := pretty.Compare(names, []string{"policy1"}); diff != "" || it.Err() != nil {
+		t.Errorf("Received different policies (received/wanted) %v, error %v", diff, it.Err())
+	}
+	response, err := c.ListAllPolicies(&api.Filter{PathPrefix: "/policies/"})
+	if err != nil {
+		t.Fatalf("Unexpected error listing policies %v", err)
+	}
+	if diff := pretty.Compare(response.Policies, []api.PolicyIdentity{{Org: "policies", Name: "policy1"}}); diff != "" {
+		t.Errorf("Received different policies (received/wanted) %v", diff)
+	}
+
+	// Attached groups
+	if _, err := c.AddGroup("policies", "group1", "/path/"); err != nil {
+		t.Fatalf("Unexpected error adding group %v", err)
+	}
+	if err := c.AttachPolicyToGroup("policies", "group1", "policy1"); err != nil {
+		t.Fatalf("Unexpected error attaching policy %v", err)
+	}
+	groupIt := c.AttachedGroups("policies", "policy1")
+	if !groupIt.Next() || groupIt.Name() != "group1" || groupIt.Next() || groupIt.Err() != nil {
+		t.Errorf("Received different attached groups, error %v", groupIt.Err())
+	}
+
+	// Versions
+	versions := []int{}
+	versionIt := c.PolicyVersions("policies", "policy1")
+	for versionIt.Next() {
+		versions = append(versions, versionIt.Version().Version)
+	}
+	if versionIt.Err() != nil || len(versions) != 2 {
+		t.Fatalf("Received different versions %v, error %v", versions, versionIt.Err())
+	}
+	version, err := c.GetPolicyVersion("policies", "policy1", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error getting version %v", err)
+	}
+	if diff := pretty.Compare(*version.Statements, statements); diff != "" {
+		t.Errorf("Received different statements (received/wanted) %v", diff)
+	}
+	versionDiff, err := c.DiffPolicyVersions("policies", "policy1", 1, 2)
+	if err != nil {
+		t.Fatalf("Unexpected error comparing versions %v", err)
+	}
+	if diff := pretty.Compare(versionDiff.RemovedStatements, statements); diff != "" {
+		t.Errorf("Received different removed statements (received/wanted) %v", diff)
+	}
+	if diff := pretty.Compare(versionDiff.AddedStatements, newStatements); diff != "" {
+		t.Errorf("Received different added statements (received/wanted) %v", diff)
+	}
+	policy, err = c.RollbackPolicy("policies", "policy1", 1)
+	if err != nil {
+		t.Fatalf("Unexpected error rolling back policy %v", err)
+	}
+	if diff := pretty.Compare(*policy.Statements, statements); diff != "" {
+		t.Errorf("Received different statements (received/wanted) %v", diff)
+	}
+	if _, err := c.GetPolicyVersion("policies", "policy1", 10); !IsNotFound(err) {
+		t.Errorf("Received different error (wanted:not found / received:%v)", err)
+	}
+
+	// Lint
+	report, err := c.LintPolicy("policies", "policy1", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error linting policy %v", err)
+	}
+	if report.Org != "policies" || report.Name != "policy1" {
+		t.Errorf("Received different report %v", report)
+	}
+	reports, err := c.LintPolicies("policies", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error linting policies %v", err)
+	}
+	if len(reports.Reports) != 1 {
+		t.Errorf("Received different reports %v", reports.Reports)
+	}
+
+	// Remove
+	if err := c.RemovePolicy("policies", "policy1"); err != nil {
+		t.Fatalf("Unexpected error removing policy %v", err)
+	}
+	if _, err := c.GetPolicy("policies", "policy1"); !IsNotFound(err) {
+		t.Errorf("Received different error (wanted:not found / received:%v)", err)
+	}
+}
@@ -0,0 +1,129 @@
+package client
+
+import (
+	"net/http"
+
+	"github.com/Tecsisa/foulkon/api"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+// USER API
+
+// AddUser creates a user
+func (c *Client) AddUser(externalID string, path string) (*api.User, error) {
+	request := &internalhttp.CreateUserRequest{
+		ExternalID: externalID,
+		Path:       path,
+	}
+	user := &api.User{}
+	if err := c.do(http.MethodPost, internalhttp.USER_ROOT_URL, nil, request, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUser returns the user with this external id
+func (c *Client) GetUser(externalID string) (*api.User, error) {
+	user := &api.User{}
+	if err := c.do(http.MethodGet, userPath(externalID), nil, nil, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// ListUsers returns a page of user external ids filtered by path prefix
+func (c *Client) ListUsers(filter *api.Filter) (*internalhttp.GetUserExternalIDsResponse, error) {
+	response := &internalhttp.GetUserExternalIDsResponse{}
+	if err := c.do(http.MethodGet, internalhttp.USER_ROOT_URL, filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// Users iterates over external ids of all users with path prefix
+func (c *Client) Users(pathPrefix string) *NameIterator {
+	it := &NameIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		filter.PathPrefix = pathPrefix
+		response, err := c.ListUsers(filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.ExternalIDs
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// UpdateUser changes the path of a user
+func (c *Client) UpdateUser(externalID string, path string) (*api.User, error) {
+	request := &internalhttp.UpdateUserRequest{
+		Path: path,
+	}
+	user := &api.User{}
+	if err := c.do(http.MethodPut, userPath(externalID), nil, request, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// RemoveUser deletes a user
+func (c *Client) RemoveUser(externalID string) error {
+	return c.do(http.MethodDelete, userPath(externalID), nil, nil, nil)
+}
+
+// ListGroupsByUser returns a page of groups that the user is a member of
+func (c *Client) ListGroupsByUser(externalID string, filter *api.Filter) (*internalhttp.GetGroupsByUserIdResponse, error) {
+	response := &internalhttp.GetGroupsByUserIdResponse{}
+	if err := c.do(http.MethodGet, userPath(externalID, "groups"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// GroupsByUser iterates over all groups that the user is a member of
+func (c *Client) GroupsByUser(externalID string) *GroupIdentityIterator {
+	it := &GroupIdentityIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListGroupsByUser(externalID, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.Groups
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// ListAttachedUserPolicies returns a page of policies attached to the user
+func (c *Client) ListAttachedUserPolicies(externalID string, filter *api.Filter) (*internalhttp.ListAttachedUserPoliciesResponse, error) {
+	response := &internalhttp.ListAttachedUserPoliciesResponse{}
+	if err := c.do(http.MethodGet, userPath(externalID, "policies"), filterQuery(filter), nil, response); err != nil {
+		return nil, err
+	}
+	return response, nil
+}
+
+// AttachedUserPolicies iterates over all policies attached to the user
+func (c *Client) AttachedUserPolicies(externalID string) *PolicyIdentityIterator {
+	it := &PolicyIdentityIterator{}
+	it.fetch = func(filter *api.Filter) (int, int, error) {
+		response, err := c.ListAttachedUserPolicies(externalID, filter)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.items = response.AttachedPolicies
+		return len(it.items), response.Total, nil
+	}
+	return it
+}
+
+// AttachPolicyToUser attaches a policy of the organization to the user
+func (c *Client) AttachPolicyToUser(externalID string, org string, policyName string) error {
+	return c.do(http.MethodPost, userPath(externalID, "organizations", org, "policies", policyName), nil, nil, nil)
+}
+
+// DetachPolicyFromUser detaches a policy of the organization from the user
+func (c *Client) DetachPolicyFromUser(externalID string, org string, policyName string) error {
+	return c.do(http.MethodDelete, userPath(externalID, "organizations", org, "policies", policyName), nil, nil, nil)
+}
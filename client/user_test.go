@@ -0,0 +1,107 @@
+package client
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestClient_Users(t *testing.T) {
+	c := adminClient
+
+	user, err := c.AddUser("users-user1", "/users/")
+	if err != nil {
+		t.Fatalf("Unexpected error adding user %v", err)
+	}
+	if user.ExternalID != "users-user1" || user.Urn != api.CreateUrn("", api.RESOURCE_USER, "/users/", "users-user1") {
+		t.Errorf("Received different user %v", user)
+	}
+	if _, err := c.AddUser("users-user2", "/users/"); err != nil {
+		t.Fatalf("Unexpected error adding user %v", err)
+	}
+
+	user, err = c.UpdateUser("users-user1", "/users/updated/")
+	if err != nil {
+		t.Fatalf("Unexpected error updating user %v", err)
+	}
+	if user.Path != "/users/updated/" {
+		t.Errorf("Received different path (wanted:/users/updated/ / received:%v)", user.Path)
+	}
+	user, err = c.GetUser("users-user1")
+	if err != nil || user.Path != "/users/updated/" {
+		t.Errorf("Received different user %v, error %v", user, err)
+	}
+
+	// List and iterate
+	response, err := c.ListUsers(&api.Filter{PathPrefix: "/users/", Limit: 1})
+	if err != nil {
+		t.Fatalf("Unexpected error listing users %v", err)
+	}
+	if response.Total != 2 || len(response.ExternalIDs) != 1 || response.Limit != 1 {
+		t.Errorf("Received different response %v", response)
+	}
+	externalIDs := []string{}
+	it := c.Users("/users/")
+	for it.Next() {
+		externalIDs = append(externalIDs, it.Name())
+	}
+	if it.Err() != nil {
+		t.Fatalf("Unexpected error iterating users %v", it.Err())
+	}
+	if diff := pretty.Compare(externalIDs, []string{"users-user1", "users-user2"}); diff != "" {
+		t.Errorf("Received different users (received/wanted) %v", diff)
+	}
+
+	// Groups and policies of user
+	if _, err := c.AddGroup("users", "group1", "/path/"); err != nil {
+		t.Fatalf("Unexpected error adding group %v", err)
+	}
+	if err := c.AddMember("users", "group1", "users-user1"); err != nil {
+		t.Fatalf("Unexpected error adding member %v", err)
+	}
+	groups := []api.GroupIdentity{}
+	groupIt := c.GroupsByUser("users-user1")
+	for groupIt.Next() {
+		groups = append(groups, groupIt.Group())
+	}
+	if diff := pretty.Compare(groups, []api.GroupIdentity{{Org: "users", Name: "group1"}}); diff != "" || groupIt.Err() != nil {
+		t.Errorf("Received different groups (received/wanted) %v, error %v", diff, groupIt.Err())
+	}
+
+	statements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{api.USER_ACTION_GET_USER},
+			Resources: []string{"urn:iws:iam::user/users/*"},
+		},
+	}
+	if _, err := c.AddPolicy("users", "policy1", "/path/", statements); err != nil {
+		t.Fatalf("Unexpected error adding policy %v", err)
+	}
+	if err := c.AttachPolicyToUser("users-user1", "users", "policy1"); err != nil {
+		t.Fatalf("Unexpected error attaching policy %v", err)
+	}
+	policiesResponse, err := c.ListAttachedUserPolicies("users-user1", nil)
+	if err != nil {
+		t.Fatalf("Unexpected error listing policies %v", err)
+	}
+	if diff := pretty.Compare(policiesResponse.AttachedPolicies, []api.PolicyIdentity{{Org: "users", Name: "policy1"}}); diff != "" {
+		t.Errorf("Received different policies (received/wanted) %v", diff)
+	}
+	if err := c.DetachPolicyFromUser("users-user1", "users", "policy1"); err != nil {
+		t.Fatalf("Unexpected error detaching policy %v", err)
+	}
+	policyIt := c.AttachedUserPolicies("users-user1")
+	if policyIt.Next() || policyIt.Err() != nil || policyIt.Total() != 0 {
+		t.Errorf("Received different policies, total %v, error %v", policyIt.Total(), policyIt.Err())
+	}
+
+	// Remove
+	if err := c.RemoveUser("users-user2"); err != nil {
+		t.Fatalf("Unexpected error removing user %v", err)
+	}
+	if _, err := c.GetUser("users-user2"); !IsNotFound(err) {
+		t.Errorf("Received different error (wanted:not found / received:%v)", err)
+	}
+}
@@ -0,0 +1,748 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/client"
+	"github.com/ghodss/yaml"
+	"github.com/pelletier/go-toml"
+)
+
+// Change types of a plan
+const (
+	APPLY_ADD    = "+"
+	APPLY_CHANGE = "~"
+	APPLY_REMOVE = "-"
+)
+
+// ApplyChange is a change of a plan, with the worker request that does it
+type ApplyChange struct {
+	Type        string
+	Description string
+
+	do func(c *client.Client) error
+}
+
+// ApplyPlan has the changes needed to converge a worker to definition files, in the order to apply them
+type ApplyPlan struct {
+	Changes []ApplyChange
+}
+
+// This aux method reads definition files of dir and compares them with worker. Organizations of these files
+// are managed: their groups and policies not defined are removed, as well as members and policies attached to
+// groups not defined. Users are created or updated, but never removed, and only policies attached to defined
+// users are compared, because worker API doesn't list users attached to a policy.
+func planApply(c *client.Client, dir string) (*ApplyPlan, error) {
+	desired, err := readApplyDefinitions(dir)
+	if err != nil {
+		return nil, err
+	}
+	current, err := getApplyState(c, desired)
+	if err != nil {
+		return nil, err
+	}
+	return planApplyChanges(desired, current)
+}
+
+// This aux method does the changes of plan in order, writing each one to out. Changes are independent
+// requests, so if one fails, previous ones are kept and the plan can be computed and applied again.
+func applyPlan(c *client.Client, plan *ApplyPlan, out io.Writer) error {
+	for i, change := range plan.Changes {
+		if err := change.do(c); err != nil {
+			return fmt.Errorf("Cannot apply change %v %v, %v of %v changes applied, error: %v",
+				change.Type, change.Description, i, len(plan.Changes), err)
+		}
+		fmt.Fprintf(out, "%v %v\n", change.Type, change.Description)
+	}
+	return nil
+}
+
+// This aux method writes changes of plan and their counts to out
+func printApplyPlan(plan *ApplyPlan, out io.Writer) {
+	if len(plan.Changes) == 0 {
+		fmt.Fprintln(out, "No changes, worker matches definitions")
+		return
+	}
+	counts := map[string]int{}
+	for _, change := range plan.Changes {
+		fmt.Fprintf(out, "%v %v\n", change.Type, change.Description)
+		counts[change.Type]++
+	}
+	fmt.Fprintf(out, "Plan: %v to add, %v to change, %v to remove\n",
+		counts[APPLY_ADD], counts[APPLY_CHANGE], counts[APPLY_REMOVE])
+}
+
+// DEFINITIONS
+
+// Content of a definition file: groups and policies of an organization, and users with policies of it attached
+type applyDefinition struct {
+	Org      string        `json:"org"`
+	Users    []applyUser   `json:"users"`
+	Groups   []applyGroup  `json:"groups"`
+	Policies []applyPolicy `json:"policies"`
+}
+
+type applyUser struct {
+	ExternalID string   `json:"externalId"`
+	Path       string   `json:"path"`
+	Policies   []string `json:"policies"`
+}
+
+type applyGroup struct {
+	Name     string   `json:"name"`
+	Path     string   `json:"path"`
+	Members  []string `json:"members"`
+	Policies []string `json:"policies"`
+}
+
+type applyPolicy struct {
+	Name       string          `json:"name"`
+	Path       string          `json:"path"`
+	Statements []api.Statement `json:"statements"`
+}
+
+// State of a worker, read from definitions or from worker
+type applyState struct {
+	// Users by external id
+	users map[string]*applyUser
+	// Organizations by name
+	orgs map[string]*applyOrg
+}
+
+type applyOrg struct {
+	groups   map[string]*applyGroup
+	policies map[string]*applyPolicy
+	// Names of policies of organization attached to each user, by external id
+	userPolicies map[string][]string
+}
+
+// This aux method reads all TOML and YAML definition files of dir and its subdirectories
+func readApplyDefinitions(dir string) (*applyState, error) {
+	var files []string
+	err := filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return nil
+		}
+		switch filepath.Ext(path) {
+		case ".toml", ".yaml", ".yml":
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(files) < 1 {
+		return nil, fmt.Errorf("No definition files in %v", dir)
+	}
+	sort.Strings(files)
+
+	state := &applyState{
+		users: map[string]*applyUser{},
+		orgs:  map[string]*applyOrg{},
+	}
+	for _, file := range files {
+		definition, err := readApplyDefinitionFile(file)
+		if err != nil {
+			return nil, err
+		}
+		if err := state.addDefinition(definition); err != nil {
+			return nil, fmt.Errorf("Invalid definition file %v, error: %v", file, err)
+		}
+	}
+	if err := state.checkReferences(); err != nil {
+		return nil, err
+	}
+	return state, nil
+}
+
+// This aux method parses a definition file. TOML tree or YAML document is converted to JSON to decode it with API types
+func readApplyDefinitionFile(file string) (*applyDefinition, error) {
+	var data []byte
+	switch filepath.Ext(file) {
+	case ".yaml", ".yml":
+		content, err := ioutil.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read definition file %v, error: %v", file, err)
+		}
+		data, err = yaml.YAMLToJSON(content)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read definition file %v, error: %v", file, err)
+		}
+	default:
+		tree, err := toml.LoadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read definition file %v, error: %v", file, err)
+		}
+		data, err = json.Marshal(tomlTreeToMap(tree))
+		if err != nil {
+			return nil, fmt.Errorf("Cannot read definition file %v, error: %v", file, err)
+		}
+	}
+	definition := &applyDefinition{}
+	if err := json.Unmarshal(data, definition); err != nil {
+		return nil, fmt.Errorf("Cannot parse definition file %v, error: %v", file, err)
+	}
+	return definition, nil
+}
+
+// This aux method converts a TOML tree to maps and slices, keeping all tables of arrays of tables
+func tomlTreeToMap(tree *toml.TomlTree) map[string]interface{} {
+	result := map[string]interface{}{}
+	for _, key := range tree.Keys() {
+		result[key] = tomlValueToInterface(tree.GetPath([]string{key}))
+	}
+	return result
+}
+
+func tomlValueToInterface(value interface{}) interface{} {
+	switch v := value.(type) {
+	case *toml.TomlTree:
+		return tomlTreeToMap(v)
+	case []*toml.TomlTree:
+		result := make([]interface{}, 0, len(v))
+		for _, tree := range v {
+			result = append(result, tomlTreeToMap(tree))
+		}
+		return result
+	case []interface{}:
+		result := make([]interface{}, 0, len(v))
+		for _, item := range v {
+			result = append(result, tomlValueToInterface(item))
+		}
+		return result
+	default:
+		return v
+	}
+}
+
+// This aux method validates a definition and adds it to state
+func (s *applyState) addDefinition(definition *applyDefinition) error {
+	if !api.IsValidOrg(definition.Org) {
+		return fmt.Errorf("Invalid parameter: org %v", definition.Org)
+	}
+	org := s.getOrg(definition.Org)
+
+	for _, policy := range definition.Policies {
+		if !api.IsValidName(policy.Name) {
+			return fmt.Errorf("Invalid parameter: policy name %v", policy.Name)
+		}
+		if _, ok := org.policies[policy.Name]; ok {
+			return fmt.Errorf("Duplicated policy %v in organization %v", policy.Name, definition.Org)
+		}
+		if !api.IsValidPath(policy.Path) {
+			return fmt.Errorf("Invalid parameter: policy %v path %v", policy.Name, policy.Path)
+		}
+		if len(policy.Statements) < 1 {
+			return fmt.Errorf("Invalid parameter: policy %v has empty statements", policy.Name)
+		}
+		if err := api.AreValidStatements(&policy.Statements); err != nil {
+			return fmt.Errorf("Policy %v: %v", policy.Name, err.(*api.Error).Message)
+		}
+		p := policy
+		org.policies[policy.Name] = &p
+	}
+
+	for _, group := range definition.Groups {
+		if !api.IsValidName(group.Name) {
+			return fmt.Errorf("Invalid parameter: group name %v", group.Name)
+		}
+		if _, ok := org.groups[group.Name]; ok {
+			return fmt.Errorf("Duplicated group %v in organization %v", group.Name, definition.Org)
+		}
+		if !api.IsValidPath(group.Path) {
+			return fmt.Errorf("Invalid parameter: group %v path %v", group.Name, group.Path)
+		}
+		for _, member := range group.Members {
+			if !api.IsValidUserExternalID(member) {
+				return fmt.Errorf("Invalid parameter: group %v member %v", group.Name, member)
+			}
+		}
+		g := group
+		org.groups[group.Name] = &g
+	}
+
+	for _, user := range definition.Users {
+		if !api.IsValidUserExternalID(user.ExternalID) {
+			return fmt.Errorf("Invalid parameter: user externalId %v", user.ExternalID)
+		}
+		if !api.IsValidPath(user.Path) {
+			return fmt.Errorf("Invalid parameter: user %v path %v", user.ExternalID, user.Path)
+		}
+		if u, ok := s.users[user.ExternalID]; ok && u.Path != user.Path {
+			return fmt.Errorf("User %v is defined with different paths %v and %v", user.ExternalID, u.Path, user.Path)
+		}
+		if _, ok := org.userPolicies[user.ExternalID]; ok {
+			return fmt.Errorf("Duplicated user %v in organization %v", user.ExternalID, definition.Org)
+		}
+		s.users[user.ExternalID] = &applyUser{ExternalID: user.ExternalID, Path: user.Path}
+		org.userPolicies[user.ExternalID] = user.Policies
+	}
+
+	return nil
+}
+
+// This aux method checks that attached policies are defined, once all files are read
+func (s *applyState) checkReferences() error {
+	for orgName, org := range s.orgs {
+		for _, group := range org.groups {
+			for _, policy := range group.Policies {
+				if _, ok := org.policies[policy]; !ok {
+					return fmt.Errorf("Policy %v attached to group %v isn't defined in organization %v", policy, group.Name, orgName)
+				}
+			}
+		}
+		for user, policies := range org.userPolicies {
+			for _, policy := range policies {
+				if _, ok := org.policies[policy]; !ok {
+					return fmt.Errorf("Policy %v attached to user %v isn't defined in organization %v", policy, user, orgName)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (s *applyState) getOrg(name string) *applyOrg {
+	org, ok := s.orgs[name]
+	if !ok {
+		org = &applyOrg{
+			groups:       map[string]*applyGroup{},
+			policies:     map[string]*applyPolicy{},
+			userPolicies: map[string][]string{},
+		}
+		s.orgs[name] = org
+	}
+	return org
+}
+
+// WORKER STATE
+
+// This aux method reads from worker the organizations of desired state, its users and group members
+func getApplyState(c *client.Client, desired *applyState) (*applyState, error) {
+	current := &applyState{
+		users: map[string]*applyUser{},
+		orgs:  map[string]*applyOrg{},
+	}
+
+	for _, orgName := range sortedKeys(desired.orgs) {
+		org := current.getOrg(orgName)
+
+		groups, err := readNames(c.Groups(orgName, ""))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range groups {
+			group, err := c.GetGroup(orgName, name)
+			if err != nil {
+				return nil, err
+			}
+			members, err := readNames(c.Members(orgName, name))
+			if err != nil {
+				return nil, err
+			}
+			policies, err := readNames(c.AttachedGroupPolicies(orgName, name))
+			if err != nil {
+				return nil, err
+			}
+			org.groups[name] = &applyGroup{
+				Name:     name,
+				Path:     group.Path,
+				Members:  members,
+				Policies: policies,
+			}
+		}
+
+		policies, err := readNames(c.Policies(orgName, ""))
+		if err != nil {
+			return nil, err
+		}
+		for _, name := range policies {
+			policy, err := c.GetPolicy(orgName, name)
+			if err != nil {
+				return nil, err
+			}
+			statements := []api.Statement{}
+			if policy.Statements != nil {
+				statements = *policy.Statements
+			}
+			org.policies[name] = &applyPolicy{
+				Name:       name,
+				Path:       policy.Path,
+				Statements: statements,
+			}
+		}
+	}
+
+	// Users that are defined or members of defined groups
+	externalIDs := map[string]bool{}
+	for externalID := range desired.users {
+		externalIDs[externalID] = true
+	}
+	for _, org := range desired.orgs {
+		for _, group := range org.groups {
+			for _, member := range group.Members {
+				externalIDs[member] = true
+			}
+		}
+	}
+	for _, externalID := range sortedKeys(externalIDs) {
+		user, err := c.GetUser(externalID)
+		if err != nil {
+			if client.IsNotFound(err) {
+				continue
+			}
+			return nil, err
+		}
+		current.users[externalID] = &applyUser{ExternalID: externalID, Path: user.Path}
+
+		if _, ok := desired.users[externalID]; !ok {
+			continue
+		}
+		it := c.AttachedUserPolicies(externalID)
+		for it.Next() {
+			policy := it.Policy()
+			if org, ok := current.orgs[policy.Org]; ok {
+				org.userPolicies[externalID] = append(org.userPolicies[externalID], policy.Name)
+			}
+		}
+		if it.Err() != nil {
+			return nil, it.Err()
+		}
+	}
+
+	return current, nil
+}
+
+// This aux method reads all names of an iterator
+func readNames(it *client.NameIterator) ([]string, error) {
+	names := []string{}
+	for it.Next() {
+		names = append(names, it.Name())
+	}
+	if it.Err() != nil {
+		return nil, it.Err()
+	}
+	return names, nil
+}
+
+// PLAN
+
+// This aux method computes changes from current to desired state. Creations and updates go first, so
+// removals don't leave users without permissions they keep in desired state.
+func planApplyChanges(desired *applyState, current *applyState) (*ApplyPlan, error) {
+	var adds, removals []ApplyChange
+
+	// Users
+	for _, externalID := range sortedKeys(desired.users) {
+		user := desired.users[externalID]
+		currentUser, ok := current.users[externalID]
+		switch {
+		case !ok:
+			adds = append(adds, ApplyChange{
+				Type:        APPLY_ADD,
+				Description: fmt.Sprintf("create user %v with path %v", externalID, user.Path),
+				do:          addUser(externalID, user.Path),
+			})
+		case currentUser.Path != user.Path:
+			adds = append(adds, ApplyChange{
+				Type:        APPLY_CHANGE,
+				Description: fmt.Sprintf("update user %v path %v to %v", externalID, currentUser.Path, user.Path),
+				do:          updateUser(externalID, user.Path),
+			})
+		}
+	}
+
+	// Group members must be defined or exist
+	for _, orgName := range sortedKeys(desired.orgs) {
+		for _, group := range desired.orgs[orgName].groups {
+			for _, member := range group.Members {
+				_, defined := desired.users[member]
+				_, exists := current.users[member]
+				if !defined && !exists {
+					return nil, fmt.Errorf("Member %v of group %v in organization %v isn't defined and doesn't exist in worker", member, group.Name, orgName)
+				}
+			}
+		}
+	}
+
+	for _, orgName := range sortedKeys(desired.orgs) {
+		org := desired.orgs[orgName]
+		currentOrg := current.orgs[orgName]
+
+		// Policies
+		for _, name := range sortedKeys(org.policies) {
+			policy := org.policies[name]
+			currentPolicy, ok := currentOrg.policies[name]
+			switch {
+			case !ok:
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_ADD,
+					Description: fmt.Sprintf("create policy %v in organization %v", name, orgName),
+					do:          addPolicy(orgName, policy),
+				})
+			case currentPolicy.Path != policy.Path || !isSameApplyStatements(currentPolicy.Statements, policy.Statements):
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_CHANGE,
+					Description: fmt.Sprintf("update policy %v in organization %v", name, orgName),
+					do:          updatePolicy(orgName, policy),
+				})
+			}
+		}
+		for _, name := range sortedKeys(currentOrg.policies) {
+			if _, ok := org.policies[name]; !ok {
+				removals = append(removals, ApplyChange{
+					Type:        APPLY_REMOVE,
+					Description: fmt.Sprintf("delete policy %v in organization %v", name, orgName),
+					do:          removePolicy(orgName, name),
+				})
+			}
+		}
+
+		// Groups with their members and policies
+		var groupRemovals []ApplyChange
+		for _, name := range sortedKeys(org.groups) {
+			group := org.groups[name]
+			currentGroup, ok := currentOrg.groups[name]
+			switch {
+			case !ok:
+				currentGroup = &applyGroup{}
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_ADD,
+					Description: fmt.Sprintf("create group %v in organization %v", name, orgName),
+					do:          addGroup(orgName, group),
+				})
+			case currentGroup.Path != group.Path:
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_CHANGE,
+					Description: fmt.Sprintf("update group %v in organization %v path %v to %v", name, orgName, currentGroup.Path, group.Path),
+					do:          updateGroup(orgName, group),
+				})
+			}
+
+			added, removed := diffNames(currentGroup.Members, group.Members)
+			for _, member := range added {
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_ADD,
+					Description: fmt.Sprintf("add member %v to group %v in organization %v", member, name, orgName),
+					do:          addMember(orgName, name, member),
+				})
+			}
+			for _, member := range removed {
+				groupRemovals = append(groupRemovals, ApplyChange{
+					Type:        APPLY_REMOVE,
+					Description: fmt.Sprintf("remove member %v from group %v in organization %v", member, name, orgName),
+					do:          removeMember(orgName, name, member),
+				})
+			}
+
+			added, removed = diffNames(currentGroup.Policies, group.Policies)
+			for _, policy := range added {
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_ADD,
+					Description: fmt.Sprintf("attach policy %v to group %v in organization %v", policy, name, orgName),
+					do:          attachPolicyToGroup(orgName, name, policy),
+				})
+			}
+			for _, policy := range removed {
+				// Relations of removed policies are removed with them
+				if _, ok := org.policies[policy]; !ok {
+					continue
+				}
+				groupRemovals = append(groupRemovals, ApplyChange{
+					Type:        APPLY_REMOVE,
+					Description: fmt.Sprintf("detach policy %v from group %v in organization %v", policy, name, orgName),
+					do:          detachPolicyToGroup(orgName, name, policy),
+				})
+			}
+		}
+		for _, name := range sortedKeys(currentOrg.groups) {
+			if _, ok := org.groups[name]; !ok {
+				groupRemovals = append(groupRemovals, ApplyChange{
+					Type:        APPLY_REMOVE,
+					Description: fmt.Sprintf("delete group %v in organization %v", name, orgName),
+					do:          removeGroup(orgName, name),
+				})
+			}
+		}
+
+		// Policies attached to defined users. Attachments to other users are kept.
+		for _, externalID := range sortedKeys(org.userPolicies) {
+			added, removed := diffNames(currentOrg.userPolicies[externalID], org.userPolicies[externalID])
+			for _, policy := range added {
+				adds = append(adds, ApplyChange{
+					Type:        APPLY_ADD,
+					Description: fmt.Sprintf("attach policy %v in organization %v to user %v", policy, orgName, externalID),
+					do:          attachPolicyToUser(externalID, orgName, policy),
+				})
+			}
+			for _, policy := range removed {
+				if _, ok := org.policies[policy]; !ok {
+					continue
+				}
+				groupRemovals = append(groupRemovals, ApplyChange{
+					Type:        APPLY_REMOVE,
+					Description: fmt.Sprintf("detach policy %v in organization %v from user %v", policy, orgName, externalID),
+					do:          detachPolicyFromUser(externalID, orgName, policy),
+				})
+			}
+		}
+
+		// Relations and groups are removed before policies
+		removals = append(groupRemovals, removals...)
+	}
+
+	return &ApplyPlan{Changes: append(adds, removals...)}, nil
+}
+
+// CHANGES
+
+// These aux methods return the worker request of a change, with their own copy of arguments
+
+func addUser(externalID string, path string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		_, err := c.AddUser(externalID, path)
+		return err
+	}
+}
+
+func updateUser(externalID string, path string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		_, err := c.UpdateUser(externalID, path)
+		return err
+	}
+}
+
+func addPolicy(org string, policy *applyPolicy) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		_, err := c.AddPolicy(org, policy.Name, policy.Path, policy.Statements)
+		return err
+	}
+}
+
+func updatePolicy(org string, policy *applyPolicy) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		_, err := c.UpdatePolicy(org, policy.Name, policy.Name, policy.Path, policy.Statements)
+		return err
+	}
+}
+
+func removePolicy(org string, name string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.RemovePolicy(org, name)
+	}
+}
+
+func addGroup(org string, group *applyGroup) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		_, err := c.AddGroup(org, group.Name, group.Path)
+		return err
+	}
+}
+
+func updateGroup(org string, group *applyGroup) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		_, err := c.UpdateGroup(org, group.Name, group.Name, group.Path)
+		return err
+	}
+}
+
+func removeGroup(org string, name string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.RemoveGroup(org, name)
+	}
+}
+
+func addMember(org string, name string, externalID string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.AddMember(org, name, externalID)
+	}
+}
+
+func removeMember(org string, name string, externalID string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.RemoveMember(org, name, externalID)
+	}
+}
+
+func attachPolicyToGroup(org string, name string, policy string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.AttachPolicyToGroup(org, name, policy)
+	}
+}
+
+func detachPolicyToGroup(org string, name string, policy string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.DetachPolicyToGroup(org, name, policy)
+	}
+}
+
+func attachPolicyToUser(externalID string, org string, policy string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.AttachPolicyToUser(externalID, org, policy)
+	}
+}
+
+func detachPolicyFromUser(externalID string, org string, policy string) func(c *client.Client) error {
+	return func(c *client.Client) error {
+		return c.DetachPolicyFromUser(externalID, org, policy)
+	}
+}
+
+// This aux method returns names of desired not in current, and names of current not in desired
+func diffNames(current []string, desired []string) ([]string, []string) {
+	currentNames := map[string]bool{}
+	for _, name := range current {
+		currentNames[name] = true
+	}
+	desiredNames := map[string]bool{}
+	for _, name := range desired {
+		desiredNames[name] = true
+	}
+
+	var added, removed []string
+	for _, name := range sortedKeys(desiredNames) {
+		if !currentNames[name] {
+			added = append(added, name)
+		}
+	}
+	for _, name := range sortedKeys(currentNames) {
+		if !desiredNames[name] {
+			removed = append(removed, name)
+		}
+	}
+	return added, removed
+}
+
+func isSameApplyStatements(a []api.Statement, b []api.Statement) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i].String() != b[i].String() {
+			return false
+		}
+	}
+	return true
+}
+
+// This aux method returns sorted keys of a map with string keys
+func sortedKeys(m interface{}) []string {
+	keys := []string{}
+	for _, key := range reflect.ValueOf(m).MapKeys() {
+		keys = append(keys, key.String())
+	}
+	sort.Strings(keys)
+	return keys
+}
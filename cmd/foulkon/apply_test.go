@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/auth"
+	"github.com/Tecsisa/foulkon/client"
+	"github.com/Tecsisa/foulkon/database/memory"
+	"github.com/Tecsisa/foulkon/foulkon"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestReadApplyDefinitions(t *testing.T) {
+	tomlDefinition := `
+org = "tecsisa"
+
+[[users]]
+externalId = "user1"
+path = "/example/"
+policies = ["policy1"]
+
+[[groups]]
+name = "group1"
+path = "/example/"
+members = ["user1"]
+policies = ["policy1"]
+
+[[policies]]
+name = "policy1"
+path = "/example/"
+
+  [[policies.statements]]
+  effect = "allow"
+  actions = ["iam:GetUser"]
+  resources = ["urn:iws:iam::user/example/*"]
+`
+	yamlDefinition := `
+org: tecsisa
+users:
+- externalId: user1
+  path: /example/
+  policies: [policy1]
+groups:
+- name: group1
+  path: /example/
+  members: [user1]
+  policies: [policy1]
+policies:
+- name: policy1
+  path: /example/
+  statements:
+  - effect: allow
+    actions: ["iam:GetUser"]
+    resources: ["urn:iws:iam::user/example/*"]
+`
+	testcases := map[string]struct {
+		files       map[string]string
+		expectedErr bool
+	}{
+		"OkCaseTOML": {
+			files: map[string]string{"tecsisa.toml": tomlDefinition},
+		},
+		"OkCaseYAML": {
+			files: map[string]string{"tecsisa.yaml": yamlDefinition},
+		},
+		"OkCaseYML": {
+			files: map[string]string{"tecsisa.yml": yamlDefinition},
+		},
+		"ErrorCaseInvalidYAML": {
+			files:       map[string]string{"tecsisa.yaml": "org: [tecsisa"},
+			expectedErr: true,
+		},
+		"ErrorCaseDuplicatedInTOMLAndYAML": {
+			files: map[string]string{
+				"tecsisa.toml": tomlDefinition,
+				"tecsisa.yaml": yamlDefinition,
+			},
+			expectedErr: true,
+		},
+	}
+
+	var expectedState *applyState
+	for _, n := range []string{"OkCaseTOML", "OkCaseYAML", "OkCaseYML", "ErrorCaseInvalidYAML", "ErrorCaseDuplicatedInTOMLAndYAML"} {
+		test := testcases[n]
+		dir, err := ioutil.TempDir("", "foulkon-apply")
+		if err != nil {
+			t.Fatalf("Test case %v. Unexpected error creating dir %v", n, err)
+		}
+		for name, content := range test.files {
+			if err := ioutil.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+				t.Fatalf("Test case %v. Unexpected error writing file %v", n, err)
+			}
+		}
+		state, err := readApplyDefinitions(dir)
+		os.RemoveAll(dir)
+		if test.expectedErr != (err != nil) {
+			t.Errorf("Test case %v. Unexpected error %v", n, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		// Every format has the same state than TOML
+		if expectedState == nil {
+			expectedState = state
+			continue
+		}
+		if diff := pretty.Compare(state, expectedState); diff != "" {
+			t.Errorf("Test case %v. Received different state (received/wanted) %v", n, diff)
+		}
+	}
+}
+
+func TestPlanAndApply(t *testing.T) {
+	logger := &log.Logger{
+		Out:       bytes.NewBuffer([]byte{}),
+		Formatter: &log.TextFormatter{},
+		Hooks:     make(log.LevelHooks),
+		Level:     log.DebugLevel,
+	}
+	repo := memory.NewMemoryRepo()
+	authApi := api.AuthAPI{
+		UserRepo:   repo,
+		GroupRepo:  repo,
+		PolicyRepo: repo,
+		AuthzRepo:  repo,
+		AuditRepo:  repo,
+		ImportRepo: repo,
+		APIKeyRepo: repo,
+		Logger:     logger,
+	}
+	worker := &foulkon.Worker{
+		Logger:        logger,
+		Authenticator: auth.NewAuthenticator(nil, "admin", "admin"),
+		UserApi:       authApi,
+		GroupApi:      authApi,
+		PolicyApi:     authApi,
+		AuthzApi:      authApi,
+		AuditApi:      authApi,
+		ImportApi:     authApi,
+		APIKeyApi:     authApi,
+	}
+	server := httptest.NewServer(internalhttp.WorkerHandlerRouter(worker))
+	defer server.Close()
+	c := client.NewClient(server.URL, client.NewBasicCredentials("admin", "admin"), nil)
+
+	definition := `
+org: tecsisa
+users:
+- externalId: user1
+  path: /example/
+  policies: [policy1]
+groups:
+- name: group1
+  path: /example/
+  members: [user1]
+  policies: [policy1]
+policies:
+- name: policy1
+  path: /example/
+  statements:
+  - effect: allow
+    actions: ["iam:GetUser"]
+    resources: ["urn:iws:iam::user/example/*"]
+`
+	changedDefinition := `
+org: tecsisa
+users:
+- externalId: user1
+  path: /example/
+policies:
+- name: policy1
+  path: /changed/
+  statements:
+  - effect: allow
+    actions: ["iam:GetUser"]
+    resources: ["urn:iws:iam::user/example/*"]
+`
+	testcases := map[string]struct {
+		definition      string
+		expectedChanges []string
+	}{
+		"OkCaseCreate": {
+			definition: definition,
+			expectedChanges: []string{
+				"+ create user user1 with path /example/",
+				"+ create policy policy1 in organization tecsisa",
+				"+ create group group1 in organization tecsisa",
+				"+ add member user1 to group group1 in organization tecsisa",
+				"+ attach policy policy1 to group group1 in organization tecsisa",
+				"+ attach policy policy1 in organization tecsisa to user user1",
+			},
+		},
+		"OkCaseNoChanges": {
+			definition: definition,
+		},
+		"OkCaseChangeAndRemove": {
+			definition: changedDefinition,
+			expectedChanges: []string{
+				"~ update policy policy1 in organization tecsisa",
+				"- delete group group1 in organization tecsisa",
+				"- detach policy policy1 in organization tecsisa from user user1",
+			},
+		},
+	}
+
+	// Each case is applied on the worker state left by the previous one
+	for _, n := range []string{"OkCaseCreate", "OkCaseNoChanges", "OkCaseChangeAndRemove"} {
+		test := testcases[n]
+		dir, err := ioutil.TempDir("", "foulkon-apply")
+		if err != nil {
+			t.Fatalf("Test case %v. Unexpected error creating dir %v", n, err)
+		}
+		if err := ioutil.WriteFile(filepath.Join(dir, "tecsisa.yaml"), []byte(test.definition), 0644); err != nil {
+			t.Fatalf("Test case %v. Unexpected error writing file %v", n, err)
+		}
+		plan, err := planApply(c, dir)
+		os.RemoveAll(dir)
+		if err != nil {
+			t.Fatalf("Test case %v. Unexpected error planning %v", n, err)
+		}
+		changes := []string{}
+		for _, change := range plan.Changes {
+			changes = append(changes, change.Type+" "+change.Description)
+		}
+		if test.expectedChanges == nil {
+			test.expectedChanges = []string{}
+		}
+		if diff := pretty.Compare(changes, test.expectedChanges); diff != "" {
+			t.Fatalf("Test case %v. Received different changes (received/wanted) %v", n, diff)
+		}
+		if err := applyPlan(c, plan, bytes.NewBuffer([]byte{})); err != nil {
+			t.Fatalf("Test case %v. Unexpected error applying %v", n, err)
+		}
+	}
+}
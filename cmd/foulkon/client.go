@@ -0,0 +1,32 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Tecsisa/foulkon/client"
+	"github.com/Tecsisa/foulkon/foulkon"
+	"github.com/pelletier/go-toml"
+	"github.com/satori/go.uuid"
+)
+
+// Timeout of requests to worker, imports of big documents can take long
+const WORKER_TIMEOUT = 5 * time.Minute
+
+// This aux method returns a client for the worker of the config file with admin credentials. If workerURL
+// is empty, it's created from server host and port. All requests of a command have the same request id,
+// so worker logs and audit events of the command can be found together.
+func newWorkerClient(configFile string, workerURL string) (*client.Client, error) {
+	// Access to file
+	config, err := toml.LoadFile(configFile)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read configuration file %v, error: %v", configFile, err)
+	}
+	workerURL, username, password, err := foulkon.GetWorkerAdminAccess(config, workerURL)
+	if err != nil {
+		return nil, err
+	}
+	c := client.NewClient(workerURL, client.NewBasicCredentials(username, password), &http.Client{Timeout: WORKER_TIMEOUT})
+	return c.WithRequestID(uuid.NewV4().String()), nil
+}
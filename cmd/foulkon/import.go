@@ -0,0 +1,116 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/client"
+	"github.com/ghodss/yaml"
+)
+
+// Formats of export and import documents
+const (
+	IMPORT_FORMAT_JSON = "json"
+	IMPORT_FORMAT_YAML = "yaml"
+)
+
+// This aux method writes the document of the organization, or of all organizations if org is empty, to out in format
+func exportOrg(c *client.Client, org string, format string, out io.Writer) error {
+	if err := checkImportFormat(format); err != nil {
+		return err
+	}
+	document, err := c.Export(org)
+	if err != nil {
+		return err
+	}
+	data, err := encodeExportDocument(document, format)
+	if err != nil {
+		return err
+	}
+	_, err = out.Write(data)
+	return err
+}
+
+// This aux method applies the document in file, written in format, to the organization, or to all organizations if org is empty
+func importFile(c *client.Client, org string, file string, format string, prune bool) (*api.ImportResult, error) {
+	if err := checkImportFormat(format); err != nil {
+		return nil, err
+	}
+	document, err := readImportFile(file, format)
+	if err != nil {
+		return nil, err
+	}
+	return c.Import(org, document, prune)
+}
+
+// This aux method writes counts and conflicts of an import to out
+func printImportResult(result *api.ImportResult, out io.Writer) {
+	for _, c := range []struct {
+		name   string
+		counts api.ImportCounts
+	}{
+		{"users", result.Users},
+		{"groups", result.Groups},
+		{"policies", result.Policies},
+		{"members", result.Members},
+		{"group policies", result.GroupPolicies},
+		{"user policies", result.UserPolicies},
+	} {
+		fmt.Fprintf(out, "%v: created %v, updated %v, unchanged %v, removed %v, conflicts %v\n", c.name,
+			c.counts.Created, c.counts.Updated, c.counts.Unchanged, c.counts.Removed, c.counts.Conflicts)
+	}
+	for _, conflict := range result.Conflicts {
+		fmt.Fprintf(out, "conflict: %v\n", conflict)
+	}
+}
+
+// PRIVATE HELPER METHODS
+
+func checkImportFormat(format string) error {
+	if format != IMPORT_FORMAT_JSON && format != IMPORT_FORMAT_YAML {
+		return fmt.Errorf("Unknown format %v, expected %v or %v", format, IMPORT_FORMAT_JSON, IMPORT_FORMAT_YAML)
+	}
+	return nil
+}
+
+// This aux method writes a document in format. YAML document is converted from JSON, so it has the same keys.
+func encodeExportDocument(document *api.ExportDocument, format string) ([]byte, error) {
+	data, err := json.MarshalIndent(document, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	if format == IMPORT_FORMAT_YAML {
+		return yaml.JSONToYAML(data)
+	}
+	return append(data, '\n'), nil
+}
+
+// This aux method reads a document file in format, or standard input if file is -. YAML document is converted
+// to JSON, so it has the same keys.
+func readImportFile(file string, format string) (*api.ExportDocument, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if format == IMPORT_FORMAT_YAML {
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("Cannot parse import file %v, error: %v", file, err)
+		}
+	}
+
+	document := &api.ExportDocument{}
+	if err := json.Unmarshal(data, document); err != nil {
+		return nil, fmt.Errorf("Cannot parse import file %v, error: %v", file, err)
+	}
+	return document, nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestImportDocumentRoundTrip(t *testing.T) {
+	document := &api.ExportDocument{
+		Org: "tecsisa",
+		Users: []api.ExportUser{
+			{
+				ExternalID: "user1",
+				Path:       "/example/",
+				Policies: []api.PolicyIdentity{
+					{
+						Org:  "tecsisa",
+						Name: "policy1",
+					},
+				},
+			},
+		},
+		Groups: []api.ExportGroup{
+			{
+				Org:      "tecsisa",
+				Name:     "group1",
+				Path:     "/example/",
+				Members:  []string{"user1"},
+				Policies: []string{"policy1"},
+			},
+		},
+		Policies: []api.ExportPolicy{
+			{
+				Org:  "tecsisa",
+				Name: "policy1",
+				Path: "/example/",
+				Statements: []api.Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{"iam:GetUser", "example:*"},
+						Resources: []string{"urn:iws:iam::user/example/*"},
+						Conditions: []api.Condition{
+							{
+								Operator: api.CONDITION_OPERATOR_STRING_EQUALS,
+								Key:      "Foulkon-Context-Team",
+								// Values that YAML would read as other types if they weren't quoted
+								Values: []string{"yes", "true", "123", "null", "", "2016-01-02T15:04:05Z"},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	testcases := map[string]struct {
+		format string
+	}{
+		"OkCaseJSON": {
+			format: IMPORT_FORMAT_JSON,
+		},
+		"OkCaseYAML": {
+			format: IMPORT_FORMAT_YAML,
+		},
+	}
+
+	for n, test := range testcases {
+		data, err := encodeExportDocument(document, test.format)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error encoding document %v", n, err)
+			continue
+		}
+		file := filepath.Join(os.TempDir(), "foulkon-export."+test.format)
+		if err := ioutil.WriteFile(file, data, 0644); err != nil {
+			t.Fatalf("Test case %v. Unexpected error writing file %v", n, err)
+		}
+		received, err := readImportFile(file, test.format)
+		os.Remove(file)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error reading document %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(received, document); diff != "" {
+			t.Errorf("Test case %v. Received different document (received/wanted) %v", n, diff)
+		}
+	}
+}
+
+func TestReadImportFile(t *testing.T) {
+	testcases := map[string]struct {
+		content     string
+		format      string
+		expectedErr bool
+	}{
+		"OkCaseJSON": {
+			content: `{"org":"tecsisa","users":[{"externalId":"user1","path":"/example/"}]}`,
+			format:  IMPORT_FORMAT_JSON,
+		},
+		"OkCaseYAML": {
+			content: "org: tecsisa\nusers:\n- externalId: user1\n  path: /example/\n",
+			format:  IMPORT_FORMAT_YAML,
+		},
+		"OkCaseJSONAsYAML": {
+			content: `{"org":"tecsisa","users":[{"externalId":"user1","path":"/example/"}]}`,
+			format:  IMPORT_FORMAT_YAML,
+		},
+		"ErrorCaseYAMLAsJSON": {
+			content:     "org: tecsisa\n",
+			format:      IMPORT_FORMAT_JSON,
+			expectedErr: true,
+		},
+		"ErrorCaseInvalidYAML": {
+			content:     "org: [tecsisa\n",
+			format:      IMPORT_FORMAT_YAML,
+			expectedErr: true,
+		},
+		"ErrorCaseInvalidDocument": {
+			content:     "users: user1\n",
+			format:      IMPORT_FORMAT_YAML,
+			expectedErr: true,
+		},
+	}
+
+	for n, test := range testcases {
+		file := filepath.Join(os.TempDir(), "foulkon-import")
+		if err := ioutil.WriteFile(file, []byte(test.content), 0644); err != nil {
+			t.Fatalf("Test case %v. Unexpected error writing file %v", n, err)
+		}
+		document, err := readImportFile(file, test.format)
+		os.Remove(file)
+		if test.expectedErr != (err != nil) {
+			t.Errorf("Test case %v. Unexpected error %v", n, err)
+			continue
+		}
+		if err != nil {
+			continue
+		}
+		if document.Org != "tecsisa" || len(document.Users) != 1 || document.Users[0].ExternalID != "user1" {
+			t.Errorf("Test case %v. Received different document %v", n, document)
+		}
+	}
+}
+
+func TestCheckImportFormat(t *testing.T) {
+	for _, format := range []string{IMPORT_FORMAT_JSON, IMPORT_FORMAT_YAML} {
+		if err := checkImportFormat(format); err != nil {
+			t.Errorf("Format %v. Unexpected error %v", format, err)
+		}
+	}
+	if err := checkImportFormat("toml"); err == nil {
+		t.Error("Format toml. Expected error")
+	}
+}
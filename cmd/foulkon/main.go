@@ -0,0 +1,222 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/Tecsisa/foulkon/foulkon"
+	"github.com/pelletier/go-toml"
+)
+
+const usage = `Usage: foulkon <command> [arguments]
+
+Commands:
+  migrate up|down|status -config-file=<worker config file>
+      up      applies all pending database schema migrations
+      down    reverts last applied database schema migration
+      status  shows database schema version and migrations
+  lint -org=<org> [-proxy-config-file=<proxy config file>] <policy files>
+      analyzes policy files, and exits with status 1 if problems are found
+  export -config-file=<worker config file> [-worker-url=<url>] [-org=<org>] [-format=json|yaml]
+      writes users, groups and policies of the organization, or of all organizations, as JSON or YAML
+  import -config-file=<worker config file> [-worker-url=<url>] [-org=<org>] [-format=json|yaml] [-prune] <file>
+      applies a document written by export, or read from standard input if file is -, and exits
+      with status 1 if there are conflicts. With -prune, elements not in the document are removed
+  apply -config-file=<worker config file> [-worker-url=<url>] [-yes] -f <dir>
+      reads TOML and YAML definition files of dir, prints the plan to converge worker to them and
+      applies it on confirmation. Policies attached to users not defined in files are kept
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+
+	switch os.Args[1] {
+	case "migrate":
+		os.Exit(migrate(os.Args[2:]))
+	case "lint":
+		os.Exit(lint(os.Args[2:]))
+	case "export":
+		os.Exit(export(os.Args[2:]))
+	case "import":
+		os.Exit(importDocument(os.Args[2:]))
+	case "apply":
+		os.Exit(apply(os.Args[2:]))
+	default:
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(1)
+	}
+}
+
+func migrate(args []string) int {
+	// Command can be before or after flags
+	command := ""
+	if len(args) > 0 && !strings.HasPrefix(args[0], "-") {
+		command = args[0]
+		args = args[1:]
+	}
+
+	fs := flag.NewFlagSet("migrate", flag.ExitOnError)
+	configFile := fs.String("config-file", "", "Config file for worker")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	if command == "" {
+		command = fs.Arg(0)
+	}
+
+	// Access to file
+	config, err := toml.LoadFile(*configFile)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Cannot read configuration file %v, error: %v\n", *configFile, err)
+		return 1
+	}
+
+	if err := foulkon.Migrate(config, command, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+func lint(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policies")
+	proxyConfigFile := fs.String("proxy-config-file", "", "Config file for proxy, to check actions of its resources")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	// Access to proxy file, if any
+	var proxyConfig *toml.TomlTree
+	if *proxyConfigFile != "" {
+		var err error
+		proxyConfig, err = toml.LoadFile(*proxyConfigFile)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Cannot read configuration file %v, error: %v\n", *proxyConfigFile, err)
+			return 1
+		}
+	}
+
+	findings, err := foulkon.Lint(*org, fs.Args(), proxyConfig, os.Stdout)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	if findings > 0 {
+		return 1
+	}
+	return 0
+}
+
+func export(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	configFile := fs.String("config-file", "", "Config file for worker")
+	workerURL := fs.String("worker-url", "", "URL of worker, by default from server host and port of config file")
+	org := fs.String("org", "", "Organization to export, empty to export all organizations")
+	format := fs.String("format", IMPORT_FORMAT_JSON, "Format of the document, json or yaml")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	c, err := newWorkerClient(*configFile, *workerURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	if err := exportOrg(c, *org, *format, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	return 0
+}
+
+func importDocument(args []string) int {
+	fs := flag.NewFlagSet("import", flag.ExitOnError)
+	configFile := fs.String("config-file", "", "Config file for worker")
+	workerURL := fs.String("worker-url", "", "URL of worker, by default from server host and port of config file")
+	org := fs.String("org", "", "Organization to import, empty to import all organizations")
+	format := fs.String("format", IMPORT_FORMAT_JSON, "Format of the document, json or yaml")
+	prune := fs.Bool("prune", false, "Remove elements that aren't in the document")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	if fs.NArg() != 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 1
+	}
+
+	c, err := newWorkerClient(*configFile, *workerURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	result, err := importFile(c, *org, fs.Arg(0), *format, *prune)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	printImportResult(result, os.Stdout)
+	if len(result.Conflicts) > 0 {
+		return 1
+	}
+	return 0
+}
+
+func apply(args []string) int {
+	fs := flag.NewFlagSet("apply", flag.ExitOnError)
+	configFile := fs.String("config-file", "", "Config file for worker")
+	workerURL := fs.String("worker-url", "", "URL of worker, by default from server host and port of config file")
+	dir := fs.String("f", "", "Directory with definition files")
+	yes := fs.Bool("yes", false, "Apply the plan without confirmation")
+	if err := fs.Parse(args); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	if *dir == "" {
+		fmt.Fprint(os.Stderr, usage)
+		return 1
+	}
+
+	c, err := newWorkerClient(*configFile, *workerURL)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+
+	plan, err := planApply(c, *dir)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	printApplyPlan(plan, os.Stdout)
+	if len(plan.Changes) == 0 {
+		return 0
+	}
+
+	// Ask for confirmation
+	if !*yes {
+		fmt.Print("Apply these changes? Only 'yes' will be accepted: ")
+		answer, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+		if strings.TrimSpace(answer) != "yes" {
+			fmt.Println("Apply cancelled")
+			return 1
+		}
+	}
+
+	if err := applyPlan(c, plan, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	fmt.Printf("Apply complete, %v changes applied\n", len(plan.Changes))
+	return 0
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/client"
+)
+
+// API KEY COMMANDS
+
+func createAPIKey(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("api-key create", flag.ExitOnError)
+	externalID := fs.String("user", "", "External id of user")
+	name := fs.String("name", "", "Name of API key")
+	expireAt := fs.String("expire-at", "", "Expiration date in RFC 3339 format, e.g. 2017-01-02T15:04:05Z")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "user", "name") {
+		return 1
+	}
+	expiration, ok := parseExpireAt(*expireAt)
+	if !ok {
+		return 1
+	}
+
+	apiKey, err := c.CreateAPIKey(*externalID, *name, expiration)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, apiKey, func(w io.Writer) {
+		printAPIKeySecret(w, apiKey)
+	})
+}
+
+func listAPIKeys(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("api-key list", flag.ExitOnError)
+	externalID := fs.String("user", "", "External id of user")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "user") {
+		return 1
+	}
+
+	apiKeys := []*api.APIKey{}
+	it := c.APIKeys(*externalID)
+	for it.Next() {
+		apiKey := it.APIKey()
+		apiKeys = append(apiKeys, &apiKey)
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, apiKeys, func(w io.Writer) {
+		printAPIKeys(w, apiKeys...)
+	})
+}
+
+func getAPIKey(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("api-key get", flag.ExitOnError)
+	externalID := fs.String("user", "", "External id of user")
+	id := fs.String("id", "", "Identifier of API key")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "user", "id") {
+		return 1
+	}
+
+	apiKey, err := c.GetAPIKey(*externalID, *id)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, apiKey, func(w io.Writer) {
+		printAPIKeys(w, apiKey)
+	})
+}
+
+func rotateAPIKey(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("api-key rotate", flag.ExitOnError)
+	externalID := fs.String("user", "", "External id of user")
+	id := fs.String("id", "", "Identifier of API key")
+	expireAt := fs.String("expire-at", "", "New expiration date in RFC 3339 format, current one is kept if empty")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "user", "id") {
+		return 1
+	}
+	expiration, ok := parseExpireAt(*expireAt)
+	if !ok {
+		return 1
+	}
+
+	apiKey, err := c.RotateAPIKey(*externalID, *id, expiration)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, apiKey, func(w io.Writer) {
+		printAPIKeySecret(w, apiKey)
+	})
+}
+
+func revokeAPIKey(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("api-key revoke", flag.ExitOnError)
+	externalID := fs.String("user", "", "External id of user")
+	id := fs.String("id", "", "Identifier of API key")
+	if !parseFlags(fs, args, "user", "id") {
+		return 1
+	}
+
+	if _, err := c.RevokeAPIKey(*externalID, *id); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// This aux method parses an optional expiration date, printing the error if it isn't valid
+func parseExpireAt(value string) (*time.Time, bool) {
+	if value == "" {
+		return nil, true
+	}
+	expireAt, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid expiration date %v, error: %v\n", value, err)
+		return nil, false
+	}
+	return &expireAt, true
+}
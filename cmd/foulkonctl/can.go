@@ -0,0 +1,91 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/Tecsisa/foulkon/client"
+)
+
+// Flag that can be repeated, e.g. -urn=a -urn=b
+type stringsFlag []string
+
+func (s *stringsFlag) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringsFlag) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+// CanResult is the result of checking an action over a resource
+type CanResult struct {
+	Urn     string `json:"urn, omitempty"`
+	Allowed bool   `json:"allowed"`
+}
+
+// AUTHORIZATION COMMANDS
+
+func can(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("can", flag.ExitOnError)
+	externalID := fs.String("user", "", "External id of user, authenticated user if empty")
+	action := fs.String("action", "", "Action to check")
+	urns := &stringsFlag{}
+	fs.Var(urns, "urn", "Urn of resource to check, can be repeated")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "action", "urn") {
+		return 1
+	}
+
+	var allowed []string
+	var err error
+	if *externalID == "" {
+		allowed, err = c.GetAuthorizedExternalResources(*action, *urns)
+	} else {
+		allowed, err = c.GetAuthorizedExternalResourcesForUser(*externalID, *action, *urns)
+	}
+	if err != nil {
+		// Authenticated user without any permission for this action receives a forbidden response
+		if *externalID != "" || !client.IsUnauthorized(err) {
+			return fail(err)
+		}
+		allowed = []string{}
+	}
+
+	isAllowed := map[string]bool{}
+	for _, urn := range allowed {
+		isAllowed[urn] = true
+	}
+	results := []CanResult{}
+	status := 0
+	for _, urn := range *urns {
+		results = append(results, CanResult{Urn: urn, Allowed: isAllowed[urn]})
+		if !isAllowed[urn] {
+			status = 1
+		}
+	}
+
+	if code := printOutput(*output, results, func(w io.Writer) {
+		if len(results) == 1 {
+			fmt.Fprintln(w, yesNo(results[0].Allowed))
+			return
+		}
+		printRow(w, "URN", "ALLOWED")
+		for _, result := range results {
+			printRow(w, result.Urn, yesNo(result.Allowed))
+		}
+	}); code != 0 {
+		return code
+	}
+	return status
+}
+
+func yesNo(allowed bool) string {
+	if allowed {
+		return "yes"
+	}
+	return "no"
+}
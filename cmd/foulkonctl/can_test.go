@@ -0,0 +1,81 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestCan(t *testing.T) {
+	urnA := "urn:ews:product:instance:resource/a"
+	urnB := "urn:ews:product:instance:resource/b"
+	testcases := map[string]struct {
+		args           []string
+		expectedStatus int
+		expectedOutput string
+	}{
+		"OkCaseAllowed": {
+			args:           []string{"-profile=user1", "can", "-action=product:GetResource", "-urn=" + urnA},
+			expectedOutput: "yes\n",
+		},
+		"OkCaseAdmin": {
+			args:           []string{"can", "-action=product:GetResource", "-urn=" + urnB},
+			expectedOutput: "yes\n",
+		},
+		"OkCaseUser": {
+			args:           []string{"can", "-user=user1", "-action=product:GetResource", "-urn=" + urnA},
+			expectedOutput: "yes\n",
+		},
+		"OkCaseNotAllowed": {
+			args:           []string{"-profile=user1", "can", "-action=product:GetResource", "-urn=" + urnB},
+			expectedStatus: 1,
+			expectedOutput: "no\n",
+		},
+		"OkCaseNoPermissions": {
+			args:           []string{"-profile=user1", "can", "-action=product:DeleteResource", "-urn=" + urnA},
+			expectedStatus: 1,
+			expectedOutput: "no\n",
+		},
+		"OkCaseUserNotAllowed": {
+			args:           []string{"can", "-user=user2", "-action=product:GetResource", "-urn=" + urnA},
+			expectedStatus: 1,
+			expectedOutput: "no\n",
+		},
+		"OkCaseSeveralUrns": {
+			args:           []string{"can", "-user=user1", "-action=product:GetResource", "-urn=" + urnA, "-urn=" + urnB},
+			expectedStatus: 1,
+			expectedOutput: fmt.Sprintf("%-37v%v\n%-37v%v\n%-37v%v\n", "URN", "ALLOWED", urnA, "yes", urnB, "no"),
+		},
+		"OkCaseSeveralUrnsAllowed": {
+			args:           []string{"can", "-action=product:GetResource", "-urn=" + urnA, "-urn=" + urnB},
+			expectedOutput: fmt.Sprintf("%-37v%v\n%-37v%v\n%-37v%v\n", "URN", "ALLOWED", urnA, "yes", urnB, "yes"),
+		},
+		"OkCaseJSON": {
+			args:           []string{"-profile=user1", "can", "-action=product:GetResource", "-urn=" + urnA, "-urn=" + urnB, "-o=json"},
+			expectedStatus: 1,
+			expectedOutput: fmt.Sprintf("[\n  {\n    \"urn\": %q,\n    \"allowed\": true\n  },\n  {\n    \"urn\": %q,\n    \"allowed\": false\n  }\n]\n", urnA, urnB),
+		},
+		"ErrorCaseNoAction": {
+			args:           []string{"can", "-urn=" + urnA},
+			expectedStatus: 1,
+		},
+		"ErrorCaseNoUrn": {
+			args:           []string{"can", "-action=product:GetResource"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseUserNotFound": {
+			args:           []string{"can", "-user=unknown", "-action=product:GetResource", "-urn=" + urnA},
+			expectedStatus: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		status, output := runCommand(test.args...)
+		if status != test.expectedStatus {
+			t.Errorf("Test %v failed. Received different exit status (wanted:%v / received:%v)", n, test.expectedStatus, status)
+			continue
+		}
+		if output != test.expectedOutput {
+			t.Errorf("Test %v failed. Received different output (wanted:%q / received:%q)", n, test.expectedOutput, output)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/client"
+)
+
+// GROUP COMMANDS
+
+func listGroups(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group list", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of groups, empty to list groups of all organizations")
+	pathPrefix := fs.String("path-prefix", "", "Path prefix of groups")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args) {
+		return 1
+	}
+
+	groups := []api.GroupIdentity{}
+	if *org == "" {
+		it := c.AllGroups(*pathPrefix)
+		for it.Next() {
+			groups = append(groups, it.Group())
+		}
+		if it.Err() != nil {
+			return fail(it.Err())
+		}
+	} else {
+		it := c.Groups(*org, *pathPrefix)
+		for it.Next() {
+			groups = append(groups, api.GroupIdentity{Org: *org, Name: it.Name()})
+		}
+		if it.Err() != nil {
+			return fail(it.Err())
+		}
+	}
+	return printOutput(*output, groups, func(w io.Writer) {
+		printGroupIdentities(w, groups)
+	})
+}
+
+func getGroup(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group get", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	group, err := c.GetGroup(*org, *name)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, group, func(w io.Writer) {
+		printGroups(w, group)
+	})
+}
+
+func addGroup(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group add", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	path := fs.String("path", "", "Path of group")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name", "path") {
+		return 1
+	}
+
+	group, err := c.AddGroup(*org, *name, *path)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, group, func(w io.Writer) {
+		printGroups(w, group)
+	})
+}
+
+func updateGroup(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group update", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	newName := fs.String("new-name", "", "New name of group, current name if empty")
+	newPath := fs.String("path", "", "New path of group, current path if empty")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	// Keep current values not received
+	if *newName == "" || *newPath == "" {
+		group, err := c.GetGroup(*org, *name)
+		if err != nil {
+			return fail(err)
+		}
+		if *newName == "" {
+			*newName = group.Name
+		}
+		if *newPath == "" {
+			*newPath = group.Path
+		}
+	}
+
+	group, err := c.UpdateGroup(*org, *name, *newName, *newPath)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, group, func(w io.Writer) {
+		printGroups(w, group)
+	})
+}
+
+func removeGroup(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group remove", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	if err := c.RemoveGroup(*org, *name); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func listMembers(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group members", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	members := []string{}
+	it := c.Members(*org, *name)
+	for it.Next() {
+		members = append(members, it.Name())
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, members, func(w io.Writer) {
+		printNames(w, "EXTERNAL ID", members)
+	})
+}
+
+func addMember(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group add-member", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	externalID := fs.String("user", "", "External id of user")
+	if !parseFlags(fs, args, "org", "name", "user") {
+		return 1
+	}
+
+	if err := c.AddMember(*org, *name, *externalID); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func removeMember(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group remove-member", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	externalID := fs.String("user", "", "External id of user")
+	if !parseFlags(fs, args, "org", "name", "user") {
+		return 1
+	}
+
+	if err := c.RemoveMember(*org, *name, *externalID); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func listAttachedGroupPolicies(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group policies", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group")
+	name := fs.String("name", "", "Name of group")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	policies := []string{}
+	it := c.AttachedGroupPolicies(*org, *name)
+	for it.Next() {
+		policies = append(policies, it.Name())
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, policies, func(w io.Writer) {
+		printNames(w, "POLICY", policies)
+	})
+}
+
+func attachPolicyToGroup(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group attach", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group and policy")
+	name := fs.String("name", "", "Name of group")
+	policy := fs.String("policy", "", "Name of policy")
+	if !parseFlags(fs, args, "org", "name", "policy") {
+		return 1
+	}
+
+	if err := c.AttachPolicyToGroup(*org, *name, *policy); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func detachPolicyToGroup(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("group detach", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of group and policy")
+	name := fs.String("name", "", "Name of group")
+	policy := fs.String("policy", "", "Name of policy")
+	if !parseFlags(fs, args, "org", "name", "policy") {
+		return 1
+	}
+
+	if err := c.DetachPolicyToGroup(*org, *name, *policy); err != nil {
+		return fail(err)
+	}
+	return 0
+}
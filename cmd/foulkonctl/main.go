@@ -0,0 +1,169 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Tecsisa/foulkon/client"
+)
+
+const usage = `Usage: foulkonctl [-profile-file=<file>] [-profile=<name>] <command> [arguments]
+
+Commands:
+  user list|get|add|update|remove|groups|policies|attach|detach
+  group list|get|add|update|remove|members|add-member|remove-member|policies|attach|detach
+  policy list|get|add|update|remove|groups
+  api-key create|list|get|rotate|revoke
+  can -action=<action> -urn=<urn> [-urn=<urn>...] [-user=<external id>]
+      checks if the user, or the authenticated user, is allowed to do the action over each
+      resource, and exits with status 1 if any resource isn't allowed
+
+Run 'foulkonctl <command> <subcommand> -h' to see arguments of a subcommand. Commands with output
+accept -o=table|json, table by default.
+`
+
+// command runs a subcommand with the client of profile and its arguments, returning exit status
+type command func(c *client.Client, args []string) int
+
+var commands = map[string]map[string]command{
+	"user": {
+		"list":     listUsers,
+		"get":      getUser,
+		"add":      addUser,
+		"update":   updateUser,
+		"remove":   removeUser,
+		"groups":   listGroupsByUser,
+		"policies": listAttachedUserPolicies,
+		"attach":   attachPolicyToUser,
+		"detach":   detachPolicyFromUser,
+	},
+	"group": {
+		"list":          listGroups,
+		"get":           getGroup,
+		"add":           addGroup,
+		"update":        updateGroup,
+		"remove":        removeGroup,
+		"members":       listMembers,
+		"add-member":    addMember,
+		"remove-member": removeMember,
+		"policies":      listAttachedGroupPolicies,
+		"attach":        attachPolicyToGroup,
+		"detach":        detachPolicyToGroup,
+	},
+	"policy": {
+		"list":   listPolicies,
+		"get":    getPolicy,
+		"add":    addPolicy,
+		"update": updatePolicy,
+		"remove": removePolicy,
+		"groups": listAttachedGroups,
+	},
+	"api-key": {
+		"create": createAPIKey,
+		"list":   listAPIKeys,
+		"get":    getAPIKey,
+		"rotate": rotateAPIKey,
+		"revoke": revokeAPIKey,
+	},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+// This aux method runs the command of arguments, returning exit status
+func run(args []string) int {
+	fs := flag.NewFlagSet("foulkonctl", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprint(os.Stderr, usage)
+	}
+	profileFile := fs.String("profile-file", defaultProfileFile(), "Profile file with worker url and credentials")
+	profileName := fs.String("profile", DEFAULT_PROFILE, "Profile of profile file to use")
+	fs.Parse(args)
+
+	args = fs.Args()
+	if len(args) < 1 {
+		fmt.Fprint(os.Stderr, usage)
+		return 1
+	}
+
+	// Find command
+	var cmd command
+	if args[0] == "can" {
+		cmd = can
+		args = args[1:]
+	} else {
+		subcommands, ok := commands[args[0]]
+		if !ok {
+			fmt.Fprint(os.Stderr, usage)
+			return 1
+		}
+		if len(args) < 2 || subcommands[args[1]] == nil {
+			fmt.Fprintf(os.Stderr, "Usage: foulkonctl %v %v [arguments]\n", args[0], strings.Join(sortedCommands(subcommands), "|"))
+			return 1
+		}
+		cmd = subcommands[args[1]]
+		args = args[2:]
+	}
+
+	profile, err := loadProfile(*profileFile, *profileName)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err.Error())
+		return 1
+	}
+	return cmd(profile.newClient(), args)
+}
+
+// This aux method returns .foulkonctl.toml file in home directory
+func defaultProfileFile() string {
+	home := os.Getenv("HOME")
+	if home == "" {
+		return DEFAULT_PROFILE_FILE
+	}
+	return filepath.Join(home, DEFAULT_PROFILE_FILE)
+}
+
+func sortedCommands(subcommands map[string]command) []string {
+	names := []string{}
+	for name := range subcommands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// This aux method parses the arguments of a subcommand, checking that mandatory flags are not empty
+func parseFlags(fs *flag.FlagSet, args []string, mandatory ...string) bool {
+	if err := fs.Parse(args); err != nil {
+		return false
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(os.Stderr, "Unexpected arguments %v\n", fs.Args())
+		fs.Usage()
+		return false
+	}
+	for _, name := range mandatory {
+		if fs.Lookup(name).Value.String() == "" {
+			fmt.Fprintf(os.Stderr, "Flag -%v is mandatory\n", name)
+			fs.Usage()
+			return false
+		}
+	}
+	if output := fs.Lookup("o"); output != nil {
+		if format := output.Value.String(); format != OUTPUT_TABLE && format != OUTPUT_JSON {
+			fmt.Fprintf(os.Stderr, "Invalid output format %v\n", format)
+			return false
+		}
+	}
+	return true
+}
+
+// This aux method prints the error of a request and returns exit status 1
+func fail(err error) int {
+	fmt.Fprintln(os.Stderr, err.Error())
+	return 1
+}
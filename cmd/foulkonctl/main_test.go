@@ -0,0 +1,261 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/auth"
+	"github.com/Tecsisa/foulkon/client"
+	"github.com/Tecsisa/foulkon/database/memory"
+	"github.com/Tecsisa/foulkon/foulkon"
+	internalhttp "github.com/Tecsisa/foulkon/http"
+)
+
+const (
+	adminUser     = "admin"
+	adminPassword = "admin"
+	userToken     = "user1-token"
+)
+
+// Profile file with profiles of worker, created for tests
+var profileFile string
+
+// Aux connector that authenticates user1 with a bearer token
+type TestConnector struct{}
+
+func (tc TestConnector) Authenticate(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer "+userToken {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		h.ServeHTTP(w, r)
+	})
+}
+
+func (tc TestConnector) RetrieveUserID(r http.Request) string {
+	return "user1"
+}
+
+// Main Test that starts a worker with in-memory database and writes profiles to call it
+func TestMain(m *testing.M) {
+	logger := &log.Logger{
+		Out:       bytes.NewBuffer([]byte{}),
+		Formatter: &log.TextFormatter{},
+		Hooks:     make(log.LevelHooks),
+		Level:     log.DebugLevel,
+	}
+	repo := memory.NewMemoryRepo()
+	authApi := api.AuthAPI{
+		UserRepo:   repo,
+		GroupRepo:  repo,
+		PolicyRepo: repo,
+		AuthzRepo:  repo,
+		AuditRepo:  repo,
+		ImportRepo: repo,
+		APIKeyRepo: repo,
+		Logger:     logger,
+	}
+	worker := &foulkon.Worker{
+		Logger:        logger,
+		Authenticator: auth.NewAuthenticator(TestConnector{}, adminUser, adminPassword),
+		UserApi:       authApi,
+		GroupApi:      authApi,
+		PolicyApi:     authApi,
+		AuthzApi:      authApi,
+		AuditApi:      authApi,
+		ImportApi:     authApi,
+		APIKeyApi:     authApi,
+	}
+	server := httptest.NewServer(internalhttp.WorkerHandlerRouter(worker))
+
+	// user1 can get resource a, user2 has no permissions
+	adminClient := client.NewClient(server.URL, client.NewBasicCredentials(adminUser, adminPassword), nil)
+	if err := addTestData(adminClient); err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected error adding test data: %v\n", err)
+		os.Exit(1)
+	}
+
+	dir, err := ioutil.TempDir("", "foulkonctl")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected error creating profile dir: %v\n", err)
+		os.Exit(1)
+	}
+	profileFile = filepath.Join(dir, "profiles.toml")
+	profiles := fmt.Sprintf(`
+[default]
+worker-url = "%v"
+username = "%v"
+password = "%v"
+
+[user1]
+worker-url = "%v"
+token = "%v"
+
+[unreachable]
+worker-url = "http://127.0.0.1:1"
+username = "%v"
+password = "%v"
+`, server.URL, adminUser, adminPassword, server.URL, userToken, adminUser, adminPassword)
+	if err := ioutil.WriteFile(profileFile, []byte(profiles), 0600); err != nil {
+		fmt.Fprintf(os.Stderr, "Unexpected error writing profile file: %v\n", err)
+		os.Exit(1)
+	}
+
+	result := m.Run()
+	server.Close()
+	os.RemoveAll(dir)
+	os.Exit(result)
+}
+
+func TestRun(t *testing.T) {
+	testcases := map[string]struct {
+		args           []string
+		expectedStatus int
+		expectedOutput string
+	}{
+		"OkCaseCommand": {
+			args:           []string{"user", "get", "-id=user2", "-o=json"},
+			expectedOutput: "\"externalId\": \"user2\"",
+		},
+		"OkCaseProfile": {
+			args:           []string{"-profile=user1", "can", "-action=product:GetResource", "-urn=urn:ews:product:instance:resource/a"},
+			expectedOutput: "yes\n",
+		},
+		"ErrorCaseNoCommand": {
+			args:           []string{},
+			expectedStatus: 1,
+		},
+		"ErrorCaseUnknownCommand": {
+			args:           []string{"unknown", "list"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseNoSubcommand": {
+			args:           []string{"user"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseUnknownSubcommand": {
+			args:           []string{"user", "unknown"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseUnknownProfile": {
+			args:           []string{"-profile=unknown", "user", "list"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseMandatoryFlag": {
+			args:           []string{"user", "get"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseUnexpectedArguments": {
+			args:           []string{"user", "list", "user1"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseInvalidOutput": {
+			args:           []string{"user", "list", "-o=xml"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseNotFound": {
+			args:           []string{"user", "get", "-id=unknown"},
+			expectedStatus: 1,
+		},
+		"ErrorCaseWorkerUnreachable": {
+			args:           []string{"-profile=unreachable", "user", "list"},
+			expectedStatus: 1,
+		},
+	}
+
+	for n, test := range testcases {
+		status, output := runCommand(test.args...)
+		if status != test.expectedStatus {
+			t.Errorf("Test %v failed. Received different exit status (wanted:%v / received:%v)", n, test.expectedStatus, status)
+			continue
+		}
+		if !bytes.Contains([]byte(output), []byte(test.expectedOutput)) {
+			t.Errorf("Test %v failed. Output %q doesn't contain %q", n, output, test.expectedOutput)
+		}
+	}
+}
+
+func TestOutputFormats(t *testing.T) {
+	testcases := map[string]struct {
+		args           []string
+		expectedOutput string
+	}{
+		"OkCaseTable": {
+			args:           []string{"user", "list", "-path-prefix=/example/"},
+			expectedOutput: "EXTERNAL ID\nuser1\nuser2\n",
+		},
+		"OkCaseTableByDefault": {
+			args:           []string{"user", "list", "-path-prefix=/example/", "-o=table"},
+			expectedOutput: "EXTERNAL ID\nuser1\nuser2\n",
+		},
+		"OkCaseJSON": {
+			args:           []string{"user", "list", "-path-prefix=/example/", "-o=json"},
+			expectedOutput: "[\n  \"user1\",\n  \"user2\"\n]\n",
+		},
+		"OkCaseTableColumns": {
+			args: []string{"user", "groups", "-id=user1"},
+			expectedOutput: "ORG      NAME\n" +
+				"example  group1\n",
+		},
+		"OkCaseJSONIdentities": {
+			args:           []string{"user", "groups", "-id=user1", "-o=json"},
+			expectedOutput: "[\n  {\n    \"org\": \"example\",\n    \"name\": \"group1\"\n  }\n]\n",
+		},
+	}
+
+	for n, test := range testcases {
+		status, output := runCommand(test.args...)
+		if status != 0 {
+			t.Errorf("Test %v failed. Unexpected exit status %v", n, status)
+			continue
+		}
+		if output != test.expectedOutput {
+			t.Errorf("Test %v failed. Received different output (wanted:%q / received:%q)", n, test.expectedOutput, output)
+		}
+	}
+}
+
+// This aux method runs foulkonctl with profile file of tests, returning exit status and standard output
+func runCommand(args ...string) (int, string) {
+	out := bytes.NewBuffer([]byte{})
+	stdout = out
+	defer func() {
+		stdout = os.Stdout
+	}()
+	status := run(append([]string{"-profile-file=" + profileFile}, args...))
+	return status, out.String()
+}
+
+// This aux method adds users, and a policy attached to a group of user1
+func addTestData(c *client.Client) error {
+	for _, externalID := range []string{"user1", "user2"} {
+		if _, err := c.AddUser(externalID, "/example/"); err != nil {
+			return err
+		}
+	}
+	if _, err := c.AddGroup("example", "group1", "/example/"); err != nil {
+		return err
+	}
+	if err := c.AddMember("example", "group1", "user1"); err != nil {
+		return err
+	}
+	if _, err := c.AddPolicy("example", "policy1", "/example/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"product:GetResource"},
+			Resources: []string{"urn:ews:product:instance:resource/a"},
+		},
+	}); err != nil {
+		return err
+	}
+	return c.AttachPolicyToGroup("example", "group1", "policy1")
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+)
+
+const (
+	OUTPUT_TABLE = "table"
+	OUTPUT_JSON  = "json"
+)
+
+// Standard output of commands
+var stdout io.Writer = os.Stdout
+
+// This aux method adds output format flag to a subcommand
+func outputFlag(fs *flag.FlagSet) *string {
+	return fs.String("o", OUTPUT_TABLE, "Output format: table or json")
+}
+
+// This aux method writes value as indented JSON, or calls table to write it as a table, to standard output
+func printOutput(format string, value interface{}, table func(w io.Writer)) int {
+	if format == OUTPUT_JSON {
+		data, err := json.MarshalIndent(value, "", "  ")
+		if err != nil {
+			return fail(err)
+		}
+		fmt.Fprintln(stdout, string(data))
+		return 0
+	}
+
+	w := tabwriter.NewWriter(stdout, 0, 8, 2, ' ', 0)
+	table(w)
+	if err := w.Flush(); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+// This aux method writes a row of a table with its columns separated by tabs
+func printRow(w io.Writer, columns ...string) {
+	fmt.Fprintln(w, strings.Join(columns, "\t"))
+}
+
+func formatTime(t time.Time) string {
+	return t.Format("2006-01-02 15:04:05 MST")
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return "-"
+	}
+	return formatTime(*t)
+}
+
+// TABLES
+
+func printUsers(w io.Writer, users ...*api.User) {
+	printRow(w, "EXTERNAL ID", "PATH", "URN", "CREATED")
+	for _, user := range users {
+		printRow(w, user.ExternalID, user.Path, user.Urn, formatTime(user.CreateAt))
+	}
+}
+
+func printGroups(w io.Writer, groups ...*api.Group) {
+	printRow(w, "ORG", "NAME", "PATH", "URN", "CREATED")
+	for _, group := range groups {
+		printRow(w, group.Org, group.Name, group.Path, group.Urn, formatTime(group.CreateAt))
+	}
+}
+
+// Policy is written as a table with the policy and a table with its statements
+func printPolicy(w io.Writer, policy *api.Policy) {
+	printRow(w, "ORG", "NAME", "PATH", "URN", "CREATED")
+	printRow(w, policy.Org, policy.Name, policy.Path, policy.Urn, formatTime(policy.CreateAt))
+	if policy.Statements == nil {
+		return
+	}
+	printRow(w)
+	printRow(w, "EFFECT", "ACTIONS", "RESOURCES", "CONDITIONS")
+	for _, statement := range *policy.Statements {
+		conditions := []string{}
+		for _, condition := range statement.Conditions {
+			conditions = append(conditions, fmt.Sprintf("%v %v %v", condition.Key, condition.Operator, condition.Values))
+		}
+		printRow(w, statement.Effect, strings.Join(statement.Actions, ","), strings.Join(statement.Resources, ","),
+			strings.Join(conditions, ","))
+	}
+}
+
+func printNames(w io.Writer, header string, names []string) {
+	printRow(w, header)
+	for _, name := range names {
+		printRow(w, name)
+	}
+}
+
+func printGroupIdentities(w io.Writer, groups []api.GroupIdentity) {
+	printRow(w, "ORG", "NAME")
+	for _, group := range groups {
+		printRow(w, group.Org, group.Name)
+	}
+}
+
+func printPolicyIdentities(w io.Writer, policies []api.PolicyIdentity) {
+	printRow(w, "ORG", "NAME")
+	for _, policy := range policies {
+		printRow(w, policy.Org, policy.Name)
+	}
+}
+
+func printAPIKeys(w io.Writer, apiKeys ...*api.APIKey) {
+	printRow(w, "ID", "NAME", "CREATED", "ROTATED", "EXPIRES", "REVOKED")
+	for _, apiKey := range apiKeys {
+		printRow(w, apiKey.ID, apiKey.Name, formatTime(apiKey.CreateAt), formatTime(apiKey.RotateAt),
+			formatOptionalTime(apiKey.ExpireAt), formatOptionalTime(apiKey.RevokeAt))
+	}
+}
+
+// Secret is only received when a key is created or rotated, so it is written with the key
+func printAPIKeySecret(w io.Writer, apiKey *api.APIKeySecret) {
+	printRow(w, "ID", "NAME", "EXPIRES", "KEY")
+	printRow(w, apiKey.ID, apiKey.Name, formatOptionalTime(apiKey.ExpireAt), apiKey.Key)
+}
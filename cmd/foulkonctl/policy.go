@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/client"
+)
+
+// POLICY COMMANDS
+
+func listPolicies(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("policy list", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policies, empty to list policies of all organizations")
+	pathPrefix := fs.String("path-prefix", "", "Path prefix of policies")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args) {
+		return 1
+	}
+
+	policies := []api.PolicyIdentity{}
+	if *org == "" {
+		it := c.AllPolicies(*pathPrefix)
+		for it.Next() {
+			policies = append(policies, it.Policy())
+		}
+		if it.Err() != nil {
+			return fail(it.Err())
+		}
+	} else {
+		it := c.Policies(*org, *pathPrefix)
+		for it.Next() {
+			policies = append(policies, api.PolicyIdentity{Org: *org, Name: it.Name()})
+		}
+		if it.Err() != nil {
+			return fail(it.Err())
+		}
+	}
+	return printOutput(*output, policies, func(w io.Writer) {
+		printPolicyIdentities(w, policies)
+	})
+}
+
+func getPolicy(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("policy get", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policy")
+	name := fs.String("name", "", "Name of policy")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	policy, err := c.GetPolicy(*org, *name)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, policy, func(w io.Writer) {
+		printPolicy(w, policy)
+	})
+}
+
+func addPolicy(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("policy add", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policy")
+	file := fs.String("f", "", "JSON policy file with name, path and statements, or - to read standard input")
+	name := fs.String("name", "", "Name of policy, instead of name of policy file")
+	path := fs.String("path", "", "Path of policy, instead of path of policy file")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "f") {
+		return 1
+	}
+
+	policyFile, err := readPolicyFile(*file)
+	if err != nil {
+		return fail(err)
+	}
+	if *name != "" {
+		policyFile.Name = *name
+	}
+	if *path != "" {
+		policyFile.Path = *path
+	}
+
+	policy, err := c.AddPolicy(*org, policyFile.Name, policyFile.Path, *policyFile.Statements)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, policy, func(w io.Writer) {
+		printPolicy(w, policy)
+	})
+}
+
+func updatePolicy(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("policy update", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policy")
+	name := fs.String("name", "", "Name of policy")
+	file := fs.String("f", "", "JSON policy file with new statements, or - to read standard input. Current statements if empty")
+	newName := fs.String("new-name", "", "New name of policy, current name if empty")
+	newPath := fs.String("path", "", "New path of policy, current path if empty")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	// Keep current values not received
+	current, err := c.GetPolicy(*org, *name)
+	if err != nil {
+		return fail(err)
+	}
+	if *newName == "" {
+		*newName = current.Name
+	}
+	if *newPath == "" {
+		*newPath = current.Path
+	}
+	statements := *current.Statements
+	if *file != "" {
+		policyFile, err := readPolicyFile(*file)
+		if err != nil {
+			return fail(err)
+		}
+		statements = *policyFile.Statements
+	}
+
+	policy, err := c.UpdatePolicy(*org, *name, *newName, *newPath, statements)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, policy, func(w io.Writer) {
+		printPolicy(w, policy)
+	})
+}
+
+func removePolicy(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("policy remove", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policy")
+	name := fs.String("name", "", "Name of policy")
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	if err := c.RemovePolicy(*org, *name); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func listAttachedGroups(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("policy groups", flag.ExitOnError)
+	org := fs.String("org", "", "Organization of policy")
+	name := fs.String("name", "", "Name of policy")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "org", "name") {
+		return 1
+	}
+
+	groups := []string{}
+	it := c.AttachedGroups(*org, *name)
+	for it.Next() {
+		groups = append(groups, it.Name())
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, groups, func(w io.Writer) {
+		printNames(w, "GROUP", groups)
+	})
+}
+
+// This aux method reads a policy file, in the same format as files of foulkon lint command
+func readPolicyFile(file string) (*api.Policy, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = ioutil.ReadAll(os.Stdin)
+	} else {
+		data, err = ioutil.ReadFile(file)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &api.Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("Cannot parse policy file %v, error: %v", file, err)
+	}
+	if policy.Statements == nil {
+		return nil, fmt.Errorf("Invalid policy file %v, error: Empty statements", file)
+	}
+	return policy, nil
+}
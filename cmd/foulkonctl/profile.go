@@ -0,0 +1,87 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+
+	"github.com/Tecsisa/foulkon/client"
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	DEFAULT_PROFILE_FILE = ".foulkonctl.toml"
+	DEFAULT_PROFILE      = "default"
+)
+
+// Values like '${SOME_KEY}' are read from OS ENV vars
+var rEnvVar, _ = regexp.Compile(`^\$\{(\w+)\}$`)
+
+// Profile has worker url and credentials: admin username and password, an OIDC ID token or an API key
+type Profile struct {
+	WorkerURL string
+	Username  string
+	Password  string
+	Token     string
+	APIKey    string
+}
+
+// This aux method reads a profile of profile file
+func loadProfile(file string, name string) (*Profile, error) {
+	config, err := toml.LoadFile(file)
+	if err != nil {
+		return nil, fmt.Errorf("Cannot read profile file %v, error: %v", file, err)
+	}
+	if !config.Has(name) {
+		return nil, fmt.Errorf("Cannot find profile %v in profile file %v", name, file)
+	}
+
+	profile := &Profile{
+		WorkerURL: getVar(config, name+".worker-url"),
+		Username:  getVar(config, name+".username"),
+		Password:  getVar(config, name+".password"),
+		Token:     getVar(config, name+".token"),
+		APIKey:    getVar(config, name+".api-key"),
+	}
+	if profile.WorkerURL == "" {
+		return nil, fmt.Errorf("Cannot retrieve configuration value worker-url of profile %v", name)
+	}
+	if profile.Token == "" && profile.APIKey == "" && (profile.Username == "" || profile.Password == "") {
+		return nil, fmt.Errorf("Profile %v needs username and password, token or api-key", name)
+	}
+	return profile, nil
+}
+
+// This aux method returns a client with profile credentials
+func (p *Profile) newClient() *client.Client {
+	var credentials client.Credentials
+	if p.Token != "" {
+		credentials = client.NewBearerCredentials(p.Token)
+	} else if p.APIKey != "" {
+		credentials = client.NewAPIKeyCredentials(p.APIKey)
+	} else {
+		credentials = client.NewBasicCredentials(p.Username, p.Password)
+	}
+	return client.NewClient(p.WorkerURL, credentials, nil)
+}
+
+// Check variables in TOML file.
+// If the value of a key is '${SOME_KEY}', we will search the value in the OS ENV vars
+// If the value of a key is 'something_else', returns that as the value
+// If the key doesn't exist, returns empty value
+func getVar(config *toml.TomlTree, key string) string {
+	if !config.Has(key) {
+		return ""
+	}
+	value, ok := config.Get(key).(string)
+	if !ok {
+		return ""
+	}
+	match := rEnvVar.FindStringSubmatch(value)
+	if match != nil && len(match) > 1 {
+		if match[1] != "" {
+			return os.Getenv(match[1])
+		}
+	}
+	return value
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/pelletier/go-toml"
+)
+
+func TestLoadProfile(t *testing.T) {
+	testcases := map[string]struct {
+		content         string
+		name            string
+		noFile          bool
+		expectedProfile *Profile
+		expectedErr     bool
+	}{
+		"OkCaseBasicCredentials": {
+			content: `
+[default]
+worker-url = "http://localhost:8000"
+username = "admin"
+password = "admin"
+`,
+			name: "default",
+			expectedProfile: &Profile{
+				WorkerURL: "http://localhost:8000",
+				Username:  "admin",
+				Password:  "admin",
+			},
+		},
+		"OkCaseToken": {
+			content: `
+[oidc]
+worker-url = "http://localhost:8000"
+token = "token"
+`,
+			name: "oidc",
+			expectedProfile: &Profile{
+				WorkerURL: "http://localhost:8000",
+				Token:     "token",
+			},
+		},
+		"OkCaseAPIKey": {
+			content: `
+[key]
+worker-url = "http://localhost:8000"
+api-key = "key"
+`,
+			name: "key",
+			expectedProfile: &Profile{
+				WorkerURL: "http://localhost:8000",
+				APIKey:    "key",
+			},
+		},
+		"OkCaseEnvVars": {
+			content: `
+[default]
+worker-url = "${FOULKONCTL_TEST_WORKER_URL}"
+username = "admin"
+password = "${FOULKONCTL_TEST_PASSWORD}"
+`,
+			name: "default",
+			expectedProfile: &Profile{
+				WorkerURL: "http://localhost:8000",
+				Username:  "admin",
+				Password:  "secret",
+			},
+		},
+		"ErrorCaseNoFile": {
+			name:        "default",
+			noFile:      true,
+			expectedErr: true,
+		},
+		"ErrorCaseInvalidFile": {
+			content:     "[default",
+			name:        "default",
+			expectedErr: true,
+		},
+		"ErrorCaseProfileNotFound": {
+			content: `
+[default]
+worker-url = "http://localhost:8000"
+token = "token"
+`,
+			name:        "other",
+			expectedErr: true,
+		},
+		"ErrorCaseNoWorkerURL": {
+			content: `
+[default]
+token = "token"
+`,
+			name:        "default",
+			expectedErr: true,
+		},
+		"ErrorCaseEnvVarNotSet": {
+			content: `
+[default]
+worker-url = "${FOULKONCTL_TEST_NOT_SET}"
+token = "token"
+`,
+			name:        "default",
+			expectedErr: true,
+		},
+		"ErrorCaseNoPassword": {
+			content: `
+[default]
+worker-url = "http://localhost:8000"
+username = "admin"
+`,
+			name:        "default",
+			expectedErr: true,
+		},
+	}
+
+	os.Setenv("FOULKONCTL_TEST_WORKER_URL", "http://localhost:8000")
+	os.Setenv("FOULKONCTL_TEST_PASSWORD", "secret")
+	os.Unsetenv("FOULKONCTL_TEST_NOT_SET")
+	defer os.Unsetenv("FOULKONCTL_TEST_WORKER_URL")
+	defer os.Unsetenv("FOULKONCTL_TEST_PASSWORD")
+
+	dir, err := ioutil.TempDir("", "foulkonctl-profile")
+	if err != nil {
+		t.Fatalf("Unexpected error creating profile dir: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	for n, test := range testcases {
+		file := filepath.Join(dir, n+".toml")
+		if !test.noFile {
+			if err := ioutil.WriteFile(file, []byte(test.content), 0600); err != nil {
+				t.Fatalf("Test %v failed. Unexpected error writing profile file: %v", n, err)
+			}
+		}
+		profile, err := loadProfile(file, test.name)
+		if test.expectedErr {
+			if err == nil {
+				t.Errorf("Test %v failed. Expected error", n)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(profile, test.expectedProfile); diff != "" {
+			t.Errorf("Test %v failed. Received different profiles (received/wanted) %v", n, diff)
+		}
+	}
+}
+
+func TestGetVar(t *testing.T) {
+	config, err := toml.Load(`
+[default]
+plain = "value"
+env = "${FOULKONCTL_TEST_VAR}"
+unset = "${FOULKONCTL_TEST_NOT_SET}"
+partial = "prefix-${FOULKONCTL_TEST_VAR}"
+number = 1
+`)
+	if err != nil {
+		t.Fatalf("Unexpected error loading config: %v", err)
+	}
+	os.Setenv("FOULKONCTL_TEST_VAR", "fromenv")
+	os.Unsetenv("FOULKONCTL_TEST_NOT_SET")
+	defer os.Unsetenv("FOULKONCTL_TEST_VAR")
+
+	testcases := map[string]struct {
+		key           string
+		expectedValue string
+	}{
+		"OkCasePlainValue": {
+			key:           "default.plain",
+			expectedValue: "value",
+		},
+		"OkCaseEnvVar": {
+			key:           "default.env",
+			expectedValue: "fromenv",
+		},
+		"OkCaseEnvVarNotSet": {
+			key:           "default.unset",
+			expectedValue: "",
+		},
+		"OkCaseNotWholeValue": {
+			key:           "default.partial",
+			expectedValue: "prefix-${FOULKONCTL_TEST_VAR}",
+		},
+		"OkCaseNotString": {
+			key:           "default.number",
+			expectedValue: "",
+		},
+		"OkCaseKeyNotFound": {
+			key:           "default.unknown",
+			expectedValue: "",
+		},
+	}
+
+	for n, test := range testcases {
+		if value := getVar(config, test.key); value != test.expectedValue {
+			t.Errorf("Test %v failed. Received different values (wanted:%v / received:%v)", n, test.expectedValue, value)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package main
+
+import (
+	"flag"
+	"io"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/client"
+)
+
+// USER COMMANDS
+
+func listUsers(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user list", flag.ExitOnError)
+	pathPrefix := fs.String("path-prefix", "", "Path prefix of users")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args) {
+		return 1
+	}
+
+	externalIDs := []string{}
+	it := c.Users(*pathPrefix)
+	for it.Next() {
+		externalIDs = append(externalIDs, it.Name())
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, externalIDs, func(w io.Writer) {
+		printNames(w, "EXTERNAL ID", externalIDs)
+	})
+}
+
+func getUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user get", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "id") {
+		return 1
+	}
+
+	user, err := c.GetUser(*externalID)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, user, func(w io.Writer) {
+		printUsers(w, user)
+	})
+}
+
+func addUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user add", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	path := fs.String("path", "", "Path of user")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "id", "path") {
+		return 1
+	}
+
+	user, err := c.AddUser(*externalID, *path)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, user, func(w io.Writer) {
+		printUsers(w, user)
+	})
+}
+
+func updateUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user update", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	path := fs.String("path", "", "New path of user")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "id", "path") {
+		return 1
+	}
+
+	user, err := c.UpdateUser(*externalID, *path)
+	if err != nil {
+		return fail(err)
+	}
+	return printOutput(*output, user, func(w io.Writer) {
+		printUsers(w, user)
+	})
+}
+
+func removeUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user remove", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	if !parseFlags(fs, args, "id") {
+		return 1
+	}
+
+	if err := c.RemoveUser(*externalID); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func listGroupsByUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user groups", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "id") {
+		return 1
+	}
+
+	groups := []api.GroupIdentity{}
+	it := c.GroupsByUser(*externalID)
+	for it.Next() {
+		groups = append(groups, it.Group())
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, groups, func(w io.Writer) {
+		printGroupIdentities(w, groups)
+	})
+}
+
+func listAttachedUserPolicies(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user policies", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	output := outputFlag(fs)
+	if !parseFlags(fs, args, "id") {
+		return 1
+	}
+
+	policies := []api.PolicyIdentity{}
+	it := c.AttachedUserPolicies(*externalID)
+	for it.Next() {
+		policies = append(policies, it.Policy())
+	}
+	if it.Err() != nil {
+		return fail(it.Err())
+	}
+	return printOutput(*output, policies, func(w io.Writer) {
+		printPolicyIdentities(w, policies)
+	})
+}
+
+func attachPolicyToUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user attach", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	org := fs.String("org", "", "Organization of policy")
+	policy := fs.String("policy", "", "Name of policy")
+	if !parseFlags(fs, args, "id", "org", "policy") {
+		return 1
+	}
+
+	if err := c.AttachPolicyToUser(*externalID, *org, *policy); err != nil {
+		return fail(err)
+	}
+	return 0
+}
+
+func detachPolicyFromUser(c *client.Client, args []string) int {
+	fs := flag.NewFlagSet("user detach", flag.ExitOnError)
+	externalID := fs.String("id", "", "External id of user")
+	org := fs.String("org", "", "Organization of policy")
+	policy := fs.String("policy", "", "Name of policy")
+	if !parseFlags(fs, args, "id", "org", "policy") {
+		return 1
+	}
+
+	if err := c.DetachPolicyFromUser(*externalID, *org, *policy); err != nil {
+		return fail(err)
+	}
+	return 0
+}
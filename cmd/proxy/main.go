@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
@@ -37,6 +38,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	server := &http.Server{
+		Addr:    proxy.Host + ":" + proxy.Port,
+		Handler: internalhttp.ProxyHandlerRouter(proxy),
+	}
+	// Metrics are served in their own listener, so they never hide a proxy resource
+	var metricsServer *http.Server
+	if proxy.MetricsAddress != "" {
+		metricsServer = &http.Server{
+			Addr:    proxy.MetricsAddress,
+			Handler: internalhttp.MetricsHandlerRouter(),
+		}
+		go func() {
+			proxy.Logger.Infof("Metrics server running in %v", proxy.MetricsAddress)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				proxy.Logger.Errorf("Metrics server stopped: %v", err)
+			}
+		}()
+	}
+	// Closed when in-flight requests finish after a stop signal
+	stopped := make(chan struct{})
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig,
 		syscall.SIGHUP,
@@ -48,7 +70,30 @@ func main() {
 		for {
 			sigrecv := <-sig
 			switch sigrecv {
-			case syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGQUIT:
+			case syscall.SIGHUP:
+				proxy.Logger.Infof("Signal '%v' received, reloading proxy file %v...", sigrecv.String(), *configFile)
+				config, err := toml.LoadFile(*configFile)
+				if err != nil {
+					proxy.Logger.Errorf("Cannot read proxy file %v, error: %v", *configFile, err)
+					continue
+				}
+				if err := proxy.Reload(config); err != nil {
+					proxy.Logger.Errorf("Configuration not reloaded, error: %v", err)
+				}
+			case syscall.SIGINT, syscall.SIGTERM:
+				proxy.Logger.Infof("Signal '%v' received, waiting %v for in-flight requests before closing proxy...",
+					sigrecv.String(), proxy.ShutdownTimeout)
+				ctx, cancel := context.WithTimeout(context.Background(), proxy.ShutdownTimeout)
+				if err := server.Shutdown(ctx); err != nil {
+					proxy.Logger.Errorf("Proxy closed before finishing in-flight requests: %v", err)
+				}
+				if metricsServer != nil {
+					metricsServer.Shutdown(ctx)
+				}
+				cancel()
+				close(stopped)
+				return
+			case syscall.SIGQUIT:
 				proxy.Logger.Infof("Signal '%v' received, closing proxy...", sigrecv.String())
 				os.Exit(foulkon.CloseProxy())
 			default:
@@ -59,9 +104,14 @@ func main() {
 
 	proxy.Logger.Infof("Server running in %v:%v", proxy.Host, proxy.Port)
 	if proxy.CertFile != "" && proxy.KeyFile != "" {
-		proxy.Logger.Error(http.ListenAndServeTLS(proxy.Host+":"+proxy.Port, proxy.CertFile, proxy.KeyFile, internalhttp.ProxyHandlerRouter(proxy)).Error())
+		err = server.ListenAndServeTLS(proxy.CertFile, proxy.KeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		<-stopped
 	} else {
-		proxy.Logger.Error(http.ListenAndServe(proxy.Host+":"+proxy.Port, internalhttp.ProxyHandlerRouter(proxy)).Error())
+		proxy.Logger.Error(err.Error())
 	}
 
 	os.Exit(foulkon.CloseProxy())
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"net/http"
@@ -39,6 +40,27 @@ func main() {
 		os.Exit(1)
 	}
 
+	server := &http.Server{
+		Addr:    core.Host + ":" + core.Port,
+		Handler: internalhttp.WorkerHandlerRouter(core),
+	}
+	// Metrics are served in their own listener, apart from worker API
+	var metricsServer *http.Server
+	if core.MetricsAddress != "" {
+		metricsServer = &http.Server{
+			Addr:    core.MetricsAddress,
+			Handler: internalhttp.MetricsHandlerRouter(),
+		}
+		go func() {
+			core.Logger.Infof("Metrics server running in %v", core.MetricsAddress)
+			if err := metricsServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				core.Logger.Errorf("Metrics server stopped: %v", err)
+			}
+		}()
+	}
+	// Closed when in-flight requests finish after a stop signal
+	stopped := make(chan struct{})
+
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig,
 		syscall.SIGHUP,
@@ -50,7 +72,30 @@ func main() {
 		for {
 			sigrecv := <-sig
 			switch sigrecv {
-			case syscall.SIGINT, syscall.SIGHUP, syscall.SIGTERM, syscall.SIGQUIT:
+			case syscall.SIGHUP:
+				core.Logger.Infof("Signal '%v' received, reloading configuration file %v...", sigrecv.String(), *configFile)
+				config, err := toml.LoadFile(*configFile)
+				if err != nil {
+					core.Logger.Errorf("Cannot read configuration file %v, error: %v", *configFile, err)
+					continue
+				}
+				if err := core.Reload(config); err != nil {
+					core.Logger.Errorf("Configuration not reloaded, error: %v", err)
+				}
+			case syscall.SIGINT, syscall.SIGTERM:
+				core.Logger.Infof("Signal '%v' received, waiting %v for in-flight requests before closing worker...",
+					sigrecv.String(), core.ShutdownTimeout)
+				ctx, cancel := context.WithTimeout(context.Background(), core.ShutdownTimeout)
+				if err := server.Shutdown(ctx); err != nil {
+					core.Logger.Errorf("Worker closed before finishing in-flight requests: %v", err)
+				}
+				if metricsServer != nil {
+					metricsServer.Shutdown(ctx)
+				}
+				cancel()
+				close(stopped)
+				return
+			case syscall.SIGQUIT:
 				core.Logger.Infof("Signal '%v' received, closing worker...", sigrecv.String())
 				os.Exit(foulkon.CloseWorker())
 			default:
@@ -61,9 +106,14 @@ func main() {
 
 	core.Logger.Infof("Server running in %v:%v", core.Host, core.Port)
 	if core.CertFile != "" && core.KeyFile != "" {
-		core.Logger.Error(http.ListenAndServeTLS(core.Host+":"+core.Port, core.CertFile, core.KeyFile, internalhttp.WorkerHandlerRouter(core)).Error())
+		err = server.ListenAndServeTLS(core.CertFile, core.KeyFile)
+	} else {
+		err = server.ListenAndServe()
+	}
+	if err == http.ErrServerClosed {
+		<-stopped
 	} else {
-		core.Logger.Error(http.ListenAndServe(core.Host+":"+core.Port, internalhttp.WorkerHandlerRouter(core)).Error())
+		core.Logger.Error(err.Error())
 	}
 
 	os.Exit(foulkon.CloseWorker())
@@ -0,0 +1,1148 @@
+// Package conformance contains the tests that every repository backend must pass,
+// so all of them behave the same way for the API.
+package conformance
+
+import (
+	"fmt"
+	"io/ioutil"
+	"sort"
+	"testing"
+	"time"
+
+	log "github.com/Sirupsen/logrus"
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+// Repo groups all repositories implemented by a backend
+type Repo interface {
+	api.UserRepo
+	api.GroupRepo
+	api.PolicyRepo
+	api.AuthzRepo
+	api.AuditRepo
+	api.ImportRepo
+	api.APIKeyRepo
+}
+
+// RunTests runs the conformance suite. newRepo is called before every test and
+// it must return a repository without data.
+func RunTests(t *testing.T, newRepo func() Repo) {
+	tests := map[string]func(t *testing.T, repo Repo){
+		"Users":                    testUsers,
+		"UsersFiltered":            testUsersFiltered,
+		"Groups":                   testGroups,
+		"GroupMembers":             testGroupMembers,
+		"Policies":                 testPolicies,
+		"PolicyAttachments":        testPolicyAttachments,
+		"PolicyVersions":           testPolicyVersions,
+		"RemoveCascades":           testRemoveCascades,
+		"EffectiveStatements":      testEffectiveStatements,
+		"EffectiveStatementsError": testEffectiveStatementsError,
+		"ExplainAuthorization":     testExplainAuthorization,
+		"AuditEvents":              testAuditEvents,
+		"ImportChanges":            testImportChanges,
+		"ImportChangesRollback":    testImportChangesRollback,
+		"APIKeys":                  testAPIKeys,
+	}
+
+	names := []string{}
+	for n := range tests {
+		names = append(names, n)
+	}
+	sort.Strings(names)
+	for _, n := range names {
+		test := tests[n]
+		t.Run(n, func(t *testing.T) {
+			test(t, newRepo())
+		})
+	}
+}
+
+func testUsers(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+
+	// Duplicated user
+	_, err := repo.AddUser(*user)
+	checkErrorCode(t, "AddUser duplicated", err, database.INTERNAL_ERROR)
+
+	// Retrieve user
+	received, err := repo.GetUserByExternalID("ExternalID")
+	checkResponse(t, "GetUserByExternalID", err, user, received)
+
+	// Unknown user
+	_, err = repo.GetUserByExternalID("Unknown")
+	checkError(t, "GetUserByExternalID unknown", err, &database.Error{
+		Code:    database.USER_NOT_FOUND,
+		Message: "User with externalId Unknown not found",
+	})
+
+	// Update user
+	updated, err := repo.UpdateUser(*user, "/newpath/", "NewUrn")
+	expected := *user
+	expected.Path = "/newpath/"
+	expected.Urn = "NewUrn"
+	checkResponse(t, "UpdateUser", err, &expected, updated)
+	received, err = repo.GetUserByExternalID("ExternalID")
+	checkResponse(t, "GetUserByExternalID after update", err, &expected, received)
+
+	// Remove user
+	if err := repo.RemoveUser("UserID"); err != nil {
+		t.Fatalf("Unexpected error removing user: %v", err)
+	}
+	_, err = repo.GetUserByExternalID("ExternalID")
+	checkErrorCode(t, "GetUserByExternalID after remove", err, database.USER_NOT_FOUND)
+}
+
+func testUsersFiltered(t *testing.T, repo Repo) {
+	mustAddUser(t, repo, "UserID1", "ExternalID1", "/path/")
+	mustAddUser(t, repo, "UserID2", "ExternalID2", "/path/sub/")
+	mustAddUser(t, repo, "UserID3", "ExternalID3", "/other/")
+
+	testcases := map[string]struct {
+		filter        *api.Filter
+		expectedTotal int
+		expectedCount int
+	}{
+		"AllUsers": {
+			filter:        &api.Filter{},
+			expectedTotal: 3,
+			expectedCount: 3,
+		},
+		"PathPrefix": {
+			filter:        &api.Filter{PathPrefix: "/path/"},
+			expectedTotal: 2,
+			expectedCount: 2,
+		},
+		"Limit": {
+			filter:        &api.Filter{Limit: 2},
+			expectedTotal: 3,
+			expectedCount: 2,
+		},
+		"Offset": {
+			filter:        &api.Filter{Offset: 2, Limit: 2},
+			expectedTotal: 3,
+			expectedCount: 1,
+		},
+		"OffsetOutOfRange": {
+			filter:        &api.Filter{Offset: 5, Limit: 2},
+			expectedTotal: 3,
+			expectedCount: 0,
+		},
+	}
+
+	for n, test := range testcases {
+		users, total, err := repo.GetUsersFiltered(test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if total != test.expectedTotal || len(users) != test.expectedCount {
+			t.Errorf("Test %v failed. Received total %v and %v users, expected total %v and %v users",
+				n, total, len(users), test.expectedTotal, test.expectedCount)
+		}
+	}
+}
+
+func testGroups(t *testing.T, repo Repo) {
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+	mustAddGroup(t, repo, "GroupID2", "Org2", "Name", "/path/")
+
+	// Duplicated group
+	_, err := repo.AddGroup(*group)
+	checkErrorCode(t, "AddGroup duplicated", err, database.INTERNAL_ERROR)
+
+	// Retrieve group
+	received, err := repo.GetGroupByName("Org", "Name")
+	checkResponse(t, "GetGroupByName", err, group, received)
+
+	// Unknown group
+	_, err = repo.GetGroupByName("Org", "Unknown")
+	checkError(t, "GetGroupByName unknown", err, &database.Error{
+		Code:    database.GROUP_NOT_FOUND,
+		Message: "Group with organization Org and name Unknown not found",
+	})
+
+	// Filter by organization
+	groups, total, err := repo.GetGroupsFiltered("Org", &api.Filter{})
+	checkResponse(t, "GetGroupsFiltered", err, []api.Group{*group}, groups)
+	if total != 1 {
+		t.Errorf("GetGroupsFiltered failed. Received total %v", total)
+	}
+	_, total, err = repo.GetGroupsFiltered("", &api.Filter{})
+	if err != nil || total != 2 {
+		t.Errorf("GetGroupsFiltered without organization failed. Received total %v, error %v", total, err)
+	}
+
+	// Update group
+	updated, err := repo.UpdateGroup(*group, "NewName", "/newpath/", "NewUrn")
+	expected := *group
+	expected.Name = "NewName"
+	expected.Path = "/newpath/"
+	expected.Urn = "NewUrn"
+	checkResponse(t, "UpdateGroup", err, &expected, updated)
+	received, err = repo.GetGroupByName("Org", "NewName")
+	checkResponse(t, "GetGroupByName after update", err, &expected, received)
+
+	// Remove group
+	if err := repo.RemoveGroup("GroupID"); err != nil {
+		t.Fatalf("Unexpected error removing group: %v", err)
+	}
+	_, err = repo.GetGroupByName("Org", "NewName")
+	checkErrorCode(t, "GetGroupByName after remove", err, database.GROUP_NOT_FOUND)
+}
+
+func testGroupMembers(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+
+	if err := repo.AddMember(user.ID, group.ID); err != nil {
+		t.Fatalf("Unexpected error adding member: %v", err)
+	}
+	err := repo.AddMember(user.ID, group.ID)
+	checkErrorCode(t, "AddMember duplicated", err, database.INTERNAL_ERROR)
+
+	isMember, err := repo.IsMemberOfGroup(user.ID, group.ID)
+	checkResponse(t, "IsMemberOfGroup", err, true, isMember)
+
+	members, total, err := repo.GetGroupMembers(group.ID, &api.Filter{})
+	checkResponse(t, "GetGroupMembers", err, []api.User{*user}, members)
+	if total != 1 {
+		t.Errorf("GetGroupMembers failed. Received total %v", total)
+	}
+
+	groups, total, err := repo.GetGroupsByUserID(user.ID, &api.Filter{})
+	checkResponse(t, "GetGroupsByUserID", err, []api.Group{*group}, groups)
+	if total != 1 {
+		t.Errorf("GetGroupsByUserID failed. Received total %v", total)
+	}
+
+	if err := repo.RemoveMember(user.ID, group.ID); err != nil {
+		t.Fatalf("Unexpected error removing member: %v", err)
+	}
+	isMember, err = repo.IsMemberOfGroup(user.ID, group.ID)
+	checkResponse(t, "IsMemberOfGroup after remove", err, false, isMember)
+}
+
+func testPolicies(t *testing.T, repo Repo) {
+	policy := mustAddPolicy(t, repo, "PolicyID", "Org", "Name", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"iam:*"},
+			Resources: []string{"urn:everything:*"},
+			Conditions: []api.Condition{
+				{
+					Operator: api.CONDITION_OPERATOR_STRING_EQUALS,
+					Key:      "Foulkon-Context-Team",
+					Values:   []string{"devops"},
+				},
+			},
+		},
+	})
+
+	// Duplicated policy
+	_, err := repo.AddPolicy(*policy, api.PolicyVersion{})
+	checkErrorCode(t, "AddPolicy duplicated", err, database.INTERNAL_ERROR)
+
+	// Retrieve policy
+	received, err := repo.GetPolicyByName("Org", "Name")
+	checkResponse(t, "GetPolicyByName", err, policy, received)
+
+	// Unknown policy
+	_, err = repo.GetPolicyByName("Org", "Unknown")
+	checkError(t, "GetPolicyByName unknown", err, &database.Error{
+		Code:    database.POLICY_NOT_FOUND,
+		Message: "Policy with organization Org and name Unknown not found",
+	})
+
+	policies, total, err := repo.GetPoliciesFiltered("Org", &api.Filter{PathPrefix: "/path/"})
+	checkResponse(t, "GetPoliciesFiltered", err, []api.Policy{*policy}, policies)
+	if total != 1 {
+		t.Errorf("GetPoliciesFiltered failed. Received total %v", total)
+	}
+
+	// Update policy
+	newStatements := []api.Statement{
+		{
+			Effect:    "deny",
+			Actions:   []string{"iam:GetUser"},
+			Resources: []string{"urn:everything:*"},
+		},
+	}
+	updated, err := repo.UpdatePolicy(*policy, "NewName", "/newpath/", "NewUrn", newStatements, api.PolicyVersion{})
+	expected := *policy
+	expected.Name = "NewName"
+	expected.Path = "/newpath/"
+	expected.Urn = "NewUrn"
+	expected.Statements = &newStatements
+	checkResponse(t, "UpdatePolicy", err, &expected, updated)
+	received, err = repo.GetPolicyByName("Org", "NewName")
+	checkResponse(t, "GetPolicyByName after update", err, &expected, received)
+
+	// Remove policy
+	if err := repo.RemovePolicy("PolicyID"); err != nil {
+		t.Fatalf("Unexpected error removing policy: %v", err)
+	}
+	_, err = repo.GetPolicyByName("Org", "NewName")
+	checkErrorCode(t, "GetPolicyByName after remove", err, database.POLICY_NOT_FOUND)
+}
+
+func testPolicyAttachments(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+	policy := mustAddPolicy(t, repo, "PolicyID", "Org", "Name", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"iam:*"},
+			Resources: []string{"urn:everything:*"},
+		},
+	})
+
+	// Group attachments
+	if err := repo.AttachPolicy(group.ID, policy.ID); err != nil {
+		t.Fatalf("Unexpected error attaching policy to group: %v", err)
+	}
+	err := repo.AttachPolicy(group.ID, policy.ID)
+	checkErrorCode(t, "AttachPolicy duplicated", err, database.INTERNAL_ERROR)
+	isAttached, err := repo.IsAttachedToGroup(group.ID, policy.ID)
+	checkResponse(t, "IsAttachedToGroup", err, true, isAttached)
+	policies, _, err := repo.GetAttachedPolicies(group.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedPolicies", err, []api.Policy{*policy}, policies)
+	groups, _, err := repo.GetAttachedGroups(policy.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedGroups", err, []api.Group{*group}, groups)
+	if err := repo.DetachPolicy(group.ID, policy.ID); err != nil {
+		t.Fatalf("Unexpected error detaching policy from group: %v", err)
+	}
+	isAttached, err = repo.IsAttachedToGroup(group.ID, policy.ID)
+	checkResponse(t, "IsAttachedToGroup after detach", err, false, isAttached)
+
+	// User attachments
+	if err := repo.AttachPolicyToUser(user.ID, policy.ID); err != nil {
+		t.Fatalf("Unexpected error attaching policy to user: %v", err)
+	}
+	err = repo.AttachPolicyToUser(user.ID, policy.ID)
+	checkErrorCode(t, "AttachPolicyToUser duplicated", err, database.INTERNAL_ERROR)
+	isAttached, err = repo.IsAttachedToUser(user.ID, policy.ID)
+	checkResponse(t, "IsAttachedToUser", err, true, isAttached)
+	policies, _, err = repo.GetAttachedUserPolicies(user.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedUserPolicies", err, []api.Policy{*policy}, policies)
+	users, _, err := repo.GetAttachedUsers(policy.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedUsers", err, []api.User{*user}, users)
+	if err := repo.DetachPolicyFromUser(user.ID, policy.ID); err != nil {
+		t.Fatalf("Unexpected error detaching policy from user: %v", err)
+	}
+	isAttached, err = repo.IsAttachedToUser(user.ID, policy.ID)
+	checkResponse(t, "IsAttachedToUser after detach", err, false, isAttached)
+}
+
+func testPolicyVersions(t *testing.T, repo Repo) {
+	statements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"iam:*"},
+			Resources: []string{"urn:everything:*"},
+			Conditions: []api.Condition{
+				{
+					Operator: api.CONDITION_OPERATOR_STRING_EQUALS,
+					Key:      "Foulkon-Context-Team",
+					Values:   []string{"devops"},
+				},
+			},
+		},
+	}
+	createAt := time.Unix(0, time.Now().UnixNano()).UTC()
+	version1 := api.PolicyVersion{
+		PolicyID:   "PolicyID",
+		Version:    1,
+		Name:       "Name",
+		Path:       "/path/",
+		Statements: &statements,
+		Author:     "Author",
+		CreateAt:   createAt,
+	}
+	policy, err := repo.AddPolicy(api.Policy{
+		ID:         "PolicyID",
+		Name:       "Name",
+		Path:       "/path/",
+		Org:        "Org",
+		Urn:        api.CreateUrn("Org", api.RESOURCE_POLICY, "/path/", "Name"),
+		CreateAt:   createAt,
+		Statements: &statements,
+	}, version1)
+	mustRun(t, err)
+	policy2 := mustAddPolicy(t, repo, "PolicyID2", "Org", "Name2", "/path/", []api.Statement{})
+
+	// Duplicated policy doesn't store its version
+	_, err = repo.AddPolicy(*policy, version1)
+	checkErrorCode(t, "AddPolicy duplicated", err, database.INTERNAL_ERROR)
+
+	// Update stores next version in the same transaction
+	version2 := api.PolicyVersion{
+		PolicyID:   policy.ID,
+		Version:    2,
+		Name:       "NewName",
+		Path:       "/newpath/",
+		Statements: &[]api.Statement{},
+		Author:     "Author2",
+		CreateAt:   createAt,
+	}
+	_, err = repo.UpdatePolicy(*policy, "NewName", "/newpath/", "NewUrn", []api.Statement{}, version2)
+	mustRun(t, err)
+
+	// Failed update doesn't store its version
+	_, err = repo.UpdatePolicy(*policy2, "Name2", "/path/", "NewUrn", []api.Statement{}, version2)
+	checkErrorCode(t, "UpdatePolicy duplicated urn", err, database.INTERNAL_ERROR)
+	_, total, err := repo.GetPolicyVersions(policy2.ID, &api.Filter{})
+	checkResponse(t, "GetPolicyVersions after failed update total", err, 1, total)
+
+	versions, total, err := repo.GetPolicyVersions(policy.ID, &api.Filter{})
+	checkResponse(t, "GetPolicyVersions", err, []api.PolicyVersion{version1, version2}, versions)
+	checkResponse(t, "GetPolicyVersions total", err, 2, total)
+	versions, total, err = repo.GetPolicyVersions(policy.ID, &api.Filter{Offset: 1, Limit: 1})
+	checkResponse(t, "GetPolicyVersions paginated", err, []api.PolicyVersion{version2}, versions)
+	checkResponse(t, "GetPolicyVersions paginated total", err, 2, total)
+
+	received, err := repo.GetPolicyVersion(policy.ID, 1)
+	checkResponse(t, "GetPolicyVersion", err, &version1, received)
+	_, err = repo.GetPolicyVersion(policy.ID, 3)
+	checkError(t, "GetPolicyVersion unknown", err, &database.Error{
+		Code:    database.POLICY_VERSION_NOT_FOUND,
+		Message: "Version 3 of policy with id PolicyID not found",
+	})
+
+	// Policies created before versioning store their previous state first
+	legacy := api.Policy{
+		ID:         "PolicyID3",
+		Name:       "Name3",
+		Path:       "/path/",
+		Org:        "Org",
+		Urn:        api.CreateUrn("Org", api.RESOURCE_POLICY, "/path/", "Name3"),
+		CreateAt:   createAt,
+		Statements: &statements,
+	}
+	mustRun(t, repo.ApplyImportChanges(api.ImportChanges{AddedPolicies: []api.Policy{legacy}}))
+	_, err = repo.UpdatePolicy(legacy, "Name3", "/newpath/", "NewUrn3", []api.Statement{}, api.PolicyVersion{
+		Name:       "Name3",
+		Path:       "/newpath/",
+		Statements: &[]api.Statement{},
+		Author:     "Author",
+		CreateAt:   createAt,
+	})
+	mustRun(t, err)
+	versions, _, err = repo.GetPolicyVersions(legacy.ID, &api.Filter{})
+	checkResponse(t, "GetPolicyVersions of policy created before versioning", err, []api.PolicyVersion{
+		{
+			PolicyID:   legacy.ID,
+			Version:    1,
+			Name:       "Name3",
+			Path:       "/path/",
+			Statements: &statements,
+			CreateAt:   createAt,
+		},
+		{
+			PolicyID:   legacy.ID,
+			Version:    2,
+			Name:       "Name3",
+			Path:       "/newpath/",
+			Statements: &[]api.Statement{},
+			Author:     "Author",
+			CreateAt:   createAt,
+		},
+	}, versions)
+
+	// Versions are removed with their policy
+	mustRun(t, repo.RemovePolicy(policy.ID))
+	versions, total, err = repo.GetPolicyVersions(policy.ID, &api.Filter{})
+	checkResponse(t, "GetPolicyVersions after remove", err, []api.PolicyVersion{}, versions)
+	checkResponse(t, "GetPolicyVersions total after remove", err, 0, total)
+	_, total, err = repo.GetPolicyVersions(policy2.ID, &api.Filter{})
+	checkResponse(t, "GetPolicyVersions of other policy after remove", err, 1, total)
+}
+
+func testRemoveCascades(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	user2 := mustAddUser(t, repo, "UserID2", "ExternalID2", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+	group2 := mustAddGroup(t, repo, "GroupID2", "Org", "Name2", "/path/")
+	policy := mustAddPolicy(t, repo, "PolicyID", "Org", "Name", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"iam:*"},
+			Resources: []string{"urn:everything:*"},
+		},
+	})
+	mustRun(t, repo.AddMember(user.ID, group.ID))
+	mustRun(t, repo.AddMember(user2.ID, group2.ID))
+	mustRun(t, repo.AttachPolicy(group.ID, policy.ID))
+	mustRun(t, repo.AttachPolicy(group2.ID, policy.ID))
+	mustRun(t, repo.AttachPolicyToUser(user.ID, policy.ID))
+	mustRun(t, repo.AttachPolicyToUser(user2.ID, policy.ID))
+
+	// Removed user is no longer member or attached
+	mustRun(t, repo.RemoveUser(user.ID))
+	isMember, err := repo.IsMemberOfGroup(user.ID, group.ID)
+	checkResponse(t, "IsMemberOfGroup after user remove", err, false, isMember)
+	isAttached, err := repo.IsAttachedToUser(user.ID, policy.ID)
+	checkResponse(t, "IsAttachedToUser after user remove", err, false, isAttached)
+
+	// Removed group has no members nor policies
+	mustRun(t, repo.RemoveGroup(group2.ID))
+	isMember, err = repo.IsMemberOfGroup(user2.ID, group2.ID)
+	checkResponse(t, "IsMemberOfGroup after group remove", err, false, isMember)
+	isAttached, err = repo.IsAttachedToGroup(group2.ID, policy.ID)
+	checkResponse(t, "IsAttachedToGroup after group remove", err, false, isAttached)
+
+	// Removed policy is no longer attached
+	mustRun(t, repo.RemovePolicy(policy.ID))
+	isAttached, err = repo.IsAttachedToGroup(group.ID, policy.ID)
+	checkResponse(t, "IsAttachedToGroup after policy remove", err, false, isAttached)
+	isAttached, err = repo.IsAttachedToUser(user2.ID, policy.ID)
+	checkResponse(t, "IsAttachedToUser after policy remove", err, false, isAttached)
+}
+
+func testEffectiveStatements(t *testing.T, repo Repo) {
+	groupStatement := api.Statement{
+		Effect:    "allow",
+		Actions:   []string{"iam:GetUser"},
+		Resources: []string{"urn:everything:*"},
+	}
+	userStatement := api.Statement{
+		Effect:    "deny",
+		Actions:   []string{"iam:RemoveUser"},
+		Resources: []string{"urn:everything:*"},
+	}
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	mustAddUser(t, repo, "UserID2", "ExternalID2", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+	group2 := mustAddGroup(t, repo, "GroupID2", "Org", "Name2", "/path/")
+	groupPolicy := mustAddPolicy(t, repo, "PolicyID", "Org", "Name", "/path/", []api.Statement{groupStatement, userStatement})
+	userPolicy := mustAddPolicy(t, repo, "PolicyID2", "Org", "Name2", "/path/", []api.Statement{userStatement})
+	mustRun(t, repo.AddMember(user.ID, group.ID))
+	mustRun(t, repo.AddMember(user.ID, group2.ID))
+	mustRun(t, repo.AttachPolicy(group.ID, groupPolicy.ID))
+	mustRun(t, repo.AttachPolicyToUser(user.ID, userPolicy.ID))
+	// Policy attached twice is returned for every attachment
+	mustRun(t, repo.AttachPolicy(group2.ID, userPolicy.ID))
+
+	groupIdentity := &api.GroupIdentity{Org: "Org", Name: "Name"}
+	group2Identity := &api.GroupIdentity{Org: "Org", Name: "Name2"}
+	groupPolicyIdentity := api.PolicyIdentity{Org: "Org", Name: "Name"}
+	userPolicyIdentity := api.PolicyIdentity{Org: "Org", Name: "Name2"}
+	statements, err := repo.GetEffectiveStatements("ExternalID")
+	checkAttachmentsTogether(t, "GetEffectiveStatements", statements)
+	sortEffectiveStatements(statements)
+	expected := []api.EffectiveStatement{
+		{Group: groupIdentity, Policy: groupPolicyIdentity, Statement: groupStatement},
+		{Group: groupIdentity, Policy: groupPolicyIdentity, Statement: userStatement},
+		{Group: group2Identity, Policy: userPolicyIdentity, Statement: userStatement},
+		{Policy: userPolicyIdentity, Statement: userStatement},
+	}
+	sortEffectiveStatements(expected)
+	checkResponse(t, "GetEffectiveStatements", err, expected, statements)
+
+	// User without policies
+	statements, err = repo.GetEffectiveStatements("ExternalID2")
+	checkResponse(t, "GetEffectiveStatements without policies", err, []api.EffectiveStatement{}, statements)
+}
+
+func testEffectiveStatementsError(t *testing.T, repo Repo) {
+	_, err := repo.GetEffectiveStatements("Unknown")
+	checkErrorCode(t, "GetEffectiveStatements unknown user", err, database.USER_NOT_FOUND)
+}
+
+// Explanation and authorization are built from the same effective statements, so both must take the same decisions
+func testExplainAuthorization(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	mustAddUser(t, repo, "UserID2", "ExternalID2", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+	group2 := mustAddGroup(t, repo, "GroupID2", "Org", "Name2", "/path/")
+	group3 := mustAddGroup(t, repo, "GroupID3", "Org", "Name3", "/path/")
+	groupPolicy := mustAddPolicy(t, repo, "PolicyID", "Org", "Name", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"product:Get*"},
+			Resources: []string{"urn:ews:product:instance:resource/*"},
+		},
+		{
+			Effect:    "deny",
+			Actions:   []string{"product:GetResource"},
+			Resources: []string{"urn:ews:product:instance:resource/denied"},
+		},
+	})
+	conditionPolicy := mustAddPolicy(t, repo, "PolicyID2", "Org", "Name2", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"product:GetResource"},
+			Resources: []string{"urn:ews:product:instance:other/*"},
+			Conditions: []api.Condition{
+				{
+					Operator: api.CONDITION_OPERATOR_STRING_EQUALS,
+					Key:      "Foulkon-Context-Team",
+					Values:   []string{"devops"},
+				},
+			},
+		},
+	})
+	userPolicy := mustAddPolicy(t, repo, "PolicyID3", "Org", "Name3", "/path/", []api.Statement{
+		{
+			Effect:    "deny",
+			Actions:   []string{"product:*"},
+			Resources: []string{"urn:ews:product:instance:resource/secret/*"},
+		},
+		{
+			Effect:    "allow",
+			Actions:   []string{"product:GetResource"},
+			Resources: []string{"urn:ews:product:instance:single"},
+		},
+	})
+	otherPolicy := mustAddPolicy(t, repo, "PolicyID4", "Org", "Name4", "/path/", []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"product:*"},
+			Resources: []string{"urn:*"},
+		},
+	})
+	mustRun(t, repo.AddMember(user.ID, group.ID))
+	mustRun(t, repo.AddMember(user.ID, group2.ID))
+	mustRun(t, repo.AttachPolicy(group.ID, groupPolicy.ID))
+	mustRun(t, repo.AttachPolicy(group2.ID, conditionPolicy.ID))
+	mustRun(t, repo.AttachPolicyToUser(user.ID, userPolicy.ID))
+	// Policies of groups without the user don't apply
+	mustRun(t, repo.AttachPolicy(group3.ID, otherPolicy.ID))
+
+	authAPI := api.AuthAPI{
+		UserRepo:   repo,
+		GroupRepo:  repo,
+		PolicyRepo: repo,
+		AuthzRepo:  repo,
+		AuditRepo:  repo,
+		ImportRepo: repo,
+		APIKeyRepo: repo,
+		Logger: &log.Logger{
+			Out:       ioutil.Discard,
+			Formatter: &log.TextFormatter{},
+			Hooks:     make(log.LevelHooks),
+			Level:     log.DebugLevel,
+		},
+	}
+	resources := []string{
+		"urn:ews:product:instance:resource/a",
+		"urn:ews:product:instance:resource/denied",
+		"urn:ews:product:instance:resource/secret/a",
+		"urn:ews:product:instance:other/a",
+		"urn:ews:product:instance:single",
+		"urn:ews:product:instance:unrelated/a",
+	}
+	teamContext := api.RequestContext{Attributes: map[string]string{"Foulkon-Context-Team": "devops"}}
+
+	for _, check := range []struct {
+		externalID string
+		action     string
+		context    api.RequestContext
+	}{
+		{"ExternalID", "product:GetResource", teamContext},
+		{"ExternalID", "product:GetResource", api.RequestContext{}},
+		{"ExternalID", "product:GetOther", teamContext},
+		{"ExternalID", "product:RemoveResource", teamContext},
+		{"ExternalID2", "product:GetResource", teamContext},
+	} {
+		name := fmt.Sprintf("%v %v %v", check.externalID, check.action, check.context.Attributes)
+		requestInfo := api.RequestInfo{
+			Identifier: "admin",
+			Admin:      true,
+			Context:    check.context,
+		}
+		allowed, err := authAPI.GetAuthorizedExternalResourcesForUser(requestInfo, check.externalID, check.action, resources)
+		if err != nil {
+			t.Fatalf("%v. Unexpected error authorizing: %v", name, err)
+		}
+		explanation, err := authAPI.ExplainAuthorization(requestInfo, check.externalID, check.action, resources)
+		if err != nil {
+			t.Fatalf("%v. Unexpected error explaining: %v", name, err)
+		}
+		explained := []string{}
+		for _, decision := range explanation.Decisions {
+			if decision.Allowed {
+				explained = append(explained, decision.Urn)
+			}
+		}
+		checkResponse(t, name+". Explained decisions", nil, allowed, explained)
+
+		restrictions, err := authAPI.GetAuthorizedRestrictions(api.RequestInfo{
+			Identifier: check.externalID,
+			Context:    check.context,
+		}, check.action, "urn:*")
+		if err != nil {
+			t.Fatalf("%v. Unexpected error retrieving restrictions: %v", name, err)
+		}
+		checkResponse(t, name+". Explained restrictions", nil, sortRestrictions(restrictions),
+			sortRestrictions(explanation.Restrictions))
+	}
+}
+
+func testAuditEvents(t *testing.T, repo Repo) {
+	now := time.Now().UTC()
+	event1 := mustAddAuditEvent(t, repo, api.AuditEvent{
+		ID:       "EventID",
+		Actor:    "Actor",
+		Action:   api.USER_ACTION_CREATE_USER,
+		Urn:      "urn:iws:iam::user/path/User",
+		After:    []byte(`{"id":"UserID","path":"/path/"}`),
+		CreateAt: now.Add(-2 * time.Hour),
+	})
+	event2 := mustAddAuditEvent(t, repo, api.AuditEvent{
+		ID:        "EventID2",
+		Actor:     "Actor",
+		RequestID: "RequestID",
+		Action:    api.USER_ACTION_UPDATE_USER,
+		Urn:       "urn:iws:iam::user/path2/User",
+		Before:    []byte(`{"id":"UserID","path":"/path/"}`),
+		After:     []byte(`{"id":"UserID","path":"/path2/"}`),
+		CreateAt:  now.Add(-time.Hour),
+	})
+	event3 := mustAddAuditEvent(t, repo, api.AuditEvent{
+		ID:       "EventID3",
+		Actor:    "Actor2",
+		Action:   api.GROUP_ACTION_DELETE_GROUP,
+		Urn:      "urn:iws:iam:Org:group/path/Group",
+		Before:   []byte(`{"id":"GroupID"}`),
+		CreateAt: now,
+	})
+
+	checkErrorCode(t, "AddAuditEvent duplicated", repo.AddAuditEvent(*event1), database.INTERNAL_ERROR)
+
+	testcases := map[string]struct {
+		filter        *api.AuditFilter
+		expected      []api.AuditEvent
+		expectedTotal int
+	}{
+		"All": {
+			filter:        &api.AuditFilter{},
+			expected:      []api.AuditEvent{*event3, *event2, *event1},
+			expectedTotal: 3,
+		},
+		"Actor": {
+			filter:        &api.AuditFilter{Actor: "Actor2"},
+			expected:      []api.AuditEvent{*event3},
+			expectedTotal: 1,
+		},
+		"Action": {
+			filter:        &api.AuditFilter{Action: api.USER_ACTION_CREATE_USER},
+			expected:      []api.AuditEvent{*event1},
+			expectedTotal: 1,
+		},
+		"UrnPrefix": {
+			filter:        &api.AuditFilter{UrnPrefix: "urn:iws:iam::user/path/"},
+			expected:      []api.AuditEvent{*event1},
+			expectedTotal: 1,
+		},
+		"Dates": {
+			filter:        &api.AuditFilter{From: now.Add(-90 * time.Minute), To: now.Add(-30 * time.Minute)},
+			expected:      []api.AuditEvent{*event2},
+			expectedTotal: 1,
+		},
+		"Pagination": {
+			filter:        &api.AuditFilter{Offset: 1, Limit: 1},
+			expected:      []api.AuditEvent{*event2},
+			expectedTotal: 3,
+		},
+		"NoResults": {
+			filter:        &api.AuditFilter{Actor: "Unknown"},
+			expected:      []api.AuditEvent{},
+			expectedTotal: 0,
+		},
+	}
+
+	for n, test := range testcases {
+		events, total, err := repo.GetAuditEventsFiltered(test.filter)
+		checkResponse(t, "GetAuditEventsFiltered "+n, err, test.expected, events)
+		checkResponse(t, "GetAuditEventsFiltered total "+n, err, test.expectedTotal, total)
+	}
+}
+
+func testImportChanges(t *testing.T, repo Repo) {
+	statements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"iam:*"},
+			Resources: []string{"urn:everything:*"},
+		},
+	}
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	user2 := mustAddUser(t, repo, "UserID2", "ExternalID2", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+	group2 := mustAddGroup(t, repo, "GroupID2", "Org", "Name2", "/path/")
+	policy := mustAddPolicy(t, repo, "PolicyID", "Org", "Name", "/path/", statements)
+	policy2 := mustAddPolicy(t, repo, "PolicyID2", "Org", "Name2", "/path/", statements)
+	mustRun(t, repo.AddMember(user.ID, group.ID))
+	mustRun(t, repo.AddMember(user2.ID, group.ID))
+	mustRun(t, repo.AttachPolicy(group.ID, policy.ID))
+	mustRun(t, repo.AttachPolicyToUser(user.ID, policy2.ID))
+
+	newStatements := []api.Statement{
+		{
+			Effect:    "deny",
+			Actions:   []string{"iam:GetUser"},
+			Resources: []string{"urn:everything:*"},
+		},
+	}
+	updatedUser := *user
+	updatedUser.Path = "/newpath/"
+	updatedUser.Urn = "NewUrn"
+	updatedGroup := *group
+	updatedGroup.Path = "/newpath/"
+	updatedGroup.Urn = "NewUrn"
+	updatedPolicy := *policy
+	updatedPolicy.Path = "/newpath/"
+	updatedPolicy.Urn = "NewUrn"
+	updatedPolicy.Statements = &newStatements
+	user3 := api.User{
+		ID:         "UserID3",
+		ExternalID: "ExternalID3",
+		Path:       "/path/",
+		Urn:        api.CreateUrn("", api.RESOURCE_USER, "/path/", "ExternalID3"),
+		CreateAt:   time.Unix(0, time.Now().UnixNano()).UTC(),
+	}
+	group3 := api.Group{
+		ID:       "GroupID3",
+		Name:     "Name3",
+		Path:     "/path/",
+		Org:      "Org",
+		Urn:      api.CreateUrn("Org", api.RESOURCE_GROUP, "/path/", "Name3"),
+		CreateAt: time.Unix(0, time.Now().UnixNano()).UTC(),
+	}
+	policy3 := api.Policy{
+		ID:         "PolicyID3",
+		Name:       "Name3",
+		Path:       "/path/",
+		Org:        "Org",
+		Urn:        api.CreateUrn("Org", api.RESOURCE_POLICY, "/path/", "Name3"),
+		CreateAt:   time.Unix(0, time.Now().UnixNano()).UTC(),
+		Statements: &statements,
+	}
+
+	err := repo.ApplyImportChanges(api.ImportChanges{
+		AddedUsers:           []api.User{user3},
+		UpdatedUsers:         []api.User{updatedUser},
+		RemovedUsers:         []api.User{*user2},
+		AddedGroups:          []api.Group{group3},
+		UpdatedGroups:        []api.Group{updatedGroup},
+		RemovedGroups:        []api.Group{*group2},
+		AddedPolicies:        []api.Policy{policy3},
+		UpdatedPolicies:      []api.Policy{updatedPolicy},
+		RemovedPolicies:      []api.Policy{*policy2},
+		AddedMembers:         []api.ImportMember{{User: &user3, Group: group}},
+		RemovedMembers:       []api.ImportMember{{User: user, Group: group}},
+		AddedGroupPolicies:   []api.ImportGroupPolicy{{Group: &group3, Policy: &policy3}},
+		RemovedGroupPolicies: []api.ImportGroupPolicy{{Group: group, Policy: policy}},
+		AddedUserPolicies:    []api.ImportUserPolicy{{User: user, Policy: &policy3}},
+	})
+	mustRun(t, err)
+
+	// Users
+	received, err := repo.GetUserByExternalID("ExternalID")
+	checkResponse(t, "GetUserByExternalID updated", err, &updatedUser, received)
+	received, err = repo.GetUserByExternalID("ExternalID3")
+	checkResponse(t, "GetUserByExternalID added", err, &user3, received)
+	_, err = repo.GetUserByExternalID("ExternalID2")
+	checkErrorCode(t, "GetUserByExternalID removed", err, database.USER_NOT_FOUND)
+
+	// Groups
+	receivedGroup, err := repo.GetGroupByName("Org", "Name")
+	checkResponse(t, "GetGroupByName updated", err, &updatedGroup, receivedGroup)
+	receivedGroup, err = repo.GetGroupByName("Org", "Name3")
+	checkResponse(t, "GetGroupByName added", err, &group3, receivedGroup)
+	_, err = repo.GetGroupByName("Org", "Name2")
+	checkErrorCode(t, "GetGroupByName removed", err, database.GROUP_NOT_FOUND)
+
+	// Policies
+	receivedPolicy, err := repo.GetPolicyByName("Org", "Name")
+	checkResponse(t, "GetPolicyByName updated", err, &updatedPolicy, receivedPolicy)
+	receivedPolicy, err = repo.GetPolicyByName("Org", "Name3")
+	checkResponse(t, "GetPolicyByName added", err, &policy3, receivedPolicy)
+	_, err = repo.GetPolicyByName("Org", "Name2")
+	checkErrorCode(t, "GetPolicyByName removed", err, database.POLICY_NOT_FOUND)
+
+	// Relations
+	members, _, err := repo.GetGroupMembers(group.ID, &api.Filter{})
+	checkResponse(t, "GetGroupMembers", err, []api.User{user3}, members)
+	policies, _, err := repo.GetAttachedPolicies(group.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedPolicies", err, []api.Policy{}, policies)
+	policies, _, err = repo.GetAttachedPolicies(group3.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedPolicies added", err, []api.Policy{policy3}, policies)
+	policies, _, err = repo.GetAttachedUserPolicies(user.ID, &api.Filter{})
+	checkResponse(t, "GetAttachedUserPolicies", err, []api.Policy{policy3}, policies)
+}
+
+func testImportChangesRollback(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	group := mustAddGroup(t, repo, "GroupID", "Org", "Name", "/path/")
+
+	// Duplicated user fails after group is removed
+	err := repo.ApplyImportChanges(api.ImportChanges{
+		RemovedGroups: []api.Group{*group},
+		AddedUsers:    []api.User{*user},
+	})
+	checkErrorCode(t, "ApplyImportChanges duplicated", err, database.INTERNAL_ERROR)
+
+	received, err := repo.GetGroupByName("Org", "Name")
+	checkResponse(t, "GetGroupByName after rollback", err, group, received)
+}
+
+// Aux methods
+func testAPIKeys(t *testing.T, repo Repo) {
+	user := mustAddUser(t, repo, "UserID", "ExternalID", "/path/")
+	user2 := mustAddUser(t, repo, "UserID2", "ExternalID2", "/path/")
+	now := time.Now().UTC()
+	expireAt := now.Add(time.Hour)
+	apiKey := mustAddAPIKey(t, repo, api.APIKey{
+		ID:         "APIKeyID",
+		Name:       "Name",
+		UserID:     user.ID,
+		ExternalID: user.ExternalID,
+		Hash:       "Hash",
+		CreateAt:   now,
+		RotateAt:   now,
+	})
+	apiKey2 := mustAddAPIKey(t, repo, api.APIKey{
+		ID:         "APIKeyID2",
+		Name:       "Name2",
+		UserID:     user.ID,
+		ExternalID: user.ExternalID,
+		Hash:       "Hash2",
+		CreateAt:   now.Add(time.Second),
+		RotateAt:   now.Add(time.Second),
+		ExpireAt:   &expireAt,
+	})
+	otherKey := mustAddAPIKey(t, repo, api.APIKey{
+		ID:         "APIKeyID3",
+		Name:       "Name",
+		UserID:     user2.ID,
+		ExternalID: user2.ExternalID,
+		Hash:       "Hash3",
+		CreateAt:   now,
+		RotateAt:   now,
+	})
+
+	_, err := repo.AddAPIKey(*apiKey)
+	checkErrorCode(t, "AddAPIKey duplicated", err, database.INTERNAL_ERROR)
+
+	received, err := repo.GetAPIKeyByID(apiKey2.ID)
+	checkResponse(t, "GetAPIKeyByID", err, apiKey2, received)
+	_, err = repo.GetAPIKeyByID("Unknown")
+	checkError(t, "GetAPIKeyByID unknown", err, &database.Error{
+		Code:    database.API_KEY_NOT_FOUND,
+		Message: "API key with id Unknown not found",
+	})
+
+	apiKeys, total, err := repo.GetAPIKeysByUserID(user.ID, &api.Filter{})
+	checkResponse(t, "GetAPIKeysByUserID", err, []api.APIKey{*apiKey, *apiKey2}, apiKeys)
+	checkResponse(t, "GetAPIKeysByUserID total", err, 2, total)
+	apiKeys, total, err = repo.GetAPIKeysByUserID(user.ID, &api.Filter{Offset: 1, Limit: 1})
+	checkResponse(t, "GetAPIKeysByUserID paginated", err, []api.APIKey{*apiKey2}, apiKeys)
+	checkResponse(t, "GetAPIKeysByUserID paginated total", err, 2, total)
+
+	// Update sets and clears optional dates
+	revokeAt := now.Add(2 * time.Second)
+	rotateAt := now.Add(3 * time.Second)
+	keyToUpdate := *apiKey2
+	keyToUpdate.Hash = "NewHash"
+	keyToUpdate.RotateAt = rotateAt
+	keyToUpdate.ExpireAt = nil
+	keyToUpdate.RevokeAt = &revokeAt
+	updated, err := repo.UpdateAPIKey(keyToUpdate)
+	expected := mustStoredAPIKey(keyToUpdate)
+	checkResponse(t, "UpdateAPIKey", err, expected, updated)
+	received, err = repo.GetAPIKeyByID(apiKey2.ID)
+	checkResponse(t, "GetAPIKeyByID after update", err, expected, received)
+	_, err = repo.UpdateAPIKey(api.APIKey{ID: "Unknown"})
+	checkErrorCode(t, "UpdateAPIKey unknown", err, database.API_KEY_NOT_FOUND)
+
+	// Keys are removed with their user
+	mustRun(t, repo.RemoveUser(user.ID))
+	apiKeys, total, err = repo.GetAPIKeysByUserID(user.ID, &api.Filter{})
+	checkResponse(t, "GetAPIKeysByUserID after remove", err, []api.APIKey{}, apiKeys)
+	checkResponse(t, "GetAPIKeysByUserID total after remove", err, 0, total)
+	_, err = repo.GetAPIKeyByID(apiKey.ID)
+	checkErrorCode(t, "GetAPIKeyByID after remove", err, database.API_KEY_NOT_FOUND)
+	received, err = repo.GetAPIKeyByID(otherKey.ID)
+	checkResponse(t, "GetAPIKeyByID of other user after remove", err, otherKey, received)
+}
+
+func mustRun(t *testing.T, err error) {
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+}
+
+func mustAddUser(t *testing.T, repo Repo, id string, externalID string, path string) *api.User {
+	user, err := repo.AddUser(api.User{
+		ID:         id,
+		ExternalID: externalID,
+		Path:       path,
+		Urn:        api.CreateUrn("", api.RESOURCE_USER, path, externalID),
+		CreateAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error adding user %v: %v", id, err)
+	}
+	return user
+}
+
+func mustAddGroup(t *testing.T, repo Repo, id string, org string, name string, path string) *api.Group {
+	group, err := repo.AddGroup(api.Group{
+		ID:       id,
+		Name:     name,
+		Path:     path,
+		Org:      org,
+		Urn:      api.CreateUrn(org, api.RESOURCE_GROUP, path, name),
+		CreateAt: time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error adding group %v: %v", id, err)
+	}
+	return group
+}
+
+func mustAddPolicy(t *testing.T, repo Repo, id string, org string, name string, path string, statements []api.Statement) *api.Policy {
+	policy, err := repo.AddPolicy(api.Policy{
+		ID:         id,
+		Name:       name,
+		Path:       path,
+		Org:        org,
+		Urn:        api.CreateUrn(org, api.RESOURCE_POLICY, path, name),
+		CreateAt:   time.Now().UTC(),
+		Statements: &statements,
+	}, api.PolicyVersion{
+		Name:       name,
+		Path:       path,
+		Statements: &statements,
+		CreateAt:   time.Now().UTC(),
+	})
+	if err != nil {
+		t.Fatalf("Unexpected error adding policy %v: %v", id, err)
+	}
+	return policy
+}
+
+func mustAddAuditEvent(t *testing.T, repo Repo, event api.AuditEvent) *api.AuditEvent {
+	event.CreateAt = time.Unix(0, event.CreateAt.UnixNano()).UTC()
+	if err := repo.AddAuditEvent(event); err != nil {
+		t.Fatalf("Unexpected error adding audit event %v: %v", event.ID, err)
+	}
+	return &event
+}
+
+func mustAddAPIKey(t *testing.T, repo Repo, apiKey api.APIKey) *api.APIKey {
+	added, err := repo.AddAPIKey(apiKey)
+	if err != nil {
+		t.Fatalf("Unexpected error adding API key %v: %v", apiKey.ID, err)
+	}
+	checkResponse(t, "AddAPIKey", nil, mustStoredAPIKey(apiKey), added)
+	return added
+}
+
+// API key with dates with the precision stored in database
+func mustStoredAPIKey(apiKey api.APIKey) *api.APIKey {
+	apiKey.CreateAt = time.Unix(0, apiKey.CreateAt.UnixNano()).UTC()
+	apiKey.RotateAt = time.Unix(0, apiKey.RotateAt.UnixNano()).UTC()
+	if apiKey.ExpireAt != nil {
+		expireAt := time.Unix(0, apiKey.ExpireAt.UnixNano()).UTC()
+		apiKey.ExpireAt = &expireAt
+	}
+	if apiKey.RevokeAt != nil {
+		revokeAt := time.Unix(0, apiKey.RevokeAt.UnixNano()).UTC()
+		apiKey.RevokeAt = &revokeAt
+	}
+	return &apiKey
+}
+
+// Statements order isn't defined, so they are sorted before comparing them
+type byOrigin []api.EffectiveStatement
+
+func (s byOrigin) Len() int      { return len(s) }
+func (s byOrigin) Swap(i, j int) { s[i], s[j] = s[j], s[i] }
+func (s byOrigin) Less(i, j int) bool {
+	return effectiveStatementKey(s[i]) < effectiveStatementKey(s[j])
+}
+
+func sortEffectiveStatements(statements []api.EffectiveStatement) {
+	sort.Sort(byOrigin(statements))
+}
+
+// Group, policy and statement as text, without the group pointer address
+func effectiveStatementKey(statement api.EffectiveStatement) string {
+	return fmt.Sprintf("%v %v", attachmentKey(statement), statement.Statement.String())
+}
+
+func attachmentKey(statement api.EffectiveStatement) string {
+	group := api.GroupIdentity{}
+	if statement.Group != nil {
+		group = *statement.Group
+	}
+	return fmt.Sprintf("%v %v", group, statement.Policy)
+}
+
+// Statements of the same group or user attachment of a policy must be together
+func checkAttachmentsTogether(t *testing.T, name string, statements []api.EffectiveStatement) {
+	seen := map[string]bool{}
+	for i, statement := range statements {
+		key := attachmentKey(statement)
+		if i > 0 && key == attachmentKey(statements[i-1]) {
+			continue
+		}
+		if seen[key] {
+			t.Errorf("%v. Statements of attachment %v are not together: %v", name, key, statements)
+			return
+		}
+		seen[key] = true
+	}
+}
+
+// Restrictions with sorted urns, as they depend on the order of statements
+func sortRestrictions(restrictions *api.Restrictions) *api.Restrictions {
+	sorted := func(urns []string) []string {
+		result := append([]string{}, urns...)
+		sort.Strings(result)
+		return result
+	}
+	return &api.Restrictions{
+		AllowedUrnPrefixes: sorted(restrictions.AllowedUrnPrefixes),
+		AllowedFullUrns:    sorted(restrictions.AllowedFullUrns),
+		DeniedUrnPrefixes:  sorted(restrictions.DeniedUrnPrefixes),
+		DeniedFullUrns:     sorted(restrictions.DeniedFullUrns),
+	}
+}
+
+func checkResponse(t *testing.T, name string, err error, expected interface{}, received interface{}) {
+	if err != nil {
+		t.Errorf("%v failed. Unexpected error: %v", name, err)
+		return
+	}
+	if diff := pretty.Compare(received, expected); diff != "" {
+		t.Errorf("%v failed. Received different responses (received/wanted) %v", name, diff)
+	}
+}
+
+func checkError(t *testing.T, name string, err error, expected *database.Error) {
+	dbError, ok := err.(*database.Error)
+	if !ok {
+		t.Errorf("%v failed. Unexpected error type: %v", name, err)
+		return
+	}
+	if diff := pretty.Compare(dbError, expected); diff != "" {
+		t.Errorf("%v failed. Received different error response (received/wanted) %v", name, diff)
+	}
+}
+
+func checkErrorCode(t *testing.T, name string, err error, expectedCode string) {
+	dbError, ok := err.(*database.Error)
+	if !ok {
+		t.Errorf("%v failed. Unexpected error type: %v", name, fmt.Sprint(err))
+		return
+	}
+	if dbError.Code != expectedCode {
+		t.Errorf("%v failed. Received error code %v, expected %v", name, dbError.Code, expectedCode)
+	}
+}
@@ -20,6 +20,12 @@ const (
 
 	// Policy Codes
 	POLICY_NOT_FOUND = "PolicyNotFound"
+
+	// Policy Version Codes
+	POLICY_VERSION_NOT_FOUND = "PolicyVersionNotFound"
+
+	// API Key Codes
+	API_KEY_NOT_FOUND = "APIKeyNotFound"
 )
 
 type Error struct {
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// API KEY REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) AddAPIKey(apiKey api.APIKey) (*api.APIKey, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check unique constraints
+	for _, k := range m.apiKeys {
+		if k.ID == apiKey.ID {
+			return nil, uniqueViolationError("api_keys", "id", apiKey.ID)
+		}
+	}
+
+	storedKey := storedAPIKey(apiKey)
+	m.apiKeys = append(m.apiKeys, storedKey)
+
+	return copyAPIKey(storedKey), nil
+}
+
+func (m *MemoryRepo) GetAPIKeyByID(id string) (*api.APIKey, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, k := range m.apiKeys {
+		if k.ID == id {
+			return copyAPIKey(k), nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.API_KEY_NOT_FOUND,
+		Message: fmt.Sprintf("API key with id %v not found", id),
+	}
+}
+
+func (m *MemoryRepo) GetAPIKeysByUserID(userID string, filter *api.Filter) ([]api.APIKey, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	// Keys are stored in order
+	apiKeys := []api.APIKey{}
+	for _, k := range m.apiKeys {
+		if k.UserID == userID {
+			apiKeys = append(apiKeys, *copyAPIKey(k))
+		}
+	}
+
+	start, end := getPage(len(apiKeys), filter)
+	return apiKeys[start:end], len(apiKeys), nil
+}
+
+func (m *MemoryRepo) UpdateAPIKey(apiKey api.APIKey) (*api.APIKey, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	for i, k := range m.apiKeys {
+		if k.ID == apiKey.ID {
+			m.apiKeys[i].Hash = apiKey.Hash
+			m.apiKeys[i].RotateAt = storedTime(apiKey.RotateAt)
+			m.apiKeys[i].ExpireAt = storedOptionalTime(apiKey.ExpireAt)
+			m.apiKeys[i].RevokeAt = storedOptionalTime(apiKey.RevokeAt)
+			return copyAPIKey(m.apiKeys[i]), nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.API_KEY_NOT_FOUND,
+		Message: fmt.Sprintf("API key with id %v not found", apiKey.ID),
+	}
+}
+
+// PRIVATE HELPER METHODS
+
+// API key with times rounded as stored in database
+func storedAPIKey(apiKey api.APIKey) api.APIKey {
+	apiKey.CreateAt = storedTime(apiKey.CreateAt)
+	apiKey.RotateAt = storedTime(apiKey.RotateAt)
+	apiKey.ExpireAt = storedOptionalTime(apiKey.ExpireAt)
+	apiKey.RevokeAt = storedOptionalTime(apiKey.RevokeAt)
+	return apiKey
+}
+
+// Copy a stored API key, so its optional times can't be modified by callers
+func copyAPIKey(apiKey api.APIKey) *api.APIKey {
+	apiKey.ExpireAt = storedOptionalTime(apiKey.ExpireAt)
+	apiKey.RevokeAt = storedOptionalTime(apiKey.RevokeAt)
+	return &apiKey
+}
+
+func storedOptionalTime(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	stored := storedTime(*t)
+	return &stored
+}
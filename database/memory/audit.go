@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/Tecsisa/foulkon/api"
+)
+
+// AUDIT REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) AddAuditEvent(event api.AuditEvent) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check unique constraints
+	for _, e := range m.auditEvents {
+		if e.ID == event.ID {
+			return uniqueViolationError("audit_events", "id", event.ID)
+		}
+	}
+
+	event.Before = append([]byte(nil), event.Before...)
+	event.After = append([]byte(nil), event.After...)
+	event.CreateAt = storedTime(event.CreateAt)
+	m.auditEvents = append(m.auditEvents, event)
+
+	return nil
+}
+
+func (m *MemoryRepo) GetAuditEventsFiltered(filter *api.AuditFilter) ([]api.AuditEvent, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	// Newest events first
+	events := []api.AuditEvent{}
+	for i := len(m.auditEvents) - 1; i >= 0; i-- {
+		e := m.auditEvents[i]
+		if len(filter.Actor) > 0 && e.Actor != filter.Actor {
+			continue
+		}
+		if len(filter.Action) > 0 && e.Action != filter.Action {
+			continue
+		}
+		if !strings.HasPrefix(e.Urn, filter.UrnPrefix) {
+			continue
+		}
+		if !filter.From.IsZero() && e.CreateAt.Before(filter.From) {
+			continue
+		}
+		if !filter.To.IsZero() && e.CreateAt.After(filter.To) {
+			continue
+		}
+		events = append(events, e)
+	}
+
+	sort.Stable(byNewest(events))
+
+	start, end := getPage(len(events), &api.Filter{Offset: filter.Offset, Limit: filter.Limit})
+	return events[start:end], len(events), nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Sort audit events by date, newest first
+type byNewest []api.AuditEvent
+
+func (e byNewest) Len() int           { return len(e) }
+func (e byNewest) Swap(i, j int)      { e[i], e[j] = e[j], e[i] }
+func (e byNewest) Less(i, j int) bool { return e[i].CreateAt.After(e[j].CreateAt) }
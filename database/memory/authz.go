@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"github.com/Tecsisa/foulkon/api"
+)
+
+// AUTHZ REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) GetEffectiveStatements(externalID string) ([]api.EffectiveStatement, error) {
+	user, err := m.GetUserByExternalID(externalID)
+	if err != nil {
+		return nil, err
+	}
+
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	// Statements of every policy attachment, group attachments first
+	statements := []api.EffectiveStatement{}
+	addStatements := func(group *api.GroupIdentity, policyID string) {
+		// Relations without policy are ignored like database join does
+		policy, err := m.getPolicyByID(policyID)
+		if err != nil {
+			return
+		}
+		for _, statement := range *policy.Statements {
+			statements = append(statements, api.EffectiveStatement{
+				Group: group,
+				Policy: api.PolicyIdentity{
+					Org:  policy.Org,
+					Name: policy.Name,
+				},
+				Statement: statement,
+			})
+		}
+	}
+	for _, userGroup := range m.groupUserRelations {
+		if userGroup.UserID != user.ID {
+			continue
+		}
+		group, err := m.getGroupByID(userGroup.GroupID)
+		if err != nil {
+			continue
+		}
+		for _, groupPolicy := range m.groupPolicyRelations {
+			if groupPolicy.GroupID == group.ID {
+				addStatements(&api.GroupIdentity{
+					Org:  group.Org,
+					Name: group.Name,
+				}, groupPolicy.PolicyID)
+			}
+		}
+	}
+	for _, userPolicy := range m.userPolicyRelations {
+		if userPolicy.UserID == user.ID {
+			addStatements(nil, userPolicy.PolicyID)
+		}
+	}
+
+	return statements, nil
+}
@@ -0,0 +1,283 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// GROUP REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) AddGroup(group api.Group) (*api.Group, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check unique constraints
+	for _, g := range m.groups {
+		switch {
+		case g.ID == group.ID:
+			return nil, uniqueViolationError("groups", "id", group.ID)
+		case g.Urn == group.Urn:
+			return nil, uniqueViolationError("groups", "urn", group.Urn)
+		}
+	}
+
+	group.CreateAt = storedTime(group.CreateAt)
+	m.groups = append(m.groups, group)
+
+	return &group, nil
+}
+
+func (m *MemoryRepo) GetGroupByName(org string, name string) (*api.Group, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, g := range m.groups {
+		if g.Org == org && g.Name == name {
+			return &g, nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.GROUP_NOT_FOUND,
+		Message: fmt.Sprintf("Group with organization %v and name %v not found", org, name),
+	}
+}
+
+func (m *MemoryRepo) GetGroupsFiltered(org string, filter *api.Filter) ([]api.Group, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	groups := []api.Group{}
+	for _, g := range m.groups {
+		if (len(org) < 1 || g.Org == org) && hasPathPrefix(g.Path, filter) {
+			groups = append(groups, g)
+		}
+	}
+
+	start, end := getPage(len(groups), filter)
+	return groups[start:end], len(groups), nil
+}
+
+func (m *MemoryRepo) UpdateGroup(group api.Group, newName string, newPath string, urn string) (*api.Group, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check unique constraints
+	for _, g := range m.groups {
+		if g.ID != group.ID && g.Urn == urn {
+			return nil, uniqueViolationError("groups", "urn", urn)
+		}
+	}
+
+	group.Name = newName
+	group.Path = newPath
+	group.Urn = urn
+	group.CreateAt = storedTime(group.CreateAt)
+	for i, g := range m.groups {
+		if g.ID == group.ID {
+			m.groups[i].Name = newName
+			m.groups[i].Path = newPath
+			m.groups[i].Urn = urn
+		}
+	}
+
+	return &group, nil
+}
+
+func (m *MemoryRepo) RemoveGroup(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Delete group
+	groups := []api.Group{}
+	for _, g := range m.groups {
+		if g.ID != id {
+			groups = append(groups, g)
+		}
+	}
+	m.groups = groups
+
+	// Delete all group relations
+	groupUserRelations := []groupUserRelation{}
+	for _, r := range m.groupUserRelations {
+		if r.GroupID != id {
+			groupUserRelations = append(groupUserRelations, r)
+		}
+	}
+	m.groupUserRelations = groupUserRelations
+
+	// Delete all group policy relations
+	groupPolicyRelations := []groupPolicyRelation{}
+	for _, r := range m.groupPolicyRelations {
+		if r.GroupID != id {
+			groupPolicyRelations = append(groupPolicyRelations, r)
+		}
+	}
+	m.groupPolicyRelations = groupPolicyRelations
+
+	return nil
+}
+
+func (m *MemoryRepo) AddMember(userID string, groupID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	relation := groupUserRelation{
+		UserID:  userID,
+		GroupID: groupID,
+	}
+	for _, r := range m.groupUserRelations {
+		if r == relation {
+			return uniqueViolationError("group_user_relations", "user_id, group_id", userID+", "+groupID)
+		}
+	}
+	m.groupUserRelations = append(m.groupUserRelations, relation)
+
+	return nil
+}
+
+func (m *MemoryRepo) RemoveMember(userID string, groupID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	relations := []groupUserRelation{}
+	for _, r := range m.groupUserRelations {
+		if r.UserID != userID || r.GroupID != groupID {
+			relations = append(relations, r)
+		}
+	}
+	m.groupUserRelations = relations
+
+	return nil
+}
+
+func (m *MemoryRepo) IsMemberOfGroup(userID string, groupID string) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, r := range m.groupUserRelations {
+		if r.UserID == userID && r.GroupID == groupID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *MemoryRepo) GetGroupMembers(groupID string, filter *api.Filter) ([]api.User, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	relations := []groupUserRelation{}
+	for _, r := range m.groupUserRelations {
+		if r.GroupID == groupID {
+			relations = append(relations, r)
+		}
+	}
+
+	start, end := getPage(len(relations), filter)
+	users := []api.User{}
+	for _, r := range relations[start:end] {
+		user, err := m.getUserByID(r.UserID)
+		if err != nil {
+			return nil, len(relations), &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		users = append(users, *user)
+	}
+
+	return users, len(relations), nil
+}
+
+func (m *MemoryRepo) AttachPolicy(groupID string, policyID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	relation := groupPolicyRelation{
+		GroupID:  groupID,
+		PolicyID: policyID,
+	}
+	for _, r := range m.groupPolicyRelations {
+		if r == relation {
+			return uniqueViolationError("group_policy_relations", "group_id, policy_id", groupID+", "+policyID)
+		}
+	}
+	m.groupPolicyRelations = append(m.groupPolicyRelations, relation)
+
+	return nil
+}
+
+func (m *MemoryRepo) DetachPolicy(groupID string, policyID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	relations := []groupPolicyRelation{}
+	for _, r := range m.groupPolicyRelations {
+		if r.GroupID != groupID || r.PolicyID != policyID {
+			relations = append(relations, r)
+		}
+	}
+	m.groupPolicyRelations = relations
+
+	return nil
+}
+
+func (m *MemoryRepo) IsAttachedToGroup(groupID string, policyID string) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, r := range m.groupPolicyRelations {
+		if r.GroupID == groupID && r.PolicyID == policyID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *MemoryRepo) GetAttachedPolicies(groupID string, filter *api.Filter) ([]api.Policy, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	relations := []groupPolicyRelation{}
+	for _, r := range m.groupPolicyRelations {
+		if r.GroupID == groupID {
+			relations = append(relations, r)
+		}
+	}
+
+	start, end := getPage(len(relations), filter)
+	policies := []api.Policy{}
+	for _, r := range relations[start:end] {
+		policy, err := m.getPolicyByID(r.PolicyID)
+		if err != nil {
+			return nil, len(relations), &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		policies = append(policies, *policy)
+	}
+
+	return policies, len(relations), nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve group by identifier. Caller must hold the lock.
+func (m *MemoryRepo) getGroupByID(id string) (*api.Group, error) {
+	for _, g := range m.groups {
+		if g.ID == id {
+			return &g, nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.GROUP_NOT_FOUND,
+		Message: fmt.Sprintf("Group with id %v not found", id),
+	}
+}
@@ -0,0 +1,131 @@
+package memory
+
+import (
+	"github.com/Tecsisa/foulkon/api"
+)
+
+// IMPORT REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) ApplyImportChanges(changes api.ImportChanges) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Apply changes over a copy of the tables, that replaces them only if all changes are applied.
+	// Statements and audit events are never modified once stored, so they can be shared with the copy.
+	tx := &MemoryRepo{
+		users:                append([]api.User{}, m.users...),
+		groups:               append([]api.Group{}, m.groups...),
+		policies:             append([]api.Policy{}, m.policies...),
+		policyVersions:       append([]api.PolicyVersion{}, m.policyVersions...),
+		groupUserRelations:   append([]groupUserRelation{}, m.groupUserRelations...),
+		groupPolicyRelations: append([]groupPolicyRelation{}, m.groupPolicyRelations...),
+		userPolicyRelations:  append([]userPolicyRelation{}, m.userPolicyRelations...),
+		auditEvents:          m.auditEvents,
+		apiKeys:              append([]api.APIKey{}, m.apiKeys...),
+	}
+	if err := tx.applyImportChanges(changes); err != nil {
+		return err
+	}
+
+	m.users = tx.users
+	m.groups = tx.groups
+	m.policies = tx.policies
+	m.policyVersions = tx.policyVersions
+	m.groupUserRelations = tx.groupUserRelations
+	m.groupPolicyRelations = tx.groupPolicyRelations
+	m.userPolicyRelations = tx.userPolicyRelations
+	m.apiKeys = tx.apiKeys
+
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Apply changes in the same order as the database: removals first, so elements can be created again
+func (m *MemoryRepo) applyImportChanges(changes api.ImportChanges) error {
+	for _, r := range changes.RemovedMembers {
+		if err := m.RemoveMember(r.User.ID, r.Group.ID); err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.RemovedGroupPolicies {
+		if err := m.DetachPolicy(r.Group.ID, r.Policy.ID); err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.RemovedUserPolicies {
+		if err := m.DetachPolicyFromUser(r.User.ID, r.Policy.ID); err != nil {
+			return err
+		}
+	}
+	for _, p := range changes.RemovedPolicies {
+		if err := m.RemovePolicy(p.ID); err != nil {
+			return err
+		}
+	}
+	for _, g := range changes.RemovedGroups {
+		if err := m.RemoveGroup(g.ID); err != nil {
+			return err
+		}
+	}
+	for _, u := range changes.RemovedUsers {
+		if err := m.RemoveUser(u.ID); err != nil {
+			return err
+		}
+	}
+
+	for _, u := range changes.AddedUsers {
+		if _, err := m.AddUser(u); err != nil {
+			return err
+		}
+	}
+	for _, u := range changes.UpdatedUsers {
+		if _, err := m.UpdateUser(u, u.Path, u.Urn); err != nil {
+			return err
+		}
+	}
+	for _, g := range changes.AddedGroups {
+		if _, err := m.AddGroup(g); err != nil {
+			return err
+		}
+	}
+	for _, g := range changes.UpdatedGroups {
+		if _, err := m.UpdateGroup(g, g.Name, g.Path, g.Urn); err != nil {
+			return err
+		}
+	}
+	for _, p := range changes.AddedPolicies {
+		if _, err := m.addPolicy(p); err != nil {
+			return err
+		}
+	}
+	for _, p := range changes.UpdatedPolicies {
+		statements := []api.Statement{}
+		if p.Statements != nil {
+			statements = *p.Statements
+		}
+		if _, err := m.updatePolicy(p, p.Name, p.Path, p.Urn, statements); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.PolicyVersions {
+		m.addPolicyVersion(v.Previous, v.Version.PolicyID, v.Version)
+	}
+
+	for _, r := range changes.AddedMembers {
+		if err := m.AddMember(r.User.ID, r.Group.ID); err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.AddedGroupPolicies {
+		if err := m.AttachPolicy(r.Group.ID, r.Policy.ID); err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.AddedUserPolicies {
+		if err := m.AttachPolicyToUser(r.User.ID, r.Policy.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package memory
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// MemoryRepo implements all repositories storing data in memory. It is safe for concurrent use,
+// and it is meant for tests and local development because data is lost when the process ends.
+type MemoryRepo struct {
+	mutex sync.RWMutex
+
+	// Tables, in insertion order
+	users                []api.User
+	groups               []api.Group
+	policies             []api.Policy
+	policyVersions       []api.PolicyVersion
+	groupUserRelations   []groupUserRelation
+	groupPolicyRelations []groupPolicyRelation
+	userPolicyRelations  []userPolicyRelation
+	auditEvents          []api.AuditEvent
+	apiKeys              []api.APIKey
+}
+
+// Group-Users Relationship
+type groupUserRelation struct {
+	UserID  string
+	GroupID string
+}
+
+// Group-Policies Relationship
+type groupPolicyRelation struct {
+	GroupID  string
+	PolicyID string
+}
+
+// User-Policies Relationship
+type userPolicyRelation struct {
+	UserID   string
+	PolicyID string
+}
+
+// NewMemoryRepo returns an empty repository
+func NewMemoryRepo() *MemoryRepo {
+	return &MemoryRepo{}
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve start and end indexes of the page requested by filter for a table with total elements.
+// Zero limit and offset means no pagination.
+func getPage(total int, filter *api.Filter) (int, int) {
+	if filter == nil || (filter.Limit <= 0 && filter.Offset <= 0) {
+		return 0, total
+	}
+	start := filter.Offset
+	if start > total {
+		start = total
+	}
+	end := total
+	if filter.Limit >= 0 && start+filter.Limit < total {
+		end = start + filter.Limit
+	}
+	return start, end
+}
+
+// Check if path matches the path prefix requested by filter. Empty prefix matches all paths.
+func hasPathPrefix(path string, filter *api.Filter) bool {
+	return filter == nil || strings.HasPrefix(path, filter.PathPrefix)
+}
+
+// Error returned when a unique constraint is violated
+func uniqueViolationError(table string, key string, value string) error {
+	return &database.Error{
+		Code:    database.INTERNAL_ERROR,
+		Message: fmt.Sprintf("Duplicate value %v for key %v in %v", value, key, table),
+	}
+}
+
+// Transform a date like database does, losing monotonic clock and location
+func storedTime(t time.Time) time.Time {
+	return time.Unix(0, t.UnixNano()).UTC()
+}
+
+// Copy statements so stored ones can't be modified by callers
+func copyStatements(statements []api.Statement) []api.Statement {
+	statementsCopy := make([]api.Statement, len(statements))
+	for i, s := range statements {
+		statementsCopy[i] = api.Statement{
+			Effect:    s.Effect,
+			Actions:   append([]string{}, s.Actions...),
+			Resources: append([]string{}, s.Resources...),
+		}
+		if len(s.Conditions) > 0 {
+			conditions := make([]api.Condition, len(s.Conditions))
+			for j, c := range s.Conditions {
+				conditions[j] = api.Condition{
+					Operator: c.Operator,
+					Key:      c.Key,
+					Values:   append([]string{}, c.Values...),
+				}
+			}
+			statementsCopy[i].Conditions = conditions
+		}
+	}
+	return statementsCopy
+}
@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database/conformance"
+)
+
+func TestMemoryRepo_Conformance(t *testing.T) {
+	conformance.RunTests(t, func() conformance.Repo {
+		return NewMemoryRepo()
+	})
+}
+
+func TestMemoryRepo_ConcurrentAccess(t *testing.T) {
+	repo := NewMemoryRepo()
+	wg := sync.WaitGroup{}
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			id := fmt.Sprintf("UserID%v", i)
+			if _, err := repo.AddUser(api.User{ID: id, ExternalID: id, Path: "/path/", Urn: id, CreateAt: time.Now()}); err != nil {
+				t.Errorf("Unexpected error adding user %v: %v", id, err)
+			}
+			if _, _, err := repo.GetUsersFiltered(&api.Filter{}); err != nil {
+				t.Errorf("Unexpected error retrieving users: %v", err)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	_, total, err := repo.GetUsersFiltered(&api.Filter{})
+	if err != nil {
+		t.Fatalf("Unexpected error retrieving users: %v", err)
+	}
+	if total != 20 {
+		t.Errorf("Received different user number: %v", total)
+	}
+}
+
+func TestGetPage(t *testing.T) {
+	testcases := map[string]struct {
+		total         int
+		filter        *api.Filter
+		expectedStart int
+		expectedEnd   int
+	}{
+		"NilFilter": {
+			total:         5,
+			expectedStart: 0,
+			expectedEnd:   5,
+		},
+		"NoPagination": {
+			total:         5,
+			filter:        &api.Filter{},
+			expectedStart: 0,
+			expectedEnd:   5,
+		},
+		"FirstPage": {
+			total:         5,
+			filter:        &api.Filter{Limit: 2},
+			expectedStart: 0,
+			expectedEnd:   2,
+		},
+		"LastPage": {
+			total:         5,
+			filter:        &api.Filter{Offset: 4, Limit: 2},
+			expectedStart: 4,
+			expectedEnd:   5,
+		},
+		"OffsetOutOfRange": {
+			total:         5,
+			filter:        &api.Filter{Offset: 10, Limit: 2},
+			expectedStart: 5,
+			expectedEnd:   5,
+		},
+	}
+
+	for n, test := range testcases {
+		start, end := getPage(test.total, test.filter)
+		if start != test.expectedStart || end != test.expectedEnd {
+			t.Errorf("Test %v failed. Received page [%v, %v), expected [%v, %v)", n, start, end, test.expectedStart, test.expectedEnd)
+		}
+	}
+}
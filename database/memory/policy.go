@@ -0,0 +1,314 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// POLICY REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) AddPolicy(policy api.Policy, version api.PolicyVersion) (*api.Policy, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	created, err := m.addPolicy(policy)
+	if err != nil {
+		return nil, err
+	}
+	m.addPolicyVersion(nil, created.ID, version)
+
+	return created, nil
+}
+
+func (m *MemoryRepo) GetPolicyByName(org string, name string) (*api.Policy, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, p := range m.policies {
+		if p.Org == org && p.Name == name {
+			return copyPolicy(p), nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.POLICY_NOT_FOUND,
+		Message: fmt.Sprintf("Policy with organization %v and name %v not found", org, name),
+	}
+}
+
+func (m *MemoryRepo) GetPoliciesFiltered(org string, filter *api.Filter) ([]api.Policy, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	policies := []api.Policy{}
+	for _, p := range m.policies {
+		if (len(org) < 1 || p.Org == org) && hasPathPrefix(p.Path, filter) {
+			policies = append(policies, *copyPolicy(p))
+		}
+	}
+
+	start, end := getPage(len(policies), filter)
+	return policies[start:end], len(policies), nil
+}
+
+func (m *MemoryRepo) UpdatePolicy(policy api.Policy, name string, path string, urn string, statements []api.Statement,
+	version api.PolicyVersion) (*api.Policy, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	updated, err := m.updatePolicy(policy, name, path, urn, statements)
+	if err != nil {
+		return nil, err
+	}
+	m.addPolicyVersion(&policy, policy.ID, version)
+
+	return updated, nil
+}
+
+func (m *MemoryRepo) RemovePolicy(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Delete policy relations (group)
+	groupPolicyRelations := []groupPolicyRelation{}
+	for _, r := range m.groupPolicyRelations {
+		if r.PolicyID != id {
+			groupPolicyRelations = append(groupPolicyRelations, r)
+		}
+	}
+	m.groupPolicyRelations = groupPolicyRelations
+
+	// Delete policy relations (user)
+	userPolicyRelations := []userPolicyRelation{}
+	for _, r := range m.userPolicyRelations {
+		if r.PolicyID != id {
+			userPolicyRelations = append(userPolicyRelations, r)
+		}
+	}
+	m.userPolicyRelations = userPolicyRelations
+
+	// Delete policy versions
+	policyVersions := []api.PolicyVersion{}
+	for _, v := range m.policyVersions {
+		if v.PolicyID != id {
+			policyVersions = append(policyVersions, v)
+		}
+	}
+	m.policyVersions = policyVersions
+
+	// Delete policy with its statements
+	policies := []api.Policy{}
+	for _, p := range m.policies {
+		if p.ID != id {
+			policies = append(policies, p)
+		}
+	}
+	m.policies = policies
+
+	return nil
+}
+
+func (m *MemoryRepo) GetAttachedGroups(policyID string, filter *api.Filter) ([]api.Group, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	relations := []groupPolicyRelation{}
+	for _, r := range m.groupPolicyRelations {
+		if r.PolicyID == policyID {
+			relations = append(relations, r)
+		}
+	}
+
+	start, end := getPage(len(relations), filter)
+	groups := []api.Group{}
+	for _, r := range relations[start:end] {
+		group, err := m.getGroupByID(r.GroupID)
+		if err != nil {
+			return nil, len(relations), &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		groups = append(groups, *group)
+	}
+
+	return groups, len(relations), nil
+}
+
+func (m *MemoryRepo) GetAttachedUsers(policyID string, filter *api.Filter) ([]api.User, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	relations := []userPolicyRelation{}
+	for _, r := range m.userPolicyRelations {
+		if r.PolicyID == policyID {
+			relations = append(relations, r)
+		}
+	}
+
+	start, end := getPage(len(relations), filter)
+	users := []api.User{}
+	for _, r := range relations[start:end] {
+		user, err := m.getUserByID(r.UserID)
+		if err != nil {
+			return nil, len(relations), &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		users = append(users, *user)
+	}
+
+	return users, len(relations), nil
+}
+
+func (m *MemoryRepo) GetPolicyVersions(policyID string, filter *api.Filter) ([]api.PolicyVersion, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	// Versions are stored in order
+	versions := []api.PolicyVersion{}
+	for _, v := range m.policyVersions {
+		if v.PolicyID == policyID {
+			versions = append(versions, *copyPolicyVersion(v))
+		}
+	}
+
+	start, end := getPage(len(versions), filter)
+	return versions[start:end], len(versions), nil
+}
+
+func (m *MemoryRepo) GetPolicyVersion(policyID string, version int) (*api.PolicyVersion, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, v := range m.policyVersions {
+		if v.PolicyID == policyID && v.Version == version {
+			return copyPolicyVersion(v), nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.POLICY_VERSION_NOT_FOUND,
+		Message: fmt.Sprintf("Version %v of policy with id %v not found", version, policyID),
+	}
+}
+
+// PRIVATE HELPER METHODS
+
+// Store policy. Caller must hold the lock.
+func (m *MemoryRepo) addPolicy(policy api.Policy) (*api.Policy, error) {
+	// Check unique constraints
+	for _, p := range m.policies {
+		switch {
+		case p.ID == policy.ID:
+			return nil, uniqueViolationError("policies", "id", policy.ID)
+		case p.Urn == policy.Urn:
+			return nil, uniqueViolationError("policies", "urn", policy.Urn)
+		}
+	}
+
+	policy.CreateAt = storedTime(policy.CreateAt)
+	statements := []api.Statement{}
+	if policy.Statements != nil {
+		statements = copyStatements(*policy.Statements)
+	}
+	policy.Statements = &statements
+	m.policies = append(m.policies, policy)
+
+	return copyPolicy(policy), nil
+}
+
+// Update stored policy. Caller must hold the lock.
+func (m *MemoryRepo) updatePolicy(policy api.Policy, name string, path string, urn string, statements []api.Statement) (*api.Policy, error) {
+	// Check unique constraints
+	for _, p := range m.policies {
+		if p.ID != policy.ID && p.Urn == urn {
+			return nil, uniqueViolationError("policies", "urn", urn)
+		}
+	}
+
+	policy.Name = name
+	policy.Path = path
+	policy.Urn = urn
+	policy.CreateAt = storedTime(policy.CreateAt)
+	newStatements := copyStatements(statements)
+	policy.Statements = &newStatements
+	for i, p := range m.policies {
+		if p.ID == policy.ID {
+			m.policies[i].Name = name
+			m.policies[i].Path = path
+			m.policies[i].Urn = urn
+			m.policies[i].Statements = &newStatements
+		}
+	}
+
+	return copyPolicy(policy), nil
+}
+
+// Store a version of the policy with the next version number. Policies created before versioning don't
+// have versions, so previous stored policy is stored as a version first. Caller must hold the lock.
+func (m *MemoryRepo) addPolicyVersion(previous *api.Policy, policyID string, version api.PolicyVersion) {
+	lastVersion := 0
+	for _, v := range m.policyVersions {
+		if v.PolicyID == policyID && v.Version > lastVersion {
+			lastVersion = v.Version
+		}
+	}
+	if previous != nil && lastVersion == 0 {
+		lastVersion++
+		m.policyVersions = append(m.policyVersions, storedPolicyVersion(api.PolicyVersion{
+			PolicyID:   policyID,
+			Version:    lastVersion,
+			Name:       previous.Name,
+			Path:       previous.Path,
+			Statements: previous.Statements,
+			CreateAt:   previous.CreateAt,
+		}))
+	}
+
+	version.PolicyID = policyID
+	version.Version = lastVersion + 1
+	m.policyVersions = append(m.policyVersions, storedPolicyVersion(version))
+}
+
+// Retrieve policy by identifier. Caller must hold the lock.
+func (m *MemoryRepo) getPolicyByID(id string) (*api.Policy, error) {
+	for _, p := range m.policies {
+		if p.ID == id {
+			return copyPolicy(p), nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.POLICY_NOT_FOUND,
+		Message: fmt.Sprintf("Policy with id %v not found", id),
+	}
+}
+
+// Copy a stored policy with its statements
+func copyPolicy(policy api.Policy) *api.Policy {
+	statements := copyStatements(*policy.Statements)
+	policy.Statements = &statements
+	return &policy
+}
+
+// Copy a stored policy version with its statements
+// Prepare version to be stored, with a copy of its statements
+func storedPolicyVersion(version api.PolicyVersion) api.PolicyVersion {
+	version.CreateAt = storedTime(version.CreateAt)
+	statements := []api.Statement{}
+	if version.Statements != nil {
+		statements = copyStatements(*version.Statements)
+	}
+	version.Statements = &statements
+	return version
+}
+
+func copyPolicyVersion(version api.PolicyVersion) *api.PolicyVersion {
+	statements := copyStatements(*version.Statements)
+	version.Statements = &statements
+	return &version
+}
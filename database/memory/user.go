@@ -0,0 +1,246 @@
+package memory
+
+import (
+	"fmt"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// USER REPOSITORY IMPLEMENTATION
+
+func (m *MemoryRepo) AddUser(user api.User) (*api.User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check unique constraints
+	for _, u := range m.users {
+		switch {
+		case u.ID == user.ID:
+			return nil, uniqueViolationError("users", "id", user.ID)
+		case u.ExternalID == user.ExternalID:
+			return nil, uniqueViolationError("users", "external_id", user.ExternalID)
+		case u.Urn == user.Urn:
+			return nil, uniqueViolationError("users", "urn", user.Urn)
+		}
+	}
+
+	user.CreateAt = storedTime(user.CreateAt)
+	m.users = append(m.users, user)
+
+	return &user, nil
+}
+
+func (m *MemoryRepo) GetUserByExternalID(id string) (*api.User, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, u := range m.users {
+		if u.ExternalID == id {
+			return &u, nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.USER_NOT_FOUND,
+		Message: fmt.Sprintf("User with externalId %v not found", id),
+	}
+}
+
+func (m *MemoryRepo) GetUsersFiltered(filter *api.Filter) ([]api.User, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	users := []api.User{}
+	for _, u := range m.users {
+		if hasPathPrefix(u.Path, filter) {
+			users = append(users, u)
+		}
+	}
+
+	start, end := getPage(len(users), filter)
+	return users[start:end], len(users), nil
+}
+
+func (m *MemoryRepo) UpdateUser(user api.User, newPath string, newUrn string) (*api.User, error) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Check unique constraints
+	for _, u := range m.users {
+		if u.ID != user.ID && u.Urn == newUrn {
+			return nil, uniqueViolationError("users", "urn", newUrn)
+		}
+	}
+
+	user.Path = newPath
+	user.Urn = newUrn
+	user.CreateAt = storedTime(user.CreateAt)
+	for i, u := range m.users {
+		if u.ID == user.ID {
+			m.users[i].Path = newPath
+			m.users[i].Urn = newUrn
+		}
+	}
+
+	return &user, nil
+}
+
+func (m *MemoryRepo) RemoveUser(id string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	// Delete user
+	users := []api.User{}
+	for _, u := range m.users {
+		if u.ID != id {
+			users = append(users, u)
+		}
+	}
+	m.users = users
+
+	// Delete all user relations
+	groupUserRelations := []groupUserRelation{}
+	for _, r := range m.groupUserRelations {
+		if r.UserID != id {
+			groupUserRelations = append(groupUserRelations, r)
+		}
+	}
+	m.groupUserRelations = groupUserRelations
+
+	// Delete all user policy relations
+	userPolicyRelations := []userPolicyRelation{}
+	for _, r := range m.userPolicyRelations {
+		if r.UserID != id {
+			userPolicyRelations = append(userPolicyRelations, r)
+		}
+	}
+	m.userPolicyRelations = userPolicyRelations
+
+	// Delete all user API keys
+	apiKeys := []api.APIKey{}
+	for _, k := range m.apiKeys {
+		if k.UserID != id {
+			apiKeys = append(apiKeys, k)
+		}
+	}
+	m.apiKeys = apiKeys
+
+	return nil
+}
+
+func (m *MemoryRepo) GetGroupsByUserID(id string, filter *api.Filter) ([]api.Group, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	relations := []groupUserRelation{}
+	for _, r := range m.groupUserRelations {
+		if r.UserID == id {
+			relations = append(relations, r)
+		}
+	}
+
+	start, end := getPage(len(relations), filter)
+	groups := []api.Group{}
+	for _, r := range relations[start:end] {
+		group, err := m.getGroupByID(r.GroupID)
+		if err != nil {
+			return nil, len(relations), &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		groups = append(groups, *group)
+	}
+
+	return groups, len(relations), nil
+}
+
+func (m *MemoryRepo) AttachPolicyToUser(userID string, policyID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	relation := userPolicyRelation{
+		UserID:   userID,
+		PolicyID: policyID,
+	}
+	for _, r := range m.userPolicyRelations {
+		if r == relation {
+			return uniqueViolationError("user_policy_relations", "user_id, policy_id", userID+", "+policyID)
+		}
+	}
+	m.userPolicyRelations = append(m.userPolicyRelations, relation)
+
+	return nil
+}
+
+func (m *MemoryRepo) DetachPolicyFromUser(userID string, policyID string) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	relations := []userPolicyRelation{}
+	for _, r := range m.userPolicyRelations {
+		if r.UserID != userID || r.PolicyID != policyID {
+			relations = append(relations, r)
+		}
+	}
+	m.userPolicyRelations = relations
+
+	return nil
+}
+
+func (m *MemoryRepo) IsAttachedToUser(userID string, policyID string) (bool, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	for _, r := range m.userPolicyRelations {
+		if r.UserID == userID && r.PolicyID == policyID {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *MemoryRepo) GetAttachedUserPolicies(userID string, filter *api.Filter) ([]api.Policy, int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	relations := []userPolicyRelation{}
+	for _, r := range m.userPolicyRelations {
+		if r.UserID == userID {
+			relations = append(relations, r)
+		}
+	}
+
+	start, end := getPage(len(relations), filter)
+	policies := []api.Policy{}
+	for _, r := range relations[start:end] {
+		policy, err := m.getPolicyByID(r.PolicyID)
+		if err != nil {
+			return nil, len(relations), &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: err.Error(),
+			}
+		}
+		policies = append(policies, *policy)
+	}
+
+	return policies, len(relations), nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve user by identifier. Caller must hold the lock.
+func (m *MemoryRepo) getUserByID(id string) (*api.User, error) {
+	for _, u := range m.users {
+		if u.ID == id {
+			return &u, nil
+		}
+	}
+
+	return nil, &database.Error{
+		Code:    database.USER_NOT_FOUND,
+		Message: fmt.Sprintf("User with id %v not found", id),
+	}
+}
@@ -0,0 +1,151 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// API KEY REPOSITORY IMPLEMENTATION
+
+func (k PostgresRepo) AddAPIKey(apiKey api.APIKey) (*api.APIKey, error) {
+
+	// Create API key model
+	apiKeyDB := apiAPIKeyToDBAPIKey(&apiKey)
+
+	// Store API key
+	err := k.Dbmap.Create(apiKeyDB).Error
+
+	// Error handling
+	if err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAPIKeyToAPIAPIKey(apiKeyDB), nil
+}
+
+func (k PostgresRepo) GetAPIKeyByID(id string) (*api.APIKey, error) {
+	apiKeyDB := &APIKey{}
+	query := k.Dbmap.Where("id = ?", id).First(apiKeyDB)
+
+	// Check if API key exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.API_KEY_NOT_FOUND,
+			Message: fmt.Sprintf("API key with id %v not found", id),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbAPIKeyToAPIAPIKey(apiKeyDB), nil
+}
+
+func (k PostgresRepo) GetAPIKeysByUserID(userID string, filter *api.Filter) ([]api.APIKey, int, error) {
+	var total int
+	apiKeys := []APIKey{}
+	query := k.Dbmap.Where("user_id = ?", userID)
+
+	// Error handling
+	if err := query.Model(&APIKey{}).Count(&total).Order("create_at, id").Offset(filter.Offset).Limit(filter.Limit).Find(&apiKeys).Error; err != nil {
+		return nil, total, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform API keys for API
+	apiAPIKeys := make([]api.APIKey, len(apiKeys), cap(apiKeys))
+	for i, ak := range apiKeys {
+		apiAPIKeys[i] = *dbAPIKeyToAPIAPIKey(&ak)
+	}
+
+	return apiAPIKeys, total, nil
+}
+
+func (k PostgresRepo) UpdateAPIKey(apiKey api.APIKey) (*api.APIKey, error) {
+	apiKeyDB := apiAPIKeyToDBAPIKey(&apiKey)
+
+	// Update API key, with map to also store empty dates
+	query := k.Dbmap.Model(&APIKey{ID: apiKey.ID}).Updates(map[string]interface{}{
+		"hash":      apiKeyDB.Hash,
+		"rotate_at": apiKeyDB.RotateAt,
+		"expire_at": apiKeyDB.ExpireAt,
+		"revoke_at": apiKeyDB.RevokeAt,
+	})
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	if query.RowsAffected == 0 {
+		return nil, &database.Error{
+			Code:    database.API_KEY_NOT_FOUND,
+			Message: fmt.Sprintf("API key with id %v not found", apiKey.ID),
+		}
+	}
+
+	return k.GetAPIKeyByID(apiKey.ID)
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an API key for API into an API key to store in db
+func apiAPIKeyToDBAPIKey(apiKey *api.APIKey) *APIKey {
+	return &APIKey{
+		ID:         apiKey.ID,
+		Name:       apiKey.Name,
+		UserID:     apiKey.UserID,
+		ExternalID: apiKey.ExternalID,
+		Hash:       apiKey.Hash,
+		CreateAt:   apiKey.CreateAt.UnixNano(),
+		RotateAt:   apiKey.RotateAt.UnixNano(),
+		ExpireAt:   optionalTimeToDB(apiKey.ExpireAt),
+		RevokeAt:   optionalTimeToDB(apiKey.RevokeAt),
+	}
+}
+
+// Transform an API key retrieved from db into an API key for API
+func dbAPIKeyToAPIAPIKey(apiKeydb *APIKey) *api.APIKey {
+	return &api.APIKey{
+		ID:         apiKeydb.ID,
+		Name:       apiKeydb.Name,
+		UserID:     apiKeydb.UserID,
+		ExternalID: apiKeydb.ExternalID,
+		Hash:       apiKeydb.Hash,
+		CreateAt:   time.Unix(0, apiKeydb.CreateAt).UTC(),
+		RotateAt:   time.Unix(0, apiKeydb.RotateAt).UTC(),
+		ExpireAt:   dbToOptionalTime(apiKeydb.ExpireAt),
+		RevokeAt:   dbToOptionalTime(apiKeydb.RevokeAt),
+	}
+}
+
+// Optional dates are stored as 0 if they aren't set
+func optionalTimeToDB(t *time.Time) int64 {
+	if t == nil {
+		return 0
+	}
+	return t.UnixNano()
+}
+
+func dbToOptionalTime(t int64) *time.Time {
+	if t == 0 {
+		return nil
+	}
+	optional := time.Unix(0, t).UTC()
+	return &optional
+}
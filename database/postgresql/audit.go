@@ -0,0 +1,96 @@
+package postgresql
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// AUDIT REPOSITORY IMPLEMENTATION
+
+func (a PostgresRepo) AddAuditEvent(event api.AuditEvent) error {
+
+	// Create audit event model
+	eventDB := &AuditEvent{
+		ID:        event.ID,
+		Actor:     event.Actor,
+		RequestID: event.RequestID,
+		Action:    event.Action,
+		Urn:       event.Urn,
+		Before:    string(event.Before),
+		After:     string(event.After),
+		CreateAt:  event.CreateAt.UnixNano(),
+	}
+
+	// Store audit event
+	err := a.Dbmap.Create(eventDB).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (a PostgresRepo) GetAuditEventsFiltered(filter *api.AuditFilter) ([]api.AuditEvent, int, error) {
+	var total int
+	events := []AuditEvent{}
+	query := a.Dbmap
+	if len(filter.Actor) > 0 {
+		query = query.Where("actor = ?", filter.Actor)
+	}
+	if len(filter.Action) > 0 {
+		query = query.Where("action = ?", filter.Action)
+	}
+	if len(filter.UrnPrefix) > 0 {
+		query = query.Where("urn like ?", filter.UrnPrefix+"%")
+	}
+	if !filter.From.IsZero() {
+		query = query.Where("create_at >= ?", filter.From.UnixNano())
+	}
+	if !filter.To.IsZero() {
+		query = query.Where("create_at <= ?", filter.To.UnixNano())
+	}
+	// Error handling
+	if err := query.Model(&AuditEvent{}).Count(&total).Order("create_at desc").Offset(filter.Offset).Limit(filter.Limit).Find(&events).Error; err != nil {
+		return nil, total, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform audit events for API
+	apiEvents := make([]api.AuditEvent, len(events), cap(events))
+	for i, e := range events {
+		apiEvents[i] = *dbAuditEventToAPIAuditEvent(&e)
+	}
+
+	return apiEvents, total, nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Transform an audit event retrieved from db into an audit event for API
+func dbAuditEventToAPIAuditEvent(eventdb *AuditEvent) *api.AuditEvent {
+	event := &api.AuditEvent{
+		ID:        eventdb.ID,
+		Actor:     eventdb.Actor,
+		RequestID: eventdb.RequestID,
+		Action:    eventdb.Action,
+		Urn:       eventdb.Urn,
+		CreateAt:  time.Unix(0, eventdb.CreateAt).UTC(),
+	}
+	if len(eventdb.Before) > 0 {
+		event.Before = json.RawMessage(eventdb.Before)
+	}
+	if len(eventdb.After) > 0 {
+		event.After = json.RawMessage(eventdb.After)
+	}
+	return event
+}
@@ -0,0 +1,70 @@
+package postgresql
+
+import (
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+)
+
+// Statements of policies attached to a user directly or through its groups, with the group and policy they come from.
+// Statements of the same attachment are together, group attachments first.
+const effectiveStatementsQuery = `SELECT groups.org AS group_org, groups.name AS group_name,
+	policies.org AS policy_org, policies.name AS policy_name, statements.* FROM users
+	JOIN (
+		SELECT group_user_relations.user_id, group_policy_relations.group_id, group_policy_relations.policy_id FROM group_user_relations
+		JOIN group_policy_relations ON group_policy_relations.group_id = group_user_relations.group_id
+		UNION
+		SELECT user_policy_relations.user_id, NULL, user_policy_relations.policy_id FROM user_policy_relations
+	) relations ON relations.user_id = users.id
+	LEFT JOIN groups ON groups.id = relations.group_id
+	JOIN policies ON policies.id = relations.policy_id
+	JOIN statements ON statements.policy_id = policies.id
+	WHERE users.external_id = ?
+	ORDER BY relations.group_id IS NULL, groups.org, groups.name, policies.org, policies.name, statements.id`
+
+// Row of effective statements query
+type effectiveStatement struct {
+	Statement
+	GroupOrg   *string
+	GroupName  *string
+	PolicyOrg  string
+	PolicyName string
+}
+
+// AUTHZ REPOSITORY IMPLEMENTATION
+
+func (p PostgresRepo) GetEffectiveStatements(externalID string) ([]api.EffectiveStatement, error) {
+	rows := []effectiveStatement{}
+	if err := p.Dbmap.Raw(effectiveStatementsQuery, externalID).Scan(&rows).Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Without statements, check if user exists
+	if len(rows) < 1 {
+		if _, err := p.GetUserByExternalID(externalID); err != nil {
+			return nil, err
+		}
+		return []api.EffectiveStatement{}, nil
+	}
+
+	statements := make([]api.EffectiveStatement, len(rows), len(rows))
+	for i, row := range rows {
+		statements[i] = api.EffectiveStatement{
+			Policy: api.PolicyIdentity{
+				Org:  row.PolicyOrg,
+				Name: row.PolicyName,
+			},
+			Statement: (*dbStatementsToAPIStatements([]Statement{row.Statement}))[0],
+		}
+		if row.GroupOrg != nil && row.GroupName != nil {
+			statements[i].Group = &api.GroupIdentity{
+				Org:  *row.GroupOrg,
+				Name: *row.GroupName,
+			}
+		}
+	}
+
+	return statements, nil
+}
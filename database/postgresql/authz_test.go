@@ -0,0 +1,160 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestPostgresRepo_GetEffectiveStatements(t *testing.T) {
+	testcases := map[string]struct {
+		// Previous data
+		previousUser         bool
+		groupPolicyStatement []Statement
+		userPolicyStatement  []Statement
+		// Postgres Repo Args
+		externalID string
+		// Expected result
+		expectedResponse []api.EffectiveStatement
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			previousUser: true,
+			groupPolicyStatement: []Statement{
+				{
+					ID:        "StatementID1",
+					PolicyID:  "GroupPolicyID",
+					Effect:    "allow",
+					Actions:   api.USER_ACTION_GET_USER,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+				},
+			},
+			userPolicyStatement: []Statement{
+				{
+					ID:        "StatementID2",
+					PolicyID:  "UserPolicyID",
+					Effect:    "deny",
+					Actions:   api.GROUP_ACTION_GET_GROUP + ";" + api.GROUP_ACTION_CREATE_GROUP,
+					Resources: api.GetUrnPrefix("", api.RESOURCE_GROUP, "/path/"),
+				},
+			},
+			externalID: "ExternalID",
+			expectedResponse: []api.EffectiveStatement{
+				{
+					Group: &api.GroupIdentity{
+						Org:  "org",
+						Name: "group",
+					},
+					Policy: api.PolicyIdentity{
+						Org:  "org",
+						Name: "groupPolicy",
+					},
+					Statement: api.Statement{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+				{
+					Policy: api.PolicyIdentity{
+						Org:  "org",
+						Name: "userPolicy",
+					},
+					Statement: api.Statement{
+						Effect: "deny",
+						Actions: []string{
+							api.GROUP_ACTION_GET_GROUP,
+							api.GROUP_ACTION_CREATE_GROUP,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_GROUP, "/path/"),
+						},
+					},
+				},
+			},
+		},
+		"OkCaseWithoutStatements": {
+			previousUser:     true,
+			externalID:       "ExternalID",
+			expectedResponse: []api.EffectiveStatement{},
+		},
+		"ErrorCaseUserNotFound": {
+			externalID: "ExternalID",
+			expectedError: &database.Error{
+				Code:    database.USER_NOT_FOUND,
+				Message: "User with externalId ExternalID not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		cleanUserTable()
+		cleanGroupTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+		cleanUserPolicyRelationTable()
+		cleanPolicyTable()
+		cleanStatementTable()
+
+		// Insert previous data
+		if test.previousUser {
+			if err := insertUser("UserID", "ExternalID", "/path/", 0, "urn"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous user: %v", n, err)
+				continue
+			}
+			if err := insertGroup("GroupID", "group", "/path/", 0, "groupUrn", "org"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group: %v", n, err)
+				continue
+			}
+			if err := insertGroupUserRelation("UserID", "GroupID"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group user relation: %v", n, err)
+				continue
+			}
+			if err := insertPolicy("GroupPolicyID", "groupPolicy", "org", "/path/", 0, "groupPolicyUrn", test.groupPolicyStatement); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous policy: %v", n, err)
+				continue
+			}
+			if err := insertGroupPolicyRelation("GroupID", "GroupPolicyID"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous group policy relation: %v", n, err)
+				continue
+			}
+			if err := insertPolicy("UserPolicyID", "userPolicy", "org", "/path/", 0, "userPolicyUrn", test.userPolicyStatement); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous policy: %v", n, err)
+				continue
+			}
+			if err := insertUserPolicyRelation("UserID", "UserPolicyID"); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous user policy relation: %v", n, err)
+				continue
+			}
+		}
+
+		receivedStatements, err := repoDB.GetEffectiveStatements(test.externalID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedStatements, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
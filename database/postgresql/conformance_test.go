@@ -0,0 +1,23 @@
+package postgresql
+
+import (
+	"testing"
+
+	"github.com/Tecsisa/foulkon/database/conformance"
+)
+
+func TestPostgresRepo_Conformance(t *testing.T) {
+	conformance.RunTests(t, func() conformance.Repo {
+		cleanUserTable()
+		cleanGroupTable()
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanGroupUserRelationTable()
+		cleanGroupPolicyRelationTable()
+		cleanUserPolicyRelationTable()
+		cleanPolicyVersionTable()
+		cleanAuditEventTable()
+		cleanAPIKeyTable()
+		return repoDB
+	})
+}
@@ -180,6 +180,18 @@ func (g PostgresRepo) RemoveGroup(id string) error {
 		}
 	}
 
+	// Delete all group policy relations
+	transaction.Where("group_id like ?", id).Delete(&GroupPolicyRelation{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
 	transaction.Commit()
 	return nil
 }
@@ -0,0 +1,208 @@
+package postgresql
+
+import (
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/database"
+	"github.com/jinzhu/gorm"
+	"github.com/satori/go.uuid"
+)
+
+// IMPORT REPOSITORY IMPLEMENTATION
+
+func (p PostgresRepo) ApplyImportChanges(changes api.ImportChanges) error {
+	transaction := p.Dbmap.Begin()
+
+	if err := applyImportChanges(transaction, changes); err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	return nil
+}
+
+// PRIVATE HELPER METHODS
+
+// Apply changes in transaction: removals first, so elements can be created again
+func applyImportChanges(transaction *gorm.DB, changes api.ImportChanges) error {
+	// Remove relations
+	for _, r := range changes.RemovedMembers {
+		if err := transaction.Where("user_id like ? AND group_id like ?", r.User.ID, r.Group.ID).Delete(&GroupUserRelation{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.RemovedGroupPolicies {
+		if err := transaction.Where("group_id like ? AND policy_id like ?", r.Group.ID, r.Policy.ID).Delete(&GroupPolicyRelation{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.RemovedUserPolicies {
+		if err := transaction.Where("user_id like ? AND policy_id like ?", r.User.ID, r.Policy.ID).Delete(&UserPolicyRelation{}).Error; err != nil {
+			return err
+		}
+	}
+
+	// Remove policies, groups and users with their relations
+	for _, policy := range changes.RemovedPolicies {
+		for _, model := range []interface{}{&GroupPolicyRelation{}, &UserPolicyRelation{}, &PolicyVersion{}, &Statement{}} {
+			if err := transaction.Where("policy_id like ?", policy.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := transaction.Where("id like ?", policy.ID).Delete(&Policy{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, group := range changes.RemovedGroups {
+		for _, model := range []interface{}{&GroupUserRelation{}, &GroupPolicyRelation{}} {
+			if err := transaction.Where("group_id like ?", group.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := transaction.Where("id like ?", group.ID).Delete(&Group{}).Error; err != nil {
+			return err
+		}
+	}
+	for _, user := range changes.RemovedUsers {
+		for _, model := range []interface{}{&GroupUserRelation{}, &UserPolicyRelation{}, &APIKey{}} {
+			if err := transaction.Where("user_id like ?", user.ID).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		if err := transaction.Where("id like ?", user.ID).Delete(&User{}).Error; err != nil {
+			return err
+		}
+	}
+
+	// Create and update users, groups and policies
+	for _, user := range changes.AddedUsers {
+		userDB := &User{
+			ID:         user.ID,
+			ExternalID: user.ExternalID,
+			Path:       user.Path,
+			CreateAt:   user.CreateAt.UnixNano(),
+			Urn:        user.Urn,
+		}
+		if err := transaction.Create(userDB).Error; err != nil {
+			return err
+		}
+	}
+	for _, user := range changes.UpdatedUsers {
+		userUpdated := User{
+			Path: user.Path,
+			Urn:  user.Urn,
+		}
+		if err := transaction.Model(&User{ID: user.ID}).Update(userUpdated).Error; err != nil {
+			return err
+		}
+	}
+	for _, group := range changes.AddedGroups {
+		groupDB := &Group{
+			ID:       group.ID,
+			Name:     group.Name,
+			Path:     group.Path,
+			Org:      group.Org,
+			CreateAt: group.CreateAt.UnixNano(),
+			Urn:      group.Urn,
+		}
+		if err := transaction.Create(groupDB).Error; err != nil {
+			return err
+		}
+	}
+	for _, group := range changes.UpdatedGroups {
+		groupUpdated := Group{
+			Name: group.Name,
+			Path: group.Path,
+			Urn:  group.Urn,
+		}
+		if err := transaction.Model(&Group{ID: group.ID}).Update(groupUpdated).Error; err != nil {
+			return err
+		}
+	}
+	for _, policy := range changes.AddedPolicies {
+		policyDB := &Policy{
+			ID:       policy.ID,
+			Name:     policy.Name,
+			Path:     policy.Path,
+			CreateAt: policy.CreateAt.UnixNano(),
+			Urn:      policy.Urn,
+			Org:      policy.Org,
+		}
+		if err := transaction.Create(policyDB).Error; err != nil {
+			return err
+		}
+		if err := createStatements(transaction, policy); err != nil {
+			return err
+		}
+	}
+	for _, policy := range changes.UpdatedPolicies {
+		policyUpdated := Policy{
+			Name: policy.Name,
+			Path: policy.Path,
+			Urn:  policy.Urn,
+		}
+		if err := transaction.Model(&Policy{ID: policy.ID}).Update(policyUpdated).Error; err != nil {
+			return err
+		}
+		// Replace statements
+		if err := transaction.Where("policy_id like ?", policy.ID).Delete(Statement{}).Error; err != nil {
+			return err
+		}
+		if err := createStatements(transaction, policy); err != nil {
+			return err
+		}
+	}
+	for _, v := range changes.PolicyVersions {
+		if err := addPolicyVersion(transaction, v.Previous, v.Version.PolicyID, v.Version); err != nil {
+			return err
+		}
+	}
+
+	// Create relations
+	for _, r := range changes.AddedMembers {
+		if err := transaction.Create(&GroupUserRelation{UserID: r.User.ID, GroupID: r.Group.ID}).Error; err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.AddedGroupPolicies {
+		if err := transaction.Create(&GroupPolicyRelation{GroupID: r.Group.ID, PolicyID: r.Policy.ID}).Error; err != nil {
+			return err
+		}
+	}
+	for _, r := range changes.AddedUserPolicies {
+		if err := transaction.Create(&UserPolicyRelation{UserID: r.User.ID, PolicyID: r.Policy.ID}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Store statements of policy in transaction
+func createStatements(transaction *gorm.DB, policy api.Policy) error {
+	if policy.Statements == nil {
+		return nil
+	}
+	for _, s := range *policy.Statements {
+		statementDB := &Statement{
+			ID:         uuid.NewV4().String(),
+			PolicyID:   policy.ID,
+			Effect:     s.Effect,
+			Actions:    stringArrayToString(s.Actions),
+			Resources:  stringArrayToString(s.Resources),
+			Conditions: conditionsToString(s.Conditions),
+		}
+		if err := transaction.Create(statementDB).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
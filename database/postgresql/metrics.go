@@ -0,0 +1,41 @@
+package postgresql
+
+import (
+	"time"
+
+	"github.com/Tecsisa/foulkon/metrics"
+	"github.com/jinzhu/gorm"
+)
+
+const metricsStartTime = "foulkon:metrics_start_time"
+
+// Repository query latency by operation and table
+var queryDuration = metrics.NewHistogramVec("foulkon_db_query_duration_seconds",
+	"Database query latency in seconds by operation (create, query, update or delete) and table.",
+	metrics.DefaultBuckets, "operation", "table")
+
+// RegisterMetricsCallbacks records latency of create, query, update and delete operations done with this connection.
+// Row queries (Row, Rows, Count) and raw executions don't run these callbacks, so they aren't recorded.
+func RegisterMetricsCallbacks(db *gorm.DB) {
+	callback := db.Callback()
+	callback.Create().Before("gorm:create").Register("foulkon:metrics_before_create", startQueryTimer)
+	callback.Create().After("gorm:create").Register("foulkon:metrics_after_create", observeQuery("create"))
+	callback.Query().Before("gorm:query").Register("foulkon:metrics_before_query", startQueryTimer)
+	callback.Query().After("gorm:query").Register("foulkon:metrics_after_query", observeQuery("query"))
+	callback.Update().Before("gorm:update").Register("foulkon:metrics_before_update", startQueryTimer)
+	callback.Update().After("gorm:update").Register("foulkon:metrics_after_update", observeQuery("update"))
+	callback.Delete().Before("gorm:delete").Register("foulkon:metrics_before_delete", startQueryTimer)
+	callback.Delete().After("gorm:delete").Register("foulkon:metrics_after_delete", observeQuery("delete"))
+}
+
+func startQueryTimer(scope *gorm.Scope) {
+	scope.InstanceSet(metricsStartTime, time.Now())
+}
+
+func observeQuery(operation string) func(scope *gorm.Scope) {
+	return func(scope *gorm.Scope) {
+		if start, ok := scope.InstanceGet(metricsStartTime); ok {
+			queryDuration.ObserveSince(start.(time.Time), operation, scope.TableName())
+		}
+	}
+}
@@ -0,0 +1,394 @@
+package postgresql
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/jinzhu/gorm"
+)
+
+// Database schema version expected by this binary. It must be the version of last migration.
+const SCHEMA_VERSION = 5
+
+// Lock identifier to avoid several workers migrating at the same time
+const migrationsLockID = 180916
+
+// Schema change with the SQL statements to apply and to revert it
+type migration struct {
+	Version     int
+	Description string
+	Up          []string
+	Down        []string
+}
+
+// Numbered migrations. Applied migrations must never be modified, add a new one instead.
+var migrations = []migration{
+	{
+		Version:     1,
+		Description: "Initial schema",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS users (
+				id text NOT NULL,
+				external_id text NOT NULL UNIQUE,
+				path text NOT NULL,
+				create_at bigint NOT NULL,
+				urn text NOT NULL UNIQUE,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS groups (
+				id text NOT NULL,
+				name text NOT NULL,
+				path text NOT NULL,
+				org text NOT NULL,
+				create_at bigint NOT NULL,
+				urn text NOT NULL UNIQUE,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS policies (
+				id text NOT NULL,
+				name text NOT NULL,
+				path text NOT NULL,
+				org text NOT NULL,
+				create_at bigint NOT NULL,
+				urn text NOT NULL UNIQUE,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS statements (
+				id text NOT NULL,
+				policy_id text NOT NULL,
+				effect text NOT NULL,
+				actions text NOT NULL,
+				resources text NOT NULL,
+				conditions text NOT NULL DEFAULT '',
+				PRIMARY KEY (id)
+			)`,
+			// Databases created by old versions without conditions
+			`ALTER TABLE statements ADD COLUMN IF NOT EXISTS conditions text NOT NULL DEFAULT ''`,
+			`CREATE TABLE IF NOT EXISTS group_user_relations (
+				user_id text NOT NULL,
+				group_id text NOT NULL,
+				PRIMARY KEY (user_id, group_id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS group_policy_relations (
+				group_id text NOT NULL,
+				policy_id text NOT NULL,
+				PRIMARY KEY (group_id, policy_id)
+			)`,
+			`CREATE TABLE IF NOT EXISTS user_policy_relations (
+				user_id text NOT NULL,
+				policy_id text NOT NULL,
+				PRIMARY KEY (user_id, policy_id)
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS user_policy_relations`,
+			`DROP TABLE IF EXISTS group_policy_relations`,
+			`DROP TABLE IF EXISTS group_user_relations`,
+			`DROP TABLE IF EXISTS statements`,
+			`DROP TABLE IF EXISTS policies`,
+			`DROP TABLE IF EXISTS groups`,
+			`DROP TABLE IF EXISTS users`,
+		},
+	},
+	{
+		Version:     2,
+		Description: "Indexes and foreign keys for relations and statements",
+		Up: []string{
+			`CREATE INDEX IF NOT EXISTS idx_statements_policy_id ON statements (policy_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_group_user_relations_group_id ON group_user_relations (group_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_group_policy_relations_policy_id ON group_policy_relations (policy_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_user_policy_relations_policy_id ON user_policy_relations (policy_id)`,
+			// Rows of removed users, groups and policies left by old versions would break constraints
+			`DELETE FROM statements WHERE NOT EXISTS (SELECT 1 FROM policies WHERE policies.id = statements.policy_id)`,
+			`DELETE FROM group_user_relations WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = group_user_relations.user_id)
+				OR NOT EXISTS (SELECT 1 FROM groups WHERE groups.id = group_user_relations.group_id)`,
+			`DELETE FROM group_policy_relations WHERE NOT EXISTS (SELECT 1 FROM groups WHERE groups.id = group_policy_relations.group_id)
+				OR NOT EXISTS (SELECT 1 FROM policies WHERE policies.id = group_policy_relations.policy_id)`,
+			`DELETE FROM user_policy_relations WHERE NOT EXISTS (SELECT 1 FROM users WHERE users.id = user_policy_relations.user_id)
+				OR NOT EXISTS (SELECT 1 FROM policies WHERE policies.id = user_policy_relations.policy_id)`,
+			// Statements and relations are removed with their user, group or policy
+			`ALTER TABLE statements ADD CONSTRAINT fk_statements_policy_id
+				FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE`,
+			`ALTER TABLE group_user_relations ADD CONSTRAINT fk_group_user_relations_user_id
+				FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE`,
+			`ALTER TABLE group_user_relations ADD CONSTRAINT fk_group_user_relations_group_id
+				FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE`,
+			`ALTER TABLE group_policy_relations ADD CONSTRAINT fk_group_policy_relations_group_id
+				FOREIGN KEY (group_id) REFERENCES groups (id) ON DELETE CASCADE`,
+			`ALTER TABLE group_policy_relations ADD CONSTRAINT fk_group_policy_relations_policy_id
+				FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE`,
+			`ALTER TABLE user_policy_relations ADD CONSTRAINT fk_user_policy_relations_user_id
+				FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE`,
+			`ALTER TABLE user_policy_relations ADD CONSTRAINT fk_user_policy_relations_policy_id
+				FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE`,
+		},
+		Down: []string{
+			`ALTER TABLE user_policy_relations DROP CONSTRAINT IF EXISTS fk_user_policy_relations_policy_id`,
+			`ALTER TABLE user_policy_relations DROP CONSTRAINT IF EXISTS fk_user_policy_relations_user_id`,
+			`ALTER TABLE group_policy_relations DROP CONSTRAINT IF EXISTS fk_group_policy_relations_policy_id`,
+			`ALTER TABLE group_policy_relations DROP CONSTRAINT IF EXISTS fk_group_policy_relations_group_id`,
+			`ALTER TABLE group_user_relations DROP CONSTRAINT IF EXISTS fk_group_user_relations_group_id`,
+			`ALTER TABLE group_user_relations DROP CONSTRAINT IF EXISTS fk_group_user_relations_user_id`,
+			`ALTER TABLE statements DROP CONSTRAINT IF EXISTS fk_statements_policy_id`,
+			`DROP INDEX IF EXISTS idx_user_policy_relations_policy_id`,
+			`DROP INDEX IF EXISTS idx_group_policy_relations_policy_id`,
+			`DROP INDEX IF EXISTS idx_group_user_relations_group_id`,
+			`DROP INDEX IF EXISTS idx_statements_policy_id`,
+		},
+	},
+	{
+		Version:     3,
+		Description: "Audit events",
+		// No foreign keys, events are kept after their actor or resource is removed
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS audit_events (
+				id text NOT NULL,
+				actor text NOT NULL,
+				request_id text NOT NULL,
+				action text NOT NULL,
+				urn text NOT NULL,
+				before text NOT NULL,
+				after text NOT NULL,
+				create_at bigint NOT NULL,
+				PRIMARY KEY (id)
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_events_create_at ON audit_events (create_at)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_events_actor ON audit_events (actor)`,
+			`CREATE INDEX IF NOT EXISTS idx_audit_events_urn ON audit_events (urn)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS audit_events`,
+		},
+	},
+	{
+		Version:     4,
+		Description: "Policy versions",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS policy_versions (
+				policy_id text NOT NULL,
+				version integer NOT NULL,
+				name text NOT NULL,
+				path text NOT NULL,
+				statements text NOT NULL,
+				author text NOT NULL,
+				create_at bigint NOT NULL,
+				PRIMARY KEY (policy_id, version),
+				CONSTRAINT fk_policy_versions_policy_id
+					FOREIGN KEY (policy_id) REFERENCES policies (id) ON DELETE CASCADE
+			)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS policy_versions`,
+		},
+	},
+	{
+		Version:     5,
+		Description: "API keys",
+		Up: []string{
+			`CREATE TABLE IF NOT EXISTS api_keys (
+				id text NOT NULL,
+				name text NOT NULL,
+				user_id text NOT NULL,
+				external_id text NOT NULL,
+				hash text NOT NULL,
+				create_at bigint NOT NULL,
+				rotate_at bigint NOT NULL,
+				expire_at bigint NOT NULL,
+				revoke_at bigint NOT NULL,
+				PRIMARY KEY (id),
+				CONSTRAINT fk_api_keys_user_id
+					FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+			)`,
+			`CREATE INDEX IF NOT EXISTS idx_api_keys_user_id ON api_keys (user_id)`,
+		},
+		Down: []string{
+			`DROP TABLE IF EXISTS api_keys`,
+		},
+	},
+}
+
+// Schema migrations table, with a row for every applied migration
+type SchemaMigration struct {
+	Version     int    `gorm:"primary_key"`
+	Description string `gorm:"not null"`
+	AppliedAt   int64  `gorm:"not null"`
+}
+
+// SchemaMigration's table name
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationStatus describes a migration and when it was applied, if it was
+type MigrationStatus struct {
+	Version     int
+	Description string
+	Applied     bool
+	AppliedAt   time.Time
+}
+
+// GetSchemaVersion returns the version of the last migration applied, 0 if there isn't any.
+func GetSchemaVersion(db *gorm.DB) (int, error) {
+	if !db.HasTable(&SchemaMigration{}) {
+		return 0, nil
+	}
+	var version int
+	row := db.Raw("SELECT COALESCE(MAX(version), 0) FROM schema_migrations").Row()
+	if err := row.Scan(&version); err != nil {
+		return 0, err
+	}
+	return version, nil
+}
+
+// CheckSchemaVersion returns an error if database schema version isn't the one expected by this binary
+func CheckSchemaVersion(db *gorm.DB) error {
+	version, err := GetSchemaVersion(db)
+	if err != nil {
+		return err
+	}
+	switch {
+	case version < SCHEMA_VERSION:
+		return fmt.Errorf("Database schema version %v is older than version %v expected, run 'foulkon migrate up' to update it",
+			version, SCHEMA_VERSION)
+	case version > SCHEMA_VERSION:
+		return fmt.Errorf("Database schema version %v is newer than version %v expected, upgrade this binary or run 'foulkon migrate down' with the one that updated it",
+			version, SCHEMA_VERSION)
+	}
+	return nil
+}
+
+// MigrateUp applies all pending migrations, each one in its own transaction. It returns applied migrations.
+func MigrateUp(db *gorm.DB) ([]MigrationStatus, error) {
+	if err := createSchemaMigrationsTable(db); err != nil {
+		return nil, err
+	}
+
+	applied := []MigrationStatus{}
+	for _, m := range migrations {
+		status, err := runMigration(db, m, true)
+		if err != nil {
+			return applied, err
+		}
+		if status != nil {
+			applied = append(applied, *status)
+		}
+	}
+	return applied, nil
+}
+
+// MigrateDown reverts last applied migration. It returns reverted migration, nil if there wasn't any.
+func MigrateDown(db *gorm.DB) (*MigrationStatus, error) {
+	version, err := GetSchemaVersion(db)
+	if err != nil {
+		return nil, err
+	}
+	if version == 0 {
+		return nil, nil
+	}
+	for _, m := range migrations {
+		if m.Version == version {
+			return runMigration(db, m, false)
+		}
+	}
+	return nil, fmt.Errorf("Database schema version %v is unknown by this binary", version)
+}
+
+// GetMigrationsStatus returns all migrations known by this binary and if they are applied
+func GetMigrationsStatus(db *gorm.DB) ([]MigrationStatus, error) {
+	appliedMigrations := []SchemaMigration{}
+	if db.HasTable(&SchemaMigration{}) {
+		if err := db.Find(&appliedMigrations).Error; err != nil {
+			return nil, err
+		}
+	}
+	appliedAt := map[int]int64{}
+	for _, m := range appliedMigrations {
+		appliedAt[m.Version] = m.AppliedAt
+	}
+
+	status := make([]MigrationStatus, len(migrations), len(migrations))
+	for i, m := range migrations {
+		status[i] = MigrationStatus{
+			Version:     m.Version,
+			Description: m.Description,
+		}
+		if t, ok := appliedAt[m.Version]; ok {
+			status[i].Applied = true
+			status[i].AppliedAt = time.Unix(0, t).UTC()
+		}
+	}
+	return status, nil
+}
+
+// PRIVATE HELPER METHODS
+
+func createSchemaMigrationsTable(db *gorm.DB) error {
+	return db.Exec(`CREATE TABLE IF NOT EXISTS schema_migrations (
+		version integer NOT NULL,
+		description text NOT NULL,
+		applied_at bigint NOT NULL,
+		PRIMARY KEY (version)
+	)`).Error
+}
+
+// Apply or revert a migration in a transaction. It returns nil status if there was nothing to do,
+// because other worker did it before.
+func runMigration(db *gorm.DB, m migration, up bool) (*MigrationStatus, error) {
+	transaction := db.Begin()
+	if err := transaction.Error; err != nil {
+		return nil, err
+	}
+
+	// Wait for other workers running migrations
+	if err := transaction.Exec("SELECT pg_advisory_xact_lock(?)", migrationsLockID).Error; err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	// Check version again with the lock taken
+	version, err := GetSchemaVersion(transaction)
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+	if (up && version >= m.Version) || (!up && version != m.Version) {
+		transaction.Rollback()
+		return nil, nil
+	}
+
+	statements := m.Down
+	if up {
+		statements = m.Up
+	}
+	for _, statement := range statements {
+		if err := transaction.Exec(statement).Error; err != nil {
+			transaction.Rollback()
+			return nil, fmt.Errorf("Error in migration %v: %v", m.Version, err)
+		}
+	}
+
+	status := &MigrationStatus{
+		Version:     m.Version,
+		Description: m.Description,
+	}
+	if up {
+		status.Applied = true
+		status.AppliedAt = time.Now().UTC()
+		err = transaction.Create(&SchemaMigration{
+			Version:     m.Version,
+			Description: m.Description,
+			AppliedAt:   status.AppliedAt.UnixNano(),
+		}).Error
+	} else {
+		err = transaction.Where("version = ?", m.Version).Delete(&SchemaMigration{}).Error
+	}
+	if err != nil {
+		transaction.Rollback()
+		return nil, err
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, err
+	}
+	return status, nil
+}
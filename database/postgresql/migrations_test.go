@@ -0,0 +1,187 @@
+package postgresql
+
+import (
+	"fmt"
+	"testing"
+)
+
+func TestMigrationsVersions(t *testing.T) {
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			t.Errorf("Migration %v has version %v, expected %v", m.Description, m.Version, i+1)
+		}
+		if len(m.Up) == 0 || len(m.Down) == 0 {
+			t.Errorf("Migration %v must have up and down statements", m.Version)
+		}
+	}
+	if last := migrations[len(migrations)-1].Version; last != SCHEMA_VERSION {
+		t.Errorf("Last migration version %v is not SCHEMA_VERSION %v", last, SCHEMA_VERSION)
+	}
+}
+
+func TestMigrations(t *testing.T) {
+	db := repoDB.Dbmap
+
+	// Revert all migrations
+	for i := SCHEMA_VERSION; i > 0; i-- {
+		reverted, err := MigrateDown(db)
+		if err != nil {
+			t.Fatalf("Unexpected error reverting migration %v: %v", i, err)
+		}
+		if reverted == nil || reverted.Version != i {
+			t.Fatalf("Received different reverted migration %v, expected %v", reverted, i)
+		}
+	}
+	reverted, err := MigrateDown(db)
+	if err != nil || reverted != nil {
+		t.Fatalf("Expected nothing to revert, received %v, error %v", reverted, err)
+	}
+	if db.HasTable(&User{}) {
+		t.Fatal("Table users must be dropped")
+	}
+
+	// Old schema is refused
+	expectedError := fmt.Sprintf("Database schema version 0 is older than version %v expected, run 'foulkon migrate up' to update it",
+		SCHEMA_VERSION)
+	if err := CheckSchemaVersion(db); err == nil || err.Error() != expectedError {
+		t.Errorf("Received different error checking schema version: %v", err)
+	}
+
+	// Apply all migrations
+	applied, err := MigrateUp(db)
+	if err != nil {
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+	if len(applied) != SCHEMA_VERSION {
+		t.Errorf("Received different applied migrations number: %v", len(applied))
+	}
+	if err := CheckSchemaVersion(db); err != nil {
+		t.Errorf("Unexpected error checking schema version: %v", err)
+	}
+	applied, err = MigrateUp(db)
+	if err != nil || len(applied) != 0 {
+		t.Errorf("Expected nothing to apply, received %v, error %v", applied, err)
+	}
+
+	status, err := GetMigrationsStatus(db)
+	if err != nil {
+		t.Fatalf("Unexpected error retrieving migrations status: %v", err)
+	}
+	for _, s := range status {
+		if !s.Applied {
+			t.Errorf("Migration %v not applied", s.Version)
+		}
+	}
+
+	// Newer schema is refused
+	if err := db.Create(&SchemaMigration{Version: SCHEMA_VERSION + 1, Description: "Future"}).Error; err != nil {
+		t.Fatalf("Unexpected error inserting migration: %v", err)
+	}
+	expectedError = fmt.Sprintf("Database schema version %v is newer than version %v expected, upgrade this binary or run 'foulkon migrate down' with the one that updated it",
+		SCHEMA_VERSION+1, SCHEMA_VERSION)
+	if err := CheckSchemaVersion(db); err == nil || err.Error() != expectedError {
+		t.Errorf("Received different error checking schema version: %v", err)
+	}
+	if _, err := MigrateDown(db); err == nil {
+		t.Error("Expected error reverting unknown migration")
+	}
+	db.Where("version = ?", SCHEMA_VERSION+1).Delete(&SchemaMigration{})
+}
+
+func TestForeignKeys(t *testing.T) {
+	db := repoDB.Dbmap
+	cleanUserTable()
+	cleanPolicyTable()
+	cleanStatementTable()
+	cleanPolicyVersionTable()
+	cleanAuditEventTable()
+	cleanAPIKeyTable()
+
+	insertOrphanStatement := func() error {
+		return db.Exec("INSERT INTO public.statements (id, policy_id, effect, actions, resources) VALUES (?, ?, ?, ?, ?)",
+			"OrphanStatementID", "UnknownPolicyID", "allow", "iam:*", "urn:*").Error
+	}
+
+	// Rows of unknown users and policies are refused
+	if err := insertOrphanStatement(); err == nil {
+		t.Error("Expected error inserting statement of unknown policy")
+	}
+	if err := db.Create(&PolicyVersion{PolicyID: "UnknownPolicyID", Version: 1, Name: "Name", Path: "/path/",
+		Statements: "[]", Author: "Author"}).Error; err == nil {
+		t.Error("Expected error inserting version of unknown policy")
+	}
+	if err := db.Create(&APIKey{ID: "UnknownAPIKeyID", Name: "Name", UserID: "UnknownUserID", ExternalID: "UnknownUser",
+		Hash: "Hash"}).Error; err == nil {
+		t.Error("Expected error inserting API key of unknown user")
+	}
+
+	// Statements and versions are removed with their policy
+	if err := insertPolicy("PolicyID", "Name", "Org", "/path/", 0, "urn", []Statement{
+		{
+			ID:        "StatementID",
+			PolicyID:  "PolicyID",
+			Effect:    "allow",
+			Actions:   "iam:*",
+			Resources: "urn:*",
+		},
+	}); err != nil {
+		t.Fatalf("Unexpected error inserting policy: %v", err)
+	}
+	if err := db.Create(&PolicyVersion{PolicyID: "PolicyID", Version: 1, Name: "Name", Path: "/path/",
+		Statements: "[]", Author: "Author"}).Error; err != nil {
+		t.Fatalf("Unexpected error inserting policy version: %v", err)
+	}
+	if err := db.Where("id = ?", "PolicyID").Delete(&Policy{}).Error; err != nil {
+		t.Fatalf("Unexpected error removing policy: %v", err)
+	}
+	if number, err := getStatementsCountFiltered("", "PolicyID", "", "", ""); err != nil || number != 0 {
+		t.Errorf("Expected statements removed with policy, received %v, error %v", number, err)
+	}
+	versions := 0
+	if err := db.Model(&PolicyVersion{}).Where("policy_id = ?", "PolicyID").Count(&versions).Error; err != nil || versions != 0 {
+		t.Errorf("Expected versions removed with policy, received %v, error %v", versions, err)
+	}
+
+	// API keys are removed with their user, audit events are kept
+	if err := insertUser("UserID", "ExternalID", "/path/", 0, "urn"); err != nil {
+		t.Fatalf("Unexpected error inserting user: %v", err)
+	}
+	if err := db.Create(&APIKey{ID: "APIKeyID", Name: "Name", UserID: "UserID", ExternalID: "ExternalID",
+		Hash: "Hash"}).Error; err != nil {
+		t.Fatalf("Unexpected error inserting API key: %v", err)
+	}
+	if err := db.Create(&AuditEvent{ID: "AuditEventID", Actor: "ExternalID", RequestID: "RequestID", Action: "RemoveUser",
+		Urn: "urn", Before: "{}", After: "{}"}).Error; err != nil {
+		t.Fatalf("Unexpected error inserting audit event: %v", err)
+	}
+	if err := db.Where("id = ?", "UserID").Delete(&User{}).Error; err != nil {
+		t.Fatalf("Unexpected error removing user: %v", err)
+	}
+	apiKeys := 0
+	if err := db.Model(&APIKey{}).Where("user_id = ?", "UserID").Count(&apiKeys).Error; err != nil || apiKeys != 0 {
+		t.Errorf("Expected API keys removed with user, received %v, error %v", apiKeys, err)
+	}
+	events := 0
+	if err := db.Model(&AuditEvent{}).Where("id = ?", "AuditEventID").Count(&events).Error; err != nil || events != 1 {
+		t.Errorf("Expected audit event kept after user removal, received %v, error %v", events, err)
+	}
+
+	// Schema without foreign keys allows orphan rows, and migration 2 removes them
+	for i := SCHEMA_VERSION; i > 1; i-- {
+		if _, err := MigrateDown(db); err != nil {
+			t.Fatalf("Unexpected error reverting migration %v: %v", i, err)
+		}
+	}
+	if err := insertOrphanStatement(); err != nil {
+		t.Errorf("Unexpected error inserting statement without foreign keys: %v", err)
+	}
+	if _, err := MigrateUp(db); err != nil {
+		t.Fatalf("Unexpected error applying migrations: %v", err)
+	}
+	if number, err := getStatementsCountFiltered("OrphanStatementID", "", "", "", ""); err != nil || number != 0 {
+		t.Errorf("Expected orphan statement removed by migration, received %v, error %v", number, err)
+	}
+	if err := insertOrphanStatement(); err == nil {
+		t.Error("Expected error inserting statement of unknown policy after migration")
+	}
+}
@@ -1,18 +1,20 @@
 package postgresql
 
 import (
+	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
 
 	"github.com/Tecsisa/foulkon/api"
 	"github.com/Tecsisa/foulkon/database"
+	"github.com/jinzhu/gorm"
 	"github.com/satori/go.uuid"
 )
 
 // POLICY REPOSITORY IMPLEMENTATION
 
-func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
+func (p PostgresRepo) AddPolicy(policy api.Policy, version api.PolicyVersion) (*api.Policy, error) {
 	// Create policy model
 	policyDB := &Policy{
 		ID:       policy.ID,
@@ -38,11 +40,12 @@ func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 	for _, statementApi := range *policy.Statements {
 		// Create statement model
 		statementDB := &Statement{
-			ID:        uuid.NewV4().String(),
-			PolicyID:  policy.ID,
-			Effect:    statementApi.Effect,
-			Actions:   stringArrayToString(statementApi.Actions),
-			Resources: stringArrayToString(statementApi.Resources),
+			ID:         uuid.NewV4().String(),
+			PolicyID:   policy.ID,
+			Effect:     statementApi.Effect,
+			Actions:    stringArrayToString(statementApi.Actions),
+			Resources:  stringArrayToString(statementApi.Resources),
+			Conditions: conditionsToString(statementApi.Conditions),
 		}
 		if err := transaction.Create(statementDB).Error; err != nil {
 			transaction.Rollback()
@@ -53,7 +56,21 @@ func (p PostgresRepo) AddPolicy(policy api.Policy) (*api.Policy, error) {
 		}
 	}
 
-	transaction.Commit()
+	// Create first version
+	if err := addPolicyVersion(transaction, nil, policy.ID, version); err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 
 	// Create API policy
 	policyApi := dbPolicyToAPIPolicy(policyDB)
@@ -188,7 +205,8 @@ func (p PostgresRepo) GetPoliciesFiltered(org string, filter *api.Filter) ([]api
 	return apiPolicies, total, nil
 }
 
-func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string, urn string, statements []api.Statement) (*api.Policy, error) {
+func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string, urn string, statements []api.Statement,
+	version api.PolicyVersion) (*api.Policy, error) {
 	// Create policy to update
 	policyUpdated := Policy{
 		Name: name,
@@ -228,11 +246,12 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 	// Create new statements
 	for _, s := range statements {
 		statementDB := &Statement{
-			ID:        uuid.NewV4().String(),
-			PolicyID:  policy.ID,
-			Effect:    s.Effect,
-			Actions:   stringArrayToString(s.Actions),
-			Resources: stringArrayToString(s.Resources),
+			ID:         uuid.NewV4().String(),
+			PolicyID:   policy.ID,
+			Effect:     s.Effect,
+			Actions:    stringArrayToString(s.Actions),
+			Resources:  stringArrayToString(s.Resources),
+			Conditions: conditionsToString(s.Conditions),
 		}
 		if err := transaction.Create(statementDB).Error; err != nil {
 			transaction.Rollback()
@@ -243,7 +262,21 @@ func (p PostgresRepo) UpdatePolicy(policy api.Policy, name string, path string,
 		}
 	}
 
-	transaction.Commit()
+	// Create new version. Policy row is locked by the update, so concurrent updates wait for this transaction.
+	if err := addPolicyVersion(transaction, &policy, policy.ID, version); err != nil {
+		transaction.Rollback()
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	if err := transaction.Commit().Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 
 	// Create API policy
 	policyApi := dbPolicyToAPIPolicy(&policyDB)
@@ -265,6 +298,24 @@ func (p PostgresRepo) RemovePolicy(id string) error {
 			Message: err.Error(),
 		}
 	}
+	// Delete policy relations (user)
+	transaction.Where("policy_id like ?", id).Delete(&UserPolicyRelation{})
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	// Delete policy versions
+	transaction.Where("policy_id like ?", id).Delete(&PolicyVersion{})
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
 	// Delete policy statements
 	transaction.Where("policy_id like ?", id).Delete(&Statement{})
 	if err := transaction.Error; err != nil {
@@ -321,8 +372,148 @@ func (p PostgresRepo) GetAttachedGroups(policyID string, filter *api.Filter) ([]
 	return groups, total, nil
 }
 
+func (p PostgresRepo) GetAttachedUsers(policyID string, filter *api.Filter) ([]api.User, int, error) {
+	var total int
+	relations := []UserPolicyRelation{}
+	query := p.Dbmap.Where("policy_id like ?", policyID).Find(&relations).Count(&total).Offset(filter.Offset).Limit(filter.Limit).Find(&relations)
+	var users []api.User
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, total, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform relations to API domain
+	if relations != nil {
+		users = make([]api.User, len(relations), cap(relations))
+		for i, r := range relations {
+			user, err := p.GetUserByID(r.UserID)
+			// Error handling
+			if err != nil {
+				return nil, total, &database.Error{
+					Code:    database.INTERNAL_ERROR,
+					Message: err.Error(),
+				}
+			}
+
+			users[i] = *user
+		}
+	}
+
+	return users, total, nil
+}
+
+func (p PostgresRepo) GetPolicyVersions(policyID string, filter *api.Filter) ([]api.PolicyVersion, int, error) {
+	var total int
+	versions := []PolicyVersion{}
+	query := p.Dbmap.Where("policy_id = ?", policyID)
+
+	// Error handling
+	if err := query.Model(&PolicyVersion{}).Count(&total).Order("version").Offset(filter.Offset).Limit(filter.Limit).Find(&versions).Error; err != nil {
+		return nil, total, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// Transform policy versions for API
+	apiVersions := make([]api.PolicyVersion, len(versions), cap(versions))
+	for i, v := range versions {
+		apiVersions[i] = *dbPolicyVersionToAPIPolicyVersion(&v)
+	}
+
+	return apiVersions, total, nil
+}
+
+func (p PostgresRepo) GetPolicyVersion(policyID string, version int) (*api.PolicyVersion, error) {
+	versionDB := &PolicyVersion{}
+	query := p.Dbmap.Where("policy_id = ? AND version = ?", policyID, version).First(versionDB)
+
+	// Check if policy version exists
+	if query.RecordNotFound() {
+		return nil, &database.Error{
+			Code:    database.POLICY_VERSION_NOT_FOUND,
+			Message: fmt.Sprintf("Version %v of policy with id %v not found", version, policyID),
+		}
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return nil, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return dbPolicyVersionToAPIPolicyVersion(versionDB), nil
+}
+
 // PRIVATE HELPER METHODS
 
+// Store a version of the policy in transaction with the next version number. Policies created before versioning
+// don't have versions, so previous stored policy is stored as a version first.
+func addPolicyVersion(transaction *gorm.DB, previous *api.Policy, policyID string, version api.PolicyVersion) error {
+	var lastVersion int
+	row := transaction.Raw("SELECT COALESCE(MAX(version), 0) FROM policy_versions WHERE policy_id = ?", policyID).Row()
+	if err := row.Scan(&lastVersion); err != nil {
+		return err
+	}
+
+	versions := []api.PolicyVersion{}
+	if previous != nil && lastVersion == 0 {
+		versions = append(versions, api.PolicyVersion{
+			Name:       previous.Name,
+			Path:       previous.Path,
+			Statements: previous.Statements,
+			CreateAt:   previous.CreateAt,
+		})
+	}
+	versions = append(versions, version)
+
+	for _, v := range versions {
+		statements := []api.Statement{}
+		if v.Statements != nil {
+			statements = *v.Statements
+		}
+		statementsJSON, err := json.Marshal(statements)
+		if err != nil {
+			return err
+		}
+
+		lastVersion++
+		versionDB := &PolicyVersion{
+			PolicyID:   policyID,
+			Version:    lastVersion,
+			Name:       v.Name,
+			Path:       v.Path,
+			Statements: string(statementsJSON),
+			Author:     v.Author,
+			CreateAt:   v.CreateAt.UnixNano(),
+		}
+		if err := transaction.Create(versionDB).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Transform a policy version retrieved from db into a policy version for API
+func dbPolicyVersionToAPIPolicyVersion(versiondb *PolicyVersion) *api.PolicyVersion {
+	statements := []api.Statement{}
+	json.Unmarshal([]byte(versiondb.Statements), &statements)
+	return &api.PolicyVersion{
+		PolicyID:   versiondb.PolicyID,
+		Version:    versiondb.Version,
+		Name:       versiondb.Name,
+		Path:       versiondb.Path,
+		Statements: &statements,
+		Author:     versiondb.Author,
+		CreateAt:   time.Unix(0, versiondb.CreateAt).UTC(),
+	}
+}
+
 // Transform a policy retrieved from db into a policy for API
 func dbPolicyToAPIPolicy(policydb *Policy) *api.Policy {
 	return &api.Policy{
@@ -340,9 +531,10 @@ func dbStatementsToAPIStatements(statements []Statement) *[]api.Statement {
 	statementsApi := make([]api.Statement, len(statements), cap(statements))
 	for i, s := range statements {
 		statementsApi[i] = api.Statement{
-			Actions:   strings.Split(s.Actions, ";"),
-			Effect:    s.Effect,
-			Resources: strings.Split(s.Resources, ";"),
+			Actions:    strings.Split(s.Actions, ";"),
+			Effect:     s.Effect,
+			Resources:  strings.Split(s.Resources, ";"),
+			Conditions: stringToConditions(s.Conditions),
 		}
 	}
 
@@ -362,3 +554,29 @@ func stringArrayToString(array []string) string {
 
 	return stringVal
 }
+
+// Transform a list of conditions into a JSON string, empty if there are no conditions
+func conditionsToString(conditions []api.Condition) string {
+	if len(conditions) < 1 {
+		return ""
+	}
+	b, err := json.Marshal(conditions)
+	if err != nil {
+		return ""
+	}
+
+	return string(b)
+}
+
+// Transform a JSON string into a list of conditions
+func stringToConditions(conditions string) []api.Condition {
+	if len(conditions) < 1 {
+		return nil
+	}
+	conditionsApi := []api.Condition{}
+	if err := json.Unmarshal([]byte(conditions), &conditionsApi); err != nil {
+		return nil
+	}
+
+	return conditionsApi
+}
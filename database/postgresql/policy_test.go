@@ -111,13 +111,13 @@ func TestPostgresRepo_AddPolicy(t *testing.T) {
 
 		// Call to repository to add a policy
 		if test.previousPolicy != nil {
-			_, err := repoDB.AddPolicy(*test.previousPolicy)
+			_, err := repoDB.AddPolicy(*test.previousPolicy, api.PolicyVersion{})
 			if err != nil {
 				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 				continue
 			}
 		}
-		receivedPolicy, err := repoDB.AddPolicy(test.policy)
+		receivedPolicy, err := repoDB.AddPolicy(test.policy, api.PolicyVersion{})
 		if test.expectedError != nil {
 			dbError, ok := err.(*database.Error)
 			if !ok || dbError == nil {
@@ -559,13 +559,13 @@ func TestPostgresRepo_UpdatePolicy(t *testing.T) {
 
 		// Call to repository to add a policy
 		if test.previousPolicy != nil {
-			_, err := repoDB.AddPolicy(*test.previousPolicy)
+			_, err := repoDB.AddPolicy(*test.previousPolicy, api.PolicyVersion{})
 			if err != nil {
 				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 				continue
 			}
 		}
-		receivedPolicy, err := repoDB.UpdatePolicy(test.policy, test.name, test.path, test.urn, test.statements)
+		receivedPolicy, err := repoDB.UpdatePolicy(test.policy, test.name, test.path, test.urn, test.statements, api.PolicyVersion{})
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
@@ -584,6 +584,7 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 		previousPolicy *api.Policy
 		id             string
 		group          *api.Group
+		userID         string
 	}{
 		"OkCase": {
 			previousPolicy: &api.Policy{
@@ -614,6 +615,7 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 				CreateAt: now,
 				Org:      "Org",
 			},
+			userID: "UserID",
 		},
 	}
 
@@ -623,10 +625,11 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 		cleanStatementTable()
 		cleanGroupTable()
 		cleanGroupPolicyRelationTable()
+		cleanUserPolicyRelationTable()
 
 		// Call to repository to add a policy
 		if test.previousPolicy != nil {
-			_, err := repoDB.AddPolicy(*test.previousPolicy)
+			_, err := repoDB.AddPolicy(*test.previousPolicy, api.PolicyVersion{})
 			if err != nil {
 				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 				continue
@@ -645,6 +648,13 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 				continue
 			}
 		}
+		if test.userID != "" {
+			err := insertUserPolicyRelation(test.userID, test.previousPolicy.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting user relation: %v", n, err)
+				continue
+			}
+		}
 		err := repoDB.RemovePolicy(test.id)
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
@@ -685,6 +695,16 @@ func TestPostgresRepo_RemovePolicy(t *testing.T) {
 			t.Errorf("Test %v failed. Received different relations number: %v", n, groupPolicyRelationNumber)
 			continue
 		}
+
+		userPolicyRelationNumber, err := getUserPolicyRelationCount(test.previousPolicy.ID, "")
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting user relations: %v", n, err)
+			continue
+		}
+		if userPolicyRelationNumber != 0 {
+			t.Errorf("Test %v failed. Received different user relations number: %v", n, userPolicyRelationNumber)
+			continue
+		}
 	}
 }
 
@@ -746,7 +766,7 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 		cleanGroupPolicyRelationTable()
 
 		// Call to repository to add a policy
-		_, err := repoDB.AddPolicy(*test.previousPolicy)
+		_, err := repoDB.AddPolicy(*test.previousPolicy, api.PolicyVersion{})
 		if err != nil {
 			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
 			continue
@@ -783,6 +803,99 @@ func TestPostgresRepo_GetAttachedGroups(t *testing.T) {
 	}
 }
 
+func TestPostgresRepo_GetAttachedUsers(t *testing.T) {
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		previousPolicy   *api.Policy
+		filter           *api.Filter
+		user             *api.User
+		expectedResponse []api.User
+	}{
+		"OkCase": {
+			previousPolicy: &api.Policy{
+				ID:       "test1",
+				Name:     "test",
+				Org:      "123",
+				Path:     "/path/",
+				CreateAt: now,
+				Urn:      api.CreateUrn("123", api.RESOURCE_POLICY, "/path/", "test"),
+				Statements: &[]api.Statement{
+					{
+						Effect: "allow",
+						Actions: []string{
+							api.USER_ACTION_GET_USER,
+						},
+						Resources: []string{
+							api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+						},
+					},
+				},
+			},
+			filter: testFilter,
+			user: &api.User{
+				ID:         "UserID",
+				ExternalID: "ExternalID",
+				Path:       "Path",
+				Urn:        "urn",
+				CreateAt:   now,
+			},
+			expectedResponse: []api.User{
+				{
+					ID:         "UserID",
+					ExternalID: "ExternalID",
+					Path:       "Path",
+					Urn:        "urn",
+					CreateAt:   now,
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean database
+		cleanPolicyTable()
+		cleanStatementTable()
+		cleanUserTable()
+		cleanUserPolicyRelationTable()
+
+		// Call to repository to add a policy
+		_, err := repoDB.AddPolicy(*test.previousPolicy, api.PolicyVersion{})
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if test.user != nil {
+			err := insertUser(test.user.ID, test.user.ExternalID, test.user.Path,
+				test.user.CreateAt.UnixNano(), test.user.Urn)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting user: %v", n, err)
+				continue
+			}
+			err = insertUserPolicyRelation(test.user.ID, test.previousPolicy.ID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting user relation: %v", n, err)
+				continue
+			}
+		}
+
+		users, total, err := repoDB.GetAttachedUsers(test.previousPolicy.ID, test.filter)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		// Check total
+		if total != len(test.expectedResponse) {
+			t.Errorf("Test %v failed. Received different total elements: %v", n, total)
+			continue
+		}
+		// Check response
+		if diff := pretty.Compare(users, test.expectedResponse); diff != "" {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
+
 func Test_dbPolicyToAPIPolicy(t *testing.T) {
 	now := time.Now().UTC()
 	testcases := map[string]struct {
@@ -886,6 +999,36 @@ func Test_dbStatementsToAPIStatements(t *testing.T) {
 				},
 			},
 		},
+		"OkCaseWithConditions": {
+			dbStatements: []Statement{
+				{
+					ID:         "0123",
+					Effect:     "allow",
+					PolicyID:   "1234",
+					Actions:    api.USER_ACTION_GET_USER,
+					Resources:  api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+					Conditions: `[{"operator":"IpAddress","key":"SourceIp","values":["10.0.0.0/8"]}]`,
+				},
+			},
+			apiStatements: &[]api.Statement{
+				{
+					Effect: "allow",
+					Actions: []string{
+						api.USER_ACTION_GET_USER,
+					},
+					Resources: []string{
+						api.GetUrnPrefix("", api.RESOURCE_USER, "/path/"),
+					},
+					Conditions: []api.Condition{
+						{
+							Operator: api.CONDITION_OPERATOR_IP_ADDRESS,
+							Key:      api.CONDITION_KEY_SOURCE_IP,
+							Values:   []string{"10.0.0.0/8"},
+						},
+					},
+				},
+			},
+		},
 	}
 
 	for n, test := range testcases {
@@ -927,3 +1070,38 @@ func Test_stringArrayToString(t *testing.T) {
 		}
 	}
 }
+
+func Test_conditionsToString(t *testing.T) {
+	testcases := map[string]struct {
+		conditions     []api.Condition
+		expectedString string
+	}{
+		"OkCase": {
+			conditions: []api.Condition{
+				{
+					Operator: api.CONDITION_OPERATOR_STRING_EQUALS,
+					Key:      "Department",
+					Values:   []string{"dev", "sales"},
+				},
+			},
+			expectedString: `[{"operator":"StringEquals","key":"Department","values":["dev","sales"]}]`,
+		},
+		"OkCaseEmpty": {
+			expectedString: "",
+		},
+	}
+
+	for n, test := range testcases {
+		received := conditionsToString(test.conditions)
+		// Check response
+		if received != test.expectedString {
+			t.Errorf("Test %v failed. Received different responses (received/wanted) %v / %v", n, received, test.expectedString)
+			continue
+		}
+		// Check it is transformed back
+		if diff := pretty.Compare(stringToConditions(received), test.conditions); diff != "" {
+			t.Errorf("Test %v failed. Received different conditions (received/wanted) %v", n, diff)
+			continue
+		}
+	}
+}
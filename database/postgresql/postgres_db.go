@@ -13,7 +13,28 @@ type PostgresRepo struct {
 	Dbmap *gorm.DB
 }
 
+// InitDb opens a connection to a database with the schema version expected by this binary
 func InitDb(datasourcename string, idleConns string, maxOpenConns string, connTTL string) (*gorm.DB, error) {
+	db, err := OpenDb(datasourcename, idleConns, maxOpenConns, connTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check schema version, it must be migrated before
+	if err := CheckSchemaVersion(db); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	// TODO:
+	// Activate sql logger
+	//db.LogMode(true)
+
+	return db, nil
+}
+
+// OpenDb opens a connection to a database without checking its schema
+func OpenDb(datasourcename string, idleConns string, maxOpenConns string, connTTL string) (*gorm.DB, error) {
 	// connect to db using GORM - github.com/jinzhu/gorm
 	db, err := gorm.Open("postgres", datasourcename)
 	if err != nil {
@@ -37,22 +58,15 @@ func InitDb(datasourcename string, idleConns string, maxOpenConns string, connTT
 	db.DB().SetMaxOpenConns(maxOpen)
 	db.DB().SetConnMaxLifetime(time.Duration(ttl) * time.Second)
 
+	// Record query latency
+	RegisterMetricsCallbacks(db)
+
 	// Check connection
 	err = db.DB().Ping()
 	if err != nil {
 		return nil, err
 	}
 
-	// Create tables if not exist =
-	err = db.AutoMigrate(&User{}, &Group{}, &Policy{}, &Statement{}, &GroupUserRelation{}, &GroupPolicyRelation{}).Error
-	if err != nil {
-		return nil, err
-	}
-
-	// TODO:
-	// Activate sql logger
-	//db.LogMode(true)
-
 	return db, nil
 }
 
@@ -100,13 +114,30 @@ func (Policy) TableName() string {
 	return "policies"
 }
 
+// Policy version table
+type PolicyVersion struct {
+	PolicyID   string `gorm:"primary_key"`
+	Version    int    `gorm:"primary_key;type:integer"`
+	Name       string `gorm:"not null"`
+	Path       string `gorm:"not null"`
+	Statements string `gorm:"not null"`
+	Author     string `gorm:"not null"`
+	CreateAt   int64  `gorm:"not null"`
+}
+
+// PolicyVersion's table name
+func (PolicyVersion) TableName() string {
+	return "policy_versions"
+}
+
 // Statement table
 type Statement struct {
-	ID        string `gorm:"primary_key"`
-	PolicyID  string `gorm:"not null"`
-	Effect    string `gorm:"not null"`
-	Actions   string `gorm:"not null"`
-	Resources string `gorm:"not null"`
+	ID         string `gorm:"primary_key"`
+	PolicyID   string `gorm:"not null"`
+	Effect     string `gorm:"not null"`
+	Actions    string `gorm:"not null"`
+	Resources  string `gorm:"not null"`
+	Conditions string `gorm:"not null;default:''"`
 }
 
 // Statement's table name
@@ -135,3 +166,49 @@ type GroupPolicyRelation struct {
 func (GroupPolicyRelation) TableName() string {
 	return "group_policy_relations"
 }
+
+// User Policy table
+type UserPolicyRelation struct {
+	UserID   string `gorm:"primary_key"`
+	PolicyID string `gorm:"primary_key"`
+}
+
+// UserPolicyRelation's table name
+func (UserPolicyRelation) TableName() string {
+	return "user_policy_relations"
+}
+
+// Audit event table
+type AuditEvent struct {
+	ID        string `gorm:"primary_key"`
+	Actor     string `gorm:"not null"`
+	RequestID string `gorm:"not null"`
+	Action    string `gorm:"not null"`
+	Urn       string `gorm:"not null"`
+	Before    string `gorm:"not null"`
+	After     string `gorm:"not null"`
+	CreateAt  int64  `gorm:"not null"`
+}
+
+// AuditEvent's table name
+func (AuditEvent) TableName() string {
+	return "audit_events"
+}
+
+// API key table. Expiration and revocation dates are 0 if they aren't set.
+type APIKey struct {
+	ID         string `gorm:"primary_key"`
+	Name       string `gorm:"not null"`
+	UserID     string `gorm:"not null"`
+	ExternalID string `gorm:"not null"`
+	Hash       string `gorm:"not null"`
+	CreateAt   int64  `gorm:"not null"`
+	RotateAt   int64  `gorm:"not null"`
+	ExpireAt   int64  `gorm:"not null"`
+	RevokeAt   int64  `gorm:"not null"`
+}
+
+// APIKey's table name
+func (APIKey) TableName() string {
+	return "api_keys"
+}
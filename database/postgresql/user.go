@@ -167,6 +167,30 @@ func (u PostgresRepo) RemoveUser(id string) error {
 		}
 	}
 
+	// delete all user policy relations
+	transaction.Where("user_id like ?", id).Delete(&UserPolicyRelation{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	// delete all user API keys
+	transaction.Where("user_id like ?", id).Delete(&APIKey{})
+
+	// Error handling
+	if err := transaction.Error; err != nil {
+		transaction.Rollback()
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
 	transaction.Commit()
 	return nil
 }
@@ -204,6 +228,95 @@ func (u PostgresRepo) GetGroupsByUserID(id string, filter *api.Filter) ([]api.Gr
 	return apiGroups, total, nil
 }
 
+func (u PostgresRepo) AttachPolicyToUser(userID string, policyID string) error {
+	// Create relation
+	relation := &UserPolicyRelation{
+		UserID:   userID,
+		PolicyID: policyID,
+	}
+
+	// Store relation
+	err := u.Dbmap.Create(relation).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (u PostgresRepo) DetachPolicyFromUser(userID string, policyID string) error {
+	// Remove relation
+	err := u.Dbmap.Where("user_id like ? AND policy_id like ?", userID, policyID).Delete(&UserPolicyRelation{}).Error
+
+	// Error handling
+	if err != nil {
+		return &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return nil
+}
+
+func (u PostgresRepo) IsAttachedToUser(userID string, policyID string) (bool, error) {
+	relation := UserPolicyRelation{}
+	query := u.Dbmap.Where("user_id like ? AND policy_id like ?", userID, policyID).First(&relation)
+
+	// Check if relation exists
+	if query.RecordNotFound() {
+		return false, nil
+	}
+
+	// Error Handling
+	if err := query.Error; err != nil {
+		return false, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+
+	return true, nil
+}
+
+func (u PostgresRepo) GetAttachedUserPolicies(userID string, filter *api.Filter) ([]api.Policy, int, error) {
+	var total int
+	relations := []UserPolicyRelation{}
+	query := u.Dbmap.Where("user_id like ?", userID)
+
+	// Error Handling
+	if err := query.Find(&relations).Count(&total).Offset(filter.Offset).Limit(filter.Limit).Find(&relations).Error; err != nil {
+		return nil, total, &database.Error{
+			Code:    database.INTERNAL_ERROR,
+			Message: err.Error(),
+		}
+	}
+	var apiPolicies []api.Policy
+	// Transform relations to API domain
+	if relations != nil {
+		apiPolicies = make([]api.Policy, len(relations), cap(relations))
+		for i, r := range relations {
+			policy, err := u.GetPolicyById(r.PolicyID)
+			// Error handling
+			if err != nil {
+				return nil, total, &database.Error{
+					Code:    database.INTERNAL_ERROR,
+					Message: err.Error(),
+				}
+			}
+
+			apiPolicies[i] = *policy
+		}
+	}
+
+	return apiPolicies, total, nil
+}
+
 // PRIVATE HELPER METHODS
 
 // Transform a user retrieved from db into a user for API
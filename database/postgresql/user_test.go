@@ -499,6 +499,7 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 	type relation struct {
 		userID        string
 		groupIDs      []string
+		policyIDs     []string
 		groupNotFound bool
 	}
 	now := time.Now().UTC()
@@ -518,8 +519,9 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 				CreateAt:   now,
 			},
 			relation: &relation{
-				userID:   "UserID",
-				groupIDs: []string{"GroupID1", "GroupID2"},
+				userID:    "UserID",
+				groupIDs:  []string{"GroupID1", "GroupID2"},
+				policyIDs: []string{"PolicyID1", "PolicyID2"},
 			},
 			userToDelete: "UserID",
 		},
@@ -529,6 +531,7 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 		// Clean user database
 		cleanUserTable()
 		cleanGroupUserRelationTable()
+		cleanUserPolicyRelationTable()
 
 		// Insert previous data
 		if test.previousUser != nil {
@@ -545,6 +548,12 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 					continue
 				}
 			}
+			for _, id := range test.relation.policyIDs {
+				if err := insertUserPolicyRelation(test.relation.userID, id); err != nil {
+					t.Errorf("Test %v failed. Unexpected error inserting previous user policy relations: %v", n, err)
+					continue
+				}
+			}
 		}
 		// Call to repository to remove user
 		err := repoDB.RemoveUser(test.userToDelete)
@@ -571,6 +580,16 @@ func TestPostgresRepo_RemoveUser(t *testing.T) {
 			continue
 		}
 
+		policyRelations, err := getUserPolicyRelationCount("", test.previousUser.ID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting user policy relations: %v", n, err)
+			continue
+		}
+		if policyRelations != 0 {
+			t.Errorf("Test %v failed. Received different user policy relations number: %v", n, policyRelations)
+			continue
+		}
+
 	}
 }
 
@@ -709,3 +728,322 @@ func TestPostgresRepo_GetGroupsByUserID(t *testing.T) {
 
 	}
 }
+
+func TestPostgresRepo_AttachPolicyToUser(t *testing.T) {
+	testcases := map[string]struct {
+		// Postgres Repo Args
+		policyID string
+		userID   string
+		// Expected result
+		expectedError *database.Error
+	}{
+		"OkCase": {
+			policyID: "PolicyID",
+			userID:   "UserID",
+		},
+		"ErrorCaseInternalError": {
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "pq: null value in column user_id violates not-null constraint",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean UserPolicyRelation database
+		cleanUserPolicyRelationTable()
+
+		// Call to repository to attach policy
+		err := repoDB.AttachPolicyToUser(test.userID, test.policyID)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+
+			// Check database
+			relations, err := getUserPolicyRelationCount(test.policyID, test.userID)
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+				continue
+			}
+			if relations != 1 {
+				t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+				continue
+			}
+		}
+	}
+}
+
+func TestPostgresRepo_DetachPolicyFromUser(t *testing.T) {
+	type relation struct {
+		policyID string
+		userID   string
+	}
+	testcases := map[string]struct {
+		// Previous data
+		relation *relation
+		// Postgres Repo Args
+		policyID string
+		userID   string
+		// Expected result
+		expectedError *database.Error
+	}{
+		"OkCase": {
+			relation: &relation{
+				policyID: "PolicyID",
+				userID:   "UserID",
+			},
+			policyID: "PolicyID",
+			userID:   "UserID",
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean UserPolicyRelation database
+		cleanUserPolicyRelationTable()
+
+		// Insert previous data
+		if test.relation != nil {
+			if err := insertUserPolicyRelation(test.relation.userID, test.relation.policyID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous user policy relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call to repository to detach policy
+		err := repoDB.DetachPolicyFromUser(test.userID, test.policyID)
+
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		// Check database
+		relations, err := getUserPolicyRelationCount(test.policyID, test.userID)
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error counting relations: %v", n, err)
+			continue
+		}
+		if relations != 0 {
+			t.Errorf("Test %v failed. Received different relations number: %v", n, relations)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_IsAttachedToUser(t *testing.T) {
+	type relation struct {
+		userID   string
+		policyID string
+	}
+	testcases := map[string]struct {
+		// Previous data
+		relation *relation
+		// Postgres Repo Args
+		userID   string
+		policyID string
+		// Expected result
+		expectedResult bool
+	}{
+		"OkCase": {
+			relation: &relation{
+				userID:   "UserID",
+				policyID: "PolicyID",
+			},
+			userID:         "UserID",
+			policyID:       "PolicyID",
+			expectedResult: true,
+		},
+		"OkCaseNotFound": {
+			relation: &relation{
+				userID:   "UserID",
+				policyID: "PolicyID",
+			},
+			userID:         "UserID",
+			policyID:       "PolicyIDXXXXXXX",
+			expectedResult: false,
+		},
+	}
+
+	for n, test := range testcases {
+		// Clean UserPolicyRelation database
+		cleanUserPolicyRelationTable()
+
+		// Insert previous data
+		if test.relation != nil {
+			if err := insertUserPolicyRelation(test.relation.userID, test.relation.policyID); err != nil {
+				t.Errorf("Test %v failed. Unexpected error inserting previous user policy relations: %v", n, err)
+				continue
+			}
+		}
+
+		// Call repository to check if policy is attached to user
+		result, err := repoDB.IsAttachedToUser(test.userID, test.policyID)
+
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+
+		if result != test.expectedResult {
+			t.Errorf("Test %v failed. Received %v, expected %v", n, result, test.expectedResult)
+			continue
+		}
+	}
+}
+
+func TestPostgresRepo_GetAttachedUserPolicies(t *testing.T) {
+	type relations struct {
+		policies       []api.Policy
+		userID         string
+		policyNotFound bool
+	}
+	now := time.Now().UTC()
+	testcases := map[string]struct {
+		// Previous data
+		relations  *relations
+		statements []Statement
+		// Postgres Repo Args
+		userID string
+		filter *api.Filter
+		// Expected result
+		expectedResponse []api.Policy
+		expectedError    *database.Error
+	}{
+		"OkCase": {
+			relations: &relations{
+				policies: []api.Policy{
+					{
+						ID:       "PolicyID1",
+						Name:     "Name1",
+						Org:      "org1",
+						Path:     "/path/",
+						CreateAt: now,
+						Urn:      "Urn1",
+					},
+					{
+						ID:       "PolicyID2",
+						Name:     "Name2",
+						Org:      "org1",
+						Path:     "/path/",
+						CreateAt: now,
+						Urn:      "Urn2",
+					},
+				},
+				userID: "UserID",
+			},
+			statements: []Statement{},
+			userID:     "UserID",
+			filter:     testFilter,
+			expectedResponse: []api.Policy{
+				{
+					ID:         "PolicyID1",
+					Name:       "Name1",
+					Org:        "org1",
+					Path:       "/path/",
+					CreateAt:   now,
+					Urn:        "Urn1",
+					Statements: &[]api.Statement{},
+				},
+				{
+					ID:         "PolicyID2",
+					Name:       "Name2",
+					Org:        "org1",
+					Path:       "/path/",
+					CreateAt:   now,
+					Urn:        "Urn2",
+					Statements: &[]api.Statement{},
+				},
+			},
+		},
+		"ErrorCase": {
+			relations: &relations{
+				policies: []api.Policy{
+					{
+						ID:       "PolicyID1",
+						Name:     "Name1",
+						Org:      "org1",
+						Path:     "/path/",
+						CreateAt: now,
+						Urn:      "Urn1",
+					},
+					{
+						ID:       "PolicyID2",
+						Name:     "Name2",
+						Org:      "org1",
+						Path:     "/path/",
+						CreateAt: now,
+						Urn:      "Urn2",
+					},
+				},
+				userID:         "UserID",
+				policyNotFound: true,
+			},
+			statements: []Statement{},
+			userID:     "UserID",
+			filter:     testFilter,
+			expectedError: &database.Error{
+				Code:    database.INTERNAL_ERROR,
+				Message: "Code: PolicyNotFound, Message: Policy with id PolicyID1 not found",
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		cleanPolicyTable()
+		cleanUserPolicyRelationTable()
+
+		// Insert previous data
+		if test.relations != nil {
+			for _, policy := range test.relations.policies {
+				if err := insertUserPolicyRelation(test.relations.userID, policy.ID); err != nil {
+					t.Errorf("Test %v failed. Unexpected error inserting previous user policy relations: %v", n, err)
+					continue
+				}
+				if !test.relations.policyNotFound {
+					if err := insertPolicy(policy.ID, policy.Name, policy.Org, policy.Path,
+						policy.CreateAt.UnixNano(), policy.Urn, test.statements); err != nil {
+						t.Errorf("Test %v failed. Unexpected error inserting previous data: %v", n, err)
+						continue
+					}
+				}
+			}
+
+		}
+
+		receivedPolicies, total, err := repoDB.GetAttachedUserPolicies(test.userID, test.filter)
+		if test.expectedError != nil {
+			dbError, ok := err.(*database.Error)
+			if !ok || dbError == nil {
+				t.Errorf("Test %v failed. Unexpected data retrieved from error: %v", n, err)
+				continue
+			}
+			if diff := pretty.Compare(dbError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		} else {
+			if err != nil {
+				t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+				continue
+			}
+			// Check total
+			if total != len(test.expectedResponse) {
+				t.Errorf("Test %v failed. Received different total elements: %v", n, total)
+				continue
+			}
+			// Check response
+			if diff := pretty.Compare(receivedPolicies, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
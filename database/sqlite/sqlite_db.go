@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/Tecsisa/foulkon/database/postgresql"
+	"github.com/jinzhu/gorm"
+	_ "github.com/mattn/go-sqlite3" //GORM needs to import the go-sqlite3 driver
+)
+
+// SqliteRepo stores data in a SQLite database file. Tables and queries are the same as in
+// PostgreSQL, so it uses the PostgreSQL repository implementation over a SQLite connection.
+type SqliteRepo struct {
+	postgresql.PostgresRepo
+}
+
+// NewSqliteRepo returns a repository that uses a connection created by InitDb
+func NewSqliteRepo(db *gorm.DB) SqliteRepo {
+	return SqliteRepo{
+		PostgresRepo: postgresql.PostgresRepo{
+			Dbmap: db,
+		},
+	}
+}
+
+func InitDb(path string) (*gorm.DB, error) {
+	// Connection options:
+	//  - LIKE operator is case sensitive like in PostgreSQL.
+	//  - Transactions take the write lock when they begin, and wait for other writers to finish.
+	dsn := "file:" + path
+	if strings.Contains(path, "?") {
+		dsn = dsn + "&"
+	} else {
+		dsn = dsn + "?"
+	}
+	dsn = dsn + "_cslike=true&_txlock=immediate&_busy_timeout=5000"
+
+	// connect to db using GORM - github.com/jinzhu/gorm
+	db, err := gorm.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check connection
+	err = db.DB().Ping()
+	if err != nil {
+		return nil, err
+	}
+
+	// Record query latency
+	postgresql.RegisterMetricsCallbacks(db)
+
+	// Create tables if not exist
+	err = db.AutoMigrate(&postgresql.User{}, &postgresql.Group{}, &postgresql.Policy{}, &postgresql.Statement{},
+		&postgresql.GroupUserRelation{}, &postgresql.GroupPolicyRelation{}, &postgresql.UserPolicyRelation{},
+		&postgresql.PolicyVersion{}, &postgresql.AuditEvent{}, &postgresql.APIKey{}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return db, nil
+}
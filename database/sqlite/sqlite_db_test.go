@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/database/conformance"
+	"github.com/Tecsisa/foulkon/database/postgresql"
+)
+
+func TestSqliteRepo_Conformance(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-sqlite")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	db, err := InitDb(filepath.Join(dir, "foulkon.db"))
+	if err != nil {
+		t.Fatalf("Unexpected error starting connector: %v", err)
+	}
+	defer db.Close()
+	repo := NewSqliteRepo(db)
+
+	conformance.RunTests(t, func() conformance.Repo {
+		db.Delete(&postgresql.User{})
+		db.Delete(&postgresql.Group{})
+		db.Delete(&postgresql.Policy{})
+		db.Delete(&postgresql.Statement{})
+		db.Delete(&postgresql.GroupUserRelation{})
+		db.Delete(&postgresql.GroupPolicyRelation{})
+		db.Delete(&postgresql.UserPolicyRelation{})
+		db.Delete(&postgresql.PolicyVersion{})
+		db.Delete(&postgresql.AuditEvent{})
+		db.Delete(&postgresql.APIKey{})
+		return repo
+	})
+}
+
+func TestInitDb(t *testing.T) {
+	dir, err := ioutil.TempDir("", "foulkon-sqlite")
+	if err != nil {
+		t.Fatalf("Unexpected error creating temporary directory: %v", err)
+	}
+	defer os.RemoveAll(dir)
+
+	testcases := map[string]struct {
+		path      string
+		wantError bool
+	}{
+		"OkCase": {
+			path: filepath.Join(dir, "foulkon.db"),
+		},
+		"OkCaseExistingDatabase": {
+			path: filepath.Join(dir, "foulkon.db"),
+		},
+		"ErrorCaseUnknownDirectory": {
+			path:      filepath.Join(dir, "unknown", "foulkon.db"),
+			wantError: true,
+		},
+	}
+
+	for _, n := range []string{"OkCase", "OkCaseExistingDatabase", "ErrorCaseUnknownDirectory"} {
+		test := testcases[n]
+		db, err := InitDb(test.path)
+		if test.wantError {
+			if err == nil {
+				t.Errorf("Test %v failed. Expected error", n)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("Test %v failed. Unexpected error: %v", n, err)
+			continue
+		}
+		if !db.HasTable(&postgresql.Statement{}) {
+			t.Errorf("Test %v failed. Table statements not created", n)
+		}
+		db.Close()
+	}
+}
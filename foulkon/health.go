@@ -0,0 +1,67 @@
+package foulkon
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// Timeout for readiness checks that call other services
+	READINESS_CHECK_TIMEOUT = 5 * time.Second
+
+	// Worker liveness path checked by proxy
+	WORKER_HEALTH_PATH = "/health"
+)
+
+// ReadinessCheck checks that a dependency is available to serve requests
+type ReadinessCheck struct {
+	Name  string
+	Check func() error
+}
+
+// Checks that database connection pool can reach database
+func databaseCheck(db *sql.DB) ReadinessCheck {
+	return ReadinessCheck{
+		Name: "database",
+		Check: func() error {
+			return db.Ping()
+		},
+	}
+}
+
+// Checks that url answers with a 200 status code
+func httpCheck(name string, url string) ReadinessCheck {
+	return ReadinessCheck{
+		Name: name,
+		Check: func() error {
+			client := &http.Client{Timeout: READINESS_CHECK_TIMEOUT}
+			res, err := client.Get(url)
+			if err != nil {
+				return err
+			}
+			res.Body.Close()
+			if res.StatusCode != http.StatusOK {
+				return fmt.Errorf("Unexpected status code %v from %v", res.StatusCode, url)
+			}
+			return nil
+		},
+	}
+}
+
+// Checks that configuration was loaded. Worker and proxy aren't created until it is loaded, so it always succeeds.
+func configCheck() ReadinessCheck {
+	return ReadinessCheck{
+		Name: "config",
+		Check: func() error {
+			return nil
+		},
+	}
+}
+
+// Retrieve OIDC discovery document url for this issuer
+func oidcDiscoveryURL(issuer string) string {
+	return strings.TrimSuffix(issuer, "/") + "/.well-known/openid-configuration"
+}
@@ -0,0 +1,69 @@
+package foulkon
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/pelletier/go-toml"
+)
+
+// Lint analyzes policy files of the organization, writing findings to out. Each file contains a policy
+// with the same format used to create it in the API. If proxy configuration isn't nil, actions of its
+// resources are the known actions. It returns the number of findings.
+func Lint(org string, files []string, proxyConfig *toml.TomlTree, out io.Writer) (int, error) {
+	if !api.IsValidOrg(org) {
+		return 0, fmt.Errorf("Invalid parameter: org %v", org)
+	}
+	if len(files) < 1 {
+		return 0, fmt.Errorf("No policy files to lint")
+	}
+
+	var knownActions []string
+	if proxyConfig != nil {
+		resources, err := getAPIResources(proxyConfig)
+		if err != nil {
+			return 0, err
+		}
+		for _, resource := range resources {
+			knownActions = append(knownActions, resource.Action)
+		}
+	}
+
+	total := 0
+	for _, file := range files {
+		policy, err := readPolicyFile(file)
+		if err != nil {
+			return total, err
+		}
+		findings := api.LintStatements(org, *policy.Statements, knownActions)
+		for _, finding := range findings {
+			fmt.Fprintf(out, "%v: policy %v, statement %v: %v: %v\n", file, policy.Name, finding.Statement, finding.Type, finding.Message)
+		}
+		total += len(findings)
+	}
+	return total, nil
+}
+
+// This aux method reads a policy file and validates its statements
+func readPolicyFile(file string) (*api.Policy, error) {
+	f, err := os.Open(file)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	policy := &api.Policy{}
+	if err := json.NewDecoder(f).Decode(policy); err != nil {
+		return nil, fmt.Errorf("Cannot parse policy file %v, error: %v", file, err)
+	}
+	if policy.Statements == nil {
+		return nil, fmt.Errorf("Invalid policy file %v, error: Empty statements", file)
+	}
+	if err := api.AreValidStatements(policy.Statements); err != nil {
+		return nil, fmt.Errorf("Invalid policy file %v, error: %v", file, err.(*api.Error).Message)
+	}
+	return policy, nil
+}
@@ -0,0 +1,54 @@
+package foulkon
+
+import (
+	"errors"
+	"io"
+	"sync/atomic"
+
+	log "github.com/Sirupsen/logrus"
+)
+
+// Formatter that drops entries above a level that can be changed while the logger is used.
+// Logrus reads logger level without synchronization, so loggers keep debug level and this formatter
+// applies the configured one.
+type levelFormatter struct {
+	log.Formatter
+	level uint32
+}
+
+func (f *levelFormatter) Format(entry *log.Entry) ([]byte, error) {
+	if entry.Level > f.getLevel() {
+		return nil, nil
+	}
+	return f.Formatter.Format(entry)
+}
+
+func (f *levelFormatter) getLevel() log.Level {
+	return log.Level(atomic.LoadUint32(&f.level))
+}
+
+func (f *levelFormatter) setLevel(level log.Level) {
+	atomic.StoreUint32(&f.level, uint32(level))
+}
+
+// NewLogger creates a JSON logger whose level can be changed on reload while it is used
+func NewLogger(out io.Writer, level log.Level) *log.Logger {
+	return &log.Logger{
+		Out: out,
+		Formatter: &levelFormatter{
+			Formatter: &log.JSONFormatter{},
+			level:     uint32(level),
+		},
+		Hooks: make(log.LevelHooks),
+		Level: log.DebugLevel,
+	}
+}
+
+// This aux method returns the formatter of loggers created with NewLogger
+func getLevelFormatter(logger *log.Logger) (*levelFormatter, error) {
+	formatter, ok := logger.Formatter.(*levelFormatter)
+	if !ok {
+		return nil, errors.New("Logger not created with NewLogger, its level can't be changed")
+	}
+	return formatter, nil
+}
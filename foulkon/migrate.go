@@ -0,0 +1,85 @@
+package foulkon
+
+import (
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/Tecsisa/foulkon/database/postgresql"
+	"github.com/pelletier/go-toml"
+)
+
+const (
+	MIGRATE_UP     = "up"
+	MIGRATE_DOWN   = "down"
+	MIGRATE_STATUS = "status"
+)
+
+// Migrate runs a database schema migration command using worker configuration, writing results to out
+func Migrate(config *toml.TomlTree, command string, out io.Writer) error {
+	dbType, err := getMandatoryValue(config, "database.type")
+	if err != nil {
+		return err
+	}
+	if dbType != "postgres" {
+		return fmt.Errorf("Schema migrations aren't available for database type %v", dbType)
+	}
+	dbdsn, err := getMandatoryValue(config, "database.postgres.datasourcename")
+	if err != nil {
+		return err
+	}
+	gormDB, err := postgresql.OpenDb(dbdsn,
+		getDefaultValue(config, "database.postgres.idleconns", "5"),
+		getDefaultValue(config, "database.postgres.maxopenconns", "20"),
+		getDefaultValue(config, "database.postgres.connttl", "300"),
+	)
+	if err != nil {
+		return err
+	}
+	defer gormDB.Close()
+
+	switch command {
+	case MIGRATE_UP:
+		applied, err := postgresql.MigrateUp(gormDB)
+		for _, m := range applied {
+			fmt.Fprintf(out, "Applied migration %v: %v\n", m.Version, m.Description)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Fprintln(out, "Database schema is up to date")
+		}
+	case MIGRATE_DOWN:
+		reverted, err := postgresql.MigrateDown(gormDB)
+		if err != nil {
+			return err
+		}
+		if reverted == nil {
+			fmt.Fprintln(out, "There are no migrations to revert")
+		} else {
+			fmt.Fprintf(out, "Reverted migration %v: %v\n", reverted.Version, reverted.Description)
+		}
+	case MIGRATE_STATUS:
+		version, err := postgresql.GetSchemaVersion(gormDB)
+		if err != nil {
+			return err
+		}
+		status, err := postgresql.GetMigrationsStatus(gormDB)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "Database schema version: %v, expected version: %v\n", version, postgresql.SCHEMA_VERSION)
+		for _, m := range status {
+			if m.Applied {
+				fmt.Fprintf(out, "%4v  applied %v  %v\n", m.Version, m.AppliedAt.Format("2006-01-02 15:04:05 MST"), m.Description)
+			} else {
+				fmt.Fprintf(out, "%4v  pending %23v  %v\n", m.Version, "", m.Description)
+			}
+		}
+	default:
+		return errors.New("Unknown migrate command, expected up, down or status")
+	}
+
+	return nil
+}
@@ -3,6 +3,9 @@ package foulkon
 import (
 	"io"
 	"os"
+	"strings"
+	"sync"
+	"time"
 
 	"errors"
 
@@ -20,6 +23,9 @@ type Proxy struct {
 	Host string
 	Port string
 
+	// Time to wait for in-flight requests when proxy is stopped
+	ShutdownTimeout time.Duration
+
 	// Worker location
 	WorkerHost string
 
@@ -27,11 +33,21 @@ type Proxy struct {
 	CertFile string
 	KeyFile  string
 
+	// Address of the listener that exposes metrics, disabled if empty
+	MetricsAddress string
+
 	// Logger
 	Logger *log.Logger
 
-	// API Resources
+	// API Resources, use GetAPIResources to read them while proxy is running
 	APIResources []APIResource
+
+	// Checks done to know if proxy is ready to serve requests
+	ReadinessChecks []ReadinessCheck
+
+	// Resources can be reloaded while requests are served
+	lock        sync.RWMutex
+	reloadHooks []func(resources []APIResource) error
 }
 
 // APIResource represents external API resources to authorize
@@ -64,33 +80,17 @@ func NewProxy(config *toml.TomlTree) (*Proxy, error) {
 		loglevel = log.InfoLevel
 	}
 
-	logger := &log.Logger{
-		Out:       logOut,
-		Formatter: &log.JSONFormatter{},
-		Hooks:     make(log.LevelHooks),
-		Level:     loglevel,
-	}
-	logger.Infof("Logger type: %v, LogLevel: %v", loggerType, logger.Level.String())
+	logger := NewLogger(logOut, loglevel)
+	logger.Infof("Logger type: %v, LogLevel: %v", loggerType, loglevel.String())
 
 	// API Resources
-	resources := []APIResource{}
-	// Retrieve resource tree from toml config file
-	tree, ok := config.Get("resources").([]*toml.TomlTree)
-	if !ok {
-		err := errors.New("No resources retrieved from file")
+	resources, err := getAPIResources(config)
+	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
-	for _, t := range tree {
-		resources = append(resources, APIResource{
-			Id:     getDefaultValue(t, "id", ""),
-			Host:   getDefaultValue(t, "host", ""),
-			Url:    getDefaultValue(t, "url", ""),
-			Method: getDefaultValue(t, "method", ""),
-			Urn:    getDefaultValue(t, "urn", ""),
-			Action: getDefaultValue(t, "action", ""),
-		})
-		logger.Infof("Added resource %v", getDefaultValue(t, "id", ""))
+	for _, res := range resources {
+		logger.Infof("Added resource %v", res.Id)
 	}
 
 	host, err := getMandatoryValue(config, "server.host")
@@ -108,18 +108,73 @@ func NewProxy(config *toml.TomlTree) (*Proxy, error) {
 		logger.Error(err)
 		return nil, err
 	}
+	shutdownTimeout, err := getShutdownTimeout(config)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
 
 	return &Proxy{
-		Host:         host,
-		Port:         port,
-		WorkerHost:   workerHost,
-		CertFile:     getDefaultValue(config, "server.certfile", ""),
-		KeyFile:      getDefaultValue(config, "server.keyfile", ""),
-		Logger:       logger,
-		APIResources: resources,
+		Host:            host,
+		Port:            port,
+		ShutdownTimeout: shutdownTimeout,
+		WorkerHost:      workerHost,
+		CertFile:        getDefaultValue(config, "server.certfile", ""),
+		KeyFile:         getDefaultValue(config, "server.keyfile", ""),
+		MetricsAddress:  getDefaultValue(config, "server.metricsaddress", ""),
+		Logger:          logger,
+		APIResources:    resources,
+		ReadinessChecks: []ReadinessCheck{
+			configCheck(),
+			httpCheck("worker", strings.TrimSuffix(workerHost, "/")+WORKER_HEALTH_PATH),
+		},
 	}, nil
 }
 
+// GetAPIResources returns current resources
+func (p *Proxy) GetAPIResources() []APIResource {
+	p.lock.RLock()
+	defer p.lock.RUnlock()
+	return p.APIResources
+}
+
+// OnReload adds a function called with new resources when they are reloaded.
+// If it returns an error, reload is cancelled.
+func (p *Proxy) OnReload(hook func(resources []APIResource) error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	p.reloadHooks = append(p.reloadHooks, hook)
+}
+
+// Reload applies log level and resources from configuration values, without stopping the proxy.
+// If any of them is invalid, none is applied.
+func (p *Proxy) Reload(config *toml.TomlTree) error {
+	loglevel, err := log.ParseLevel(getDefaultValue(config, "logger.level", "info"))
+	if err != nil {
+		return err
+	}
+	resources, err := getAPIResources(config)
+	if err != nil {
+		return err
+	}
+	formatter, err := getLevelFormatter(p.Logger)
+	if err != nil {
+		return err
+	}
+
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	for _, hook := range p.reloadHooks {
+		if err := hook(resources); err != nil {
+			return err
+		}
+	}
+	formatter.setLevel(loglevel)
+	p.APIResources = resources
+	p.Logger.Infof("Configuration reloaded. LogLevel: %v, resources: %v", loglevel.String(), len(resources))
+	return nil
+}
+
 func CloseProxy() int {
 	status := 0
 	if proxyLogfile != nil {
@@ -130,3 +185,24 @@ func CloseProxy() int {
 	}
 	return status
 }
+
+// This aux method returns resources defined in config file
+func getAPIResources(config *toml.TomlTree) ([]APIResource, error) {
+	resources := []APIResource{}
+	// Retrieve resource tree from toml config file
+	tree, ok := config.Get("resources").([]*toml.TomlTree)
+	if !ok {
+		return nil, errors.New("No resources retrieved from file")
+	}
+	for _, t := range tree {
+		resources = append(resources, APIResource{
+			Id:     getDefaultValue(t, "id", ""),
+			Host:   getDefaultValue(t, "host", ""),
+			Url:    getDefaultValue(t, "url", ""),
+			Method: getDefaultValue(t, "method", ""),
+			Urn:    getDefaultValue(t, "urn", ""),
+			Action: getDefaultValue(t, "action", ""),
+		})
+	}
+	return resources, nil
+}
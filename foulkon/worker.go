@@ -2,7 +2,10 @@ package foulkon
 
 import (
 	"io"
+	"net"
 	"regexp"
+	"strconv"
+	"time"
 
 	"errors"
 	"os"
@@ -15,7 +18,10 @@ import (
 	log "github.com/Sirupsen/logrus"
 	"github.com/Tecsisa/foulkon/api"
 	"github.com/Tecsisa/foulkon/auth"
+	"github.com/Tecsisa/foulkon/database/memory"
 	"github.com/Tecsisa/foulkon/database/postgresql"
+	"github.com/Tecsisa/foulkon/database/sqlite"
+	"github.com/Tecsisa/foulkon/metrics"
 	"github.com/pelletier/go-toml"
 )
 
@@ -31,21 +37,39 @@ type Worker struct {
 	Host string
 	Port string
 
+	// Time to wait for in-flight requests when worker is stopped
+	ShutdownTimeout time.Duration
+
 	// TLS configuration
 	CertFile string
 	KeyFile  string
 
+	// Address of the listener that exposes metrics, disabled if empty
+	MetricsAddress string
+
+	// Proxies trusted to add client address to X-Forwarded-For header and to send request context headers
+	TrustedProxies []*net.IPNet
+
 	// APIs
 	UserApi   api.UserAPI
 	GroupApi  api.GroupAPI
 	PolicyApi api.PolicyAPI
 	AuthzApi  api.AuthzAPI
+	AuditApi  api.AuditAPI
+	ImportApi api.ImportAPI
+	APIKeyApi api.APIKeyAPI
 
 	// Logger
 	Logger *log.Logger
 
+	// Effective statements cache, nil if disabled
+	StatementCache *api.StatementCache
+
 	//  Auth connector
 	Authenticator *auth.Authenticator
+
+	// Checks done to know if worker is ready to serve requests
+	ReadinessChecks []ReadinessCheck
 }
 
 // NewWorker creates a Worker using configuration values
@@ -70,16 +94,12 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 		loglevel = log.InfoLevel
 	}
 
-	logger = &log.Logger{
-		Out:       logOut,
-		Formatter: &log.JSONFormatter{},
-		Hooks:     make(log.LevelHooks),
-		Level:     loglevel,
-	}
-	logger.Infof("Logger type: %v, LogLevel: %v", loggerType, logger.Level.String())
+	logger = NewLogger(logOut, loglevel)
+	logger.Infof("Logger type: %v, LogLevel: %v", loggerType, loglevel.String())
 
 	// Start DB with API
 	var authApi api.AuthAPI
+	readinessChecks := []ReadinessCheck{configCheck()}
 
 	dbType, err := getMandatoryValue(config, "database.type")
 	if err != nil {
@@ -104,6 +124,7 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 			return nil, err
 		}
 		db = gormDB.DB()
+		readinessChecks = append(readinessChecks, databaseCheck(db))
 		logger.Info("Connected to postgres database")
 
 		// Create repository
@@ -114,57 +135,130 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 			GroupRepo:  repoDB,
 			UserRepo:   repoDB,
 			PolicyRepo: repoDB,
+			AuthzRepo:  repoDB,
+			AuditRepo:  repoDB,
+			ImportRepo: repoDB,
+			APIKeyRepo: repoDB,
 		}
 
-	default:
-		err := errors.New("Unexpected db_type value in configuration file (Maybe it is empty)")
-		logger.Error(err)
-		return nil, err
-	}
-
-	authApi.Logger = logger
-
-	// Instantiate Auth Connector
-	var authConnector auth.AuthConnector
-	authType, err := getMandatoryValue(config, "authenticator.type")
-	if err != nil {
-		return nil, err
-	}
-	switch authType {
-	case "oidc":
-		issuer, err := getMandatoryValue(config, "authenticator.oidc.issuer")
-		if err != nil {
-			return nil, err
-		}
-		clientsids, err := getMandatoryValue(config, "authenticator.oidc.clientids")
+	case "sqlite": // SQLite DB
+		logger.Info("Opening sqlite database")
+		dbpath, err := getMandatoryValue(config, "database.sqlite.path")
 		if err != nil {
+			logger.Error(err)
 			return nil, err
 		}
-		authOidcConnector, err := auth.InitOIDCConnector(logger, issuer, strings.Split(clientsids, ";"))
+		gormDB, err := sqlite.InitDb(dbpath)
 		if err != nil {
 			logger.Error(err)
 			return nil, err
 		}
-		authConnector = authOidcConnector
-		logger.Infof("OIDC connector configured for issuer %v", issuer)
+		db = gormDB.DB()
+		readinessChecks = append(readinessChecks, databaseCheck(db))
+		logger.Infof("Opened sqlite database %v", dbpath)
+
+		// Create repository
+		repoDB := sqlite.NewSqliteRepo(gormDB)
+		authApi = api.AuthAPI{
+			GroupRepo:  repoDB,
+			UserRepo:   repoDB,
+			PolicyRepo: repoDB,
+			AuthzRepo:  repoDB,
+			AuditRepo:  repoDB,
+			ImportRepo: repoDB,
+			APIKeyRepo: repoDB,
+		}
+
+	case "memory": // In-memory DB, data is lost when worker stops
+		logger.Info("Using in-memory database")
+
+		// Create repository
+		repoDB := memory.NewMemoryRepo()
+		authApi = api.AuthAPI{
+			GroupRepo:  repoDB,
+			UserRepo:   repoDB,
+			PolicyRepo: repoDB,
+			AuthzRepo:  repoDB,
+			AuditRepo:  repoDB,
+			ImportRepo: repoDB,
+			APIKeyRepo: repoDB,
+		}
+
 	default:
-		err := errors.New("Unexpected auth_connector_type value in configuration file (Maybe it is empty)")
+		err := errors.New("Unexpected db_type value in configuration file (Maybe it is empty)")
 		logger.Error(err)
 		return nil, err
 	}
 
-	adminUser, err := getMandatoryValue(config, "admin.username")
+	authApi.Logger = logger
+
+	// Effective statements cache. Disabled by default
+	cacheTTL, err := strconv.Atoi(getDefaultValue(config, "cache.ttl", "0"))
 	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
-	adminPassword, err := getMandatoryValue(config, "admin.password")
+	authApi.Cache = api.NewStatementCache(time.Duration(cacheTTL) * time.Second)
+	if authApi.Cache != nil {
+		logger.Infof("Effective statements cache enabled with TTL %v seconds", cacheTTL)
+		cache := authApi.Cache
+		metrics.RegisterCounterFunc("foulkon_statement_cache_hits_total", "Effective statements cache hits.",
+			func() float64 { return float64(cache.Hits()) })
+		metrics.RegisterCounterFunc("foulkon_statement_cache_misses_total", "Effective statements cache misses.",
+			func() float64 { return float64(cache.Misses()) })
+	}
+
+	// Instantiate Auth Connectors, tried in configured order
+	authTypes, err := getMandatoryValue(config, "authenticator.type")
 	if err != nil {
-		logger.Error(err)
 		return nil, err
 	}
-	if len(strings.TrimSpace(adminUser)) < 1 || len(strings.TrimSpace(adminPassword)) < 1 {
-		err := fmt.Errorf("Admin user config unexpected adminUser:%v, adminpassword:%v", adminUser, adminPassword)
+	authConnectors := []auth.NamedAuthConnector{}
+	for _, authType := range strings.Split(authTypes, ";") {
+		authType = strings.TrimSpace(authType)
+		for _, connector := range authConnectors {
+			if connector.Name == authType {
+				err := fmt.Errorf("Duplicated authenticator type %v in configuration file", authType)
+				logger.Error(err)
+				return nil, err
+			}
+		}
+		var authConnector auth.AuthConnector
+		switch authType {
+		case "oidc":
+			issuer, err := getMandatoryValue(config, "authenticator.oidc.issuer")
+			if err != nil {
+				return nil, err
+			}
+			clientsids, err := getMandatoryValue(config, "authenticator.oidc.clientids")
+			if err != nil {
+				return nil, err
+			}
+			authOidcConnector, err := auth.InitOIDCConnector(logger, issuer, strings.Split(clientsids, ";"))
+			if err != nil {
+				logger.Error(err)
+				return nil, err
+			}
+			authConnector = authOidcConnector
+			readinessChecks = append(readinessChecks, httpCheck("oidc", oidcDiscoveryURL(issuer)))
+			logger.Infof("OIDC connector configured for issuer %v", issuer)
+		case "apikey":
+			authConnector = auth.NewAPIKeyConnector(logger, authApi)
+			logger.Infof("API key connector configured with header %v", auth.API_KEY_HEADER)
+		default:
+			err := errors.New("Unexpected auth_connector_type value in configuration file (Maybe it is empty)")
+			logger.Error(err)
+			return nil, err
+		}
+		authConnectors = append(authConnectors, auth.NamedAuthConnector{
+			Name:      authType,
+			Connector: authConnector,
+		})
+	}
+	authConnector := auth.NewChainConnector(authConnectors)
+
+	adminUser, adminPassword, err := getAdminCredentials(config)
+	if err != nil {
 		logger.Error(err)
 		return nil, err
 	}
@@ -182,26 +276,68 @@ func NewWorker(config *toml.TomlTree) (*Worker, error) {
 		logger.Error(err)
 		return nil, err
 	}
+	shutdownTimeout, err := getShutdownTimeout(config)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
+	trustedProxies, err := getTrustedProxies(config)
+	if err != nil {
+		logger.Error(err)
+		return nil, err
+	}
 
 	return &Worker{
-		Host:          host,
-		Port:          port,
-		CertFile:      getDefaultValue(config, "server.certfile", ""),
-		KeyFile:       getDefaultValue(config, "server.keyfile", ""),
-		Logger:        logger,
-		Authenticator: authenticator,
-		UserApi:       authApi,
-		GroupApi:      authApi,
-		PolicyApi:     authApi,
-		AuthzApi:      authApi,
+		Host:            host,
+		Port:            port,
+		ShutdownTimeout: shutdownTimeout,
+		CertFile:        getDefaultValue(config, "server.certfile", ""),
+		KeyFile:         getDefaultValue(config, "server.keyfile", ""),
+		MetricsAddress:  getDefaultValue(config, "server.metricsaddress", ""),
+		TrustedProxies:  trustedProxies,
+		Logger:          logger,
+		StatementCache:  authApi.Cache,
+		Authenticator:   authenticator,
+		UserApi:         authApi,
+		GroupApi:        authApi,
+		PolicyApi:       authApi,
+		AuthzApi:        authApi,
+		AuditApi:        authApi,
+		ImportApi:       authApi,
+		APIKeyApi:       authApi,
+		ReadinessChecks: readinessChecks,
 	}, nil
 }
 
+// Reload applies log level and admin credentials from configuration values, without stopping the worker.
+// If any of them is invalid, none is applied.
+func (w *Worker) Reload(config *toml.TomlTree) error {
+	loglevel, err := log.ParseLevel(getDefaultValue(config, "logger.level", "info"))
+	if err != nil {
+		return err
+	}
+	adminUser, adminPassword, err := getAdminCredentials(config)
+	if err != nil {
+		return err
+	}
+	formatter, err := getLevelFormatter(w.Logger)
+	if err != nil {
+		return err
+	}
+
+	formatter.setLevel(loglevel)
+	w.Authenticator.SetAdminCredentials(adminUser, adminPassword)
+	w.Logger.Infof("Configuration reloaded. LogLevel: %v, admin username: %v", loglevel.String(), adminUser)
+	return nil
+}
+
 func CloseWorker() int {
 	status := 0
-	if err := db.Close(); err != nil {
-		logger.Errorf("Couldn't close DB connection: %v", err)
-		status = 1
+	if db != nil {
+		if err := db.Close(); err != nil {
+			logger.Errorf("Couldn't close DB connection: %v", err)
+			status = 1
+		}
 	}
 	if workerLogfile != nil {
 		if err := workerLogfile.Close(); err != nil {
@@ -212,6 +348,87 @@ func CloseWorker() int {
 	return status
 }
 
+// GetWorkerAdminAccess returns the URL of the worker with this configuration and its admin user and password.
+// If workerURL isn't empty, it's returned as is. Else, it's created from server host and port, using https
+// if the worker has a certificate.
+func GetWorkerAdminAccess(config *toml.TomlTree, workerURL string) (string, string, string, error) {
+	username, password, err := getAdminCredentials(config)
+	if err != nil {
+		return "", "", "", err
+	}
+	if workerURL == "" {
+		host, err := getMandatoryValue(config, "server.host")
+		if err != nil {
+			return "", "", "", err
+		}
+		port, err := getMandatoryValue(config, "server.port")
+		if err != nil {
+			return "", "", "", err
+		}
+		scheme := "http"
+		if config.Has("server.certfile") && getVar(config, "server.certfile") != "" {
+			scheme = "https"
+		}
+		workerURL = fmt.Sprintf("%v://%v:%v", scheme, host, port)
+	}
+	return workerURL, username, password, nil
+}
+
+// This aux method returns admin user and password, that can't be empty
+func getAdminCredentials(config *toml.TomlTree) (string, string, error) {
+	adminUser, err := getMandatoryValue(config, "admin.username")
+	if err != nil {
+		return "", "", err
+	}
+	adminPassword, err := getMandatoryValue(config, "admin.password")
+	if err != nil {
+		return "", "", err
+	}
+	if len(strings.TrimSpace(adminUser)) < 1 || len(strings.TrimSpace(adminPassword)) < 1 {
+		return "", "", fmt.Errorf("Admin user config unexpected adminUser:%v, adminpassword:%v", adminUser, adminPassword)
+	}
+	return adminUser, adminPassword, nil
+}
+
+// This aux method returns time to wait for in-flight requests when server is stopped
+func getShutdownTimeout(config *toml.TomlTree) (time.Duration, error) {
+	value := getDefaultValue(config, "server.shutdowntimeout", "30")
+	timeout, err := strconv.Atoi(value)
+	if err != nil || timeout < 0 {
+		return 0, fmt.Errorf("Invalid server shutdowntimeout param: %v", value)
+	}
+	return time.Duration(timeout) * time.Second, nil
+}
+
+// This aux method returns addresses or CIDR ranges of trusted proxies, separated by ;
+func getTrustedProxies(config *toml.TomlTree) ([]*net.IPNet, error) {
+	trustedProxies := []*net.IPNet{}
+	value := getDefaultValue(config, "server.trustedproxies", "")
+	for _, proxy := range strings.Split(value, ";") {
+		proxy = strings.TrimSpace(proxy)
+		if proxy == "" {
+			continue
+		}
+		if !strings.Contains(proxy, "/") {
+			ip := net.ParseIP(proxy)
+			if ip == nil {
+				return nil, fmt.Errorf("Invalid server trustedproxies param: %v", proxy)
+			}
+			if ip.To4() != nil {
+				proxy += "/32"
+			} else {
+				proxy += "/128"
+			}
+		}
+		_, ipNet, err := net.ParseCIDR(proxy)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid server trustedproxies param: %v", proxy)
+		}
+		trustedProxies = append(trustedProxies, ipNet)
+	}
+	return trustedProxies, nil
+}
+
 // This aux method returns mandatory config value or any error occurred
 func getMandatoryValue(config *toml.TomlTree, key string) (string, error) {
 	if !config.Has(key) {
@@ -0,0 +1,177 @@
+package http
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// REQUESTS
+
+type CreateAPIKeyRequest struct {
+	Name     string     `json:"name, omitempty"`
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+}
+
+type RotateAPIKeyRequest struct {
+	ExpireAt *time.Time `json:"expireAt, omitempty"`
+}
+
+// RESPONSES
+
+type ListAPIKeysResponse struct {
+	APIKeys []api.APIKey `json:"apiKeys, omitempty"`
+	Limit   int          `json:"limit, omitempty"`
+	Offset  int          `json:"offset, omitempty"`
+	Total   int          `json:"total, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleCreateAPIKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := CreateAPIKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Call API key API to create the key
+	response, err := h.worker.APIKeyApi.CreateAPIKey(requestInfo, id, request.Name, request.ExpireAt)
+	if err != nil {
+		h.respondAPIKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write key with its secret to response
+	h.RespondCreated(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleListAPIKeys(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id from path
+	id := ps.ByName(USER_ID)
+
+	// Retrieve filterData
+	filterData, err := getFilterData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call API key API to retrieve keys of user
+	result, total, err := h.worker.APIKeyApi.ListAPIKeys(requestInfo, id, filterData)
+	if err != nil {
+		h.respondAPIKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListAPIKeysResponse{
+		APIKeys: result,
+		Offset:  filterData.Offset,
+		Limit:   filterData.Limit,
+		Total:   total,
+	}
+
+	// Return API keys
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetAPIKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id and key id from path
+	id := ps.ByName(USER_ID)
+	keyID := ps.ByName(API_KEY_ID)
+
+	// Call API key API to retrieve key
+	response, err := h.worker.APIKeyApi.GetAPIKey(requestInfo, id, keyID)
+	if err != nil {
+		h.respondAPIKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Return API key
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRotateAPIKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request, body is optional to keep expiration date
+	request := RotateAPIKeyRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil && err != io.EOF {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	// Retrieve user id and key id from path
+	id := ps.ByName(USER_ID)
+	keyID := ps.ByName(API_KEY_ID)
+
+	// Call API key API to rotate key
+	response, err := h.worker.APIKeyApi.RotateAPIKey(requestInfo, id, keyID, request.ExpireAt)
+	if err != nil {
+		h.respondAPIKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write key with its new secret to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRevokeAPIKey(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user id and key id from path
+	id := ps.ByName(USER_ID)
+	keyID := ps.ByName(API_KEY_ID)
+
+	// Call API key API to revoke key
+	response, err := h.worker.APIKeyApi.RevokeAPIKey(requestInfo, id, keyID)
+	if err != nil {
+		h.respondAPIKeyError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write revoked key to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+func (h *WorkerHandler) respondAPIKeyError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.API_KEY_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.API_KEY_REVOKED:
+		h.RespondConflict(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,770 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestWorkerHandler_HandleCreateAPIKey(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	expireAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		request      interface{}
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedName       string
+		expectedExpireAt   *time.Time
+		expectedResponse   *api.APIKeySecret
+		expectedError      api.Error
+		// Manager Results
+		createAPIKeyResult *api.APIKeySecret
+		// Manager Errors
+		createAPIKeyErr error
+	}{
+		"OkCase": {
+			request: &CreateAPIKeyRequest{
+				Name:     "deploy",
+				ExpireAt: &expireAt,
+			},
+			expectedStatusCode: http.StatusCreated,
+			expectedName:       "deploy",
+			expectedExpireAt:   &expireAt,
+			expectedResponse: &api.APIKeySecret{
+				APIKey: api.APIKey{
+					ID:         "KEY-ID",
+					Name:       "deploy",
+					UserID:     "USER-ID",
+					ExternalID: "service",
+					CreateAt:   now,
+					RotateAt:   now,
+					ExpireAt:   &expireAt,
+				},
+				Key: "KEY-ID.secret",
+			},
+			createAPIKeyResult: &api.APIKeySecret{
+				APIKey: api.APIKey{
+					ID:         "KEY-ID",
+					Name:       "deploy",
+					UserID:     "USER-ID",
+					ExternalID: "service",
+					Hash:       "hash",
+					CreateAt:   now,
+					RotateAt:   now,
+					ExpireAt:   &expireAt,
+				},
+				Key: "KEY-ID.secret",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			request:            "{malformed}",
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "json: cannot unmarshal string into Go value of type http.CreateAPIKeyRequest",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			request: &CreateAPIKeyRequest{
+				Name: "deploy",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedName:       "deploy",
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+			createAPIKeyErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			request: &CreateAPIKeyRequest{
+				Name: "*%~#@|",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedName:       "*%~#@|",
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+			createAPIKeyErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &CreateAPIKeyRequest{
+				Name: "deploy",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedName:       "deploy",
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			createAPIKeyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &CreateAPIKeyRequest{
+				Name: "deploy",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedName:       "deploy",
+			createAPIKeyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[CreateAPIKeyMethod][0] = test.createAPIKeyResult
+		testApi.ArgsOut[CreateAPIKeyMethod][1] = test.createAPIKeyErr
+
+		body := &bytes.Buffer{}
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/service/api-keys", body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if !test.ignoreArgsIn {
+			// Check received parameters
+			if testApi.ArgsIn[CreateAPIKeyMethod][1] != "service" {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, "service", testApi.ArgsIn[CreateAPIKeyMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[CreateAPIKeyMethod][2] != test.expectedName {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.expectedName, testApi.ArgsIn[CreateAPIKeyMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[CreateAPIKeyMethod][3], test.expectedExpireAt); diff != "" {
+				t.Errorf("Test %v failed. Received different expiration dates (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusCreated:
+			apiKeyResponse := &api.APIKeySecret{}
+			err = json.NewDecoder(res.Body).Decode(apiKeyResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result, hash is never returned
+			if diff := pretty.Compare(apiKeyResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAPIKeys(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testAPIKeys := []api.APIKey{
+		{
+			ID:         "KEY-ID",
+			Name:       "deploy",
+			UserID:     "USER-ID",
+			ExternalID: "service",
+			CreateAt:   now,
+			RotateAt:   now,
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		queryParams  url.Values
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedFilter     *api.Filter
+		expectedResponse   ListAPIKeysResponse
+		expectedError      api.Error
+		// Manager Results
+		listAPIKeysResult []api.APIKey
+		totalResult       int
+		// Manager Errors
+		listAPIKeysErr error
+	}{
+		"OkCase": {
+			queryParams: url.Values{
+				"Offset": {"0"},
+				"Limit":  {"10"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedFilter: &api.Filter{
+				Limit: 10,
+			},
+			expectedResponse: ListAPIKeysResponse{
+				APIKeys: testAPIKeys,
+				Limit:   10,
+				Total:   1,
+			},
+			listAPIKeysResult: testAPIKeys,
+			totalResult:       1,
+		},
+		"ErrorCaseInvalidFilterParams": {
+			queryParams: url.Values{
+				"Offset": {"-1"},
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Offset -1",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedFilter:     &api.Filter{},
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+			listAPIKeysErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedFilter:     &api.Filter{},
+			listAPIKeysErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListAPIKeysMethod][0] = test.listAPIKeysResult
+		testApi.ArgsOut[ListAPIKeysMethod][1] = test.totalResult
+		testApi.ArgsOut[ListAPIKeysMethod][2] = test.listAPIKeysErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+USER_ROOT_URL+"/service/api-keys", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if !test.ignoreArgsIn {
+			// Check received parameters
+			if testApi.ArgsIn[ListAPIKeysMethod][1] != "service" {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, "service", testApi.ArgsIn[ListAPIKeysMethod][1])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[ListAPIKeysMethod][2], test.expectedFilter); diff != "" {
+				t.Errorf("Test %v failed. Received different filters (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listAPIKeysResponse := ListAPIKeysResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listAPIKeysResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listAPIKeysResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetAPIKey(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.APIKey
+		expectedError      api.Error
+		// Manager Results
+		getAPIKeyResult *api.APIKey
+		// Manager Errors
+		getAPIKeyErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.APIKey{
+				ID:         "KEY-ID",
+				Name:       "deploy",
+				UserID:     "USER-ID",
+				ExternalID: "service",
+				CreateAt:   now,
+				RotateAt:   now,
+			},
+			getAPIKeyResult: &api.APIKey{
+				ID:         "KEY-ID",
+				Name:       "deploy",
+				UserID:     "USER-ID",
+				ExternalID: "service",
+				Hash:       "hash",
+				CreateAt:   now,
+				RotateAt:   now,
+			},
+		},
+		"ErrorCaseAPIKeyNotFound": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.API_KEY_NOT_FOUND,
+				Message: "Not found",
+			},
+			getAPIKeyErr: &api.Error{
+				Code:    api.API_KEY_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getAPIKeyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			getAPIKeyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[GetAPIKeyMethod][0] = test.getAPIKeyResult
+		testApi.ArgsOut[GetAPIKeyMethod][1] = test.getAPIKeyErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+USER_ROOT_URL+"/service/api-keys/KEY-ID", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[GetAPIKeyMethod][1] != "service" || testApi.ArgsIn[GetAPIKeyMethod][2] != "KEY-ID" {
+			t.Errorf("Test case %v. Received different ExternalID and key id (wanted:%v %v / received:%v %v)", n,
+				"service", "KEY-ID", testApi.ArgsIn[GetAPIKeyMethod][1], testApi.ArgsIn[GetAPIKeyMethod][2])
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			apiKeyResponse := &api.APIKey{}
+			err = json.NewDecoder(res.Body).Decode(apiKeyResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiKeyResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRotateAPIKey(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	expireAt := now.Add(time.Hour)
+	testcases := map[string]struct {
+		// API method args
+		request      interface{}
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedExpireAt   *time.Time
+		expectedResponse   *api.APIKeySecret
+		expectedError      api.Error
+		// Manager Results
+		rotateAPIKeyResult *api.APIKeySecret
+		// Manager Errors
+		rotateAPIKeyErr error
+	}{
+		"OkCase": {
+			request: &RotateAPIKeyRequest{
+				ExpireAt: &expireAt,
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedExpireAt:   &expireAt,
+			expectedResponse: &api.APIKeySecret{
+				APIKey: api.APIKey{
+					ID:       "KEY-ID",
+					RotateAt: now,
+					ExpireAt: &expireAt,
+				},
+				Key: "KEY-ID.secret",
+			},
+			rotateAPIKeyResult: &api.APIKeySecret{
+				APIKey: api.APIKey{
+					ID:       "KEY-ID",
+					RotateAt: now,
+					ExpireAt: &expireAt,
+				},
+				Key: "KEY-ID.secret",
+			},
+		},
+		"OkCaseWithoutBody": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.APIKeySecret{
+				APIKey: api.APIKey{
+					ID:       "KEY-ID",
+					RotateAt: now,
+				},
+				Key: "KEY-ID.secret",
+			},
+			rotateAPIKeyResult: &api.APIKeySecret{
+				APIKey: api.APIKey{
+					ID:       "KEY-ID",
+					RotateAt: now,
+				},
+				Key: "KEY-ID.secret",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			request:            "{malformed}",
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "json: cannot unmarshal string into Go value of type http.RotateAPIKeyRequest",
+			},
+		},
+		"ErrorCaseRevoked": {
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.API_KEY_REVOKED,
+				Message: "Revoked",
+			},
+			rotateAPIKeyErr: &api.Error{
+				Code:    api.API_KEY_REVOKED,
+				Message: "Revoked",
+			},
+		},
+		"ErrorCaseAPIKeyNotFound": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.API_KEY_NOT_FOUND,
+				Message: "Not found",
+			},
+			rotateAPIKeyErr: &api.Error{
+				Code:    api.API_KEY_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			rotateAPIKeyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[RotateAPIKeyMethod][0] = test.rotateAPIKeyResult
+		testApi.ArgsOut[RotateAPIKeyMethod][1] = test.rotateAPIKeyErr
+
+		body := &bytes.Buffer{}
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+USER_ROOT_URL+"/service/api-keys/KEY-ID/rotate", body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if !test.ignoreArgsIn {
+			// Check received parameters
+			if testApi.ArgsIn[RotateAPIKeyMethod][1] != "service" || testApi.ArgsIn[RotateAPIKeyMethod][2] != "KEY-ID" {
+				t.Errorf("Test case %v. Received different ExternalID and key id (wanted:%v %v / received:%v %v)", n,
+					"service", "KEY-ID", testApi.ArgsIn[RotateAPIKeyMethod][1], testApi.ArgsIn[RotateAPIKeyMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[RotateAPIKeyMethod][3], test.expectedExpireAt); diff != "" {
+				t.Errorf("Test %v failed. Received different expiration dates (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			apiKeyResponse := &api.APIKeySecret{}
+			err = json.NewDecoder(res.Body).Decode(apiKeyResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiKeyResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRevokeAPIKey(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.APIKey
+		expectedError      api.Error
+		// Manager Results
+		revokeAPIKeyResult *api.APIKey
+		// Manager Errors
+		revokeAPIKeyErr error
+	}{
+		"OkCase": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.APIKey{
+				ID:       "KEY-ID",
+				RevokeAt: &now,
+			},
+			revokeAPIKeyResult: &api.APIKey{
+				ID:       "KEY-ID",
+				RevokeAt: &now,
+			},
+		},
+		"ErrorCaseAlreadyRevoked": {
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.API_KEY_REVOKED,
+				Message: "Revoked",
+			},
+			revokeAPIKeyErr: &api.Error{
+				Code:    api.API_KEY_REVOKED,
+				Message: "Revoked",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			revokeAPIKeyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			revokeAPIKeyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[RevokeAPIKeyMethod][0] = test.revokeAPIKeyResult
+		testApi.ArgsOut[RevokeAPIKeyMethod][1] = test.revokeAPIKeyErr
+
+		url := fmt.Sprintf(server.URL+USER_ROOT_URL+"/%v/api-keys/%v/revoke", "service", "KEY-ID")
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[RevokeAPIKeyMethod][1] != "service" || testApi.ArgsIn[RevokeAPIKeyMethod][2] != "KEY-ID" {
+			t.Errorf("Test case %v. Received different ExternalID and key id (wanted:%v %v / received:%v %v)", n,
+				"service", "KEY-ID", testApi.ArgsIn[RevokeAPIKeyMethod][1], testApi.ArgsIn[RevokeAPIKeyMethod][2])
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			apiKeyResponse := &api.APIKey{}
+			err = json.NewDecoder(res.Body).Decode(apiKeyResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiKeyResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// RESPONSES
+
+type ListAuditEventsResponse struct {
+	AuditEvents []api.AuditEvent `json:"auditEvents, omitempty"`
+	Limit       int              `json:"limit, omitempty"`
+	Offset      int              `json:"offset, omitempty"`
+	Total       int              `json:"total, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleListAuditEvents(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+
+	// Retrieve filterData
+	filterData, err := getAuditFilterData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call audit API to retrieve audit events
+	result, total, err := h.worker.AuditApi.ListAuditEvents(requestInfo, filterData)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListAuditEventsResponse{
+		AuditEvents: result,
+		Offset:      filterData.Offset,
+		Limit:       filterData.Limit,
+		Total:       total,
+	}
+
+	// Return audit events
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+// Retrieve audit filter from query params. Dates are in RFC3339 format.
+func getAuditFilterData(r *http.Request) (*api.AuditFilter, error) {
+	filterData, err := getFilterData(r)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := &api.AuditFilter{
+		Actor:     r.URL.Query().Get("Actor"),
+		Action:    r.URL.Query().Get("Action"),
+		UrnPrefix: r.URL.Query().Get("UrnPrefix"),
+		Offset:    filterData.Offset,
+		Limit:     filterData.Limit,
+	}
+	for param, date := range map[string]*time.Time{"From": &filter.From, "To": &filter.To} {
+		value := r.URL.Query().Get(param)
+		if len(value) == 0 {
+			continue
+		}
+		*date, err = time.Parse(time.RFC3339, value)
+		if err != nil {
+			return nil, &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: %v %v", param, value),
+			}
+		}
+	}
+
+	return filter, nil
+}
@@ -0,0 +1,209 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestWorkerHandler_HandleListAuditEvents(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		queryParams  url.Values
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedFilter     *api.AuditFilter
+		expectedResponse   ListAuditEventsResponse
+		expectedError      api.Error
+		// Manager Results
+		listAuditEventsResult []api.AuditEvent
+		totalEventsResult     int
+		// Manager Errors
+		listAuditEventsErr error
+	}{
+		"OkCase": {
+			queryParams: url.Values{
+				"Actor":     {"123456"},
+				"Action":    {api.USER_ACTION_CREATE_USER},
+				"UrnPrefix": {"urn:iws:iam::user/path/"},
+				"From":      {"2016-10-01T00:00:00Z"},
+				"To":        {"2016-10-02T00:00:00Z"},
+				"Offset":    {"1"},
+				"Limit":     {"10"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedFilter: &api.AuditFilter{
+				Actor:     "123456",
+				Action:    api.USER_ACTION_CREATE_USER,
+				UrnPrefix: "urn:iws:iam::user/path/",
+				From:      time.Date(2016, 10, 1, 0, 0, 0, 0, time.UTC),
+				To:        time.Date(2016, 10, 2, 0, 0, 0, 0, time.UTC),
+				Offset:    1,
+				Limit:     10,
+			},
+			expectedResponse: ListAuditEventsResponse{
+				AuditEvents: []api.AuditEvent{
+					{
+						ID:        "EVENT-ID",
+						Actor:     "123456",
+						RequestID: "REQUEST-ID",
+						Action:    api.USER_ACTION_CREATE_USER,
+						Urn:       "urn:iws:iam::user/path/user1",
+						Before:    json.RawMessage(`null`),
+						After:     json.RawMessage(`{"id":"USER-ID"}`),
+						CreateAt:  now,
+					},
+				},
+				Offset: 1,
+				Limit:  10,
+				Total:  2,
+			},
+			listAuditEventsResult: []api.AuditEvent{
+				{
+					ID:        "EVENT-ID",
+					Actor:     "123456",
+					RequestID: "REQUEST-ID",
+					Action:    api.USER_ACTION_CREATE_USER,
+					Urn:       "urn:iws:iam::user/path/user1",
+					After:     json.RawMessage(`{"id":"USER-ID"}`),
+					CreateAt:  now,
+				},
+			},
+			totalEventsResult: 2,
+		},
+		"ErrorCaseInvalidFilterParams": {
+			queryParams: url.Values{
+				"Limit": {"-1"},
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Limit -1",
+			},
+		},
+		"ErrorCaseInvalidDate": {
+			queryParams: url.Values{
+				"From": {"yesterday"},
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: From yesterday",
+			},
+		},
+		"ErrorCaseInvalidParameterError": {
+			queryParams: url.Values{
+				"Action": {"iam:*~#"},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedFilter: &api.AuditFilter{
+				Action: "iam:*~#",
+			},
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Action iam:*~#",
+			},
+			listAuditEventsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Action iam:*~#",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedFilter:     &api.AuditFilter{},
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listAuditEventsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedFilter:     &api.AuditFilter{},
+			listAuditEventsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListAuditEventsMethod][0] = test.listAuditEventsResult
+		testApi.ArgsOut[ListAuditEventsMethod][1] = test.totalEventsResult
+		testApi.ArgsOut[ListAuditEventsMethod][2] = test.listAuditEventsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+AUDIT_URL, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		if !test.ignoreArgsIn {
+			// Check received parameters
+			filterData, ok := testApi.ArgsIn[ListAuditEventsMethod][1].(*api.AuditFilter)
+			if ok {
+				// Check result
+				if diff := pretty.Compare(filterData, test.expectedFilter); diff != "" {
+					t.Errorf("Test %v failed. Received different filters (received/wanted) %v", n, diff)
+					continue
+				}
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listAuditEventsResponse := ListAuditEventsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listAuditEventsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listAuditEventsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v",
+					n, diff)
+				continue
+			}
+		}
+	}
+}
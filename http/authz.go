@@ -16,12 +16,42 @@ type AuthorizeResourcesRequest struct {
 	Resources []string `json:"resources, omitempty"`
 }
 
+type AuthorizeResourcesForUserRequest struct {
+	ExternalID string   `json:"externalId, omitempty"`
+	Action     string   `json:"action, omitempty"`
+	Resources  []string `json:"resources, omitempty"`
+}
+
+type AuthorizeResourcesBatchRequest struct {
+	Checks []api.AuthorizationCheck `json:"checks, omitempty"`
+}
+
+type AuthorizeRestrictionsRequest struct {
+	Action    string `json:"action, omitempty"`
+	UrnPrefix string `json:"urnPrefix, omitempty"`
+}
+
+type AuthorizePrincipalsRequest struct {
+	Action   string `json:"action, omitempty"`
+	Resource string `json:"resource, omitempty"`
+}
+
+type ExplainAuthorizationRequest struct {
+	ExternalID string   `json:"externalId, omitempty"`
+	Action     string   `json:"action, omitempty"`
+	Resources  []string `json:"resources, omitempty"`
+}
+
 // RESPONSES
 
 type AuthorizeResourcesResponse struct {
 	ResourcesAllowed []string `json:"resourcesAllowed, omitempty"`
 }
 
+type AuthorizeResourcesBatchResponse struct {
+	Results []api.AuthorizationCheckResult `json:"results, omitempty"`
+}
+
 // HANDLERS
 
 func (h *WorkerHandler) HandleGetAuthorizedExternalResources(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -70,3 +100,190 @@ func (h *WorkerHandler) HandleGetAuthorizedExternalResources(w http.ResponseWrit
 
 	h.RespondOk(r, requestInfo, w, response)
 }
+
+func (h *WorkerHandler) HandleGetAuthorizedExternalResourcesForUser(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := AuthorizeResourcesForUserRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve allowed resources for the requested user
+	result, err := h.worker.AuthzApi.GetAuthorizedExternalResourcesForUser(requestInfo, request.ExternalID, request.Action, request.Resources)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	response := AuthorizeResourcesResponse{
+		ResourcesAllowed: result,
+	}
+
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetAuthorizedExternalResourcesBatch(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := AuthorizeResourcesBatchRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve allowed resources for every check
+	result, err := h.worker.AuthzApi.GetAuthorizedExternalResourcesBatch(requestInfo, request.Checks)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	response := AuthorizeResourcesBatchResponse{
+		Results: result,
+	}
+
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetAuthorizedRestrictions(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := AuthorizeRestrictionsRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve restrictions under the urn prefix
+	response, err := h.worker.AuthzApi.GetAuthorizedRestrictions(requestInfo, request.Action, request.UrnPrefix)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetAuthorizedPrincipals(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := AuthorizePrincipalsRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve users and groups allowed to do the action over the resource
+	response, err := h.worker.AuthzApi.GetAuthorizedPrincipals(requestInfo, request.Action, request.Resource)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleExplainAuthorization(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Decode request
+	request := ExplainAuthorizationRequest{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call authz API to explain decisions
+	response, err := h.worker.AuthzApi.ExplainAuthorization(requestInfo, request.ExternalID, request.Action, request.Resources)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondOk(r, requestInfo, w, response)
+}
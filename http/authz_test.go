@@ -163,3 +163,832 @@ func TestWorkerHandler_HandleGetAuthorizedExternalResources(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkerHandler_HandleGetAuthorizedExternalResourcesForUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *AuthorizeResourcesForUserRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   AuthorizeResourcesResponse
+		expectedError      api.Error
+		// Manager Results
+		getAuthorizedExternalResourcesForUserResult []string
+		// Manager Errors
+		getAuthorizedExternalResourcesForUserErr error
+	}{
+		"OkCase": {
+			request: &AuthorizeResourcesForUserRequest{
+				ExternalID: "user1",
+				Action:     "example:View",
+				Resources:  []string{"resource1", "resource2"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: AuthorizeResourcesResponse{
+				ResourcesAllowed: []string{"resource1"},
+			},
+			getAuthorizedExternalResourcesForUserResult: []string{"resource1"},
+		},
+		"OkCaseEmptyList": {
+			request: &AuthorizeResourcesForUserRequest{
+				ExternalID: "user1",
+				Action:     "example:View",
+				Resources:  []string{"resource1", "resource2"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: AuthorizeResourcesResponse{
+				ResourcesAllowed: []string{},
+			},
+			getAuthorizedExternalResourcesForUserResult: []string{},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			request: &AuthorizeResourcesForUserRequest{
+				ExternalID: "user1",
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedExternalResourcesForUserErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			request: &AuthorizeResourcesForUserRequest{
+				ExternalID: "user1",
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Error",
+			},
+			getAuthorizedExternalResourcesForUserErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &AuthorizeResourcesForUserRequest{
+				ExternalID: "user1",
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedExternalResourcesForUserErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &AuthorizeResourcesForUserRequest{
+				ExternalID: "user1",
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			getAuthorizedExternalResourcesForUserErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetAuthorizedExternalResourcesForUserMethod][0] = test.getAuthorizedExternalResourcesForUserResult
+		testApi.ArgsOut[GetAuthorizedExternalResourcesForUserMethod][1] = test.getAuthorizedExternalResourcesForUserErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+RESOURCE_CHECK_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check received parameters
+			if testApi.ArgsIn[GetAuthorizedExternalResourcesForUserMethod][1] != test.request.ExternalID {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)",
+					n, test.request.ExternalID, testApi.ArgsIn[GetAuthorizedExternalResourcesForUserMethod][1])
+				continue
+			}
+			authorizeResourcesResponse := AuthorizeResourcesResponse{}
+			err = json.NewDecoder(res.Body).Decode(&authorizeResourcesResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(authorizeResourcesResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetAuthorizedExternalResourcesBatch(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *AuthorizeResourcesBatchRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   AuthorizeResourcesBatchResponse
+		expectedError      api.Error
+		// Manager Results
+		getAuthorizedExternalResourcesBatchResult []api.AuthorizationCheckResult
+		// Manager Errors
+		getAuthorizedExternalResourcesBatchErr error
+	}{
+		"OkCase": {
+			request: &AuthorizeResourcesBatchRequest{
+				Checks: []api.AuthorizationCheck{
+					{
+						Action:    "example:View",
+						Resources: []string{"resource1", "resource2"},
+					},
+					{
+						Action:    "example:Delete",
+						Resources: []string{"resource1", "resource2"},
+					},
+				},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: AuthorizeResourcesBatchResponse{
+				Results: []api.AuthorizationCheckResult{
+					{
+						Action:           "example:View",
+						ResourcesAllowed: []string{"resource1", "resource2"},
+					},
+					{
+						Action:           "example:Delete",
+						ResourcesAllowed: []string{},
+					},
+				},
+			},
+			getAuthorizedExternalResourcesBatchResult: []api.AuthorizationCheckResult{
+				{
+					Action:           "example:View",
+					ResourcesAllowed: []string{"resource1", "resource2"},
+				},
+				{
+					Action:           "example:Delete",
+					ResourcesAllowed: []string{},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			request:            &AuthorizeResourcesBatchRequest{},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedExternalResourcesBatchErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request:            &AuthorizeResourcesBatchRequest{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedExternalResourcesBatchErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request:            &AuthorizeResourcesBatchRequest{},
+			expectedStatusCode: http.StatusInternalServerError,
+			getAuthorizedExternalResourcesBatchErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetAuthorizedExternalResourcesBatchMethod][0] = test.getAuthorizedExternalResourcesBatchResult
+		testApi.ArgsOut[GetAuthorizedExternalResourcesBatchMethod][1] = test.getAuthorizedExternalResourcesBatchErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+RESOURCE_BATCH_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check received parameters
+			if diff := pretty.Compare(testApi.ArgsIn[GetAuthorizedExternalResourcesBatchMethod][1], test.request.Checks); diff != "" {
+				t.Errorf("Test %v failed. Received different checks (received/wanted) %v", n, diff)
+				continue
+			}
+			authorizeResourcesBatchResponse := AuthorizeResourcesBatchResponse{}
+			err = json.NewDecoder(res.Body).Decode(&authorizeResourcesBatchResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(authorizeResourcesBatchResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetAuthorizedRestrictions(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *AuthorizeRestrictionsRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.Restrictions
+		expectedError      api.Error
+		// Manager Results
+		getAuthorizedRestrictionsResult *api.Restrictions
+		// Manager Errors
+		getAuthorizedRestrictionsErr error
+	}{
+		"OkCase": {
+			request: &AuthorizeRestrictionsRequest{
+				Action:    "example:View",
+				UrnPrefix: "urn:ews:example:instance:resource/*",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.Restrictions{
+				AllowedUrnPrefixes: []string{"urn:ews:example:instance:resource/*"},
+				AllowedFullUrns:    []string{},
+				DeniedUrnPrefixes:  []string{"urn:ews:example:instance:resource/private/*"},
+				DeniedFullUrns:     []string{"urn:ews:example:instance:resource/resource1"},
+			},
+			getAuthorizedRestrictionsResult: &api.Restrictions{
+				AllowedUrnPrefixes: []string{"urn:ews:example:instance:resource/*"},
+				AllowedFullUrns:    []string{},
+				DeniedUrnPrefixes:  []string{"urn:ews:example:instance:resource/private/*"},
+				DeniedFullUrns:     []string{"urn:ews:example:instance:resource/resource1"},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			request:            &AuthorizeRestrictionsRequest{},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedRestrictionsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request:            &AuthorizeRestrictionsRequest{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedRestrictionsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request:            &AuthorizeRestrictionsRequest{},
+			expectedStatusCode: http.StatusInternalServerError,
+			getAuthorizedRestrictionsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetAuthorizedRestrictionsMethod][0] = test.getAuthorizedRestrictionsResult
+		testApi.ArgsOut[GetAuthorizedRestrictionsMethod][1] = test.getAuthorizedRestrictionsErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+RESOURCE_RESTRICTIONS_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check received parameters
+			if diff := pretty.Compare(testApi.ArgsIn[GetAuthorizedRestrictionsMethod][1], test.request.Action); diff != "" {
+				t.Errorf("Test %v failed. Received different actions (received/wanted) %v", n, diff)
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[GetAuthorizedRestrictionsMethod][2], test.request.UrnPrefix); diff != "" {
+				t.Errorf("Test %v failed. Received different urn prefixes (received/wanted) %v", n, diff)
+				continue
+			}
+			restrictions := &api.Restrictions{}
+			err = json.NewDecoder(res.Body).Decode(restrictions)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(restrictions, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetAuthorizedPrincipals(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *AuthorizePrincipalsRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.AuthorizedPrincipals
+		expectedError      api.Error
+		// Manager Results
+		getAuthorizedPrincipalsResult *api.AuthorizedPrincipals
+		// Manager Errors
+		getAuthorizedPrincipalsErr error
+	}{
+		"OkCase": {
+			request: &AuthorizePrincipalsRequest{
+				Action:   "example:View",
+				Resource: "urn:ews:example:instance:resource/resource1",
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AuthorizedPrincipals{
+				Action:   "example:View",
+				Resource: "urn:ews:example:instance:resource/resource1",
+				Users:    []string{"user1"},
+				Groups: []api.GroupIdentity{
+					{
+						Org:  "example",
+						Name: "group1",
+					},
+				},
+			},
+			getAuthorizedPrincipalsResult: &api.AuthorizedPrincipals{
+				Action:   "example:View",
+				Resource: "urn:ews:example:instance:resource/resource1",
+				Users:    []string{"user1"},
+				Groups: []api.GroupIdentity{
+					{
+						Org:  "example",
+						Name: "group1",
+					},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			request:            &AuthorizePrincipalsRequest{},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedPrincipalsErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request:            &AuthorizePrincipalsRequest{},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+			getAuthorizedPrincipalsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request:            &AuthorizePrincipalsRequest{},
+			expectedStatusCode: http.StatusInternalServerError,
+			getAuthorizedPrincipalsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[GetAuthorizedPrincipalsMethod][0] = test.getAuthorizedPrincipalsResult
+		testApi.ArgsOut[GetAuthorizedPrincipalsMethod][1] = test.getAuthorizedPrincipalsErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+RESOURCE_PRINCIPALS_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check received parameters
+			if diff := pretty.Compare(testApi.ArgsIn[GetAuthorizedPrincipalsMethod][1], test.request.Action); diff != "" {
+				t.Errorf("Test %v failed. Received different actions (received/wanted) %v", n, diff)
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[GetAuthorizedPrincipalsMethod][2], test.request.Resource); diff != "" {
+				t.Errorf("Test %v failed. Received different resources (received/wanted) %v", n, diff)
+				continue
+			}
+			principals := &api.AuthorizedPrincipals{}
+			err = json.NewDecoder(res.Body).Decode(principals)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(principals, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleExplainAuthorization(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		request *ExplainAuthorizationRequest
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.AuthorizationExplanation
+		expectedError      api.Error
+		// Manager Results
+		explainAuthorizationResult *api.AuthorizationExplanation
+		// Manager Errors
+		explainAuthorizationErr error
+	}{
+		"OkCase": {
+			request: &ExplainAuthorizationRequest{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+				Resources:  []string{"urn:ews:product:instance:resource/path1/resource"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.AuthorizationExplanation{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+				Decisions: []api.ResourceDecision{
+					{
+						Urn:                 "urn:ews:product:instance:resource/path1/resource",
+						Allowed:             true,
+						DecisionType:        api.DECISION_ALLOWED_FULL_URN,
+						DecisionRestriction: "urn:ews:product:instance:resource/path1/resource",
+					},
+				},
+			},
+			explainAuthorizationResult: &api.AuthorizationExplanation{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+				Decisions: []api.ResourceDecision{
+					{
+						Urn:                 "urn:ews:product:instance:resource/path1/resource",
+						Allowed:             true,
+						DecisionType:        api.DECISION_ALLOWED_FULL_URN,
+						DecisionRestriction: "urn:ews:product:instance:resource/path1/resource",
+					},
+				},
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseInvalidParameter": {
+			request: &ExplainAuthorizationRequest{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+			explainAuthorizationErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUserNotFound": {
+			request: &ExplainAuthorizationRequest{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+			},
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Error",
+			},
+			explainAuthorizationErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			request: &ExplainAuthorizationRequest{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+			},
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+			explainAuthorizationErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Error",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			request: &ExplainAuthorizationRequest{
+				ExternalID: "user1",
+				Action:     api.USER_ACTION_GET_USER,
+			},
+			expectedStatusCode: http.StatusInternalServerError,
+			explainAuthorizationErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ExplainAuthorizationMethod][0] = test.explainAuthorizationResult
+		testApi.ArgsOut[ExplainAuthorizationMethod][1] = test.explainAuthorizationErr
+
+		var body *bytes.Buffer
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+		if body == nil {
+			body = bytes.NewBuffer([]byte{})
+		}
+		req, err := http.NewRequest(http.MethodPost, server.URL+RESOURCE_EXPLAIN_URL, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			// Check received parameters
+			if testApi.ArgsIn[ExplainAuthorizationMethod][1] != test.request.ExternalID {
+				t.Errorf("Test case %v. Received different ExternalID (wanted:%v / received:%v)", n, test.request.ExternalID, testApi.ArgsIn[ExplainAuthorizationMethod][1])
+				continue
+			}
+			explanation := &api.AuthorizationExplanation{}
+			err = json.NewDecoder(res.Body).Decode(explanation)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(explanation, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
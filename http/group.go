@@ -406,8 +406,22 @@ func (h *WorkerHandler) HandleAttachPolicyToGroup(w http.ResponseWriter, r *http
 	groupName := ps.ByName(GROUP_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
-	// Call group API to attach policy to group
-	err := h.worker.GroupApi.AttachPolicyToGroup(requestInfo, org, groupName, policyName)
+	// Retrieve dry run mode
+	dryRun, checks, err := getDryRunData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group API to attach policy to group, or only check the impact of it in dry run mode
+	var response *api.PermissionImpact
+	if dryRun {
+		response, err = h.worker.GroupApi.DryRunAttachPolicyToGroup(requestInfo, org, groupName, policyName, checks)
+	} else {
+		err = h.worker.GroupApi.AttachPolicyToGroup(requestInfo, org, groupName, policyName)
+	}
 
 	// Error handling
 	if err != nil {
@@ -430,6 +444,11 @@ func (h *WorkerHandler) HandleAttachPolicyToGroup(w http.ResponseWriter, r *http
 
 	}
 
+	// Write impact to response in dry run mode
+	if dryRun {
+		h.RespondOk(r, requestInfo, w, response)
+		return
+	}
 	h.RespondNoContent(r, requestInfo, w)
 }
 
@@ -440,8 +459,22 @@ func (h *WorkerHandler) HandleDetachPolicyToGroup(w http.ResponseWriter, r *http
 	groupName := ps.ByName(GROUP_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
-	// Call group API to detach policy to group
-	err := h.worker.GroupApi.DetachPolicyToGroup(requestInfo, org, groupName, policyName)
+	// Retrieve dry run mode
+	dryRun, checks, err := getDryRunData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call group API to detach policy to group, or only check the impact of it in dry run mode
+	var response *api.PermissionImpact
+	if dryRun {
+		response, err = h.worker.GroupApi.DryRunDetachPolicyToGroup(requestInfo, org, groupName, policyName, checks)
+	} else {
+		err = h.worker.GroupApi.DetachPolicyToGroup(requestInfo, org, groupName, policyName)
+	}
 
 	// Error handling
 	if err != nil {
@@ -462,6 +495,11 @@ func (h *WorkerHandler) HandleDetachPolicyToGroup(w http.ResponseWriter, r *http
 
 	}
 
+	// Write impact to response in dry run mode
+	if dryRun {
+		h.RespondOk(r, requestInfo, w, response)
+		return
+	}
 	h.RespondNoContent(r, requestInfo, w)
 }
 
@@ -2026,3 +2026,163 @@ func TestWorkerHandler_HandleListAttachedGroupPolicies(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkerHandler_HandlePolicyToGroupDryRun(t *testing.T) {
+	testImpact := &api.PermissionImpact{
+		Checks: []api.PermissionCheck{
+			{
+				Action:   "product:get",
+				Resource: "urn:ews:product:instance:resource/r1",
+			},
+		},
+		Users: []string{"user1"},
+		Changes: []api.PermissionChange{
+			{
+				User:     "user1",
+				Action:   "product:get",
+				Resource: "urn:ews:product:instance:resource/r1",
+				Before: &api.Restrictions{
+					AllowedUrnPrefixes: []string{},
+					AllowedFullUrns:    []string{},
+					DeniedUrnPrefixes:  []string{},
+					DeniedFullUrns:     []string{},
+				},
+				After: &api.Restrictions{
+					AllowedUrnPrefixes: []string{},
+					AllowedFullUrns:    []string{"urn:ews:product:instance:resource/r1"},
+					DeniedUrnPrefixes:  []string{},
+					DeniedFullUrns:     []string{},
+				},
+			},
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		method   string
+		query    string
+		apiIndex string
+		// Expected result
+		expectedStatusCode int
+		expectedChecks     []api.PermissionCheck
+		expectedResponse   *api.PermissionImpact
+		expectedError      api.Error
+		// Manager Errors
+		dryRunErr error
+	}{
+		"OkCaseAttach": {
+			method:             http.MethodPost,
+			query:              "DryRun=true&Action=product:get&Resource=urn:ews:product:instance:resource/r1",
+			apiIndex:           DryRunAttachPolicyToGroupMethod,
+			expectedStatusCode: http.StatusOK,
+			expectedChecks: []api.PermissionCheck{
+				{
+					Action:   "product:get",
+					Resource: "urn:ews:product:instance:resource/r1",
+				},
+			},
+			expectedResponse: testImpact,
+		},
+		"OkCaseDetachSampledChecks": {
+			method:             http.MethodDelete,
+			query:              "DryRun=1",
+			apiIndex:           DryRunDetachPolicyToGroupMethod,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   testImpact,
+		},
+		"ErrorCaseInvalidDryRun": {
+			method:             http.MethodPost,
+			query:              "DryRun=maybe",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: DryRun maybe",
+			},
+		},
+		"ErrorCaseDetachPolicyIsNotAttached": {
+			method:             http.MethodDelete,
+			query:              "DryRun=true",
+			apiIndex:           DryRunDetachPolicyToGroupMethod,
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
+				Message: "Error",
+			},
+			dryRunErr: &api.Error{
+				Code:    api.POLICY_IS_NOT_ATTACHED_TO_GROUP,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		for _, method := range []string{DryRunAttachPolicyToGroupMethod, DryRunDetachPolicyToGroupMethod} {
+			testApi.ArgsIn[method][4] = nil
+			testApi.ArgsOut[method][0] = nil
+			testApi.ArgsOut[method][1] = nil
+		}
+		if test.apiIndex != "" {
+			testApi.ArgsOut[test.apiIndex][0] = test.expectedResponse
+			testApi.ArgsOut[test.apiIndex][1] = test.dryRunErr
+		}
+
+		req, err := http.NewRequest(test.method, server.URL+API_VERSION_1+"/organizations/org1/groups/group1/policies/policy1", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.query
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if test.apiIndex != "" {
+			if testApi.ArgsIn[test.apiIndex][3] != "policy1" {
+				t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, "policy1", testApi.ArgsIn[test.apiIndex][3])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[test.apiIndex][4], test.expectedChecks); diff != "" {
+				t.Errorf("Test %v failed. Received different checks (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			impactResponse := &api.PermissionImpact{}
+			err = json.NewDecoder(res.Body).Decode(impactResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(impactResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -2,7 +2,11 @@ package http
 
 import (
 	"encoding/json"
+	"net"
 	"net/http"
+	"regexp"
+	"strings"
+	"time"
 
 	"fmt"
 	"github.com/Sirupsen/logrus"
@@ -15,10 +19,12 @@ import (
 
 const (
 	// Constants for values in url
-	USER_ID     = "userid"
-	GROUP_NAME  = "groupname"
-	POLICY_NAME = "policyname"
-	ORG_NAME    = "orgname"
+	USER_ID        = "userid"
+	GROUP_NAME     = "groupname"
+	POLICY_NAME    = "policyname"
+	POLICY_VERSION = "version"
+	API_KEY_ID     = "keyid"
+	ORG_NAME       = "orgname"
 
 	// URI Path param prefix
 	URI_PATH_PREFIX = "/:"
@@ -31,9 +37,17 @@ const (
 	ORG_ROOT = "/organizations/:" + ORG_NAME
 
 	// User API urls
-	USER_ROOT_URL      = API_VERSION_1 + "/users"
-	USER_ID_URL        = USER_ROOT_URL + URI_PATH_PREFIX + USER_ID
-	USER_ID_GROUPS_URL = USER_ID_URL + "/groups"
+	USER_ROOT_URL           = API_VERSION_1 + "/users"
+	USER_ID_URL             = USER_ROOT_URL + URI_PATH_PREFIX + USER_ID
+	USER_ID_GROUPS_URL      = USER_ID_URL + "/groups"
+	USER_ID_POLICIES_URL    = USER_ID_URL + "/policies"
+	USER_ID_POLICIES_ID_URL = USER_ID_URL + ORG_ROOT + "/policies" + URI_PATH_PREFIX + POLICY_NAME
+
+	// API key API urls
+	USER_ID_API_KEYS_URL           = USER_ID_URL + "/api-keys"
+	USER_ID_API_KEYS_ID_URL        = USER_ID_API_KEYS_URL + URI_PATH_PREFIX + API_KEY_ID
+	USER_ID_API_KEYS_ID_ROTATE_URL = USER_ID_API_KEYS_ID_URL + "/rotate"
+	USER_ID_API_KEYS_ID_REVOKE_URL = USER_ID_API_KEYS_ID_URL + "/revoke"
 
 	// Group organization API urls
 	GROUP_ORG_ROOT_URL       = API_VERSION_1 + ORG_ROOT + "/groups"
@@ -48,20 +62,54 @@ const (
 	POLICY_ID_URL        = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME
 	POLICY_ID_GROUPS_URL = POLICY_ROOT_URL + URI_PATH_PREFIX + POLICY_NAME + "/groups"
 
+	// Policy version API urls
+	POLICY_ID_VERSIONS_URL          = POLICY_ID_URL + "/versions"
+	POLICY_ID_VERSIONS_ID_URL       = POLICY_ID_VERSIONS_URL + URI_PATH_PREFIX + POLICY_VERSION
+	POLICY_ID_VERSIONS_ROLLBACK_URL = POLICY_ID_VERSIONS_ID_URL + "/rollback"
+	POLICY_ID_DIFF_URL              = POLICY_ID_URL + "/diff"
+
+	// Policy lint API urls
+	POLICY_ID_LINT_URL  = POLICY_ID_URL + "/lint"
+	POLICY_ORG_LINT_URL = API_VERSION_1 + ORG_ROOT + "/lint"
+
 	// Authorization URLs
-	RESOURCE_URL = API_VERSION_1 + "/resource"
+	RESOURCE_URL              = API_VERSION_1 + "/resource"
+	RESOURCE_BATCH_URL        = RESOURCE_URL + "/batch"
+	RESOURCE_CHECK_URL        = RESOURCE_URL + "/check"
+	RESOURCE_EXPLAIN_URL      = RESOURCE_URL + "/explain"
+	RESOURCE_RESTRICTIONS_URL = RESOURCE_URL + "/restrictions"
+	RESOURCE_PRINCIPALS_URL   = RESOURCE_URL + "/principals"
+
+	// Audit URLs
+	AUDIT_URL = API_VERSION_1 + "/audit"
+
+	// Import URLs
+	EXPORT_URL     = API_VERSION_1 + "/export"
+	IMPORT_URL     = API_VERSION_1 + "/import"
+	ORG_EXPORT_URL = API_VERSION_1 + ORG_ROOT + "/export"
+	ORG_IMPORT_URL = API_VERSION_1 + ORG_ROOT + "/import"
+
+	// Metrics URL
+	METRICS_URL = "/metrics"
 
 	// HTTP Header
-	REQUEST_ID_HEADER = "Request-ID"
+	REQUEST_ID_HEADER             = "Request-ID"
+	FORWARDED_FOR_HEADER          = "X-Forwarded-For"
+	REQUEST_CONTEXT_HEADER_PREFIX = "Foulkon-Context-"
+
+	// Max length of request ids received from callers
+	MAX_REQUEST_ID_LENGTH = 128
 )
 
+var rRequestID, _ = regexp.Compile(`^[\w\-.:]+$`)
+
 // WORKER
 
 type WorkerHandler struct {
 	worker *foulkon.Worker
 }
 
-func (wh *WorkerHandler) TransactionLog(r *http.Request, requestID string, userID string, msg string) {
+func (wh *WorkerHandler) TransactionLog(r *http.Request, requestID string, userID string, connector string, msg string) {
 
 	// TODO: X-Forwarded headers?
 	//for header, _ := range r.Header {
@@ -74,6 +122,7 @@ func (wh *WorkerHandler) TransactionLog(r *http.Request, requestID string, userI
 		"URI":       r.RequestURI,
 		"address":   r.RemoteAddr,
 		"user":      userID,
+		"connector": connector,
 	}).Info(msg)
 }
 
@@ -94,6 +143,18 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 
 	router.GET(USER_ID_GROUPS_URL, workerHandler.HandleListGroupsByUser)
 
+	router.GET(USER_ID_POLICIES_URL, workerHandler.HandleListAttachedUserPolicies)
+
+	router.POST(USER_ID_POLICIES_ID_URL, workerHandler.HandleAttachPolicyToUser)
+	router.DELETE(USER_ID_POLICIES_ID_URL, workerHandler.HandleDetachPolicyFromUser)
+
+	// API key api
+	router.POST(USER_ID_API_KEYS_URL, workerHandler.HandleCreateAPIKey)
+	router.GET(USER_ID_API_KEYS_URL, workerHandler.HandleListAPIKeys)
+	router.GET(USER_ID_API_KEYS_ID_URL, workerHandler.HandleGetAPIKey)
+	router.POST(USER_ID_API_KEYS_ID_ROTATE_URL, workerHandler.HandleRotateAPIKey)
+	router.POST(USER_ID_API_KEYS_ID_REVOKE_URL, workerHandler.HandleRevokeAPIKey)
+
 	// Group api
 	router.POST(GROUP_ORG_ROOT_URL, workerHandler.HandleAddGroup)
 	router.GET(GROUP_ORG_ROOT_URL, workerHandler.HandleListGroups)
@@ -126,21 +187,43 @@ func WorkerHandlerRouter(worker *foulkon.Worker) http.Handler {
 
 	router.GET(POLICY_ID_GROUPS_URL, workerHandler.HandleListAttachedGroups)
 
+	router.GET(POLICY_ID_VERSIONS_URL, workerHandler.HandleListPolicyVersions)
+	router.GET(POLICY_ID_VERSIONS_ID_URL, workerHandler.HandleGetPolicyVersion)
+	router.POST(POLICY_ID_VERSIONS_ROLLBACK_URL, workerHandler.HandleRollbackPolicy)
+	router.GET(POLICY_ID_DIFF_URL, workerHandler.HandleDiffPolicyVersions)
+
+	router.GET(POLICY_ID_LINT_URL, workerHandler.HandleLintPolicy)
+	router.GET(POLICY_ORG_LINT_URL, workerHandler.HandleLintPolicies)
+
 	// Special endpoint without organization URI for policies
 	router.GET(API_VERSION_1+"/policies", workerHandler.HandleListAllPolicies)
 
 	// Resources authorized endpoint
 	router.POST(RESOURCE_URL, workerHandler.HandleGetAuthorizedExternalResources)
-
-	// Return handler with request logging
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		requestID := uuid.NewV4().String()
+	router.POST(RESOURCE_BATCH_URL, workerHandler.HandleGetAuthorizedExternalResourcesBatch)
+	router.POST(RESOURCE_CHECK_URL, workerHandler.HandleGetAuthorizedExternalResourcesForUser)
+	router.POST(RESOURCE_EXPLAIN_URL, workerHandler.HandleExplainAuthorization)
+	router.POST(RESOURCE_RESTRICTIONS_URL, workerHandler.HandleGetAuthorizedRestrictions)
+	router.POST(RESOURCE_PRINCIPALS_URL, workerHandler.HandleGetAuthorizedPrincipals)
+
+	// Audit api
+	router.GET(AUDIT_URL, workerHandler.HandleListAuditEvents)
+
+	// Import api, for all organizations or only one
+	router.GET(EXPORT_URL, workerHandler.HandleExport)
+	router.POST(IMPORT_URL, workerHandler.HandleImport)
+	router.GET(ORG_EXPORT_URL, workerHandler.HandleExport)
+	router.POST(ORG_IMPORT_URL, workerHandler.HandleImport)
+
+	// Return handler with probes, metrics and request logging
+	return probeHandler(worker.ReadinessChecks, instrumentHandler(router, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID := getRequestID(r)
 		r.Header.Set(REQUEST_ID_HEADER, requestID)
 		w.Header().Add(REQUEST_ID_HEADER, requestID)
 		worker.Authenticator.Authenticate(router).ServeHTTP(w, r)
 		userID, _ := worker.Authenticator.GetAuthenticatedUser(r)
-		workerHandler.TransactionLog(r, requestID, userID, "")
-	})
+		workerHandler.TransactionLog(r, requestID, userID, worker.Authenticator.GetAuthConnector(r), "")
+	})))
 }
 
 // HTTP WORKER responses
@@ -230,7 +313,56 @@ func (wh *WorkerHandler) GetRequestInfo(r *http.Request) api.RequestInfo {
 		Identifier: userID,
 		Admin:      admin,
 		RequestID:  r.Header.Get(REQUEST_ID_HEADER),
+		Context:    getRequestContext(r, wh.worker.TrustedProxies),
+	}
+}
+
+// Retrieve the request context used to evaluate statement conditions. Source IP is the remote address,
+// or the address added to X-Forwarded-For header by the last trusted proxy. Attributes are taken from
+// headers with Foulkon-Context- prefix (e.g. Foulkon-Context-Department: dev) only if they are sent
+// by a trusted proxy, because clients can send any header.
+func getRequestContext(r *http.Request, trustedProxies []*net.IPNet) api.RequestContext {
+	remoteIP := r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		remoteIP = host
+	}
+
+	// Addresses are added to the end by each proxy, so only hops added by trusted proxies are used
+	sourceIP := remoteIP
+	if forwardedFor := r.Header[FORWARDED_FOR_HEADER]; len(forwardedFor) > 0 {
+		hops := strings.Split(strings.Join(forwardedFor, ","), ",")
+		for i := len(hops) - 1; i >= 0 && isTrustedProxy(sourceIP, trustedProxies); i-- {
+			sourceIP = strings.TrimSpace(hops[i])
+		}
+	}
+
+	attributes := map[string]string{}
+	if isTrustedProxy(remoteIP, trustedProxies) {
+		for header := range r.Header {
+			if strings.HasPrefix(header, REQUEST_CONTEXT_HEADER_PREFIX) {
+				attributes[strings.TrimPrefix(header, REQUEST_CONTEXT_HEADER_PREFIX)] = r.Header.Get(header)
+			}
+		}
+	}
+
+	return api.RequestContext{
+		SourceIP:    sourceIP,
+		CurrentTime: time.Now().UTC(),
+		Attributes:  attributes,
+	}
+}
+
+func isTrustedProxy(address string, trustedProxies []*net.IPNet) bool {
+	ip := net.ParseIP(address)
+	if ip == nil {
+		return false
+	}
+	for _, trustedProxy := range trustedProxies {
+		if trustedProxy.Contains(ip) {
+			return true
+		}
 	}
+	return false
 }
 
 // PROXY
@@ -307,16 +439,17 @@ func (ph *ProxyHandler) RespondInternalServerError(w http.ResponseWriter, proxyE
 
 // Handler returns an http.Handler for the Proxy including all resources defined in proxy file.
 func ProxyHandlerRouter(proxy *foulkon.Proxy) http.Handler {
-	// Create the muxer to handle the actual endpoints
-	router := httprouter.New()
-
-	proxyHandler := ProxyHandler{proxy: proxy, client: http.DefaultClient}
-
-	for _, res := range proxy.APIResources {
-		router.Handle(res.Method, res.Url, proxyHandler.HandleRequest(res))
+	router := &proxyRouter{
+		handler:      httprouter.New(),
+		proxyHandler: ProxyHandler{proxy: proxy, client: http.DefaultClient},
+	}
+	if err := router.setResources(proxy.GetAPIResources()); err != nil {
+		proxy.Logger.Error(err)
 	}
+	// Create new muxer when resources are reloaded
+	proxy.OnReload(router.setResources)
 
-	return router
+	return probeHandler(proxy.ReadinessChecks, router)
 }
 
 // Private Helper Methods
@@ -365,3 +498,39 @@ func getFilterData(r *http.Request) (*api.Filter, error) {
 		Limit:      limit,
 	}, nil
 }
+
+// Retrieve request id received from caller, to propagate it, or create a new one if it's missing or invalid
+func getRequestID(r *http.Request) string {
+	requestID := r.Header.Get(REQUEST_ID_HEADER)
+	if len(requestID) == 0 || len(requestID) > MAX_REQUEST_ID_LENGTH || !rRequestID.MatchString(requestID) {
+		return uuid.NewV4().String()
+	}
+	return requestID
+}
+
+// Retrieve dry run mode and the permission checks to compare in it. Each action is checked over each resource.
+func getDryRunData(r *http.Request) (bool, []api.PermissionCheck, error) {
+	var err error
+	dryRun := false
+	value := r.URL.Query().Get("DryRun")
+	if len(value) != 0 {
+		dryRun, err = strconv.ParseBool(value)
+		if err != nil {
+			return false, nil, &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: DryRun %v", value),
+			}
+		}
+	}
+
+	var checks []api.PermissionCheck
+	for _, action := range r.URL.Query()["Action"] {
+		for _, resource := range r.URL.Query()["Resource"] {
+			checks = append(checks, api.PermissionCheck{
+				Action:   action,
+				Resource: resource,
+			})
+		}
+	}
+	return dryRun, checks, nil
+}
@@ -0,0 +1,324 @@
+package http
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/foulkon"
+	"github.com/kylelemons/godebug/pretty"
+	"github.com/pelletier/go-toml"
+)
+
+func TestWorkerHandlerRouter_RequestID(t *testing.T) {
+	testcases := map[string]struct {
+		requestID         string
+		expectedRequestID string
+	}{
+		"OkCasePropagated": {
+			requestID:         "76543210-89ab-cdef-0123-456789abcdef",
+			expectedRequestID: "76543210-89ab-cdef-0123-456789abcdef",
+		},
+		"OkCaseMissing": {},
+		"OkCaseInvalid": {
+			requestID: "request id with spaces",
+		},
+		"OkCaseTooLong": {
+			requestID: strings.Repeat("a", MAX_REQUEST_ID_LENGTH+1),
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[GetUserByExternalIdMethod][0] = nil
+		testApi.ArgsOut[GetUserByExternalIdMethod][1] = nil
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+USER_ROOT_URL+"/user1", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		if test.requestID != "" {
+			req.Header.Set(REQUEST_ID_HEADER, test.requestID)
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		res.Body.Close()
+
+		// Check request id
+		requestID := res.Header.Get(REQUEST_ID_HEADER)
+		if test.expectedRequestID != "" {
+			if requestID != test.expectedRequestID {
+				t.Errorf("Test case %v. Received different request id (wanted:%v / received:%v)", n, test.expectedRequestID, requestID)
+			}
+			continue
+		}
+		if requestID == "" || requestID == test.requestID {
+			t.Errorf("Test case %v. Request id %v wasn't replaced by a new one", n, requestID)
+		}
+	}
+}
+
+func TestGetRequestContext(t *testing.T) {
+	_, trustedProxy, _ := net.ParseCIDR("10.0.0.0/8")
+	testcases := map[string]struct {
+		remoteAddr     string
+		headers        map[string][]string
+		trustedProxies []*net.IPNet
+		// Expected result
+		expectedSourceIP   string
+		expectedAttributes map[string]string
+	}{
+		"OkCaseRemoteAddress": {
+			remoteAddr:         "11.1.2.3:1234",
+			expectedSourceIP:   "11.1.2.3",
+			expectedAttributes: map[string]string{},
+		},
+		"OkCaseTrustedProxy": {
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				FORWARDED_FOR_HEADER:                         {"11.1.2.3"},
+				REQUEST_CONTEXT_HEADER_PREFIX + "Department": {"dev"},
+			},
+			trustedProxies:   []*net.IPNet{trustedProxy},
+			expectedSourceIP: "11.1.2.3",
+			expectedAttributes: map[string]string{
+				"Department": "dev",
+			},
+		},
+		"OkCaseSeveralTrustedProxies": {
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				FORWARDED_FOR_HEADER: {"11.1.2.3, 10.0.0.2"},
+			},
+			trustedProxies:     []*net.IPNet{trustedProxy},
+			expectedSourceIP:   "11.1.2.3",
+			expectedAttributes: map[string]string{},
+		},
+		"OkCaseSpoofedForwardedForWithoutTrustedProxy": {
+			remoteAddr: "11.1.2.3:1234",
+			headers: map[string][]string{
+				FORWARDED_FOR_HEADER: {"10.1.2.3"},
+			},
+			expectedSourceIP:   "11.1.2.3",
+			expectedAttributes: map[string]string{},
+		},
+		"OkCaseSpoofedForwardedForBeforeTrustedProxy": {
+			remoteAddr: "10.0.0.1:1234",
+			headers: map[string][]string{
+				FORWARDED_FOR_HEADER: {"10.1.2.3, 11.1.2.3"},
+			},
+			trustedProxies:     []*net.IPNet{trustedProxy},
+			expectedSourceIP:   "11.1.2.3",
+			expectedAttributes: map[string]string{},
+		},
+		"OkCaseSpoofedAttributesWithoutTrustedProxy": {
+			remoteAddr: "11.1.2.3:1234",
+			headers: map[string][]string{
+				REQUEST_CONTEXT_HEADER_PREFIX + "Department": {"dev"},
+			},
+			trustedProxies:     []*net.IPNet{trustedProxy},
+			expectedSourceIP:   "11.1.2.3",
+			expectedAttributes: map[string]string{},
+		},
+	}
+
+	for n, test := range testcases {
+		r := &http.Request{
+			RemoteAddr: test.remoteAddr,
+			Header:     http.Header(test.headers),
+		}
+		if r.Header == nil {
+			r.Header = http.Header{}
+		}
+		context := getRequestContext(r, test.trustedProxies)
+		if context.SourceIP != test.expectedSourceIP {
+			t.Errorf("Test case %v. Received different source IP (wanted:%v / received:%v)", n, test.expectedSourceIP, context.SourceIP)
+		}
+		if diff := pretty.Compare(context.Attributes, test.expectedAttributes); diff != "" {
+			t.Errorf("Test %v failed. Received different attributes (received/wanted) %v", n, diff)
+		}
+	}
+}
+
+func TestWorkerHandlerRouter_SpoofedRequestContext(t *testing.T) {
+	testcases := map[string]struct {
+		url string
+	}{
+		"OkCaseWorker": {
+			url: server.URL + RESOURCE_URL,
+		},
+		"OkCaseProxy": {
+			url: proxy.URL + USER_ROOT_URL + "/user",
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsIn[GetAuthorizedExternalResourcesMethod][0] = nil
+		testApi.ArgsOut[GetAuthorizedExternalResourcesMethod][0] = nil
+		testApi.ArgsOut[GetAuthorizedExternalResourcesMethod][1] = nil
+
+		method := http.MethodGet
+		body := strings.NewReader("")
+		if strings.HasPrefix(test.url, server.URL) {
+			method = http.MethodPost
+			body = strings.NewReader(`{"action":"example:user","resources":["urn:ews:example:instance1:resource/user"]}`)
+		}
+		req, err := http.NewRequest(method, test.url, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		// Client tries to satisfy IpAddress and attribute conditions
+		req.Header.Set(FORWARDED_FOR_HEADER, "10.1.2.3")
+		req.Header.Set(REQUEST_CONTEXT_HEADER_PREFIX+"Department", "dev")
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		res.Body.Close()
+
+		requestInfo, ok := testApi.ArgsIn[GetAuthorizedExternalResourcesMethod][0].(api.RequestInfo)
+		if !ok {
+			t.Errorf("Test case %v. Authorization wasn't requested", n)
+			continue
+		}
+		if requestInfo.Context.SourceIP != "127.0.0.1" {
+			t.Errorf("Test case %v. Received spoofed source IP %v", n, requestInfo.Context.SourceIP)
+		}
+		if len(requestInfo.Context.Attributes) != 0 {
+			t.Errorf("Test case %v. Received spoofed attributes %v", n, requestInfo.Context.Attributes)
+		}
+	}
+}
+
+func TestWorkerHandlerRouter_ReloadWhileServing(t *testing.T) {
+	logFile := createLogFile(t)
+	defer os.Remove(logFile)
+	worker := createWorker(t, logFile)
+	defer foulkon.CloseWorker()
+	workerServer := httptest.NewServer(WorkerHandlerRouter(worker))
+	defer workerServer.Close()
+
+	reloadWhileServing(t, logFile, func(level string) error {
+		config, err := toml.Load(workerConfig(logFile, level))
+		if err != nil {
+			return err
+		}
+		return worker.Reload(config)
+	}, func() (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodGet, workerServer.URL+USER_ROOT_URL, nil)
+		req.SetBasicAuth("admin", "admin")
+		return http.DefaultClient.Do(req)
+	})
+}
+
+// Aux method that creates an empty file for logs
+func createLogFile(t *testing.T) string {
+	logFile, err := ioutil.TempFile("", "foulkon-log")
+	if err != nil {
+		t.Fatalf("Unexpected error creating log file %v", err)
+	}
+	logFile.Close()
+	return logFile.Name()
+}
+
+// Configuration of a worker with in-memory database, that logs in logFile
+func workerConfig(logFile string, level string) string {
+	return `
+		[server]
+		host = "localhost"
+		port = "8000"
+		[admin]
+		username = "admin"
+		password = "admin"
+		[logger]
+		type = "file"
+		level = "` + level + `"
+		[logger.file]
+		dir = "` + logFile + `"
+		[database]
+		type = "memory"
+		[authenticator]
+		type = "apikey"
+	`
+}
+
+// Aux method that creates a worker with real handlers, that can serve concurrent requests
+func createWorker(t *testing.T, logFile string) *foulkon.Worker {
+	config, err := toml.Load(workerConfig(logFile, "debug"))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing config %v", err)
+	}
+	worker, err := foulkon.NewWorker(config)
+	if err != nil {
+		t.Fatalf("Unexpected error creating worker %v", err)
+	}
+	return worker
+}
+
+// Aux method that reloads log level while requests are served, to be run with -race flag,
+// and checks that reloaded level is applied to request logs written in logFile
+func reloadWhileServing(t *testing.T, logFile string, reload func(level string) error, request func() (*http.Response, error)) {
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+				res, err := request()
+				if err != nil {
+					t.Errorf("Unexpected error calling server %v", err)
+					return
+				}
+				res.Body.Close()
+			}
+		}()
+	}
+	levels := []string{"debug", "info", "warn", "error"}
+	for i := 0; i < 100; i++ {
+		if err := reload(levels[i%len(levels)]); err != nil {
+			t.Errorf("Unexpected reload error %v", err)
+		}
+	}
+	close(done)
+	wg.Wait()
+
+	// Request logs are written with info level
+	for _, level := range []string{"error", "info"} {
+		if err := reload(level); err != nil {
+			t.Fatalf("Unexpected reload error %v", err)
+		}
+		before, _ := os.Stat(logFile)
+		res, err := request()
+		if err != nil {
+			t.Fatalf("Unexpected error calling server %v", err)
+		}
+		res.Body.Close()
+		after, _ := os.Stat(logFile)
+		if logged := after.Size() > before.Size(); logged != (level == "info") {
+			t.Errorf("Level %v. Received different request logging (wanted:%v / received:%v)", level, level == "info", logged)
+		}
+	}
+}
@@ -0,0 +1,65 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/Tecsisa/foulkon/foulkon"
+)
+
+const (
+	// Probe URLs
+	HEALTH_URL = "/health"
+	READY_URL  = "/ready"
+
+	// Probe status
+	HEALTH_STATUS_OK          = "ok"
+	HEALTH_STATUS_UNAVAILABLE = "unavailable"
+)
+
+// RESPONSES
+
+type HealthResponse struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// probeHandler serves liveness in HEALTH_URL and readiness in READY_URL without authentication,
+// and passes other requests to handler
+func probeHandler(checks []foulkon.ReadinessCheck, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case HEALTH_URL:
+			writeHealthResponse(w, http.StatusOK, &HealthResponse{Status: HEALTH_STATUS_OK})
+		case READY_URL:
+			status := http.StatusOK
+			response := &HealthResponse{
+				Status: HEALTH_STATUS_OK,
+				Checks: make(map[string]string),
+			}
+			for _, check := range checks {
+				if err := check.Check(); err != nil {
+					status = http.StatusServiceUnavailable
+					response.Status = HEALTH_STATUS_UNAVAILABLE
+					response.Checks[check.Name] = err.Error()
+				} else {
+					response.Checks[check.Name] = HEALTH_STATUS_OK
+				}
+			}
+			writeHealthResponse(w, status, response)
+		default:
+			handler.ServeHTTP(w, r)
+		}
+	})
+}
+
+func writeHealthResponse(w http.ResponseWriter, status int, response *HealthResponse) {
+	b, err := json.Marshal(response)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(b)
+}
@@ -0,0 +1,88 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/foulkon"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestProbeHandler(t *testing.T) {
+	okCheck := foulkon.ReadinessCheck{
+		Name:  "database",
+		Check: func() error { return nil },
+	}
+	failedCheck := foulkon.ReadinessCheck{
+		Name:  "oidc",
+		Check: func() error { return errors.New("Provider unreachable") },
+	}
+	testcases := map[string]struct {
+		url                string
+		checks             []foulkon.ReadinessCheck
+		expectedStatusCode int
+		expectedResponse   HealthResponse
+	}{
+		"OkCaseHealth": {
+			url:                HEALTH_URL,
+			checks:             []foulkon.ReadinessCheck{failedCheck},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: HealthResponse{
+				Status: HEALTH_STATUS_OK,
+			},
+		},
+		"OkCaseReady": {
+			url:                READY_URL,
+			checks:             []foulkon.ReadinessCheck{okCheck},
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: HealthResponse{
+				Status: HEALTH_STATUS_OK,
+				Checks: map[string]string{
+					"database": HEALTH_STATUS_OK,
+				},
+			},
+		},
+		"ErrorCaseNotReady": {
+			url:                READY_URL,
+			checks:             []foulkon.ReadinessCheck{okCheck, failedCheck},
+			expectedStatusCode: http.StatusServiceUnavailable,
+			expectedResponse: HealthResponse{
+				Status: HEALTH_STATUS_UNAVAILABLE,
+				Checks: map[string]string{
+					"database": HEALTH_STATUS_OK,
+					"oidc":     "Provider unreachable",
+				},
+			},
+		},
+	}
+
+	for n, test := range testcases {
+		routers := map[string]http.Handler{
+			"worker": WorkerHandlerRouter(&foulkon.Worker{ReadinessChecks: test.checks}),
+			"proxy":  ProxyHandlerRouter(&foulkon.Proxy{ReadinessChecks: test.checks}),
+		}
+		for name, router := range routers {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, test.url, nil)
+			// Probes don't need authentication, so they are served without authenticator
+			router.ServeHTTP(w, r)
+
+			if w.Code != test.expectedStatusCode {
+				t.Errorf("Test case %v, %v. Received different http status code (wanted:%v / received:%v)",
+					n, name, test.expectedStatusCode, w.Code)
+				continue
+			}
+			response := HealthResponse{}
+			if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+				t.Errorf("Test case %v, %v. Unexpected error parsing response %v", n, name, err)
+				continue
+			}
+			if diff := pretty.Compare(response, test.expectedResponse); diff != "" {
+				t.Errorf("Test case %v, %v. Received different responses (received/wanted) %v", n, name, diff)
+			}
+		}
+	}
+}
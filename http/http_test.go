@@ -5,6 +5,7 @@ import (
 	"net/http/httptest"
 	"os"
 	"testing"
+	"time"
 
 	"bytes"
 	"fmt"
@@ -16,12 +17,15 @@ import (
 
 const (
 	// USER API METHODS
-	AddUserMethod             = "AddUser"
-	GetUserByExternalIdMethod = "GetUserByExternalId"
-	ListUsersMethod           = "ListUsers"
-	UpdateUserMethod          = "UpdateUser"
-	RemoveUserMethod          = "RemoveUser"
-	ListGroupsByUserMethod    = "ListGroupsByUser"
+	AddUserMethod                  = "AddUser"
+	GetUserByExternalIdMethod      = "GetUserByExternalId"
+	ListUsersMethod                = "ListUsers"
+	UpdateUserMethod               = "UpdateUser"
+	RemoveUserMethod               = "RemoveUser"
+	ListGroupsByUserMethod         = "ListGroupsByUser"
+	AttachPolicyToUserMethod       = "AttachPolicyToUser"
+	DetachPolicyFromUserMethod     = "DetachPolicyFromUser"
+	ListAttachedUserPoliciesMethod = "ListAttachedUserPolicies"
 
 	// GROUP API METHODS
 	AddGroupMethod                  = "AddGroup"
@@ -35,6 +39,8 @@ const (
 	AttachPolicyToGroupMethod       = "AttachPolicyToGroup"
 	DetachPolicyToGroupMethod       = "DetachPolicyToGroup"
 	ListAttachedGroupPoliciesMethod = "ListAttachedGroupPolicies"
+	DryRunAttachPolicyToGroupMethod = "DryRunAttachPolicyToGroup"
+	DryRunDetachPolicyToGroupMethod = "DryRunDetachPolicyToGroup"
 
 	// POLICY API METHODS
 	AddPolicyMethod          = "AddPolicy"
@@ -43,12 +49,39 @@ const (
 	UpdatePolicyMethod       = "UpdatePolicy"
 	RemovePolicyMethod       = "RemovePolicy"
 	ListAttachedGroupsMethod = "ListAttachedGroups"
+	ListPolicyVersionsMethod = "ListPolicyVersions"
+	GetPolicyVersionMethod   = "GetPolicyVersion"
+	DiffPolicyVersionsMethod = "DiffPolicyVersions"
+	RollbackPolicyMethod     = "RollbackPolicy"
+	LintPolicyMethod         = "LintPolicy"
+	LintPoliciesMethod       = "LintPolicies"
+	DryRunAddPolicyMethod    = "DryRunAddPolicy"
+	DryRunUpdatePolicyMethod = "DryRunUpdatePolicy"
 
 	// AUTHZ API
-	GetAuthorizedUsersMethod             = "GetAuthorizedUsers"
-	GetAuthorizedGroupsMethod            = "GetAuthorizedGroups"
-	GetAuthorizedPoliciesMethod          = "GetAuthorizedPolicies"
-	GetAuthorizedExternalResourcesMethod = "GetAuthorizedExternalResources"
+	GetAuthorizedUsersMethod                    = "GetAuthorizedUsers"
+	GetAuthorizedGroupsMethod                   = "GetAuthorizedGroups"
+	GetAuthorizedPoliciesMethod                 = "GetAuthorizedPolicies"
+	GetAuthorizedExternalResourcesMethod        = "GetAuthorizedExternalResources"
+	GetAuthorizedExternalResourcesForUserMethod = "GetAuthorizedExternalResourcesForUser"
+	GetAuthorizedExternalResourcesBatchMethod   = "GetAuthorizedExternalResourcesBatch"
+	GetAuthorizedRestrictionsMethod             = "GetAuthorizedRestrictions"
+	GetAuthorizedPrincipalsMethod               = "GetAuthorizedPrincipals"
+	ExplainAuthorizationMethod                  = "ExplainAuthorization"
+
+	// AUDIT API
+	ListAuditEventsMethod = "ListAuditEvents"
+
+	// IMPORT API
+	ExportMethod = "Export"
+	ImportMethod = "Import"
+
+	// API KEY API
+	CreateAPIKeyMethod = "CreateAPIKey"
+	GetAPIKeyMethod    = "GetAPIKey"
+	ListAPIKeysMethod  = "ListAPIKeys"
+	RotateAPIKeyMethod = "RotateAPIKey"
+	RevokeAPIKeyMethod = "RevokeAPIKey"
 )
 
 // Test server used to test handlers
@@ -123,6 +156,9 @@ func TestMain(m *testing.M) {
 		GroupApi:      testApi,
 		PolicyApi:     testApi,
 		AuthzApi:      testApi,
+		AuditApi:      testApi,
+		ImportApi:     testApi,
+		APIKeyApi:     testApi,
 	}
 
 	server = httptest.NewServer(WorkerHandlerRouter(worker))
@@ -205,6 +241,9 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsIn[UpdateUserMethod] = make([]interface{}, 3)
 	testApi.ArgsIn[RemoveUserMethod] = make([]interface{}, 2)
 	testApi.ArgsIn[ListGroupsByUserMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[AttachPolicyToUserMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[DetachPolicyFromUserMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListAttachedUserPoliciesMethod] = make([]interface{}, 3)
 
 	testApi.ArgsIn[AddGroupMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetGroupByNameMethod] = make([]interface{}, 3)
@@ -216,6 +255,8 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsIn[ListMembersMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[AttachPolicyToGroupMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[DetachPolicyToGroupMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[DryRunAttachPolicyToGroupMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[DryRunDetachPolicyToGroupMethod] = make([]interface{}, 5)
 	testApi.ArgsIn[ListAttachedGroupPoliciesMethod] = make([]interface{}, 4)
 
 	testApi.ArgsIn[AddPolicyMethod] = make([]interface{}, 5)
@@ -224,11 +265,35 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsIn[UpdatePolicyMethod] = make([]interface{}, 6)
 	testApi.ArgsIn[RemovePolicyMethod] = make([]interface{}, 3)
 	testApi.ArgsIn[ListAttachedGroupsMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[ListPolicyVersionsMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[GetPolicyVersionMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[DiffPolicyVersionsMethod] = make([]interface{}, 5)
+	testApi.ArgsIn[RollbackPolicyMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[LintPolicyMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[LintPoliciesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[DryRunAddPolicyMethod] = make([]interface{}, 6)
+	testApi.ArgsIn[DryRunUpdatePolicyMethod] = make([]interface{}, 7)
 
 	testApi.ArgsIn[GetAuthorizedUsersMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedGroupsMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedPoliciesMethod] = make([]interface{}, 4)
 	testApi.ArgsIn[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[GetAuthorizedExternalResourcesForUserMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[GetAuthorizedExternalResourcesBatchMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[GetAuthorizedRestrictionsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[GetAuthorizedPrincipalsMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ExplainAuthorizationMethod] = make([]interface{}, 4)
+
+	testApi.ArgsIn[ListAuditEventsMethod] = make([]interface{}, 2)
+
+	testApi.ArgsIn[ExportMethod] = make([]interface{}, 2)
+	testApi.ArgsIn[ImportMethod] = make([]interface{}, 4)
+
+	testApi.ArgsIn[CreateAPIKeyMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[GetAPIKeyMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[ListAPIKeysMethod] = make([]interface{}, 3)
+	testApi.ArgsIn[RotateAPIKeyMethod] = make([]interface{}, 4)
+	testApi.ArgsIn[RevokeAPIKeyMethod] = make([]interface{}, 3)
 
 	testApi.ArgsOut[AddUserMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetUserByExternalIdMethod] = make([]interface{}, 2)
@@ -236,6 +301,9 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsOut[UpdateUserMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[RemoveUserMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[ListGroupsByUserMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[AttachPolicyToUserMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[DetachPolicyFromUserMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[ListAttachedUserPoliciesMethod] = make([]interface{}, 3)
 
 	testApi.ArgsOut[AddGroupMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetGroupByNameMethod] = make([]interface{}, 2)
@@ -247,6 +315,8 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsOut[ListMembersMethod] = make([]interface{}, 3)
 	testApi.ArgsOut[AttachPolicyToGroupMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[DetachPolicyToGroupMethod] = make([]interface{}, 1)
+	testApi.ArgsOut[DryRunAttachPolicyToGroupMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[DryRunDetachPolicyToGroupMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[ListAttachedGroupPoliciesMethod] = make([]interface{}, 3)
 
 	testApi.ArgsOut[AddPolicyMethod] = make([]interface{}, 2)
@@ -255,11 +325,35 @@ func makeTestApi() *TestAPI {
 	testApi.ArgsOut[UpdatePolicyMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[RemovePolicyMethod] = make([]interface{}, 1)
 	testApi.ArgsOut[ListAttachedGroupsMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[ListPolicyVersionsMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[GetPolicyVersionMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[DiffPolicyVersionsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RollbackPolicyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[LintPolicyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[LintPoliciesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[DryRunAddPolicyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[DryRunUpdatePolicyMethod] = make([]interface{}, 2)
 
 	testApi.ArgsOut[GetAuthorizedUsersMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedGroupsMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedPoliciesMethod] = make([]interface{}, 2)
 	testApi.ArgsOut[GetAuthorizedExternalResourcesMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetAuthorizedExternalResourcesForUserMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetAuthorizedExternalResourcesBatchMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetAuthorizedRestrictionsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetAuthorizedPrincipalsMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ExplainAuthorizationMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[ListAuditEventsMethod] = make([]interface{}, 3)
+
+	testApi.ArgsOut[ExportMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ImportMethod] = make([]interface{}, 2)
+
+	testApi.ArgsOut[CreateAPIKeyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[GetAPIKeyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[ListAPIKeysMethod] = make([]interface{}, 3)
+	testApi.ArgsOut[RotateAPIKeyMethod] = make([]interface{}, 2)
+	testApi.ArgsOut[RevokeAPIKeyMethod] = make([]interface{}, 2)
 
 	return testApi
 }
@@ -358,6 +452,49 @@ func (t TestAPI) ListGroupsByUser(authenticatedUser api.RequestInfo, id string,
 	return groups, total, err
 }
 
+func (t TestAPI) AttachPolicyToUser(authenticatedUser api.RequestInfo, id string, org string, policyName string) error {
+	t.ArgsIn[AttachPolicyToUserMethod][0] = authenticatedUser
+	t.ArgsIn[AttachPolicyToUserMethod][1] = id
+	t.ArgsIn[AttachPolicyToUserMethod][2] = org
+	t.ArgsIn[AttachPolicyToUserMethod][3] = policyName
+	var err error
+	if t.ArgsOut[AttachPolicyToUserMethod][0] != nil {
+		err = t.ArgsOut[AttachPolicyToUserMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) DetachPolicyFromUser(authenticatedUser api.RequestInfo, id string, org string, policyName string) error {
+	t.ArgsIn[DetachPolicyFromUserMethod][0] = authenticatedUser
+	t.ArgsIn[DetachPolicyFromUserMethod][1] = id
+	t.ArgsIn[DetachPolicyFromUserMethod][2] = org
+	t.ArgsIn[DetachPolicyFromUserMethod][3] = policyName
+	var err error
+	if t.ArgsOut[DetachPolicyFromUserMethod][0] != nil {
+		err = t.ArgsOut[DetachPolicyFromUserMethod][0].(error)
+	}
+	return err
+}
+
+func (t TestAPI) ListAttachedUserPolicies(authenticatedUser api.RequestInfo, id string, filter *api.Filter) ([]api.PolicyIdentity, int, error) {
+	t.ArgsIn[ListAttachedUserPoliciesMethod][0] = authenticatedUser
+	t.ArgsIn[ListAttachedUserPoliciesMethod][1] = id
+	t.ArgsIn[ListAttachedUserPoliciesMethod][2] = filter
+	var policies []api.PolicyIdentity
+	if t.ArgsOut[ListAttachedUserPoliciesMethod][0] != nil {
+		policies = t.ArgsOut[ListAttachedUserPoliciesMethod][0].([]api.PolicyIdentity)
+	}
+	var total int
+	if t.ArgsOut[ListAttachedUserPoliciesMethod][1] != nil {
+		total = t.ArgsOut[ListAttachedUserPoliciesMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListAttachedUserPoliciesMethod][2] != nil {
+		err = t.ArgsOut[ListAttachedUserPoliciesMethod][2].(error)
+	}
+	return policies, total, err
+}
+
 // GROUP API
 
 func (t TestAPI) AddGroup(authenticatedUser api.RequestInfo, org string, name string, path string) (*api.Group, error) {
@@ -508,6 +645,44 @@ func (t TestAPI) DetachPolicyToGroup(authenticatedUser api.RequestInfo, org stri
 	return err
 }
 
+func (t TestAPI) DryRunAttachPolicyToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyName string,
+	checks []api.PermissionCheck) (*api.PermissionImpact, error) {
+	t.ArgsIn[DryRunAttachPolicyToGroupMethod][0] = authenticatedUser
+	t.ArgsIn[DryRunAttachPolicyToGroupMethod][1] = org
+	t.ArgsIn[DryRunAttachPolicyToGroupMethod][2] = groupName
+	t.ArgsIn[DryRunAttachPolicyToGroupMethod][3] = policyName
+	t.ArgsIn[DryRunAttachPolicyToGroupMethod][4] = checks
+
+	var impact *api.PermissionImpact
+	if t.ArgsOut[DryRunAttachPolicyToGroupMethod][0] != nil {
+		impact = t.ArgsOut[DryRunAttachPolicyToGroupMethod][0].(*api.PermissionImpact)
+	}
+	var err error
+	if t.ArgsOut[DryRunAttachPolicyToGroupMethod][1] != nil {
+		err = t.ArgsOut[DryRunAttachPolicyToGroupMethod][1].(error)
+	}
+	return impact, err
+}
+
+func (t TestAPI) DryRunDetachPolicyToGroup(authenticatedUser api.RequestInfo, org string, groupName string, policyName string,
+	checks []api.PermissionCheck) (*api.PermissionImpact, error) {
+	t.ArgsIn[DryRunDetachPolicyToGroupMethod][0] = authenticatedUser
+	t.ArgsIn[DryRunDetachPolicyToGroupMethod][1] = org
+	t.ArgsIn[DryRunDetachPolicyToGroupMethod][2] = groupName
+	t.ArgsIn[DryRunDetachPolicyToGroupMethod][3] = policyName
+	t.ArgsIn[DryRunDetachPolicyToGroupMethod][4] = checks
+
+	var impact *api.PermissionImpact
+	if t.ArgsOut[DryRunDetachPolicyToGroupMethod][0] != nil {
+		impact = t.ArgsOut[DryRunDetachPolicyToGroupMethod][0].(*api.PermissionImpact)
+	}
+	var err error
+	if t.ArgsOut[DryRunDetachPolicyToGroupMethod][1] != nil {
+		err = t.ArgsOut[DryRunDetachPolicyToGroupMethod][1].(error)
+	}
+	return impact, err
+}
+
 func (t TestAPI) ListAttachedGroupPolicies(authenticatedUser api.RequestInfo, org string, groupName string, filter *api.Filter) ([]string, int, error) {
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][0] = authenticatedUser
 	t.ArgsIn[ListAttachedGroupPoliciesMethod][1] = org
@@ -603,6 +778,47 @@ func (t TestAPI) UpdatePolicy(authenticatedUser api.RequestInfo, org string, pol
 	return policy, err
 }
 
+func (t TestAPI) DryRunAddPolicy(authenticatedUser api.RequestInfo, name string, path string, org string, statements []api.Statement,
+	checks []api.PermissionCheck) (*api.PermissionImpact, error) {
+	t.ArgsIn[DryRunAddPolicyMethod][0] = authenticatedUser
+	t.ArgsIn[DryRunAddPolicyMethod][1] = name
+	t.ArgsIn[DryRunAddPolicyMethod][2] = path
+	t.ArgsIn[DryRunAddPolicyMethod][3] = org
+	t.ArgsIn[DryRunAddPolicyMethod][4] = statements
+	t.ArgsIn[DryRunAddPolicyMethod][5] = checks
+
+	var impact *api.PermissionImpact
+	if t.ArgsOut[DryRunAddPolicyMethod][0] != nil {
+		impact = t.ArgsOut[DryRunAddPolicyMethod][0].(*api.PermissionImpact)
+	}
+	var err error
+	if t.ArgsOut[DryRunAddPolicyMethod][1] != nil {
+		err = t.ArgsOut[DryRunAddPolicyMethod][1].(error)
+	}
+	return impact, err
+}
+
+func (t TestAPI) DryRunUpdatePolicy(authenticatedUser api.RequestInfo, org string, policyName string, newName string, newPath string,
+	newStatements []api.Statement, checks []api.PermissionCheck) (*api.PermissionImpact, error) {
+	t.ArgsIn[DryRunUpdatePolicyMethod][0] = authenticatedUser
+	t.ArgsIn[DryRunUpdatePolicyMethod][1] = org
+	t.ArgsIn[DryRunUpdatePolicyMethod][2] = policyName
+	t.ArgsIn[DryRunUpdatePolicyMethod][3] = newName
+	t.ArgsIn[DryRunUpdatePolicyMethod][4] = newPath
+	t.ArgsIn[DryRunUpdatePolicyMethod][5] = newStatements
+	t.ArgsIn[DryRunUpdatePolicyMethod][6] = checks
+
+	var impact *api.PermissionImpact
+	if t.ArgsOut[DryRunUpdatePolicyMethod][0] != nil {
+		impact = t.ArgsOut[DryRunUpdatePolicyMethod][0].(*api.PermissionImpact)
+	}
+	var err error
+	if t.ArgsOut[DryRunUpdatePolicyMethod][1] != nil {
+		err = t.ArgsOut[DryRunUpdatePolicyMethod][1].(error)
+	}
+	return impact, err
+}
+
 func (t TestAPI) RemovePolicy(authenticatedUser api.RequestInfo, org string, name string) error {
 	t.ArgsIn[RemovePolicyMethod][0] = authenticatedUser
 	t.ArgsIn[RemovePolicyMethod][1] = org
@@ -635,6 +851,112 @@ func (t TestAPI) ListAttachedGroups(authenticatedUser api.RequestInfo, org strin
 	return groups, total, err
 }
 
+func (t TestAPI) ListPolicyVersions(authenticatedUser api.RequestInfo, org string, policyName string, filter *api.Filter) ([]api.PolicyVersion, int, error) {
+	t.ArgsIn[ListPolicyVersionsMethod][0] = authenticatedUser
+	t.ArgsIn[ListPolicyVersionsMethod][1] = org
+	t.ArgsIn[ListPolicyVersionsMethod][2] = policyName
+	t.ArgsIn[ListPolicyVersionsMethod][3] = filter
+
+	var versions []api.PolicyVersion
+	var total int
+	if t.ArgsOut[ListPolicyVersionsMethod][1] != nil {
+		total = t.ArgsOut[ListPolicyVersionsMethod][1].(int)
+	}
+	if t.ArgsOut[ListPolicyVersionsMethod][0] != nil {
+		versions = t.ArgsOut[ListPolicyVersionsMethod][0].([]api.PolicyVersion)
+	}
+	var err error
+	if t.ArgsOut[ListPolicyVersionsMethod][2] != nil {
+		err = t.ArgsOut[ListPolicyVersionsMethod][2].(error)
+	}
+	return versions, total, err
+}
+
+func (t TestAPI) GetPolicyVersion(authenticatedUser api.RequestInfo, org string, policyName string, version int) (*api.PolicyVersion, error) {
+	t.ArgsIn[GetPolicyVersionMethod][0] = authenticatedUser
+	t.ArgsIn[GetPolicyVersionMethod][1] = org
+	t.ArgsIn[GetPolicyVersionMethod][2] = policyName
+	t.ArgsIn[GetPolicyVersionMethod][3] = version
+
+	var policyVersion *api.PolicyVersion
+	if t.ArgsOut[GetPolicyVersionMethod][0] != nil {
+		policyVersion = t.ArgsOut[GetPolicyVersionMethod][0].(*api.PolicyVersion)
+	}
+	var err error
+	if t.ArgsOut[GetPolicyVersionMethod][1] != nil {
+		err = t.ArgsOut[GetPolicyVersionMethod][1].(error)
+	}
+	return policyVersion, err
+}
+
+func (t TestAPI) DiffPolicyVersions(authenticatedUser api.RequestInfo, org string, policyName string, fromVersion int, toVersion int) (*api.PolicyVersionDiff, error) {
+	t.ArgsIn[DiffPolicyVersionsMethod][0] = authenticatedUser
+	t.ArgsIn[DiffPolicyVersionsMethod][1] = org
+	t.ArgsIn[DiffPolicyVersionsMethod][2] = policyName
+	t.ArgsIn[DiffPolicyVersionsMethod][3] = fromVersion
+	t.ArgsIn[DiffPolicyVersionsMethod][4] = toVersion
+
+	var diff *api.PolicyVersionDiff
+	if t.ArgsOut[DiffPolicyVersionsMethod][0] != nil {
+		diff = t.ArgsOut[DiffPolicyVersionsMethod][0].(*api.PolicyVersionDiff)
+	}
+	var err error
+	if t.ArgsOut[DiffPolicyVersionsMethod][1] != nil {
+		err = t.ArgsOut[DiffPolicyVersionsMethod][1].(error)
+	}
+	return diff, err
+}
+
+func (t TestAPI) RollbackPolicy(authenticatedUser api.RequestInfo, org string, policyName string, version int) (*api.Policy, error) {
+	t.ArgsIn[RollbackPolicyMethod][0] = authenticatedUser
+	t.ArgsIn[RollbackPolicyMethod][1] = org
+	t.ArgsIn[RollbackPolicyMethod][2] = policyName
+	t.ArgsIn[RollbackPolicyMethod][3] = version
+
+	var policy *api.Policy
+	if t.ArgsOut[RollbackPolicyMethod][0] != nil {
+		policy = t.ArgsOut[RollbackPolicyMethod][0].(*api.Policy)
+	}
+	var err error
+	if t.ArgsOut[RollbackPolicyMethod][1] != nil {
+		err = t.ArgsOut[RollbackPolicyMethod][1].(error)
+	}
+	return policy, err
+}
+
+func (t TestAPI) LintPolicy(authenticatedUser api.RequestInfo, org string, policyName string, knownActions []string) (*api.PolicyLintReport, error) {
+	t.ArgsIn[LintPolicyMethod][0] = authenticatedUser
+	t.ArgsIn[LintPolicyMethod][1] = org
+	t.ArgsIn[LintPolicyMethod][2] = policyName
+	t.ArgsIn[LintPolicyMethod][3] = knownActions
+
+	var report *api.PolicyLintReport
+	if t.ArgsOut[LintPolicyMethod][0] != nil {
+		report = t.ArgsOut[LintPolicyMethod][0].(*api.PolicyLintReport)
+	}
+	var err error
+	if t.ArgsOut[LintPolicyMethod][1] != nil {
+		err = t.ArgsOut[LintPolicyMethod][1].(error)
+	}
+	return report, err
+}
+
+func (t TestAPI) LintPolicies(authenticatedUser api.RequestInfo, org string, knownActions []string) ([]api.PolicyLintReport, error) {
+	t.ArgsIn[LintPoliciesMethod][0] = authenticatedUser
+	t.ArgsIn[LintPoliciesMethod][1] = org
+	t.ArgsIn[LintPoliciesMethod][2] = knownActions
+
+	var reports []api.PolicyLintReport
+	if t.ArgsOut[LintPoliciesMethod][0] != nil {
+		reports = t.ArgsOut[LintPoliciesMethod][0].([]api.PolicyLintReport)
+	}
+	var err error
+	if t.ArgsOut[LintPoliciesMethod][1] != nil {
+		err = t.ArgsOut[LintPoliciesMethod][1].(error)
+	}
+	return reports, err
+}
+
 // AUTHZ API
 
 func (t TestAPI) GetAuthorizedUsers(authenticatedUser api.RequestInfo, resourceUrn string, action string, users []api.User) ([]api.User, error) {
@@ -664,6 +986,232 @@ func (t TestAPI) GetAuthorizedExternalResources(authenticatedUser api.RequestInf
 	return resourcesToReturn, err
 }
 
+func (t TestAPI) GetAuthorizedExternalResourcesForUser(authenticatedUser api.RequestInfo, externalID string, action string, resources []string) ([]string, error) {
+	t.ArgsIn[GetAuthorizedExternalResourcesForUserMethod][0] = authenticatedUser
+	t.ArgsIn[GetAuthorizedExternalResourcesForUserMethod][1] = externalID
+	t.ArgsIn[GetAuthorizedExternalResourcesForUserMethod][2] = action
+	t.ArgsIn[GetAuthorizedExternalResourcesForUserMethod][3] = resources
+	var resourcesToReturn []string
+	if t.ArgsOut[GetAuthorizedExternalResourcesForUserMethod][0] != nil {
+		resourcesToReturn = t.ArgsOut[GetAuthorizedExternalResourcesForUserMethod][0].([]string)
+	}
+	var err error
+	if t.ArgsOut[GetAuthorizedExternalResourcesForUserMethod][1] != nil {
+		err = t.ArgsOut[GetAuthorizedExternalResourcesForUserMethod][1].(error)
+	}
+	return resourcesToReturn, err
+}
+
+func (t TestAPI) GetAuthorizedExternalResourcesBatch(authenticatedUser api.RequestInfo, checks []api.AuthorizationCheck) ([]api.AuthorizationCheckResult, error) {
+	t.ArgsIn[GetAuthorizedExternalResourcesBatchMethod][0] = authenticatedUser
+	t.ArgsIn[GetAuthorizedExternalResourcesBatchMethod][1] = checks
+	var results []api.AuthorizationCheckResult
+	if t.ArgsOut[GetAuthorizedExternalResourcesBatchMethod][0] != nil {
+		results = t.ArgsOut[GetAuthorizedExternalResourcesBatchMethod][0].([]api.AuthorizationCheckResult)
+	}
+	var err error
+	if t.ArgsOut[GetAuthorizedExternalResourcesBatchMethod][1] != nil {
+		err = t.ArgsOut[GetAuthorizedExternalResourcesBatchMethod][1].(error)
+	}
+	return results, err
+}
+
+func (t TestAPI) GetAuthorizedRestrictions(authenticatedUser api.RequestInfo, action string, urnPrefix string) (*api.Restrictions, error) {
+	t.ArgsIn[GetAuthorizedRestrictionsMethod][0] = authenticatedUser
+	t.ArgsIn[GetAuthorizedRestrictionsMethod][1] = action
+	t.ArgsIn[GetAuthorizedRestrictionsMethod][2] = urnPrefix
+	var restrictions *api.Restrictions
+	if t.ArgsOut[GetAuthorizedRestrictionsMethod][0] != nil {
+		restrictions = t.ArgsOut[GetAuthorizedRestrictionsMethod][0].(*api.Restrictions)
+	}
+	var err error
+	if t.ArgsOut[GetAuthorizedRestrictionsMethod][1] != nil {
+		err = t.ArgsOut[GetAuthorizedRestrictionsMethod][1].(error)
+	}
+	return restrictions, err
+}
+
+func (t TestAPI) GetAuthorizedPrincipals(authenticatedUser api.RequestInfo, action string, resource string) (*api.AuthorizedPrincipals, error) {
+	t.ArgsIn[GetAuthorizedPrincipalsMethod][0] = authenticatedUser
+	t.ArgsIn[GetAuthorizedPrincipalsMethod][1] = action
+	t.ArgsIn[GetAuthorizedPrincipalsMethod][2] = resource
+	var principals *api.AuthorizedPrincipals
+	if t.ArgsOut[GetAuthorizedPrincipalsMethod][0] != nil {
+		principals = t.ArgsOut[GetAuthorizedPrincipalsMethod][0].(*api.AuthorizedPrincipals)
+	}
+	var err error
+	if t.ArgsOut[GetAuthorizedPrincipalsMethod][1] != nil {
+		err = t.ArgsOut[GetAuthorizedPrincipalsMethod][1].(error)
+	}
+	return principals, err
+}
+
+func (t TestAPI) ExplainAuthorization(authenticatedUser api.RequestInfo, externalID string, action string, resources []string) (*api.AuthorizationExplanation, error) {
+	t.ArgsIn[ExplainAuthorizationMethod][0] = authenticatedUser
+	t.ArgsIn[ExplainAuthorizationMethod][1] = externalID
+	t.ArgsIn[ExplainAuthorizationMethod][2] = action
+	t.ArgsIn[ExplainAuthorizationMethod][3] = resources
+	var explanation *api.AuthorizationExplanation
+	if t.ArgsOut[ExplainAuthorizationMethod][0] != nil {
+		explanation = t.ArgsOut[ExplainAuthorizationMethod][0].(*api.AuthorizationExplanation)
+	}
+	var err error
+	if t.ArgsOut[ExplainAuthorizationMethod][1] != nil {
+		err = t.ArgsOut[ExplainAuthorizationMethod][1].(error)
+	}
+	return explanation, err
+}
+
+// AUDIT API
+
+func (t TestAPI) ListAuditEvents(authenticatedUser api.RequestInfo, filter *api.AuditFilter) ([]api.AuditEvent, int, error) {
+	t.ArgsIn[ListAuditEventsMethod][0] = authenticatedUser
+	t.ArgsIn[ListAuditEventsMethod][1] = filter
+
+	var events []api.AuditEvent
+	var total int
+	if t.ArgsOut[ListAuditEventsMethod][1] != nil {
+		total = t.ArgsOut[ListAuditEventsMethod][1].(int)
+	}
+	if t.ArgsOut[ListAuditEventsMethod][0] != nil {
+		events = t.ArgsOut[ListAuditEventsMethod][0].([]api.AuditEvent)
+	}
+	var err error
+	if t.ArgsOut[ListAuditEventsMethod][2] != nil {
+		err = t.ArgsOut[ListAuditEventsMethod][2].(error)
+	}
+	return events, total, err
+}
+
+// IMPORT API
+
+func (t TestAPI) Export(authenticatedUser api.RequestInfo, org string) (*api.ExportDocument, error) {
+	t.ArgsIn[ExportMethod][0] = authenticatedUser
+	t.ArgsIn[ExportMethod][1] = org
+
+	var document *api.ExportDocument
+	if t.ArgsOut[ExportMethod][0] != nil {
+		document = t.ArgsOut[ExportMethod][0].(*api.ExportDocument)
+	}
+	var err error
+	if t.ArgsOut[ExportMethod][1] != nil {
+		err = t.ArgsOut[ExportMethod][1].(error)
+	}
+	return document, err
+}
+
+func (t TestAPI) Import(authenticatedUser api.RequestInfo, org string, document *api.ExportDocument, prune bool) (*api.ImportResult, error) {
+	t.ArgsIn[ImportMethod][0] = authenticatedUser
+	t.ArgsIn[ImportMethod][1] = org
+	t.ArgsIn[ImportMethod][2] = document
+	t.ArgsIn[ImportMethod][3] = prune
+
+	var result *api.ImportResult
+	if t.ArgsOut[ImportMethod][0] != nil {
+		result = t.ArgsOut[ImportMethod][0].(*api.ImportResult)
+	}
+	var err error
+	if t.ArgsOut[ImportMethod][1] != nil {
+		err = t.ArgsOut[ImportMethod][1].(error)
+	}
+	return result, err
+}
+
+// API KEY API
+
+func (t TestAPI) CreateAPIKey(authenticatedUser api.RequestInfo, externalID string, name string, expireAt *time.Time) (*api.APIKeySecret, error) {
+	t.ArgsIn[CreateAPIKeyMethod][0] = authenticatedUser
+	t.ArgsIn[CreateAPIKeyMethod][1] = externalID
+	t.ArgsIn[CreateAPIKeyMethod][2] = name
+	t.ArgsIn[CreateAPIKeyMethod][3] = expireAt
+
+	var apiKey *api.APIKeySecret
+	if t.ArgsOut[CreateAPIKeyMethod][0] != nil {
+		apiKey = t.ArgsOut[CreateAPIKeyMethod][0].(*api.APIKeySecret)
+	}
+	var err error
+	if t.ArgsOut[CreateAPIKeyMethod][1] != nil {
+		err = t.ArgsOut[CreateAPIKeyMethod][1].(error)
+	}
+	return apiKey, err
+}
+
+func (t TestAPI) GetAPIKey(authenticatedUser api.RequestInfo, externalID string, id string) (*api.APIKey, error) {
+	t.ArgsIn[GetAPIKeyMethod][0] = authenticatedUser
+	t.ArgsIn[GetAPIKeyMethod][1] = externalID
+	t.ArgsIn[GetAPIKeyMethod][2] = id
+
+	var apiKey *api.APIKey
+	if t.ArgsOut[GetAPIKeyMethod][0] != nil {
+		apiKey = t.ArgsOut[GetAPIKeyMethod][0].(*api.APIKey)
+	}
+	var err error
+	if t.ArgsOut[GetAPIKeyMethod][1] != nil {
+		err = t.ArgsOut[GetAPIKeyMethod][1].(error)
+	}
+	return apiKey, err
+}
+
+func (t TestAPI) ListAPIKeys(authenticatedUser api.RequestInfo, externalID string, filter *api.Filter) ([]api.APIKey, int, error) {
+	t.ArgsIn[ListAPIKeysMethod][0] = authenticatedUser
+	t.ArgsIn[ListAPIKeysMethod][1] = externalID
+	t.ArgsIn[ListAPIKeysMethod][2] = filter
+
+	var apiKeys []api.APIKey
+	if t.ArgsOut[ListAPIKeysMethod][0] != nil {
+		apiKeys = t.ArgsOut[ListAPIKeysMethod][0].([]api.APIKey)
+	}
+	var total int
+	if t.ArgsOut[ListAPIKeysMethod][1] != nil {
+		total = t.ArgsOut[ListAPIKeysMethod][1].(int)
+	}
+	var err error
+	if t.ArgsOut[ListAPIKeysMethod][2] != nil {
+		err = t.ArgsOut[ListAPIKeysMethod][2].(error)
+	}
+	return apiKeys, total, err
+}
+
+func (t TestAPI) RotateAPIKey(authenticatedUser api.RequestInfo, externalID string, id string, expireAt *time.Time) (*api.APIKeySecret, error) {
+	t.ArgsIn[RotateAPIKeyMethod][0] = authenticatedUser
+	t.ArgsIn[RotateAPIKeyMethod][1] = externalID
+	t.ArgsIn[RotateAPIKeyMethod][2] = id
+	t.ArgsIn[RotateAPIKeyMethod][3] = expireAt
+
+	var apiKey *api.APIKeySecret
+	if t.ArgsOut[RotateAPIKeyMethod][0] != nil {
+		apiKey = t.ArgsOut[RotateAPIKeyMethod][0].(*api.APIKeySecret)
+	}
+	var err error
+	if t.ArgsOut[RotateAPIKeyMethod][1] != nil {
+		err = t.ArgsOut[RotateAPIKeyMethod][1].(error)
+	}
+	return apiKey, err
+}
+
+func (t TestAPI) RevokeAPIKey(authenticatedUser api.RequestInfo, externalID string, id string) (*api.APIKey, error) {
+	t.ArgsIn[RevokeAPIKeyMethod][0] = authenticatedUser
+	t.ArgsIn[RevokeAPIKeyMethod][1] = externalID
+	t.ArgsIn[RevokeAPIKeyMethod][2] = id
+
+	var apiKey *api.APIKey
+	if t.ArgsOut[RevokeAPIKeyMethod][0] != nil {
+		apiKey = t.ArgsOut[RevokeAPIKeyMethod][0].(*api.APIKey)
+	}
+	var err error
+	if t.ArgsOut[RevokeAPIKeyMethod][1] != nil {
+		err = t.ArgsOut[RevokeAPIKeyMethod][1].(error)
+	}
+	return apiKey, err
+}
+
+func (t TestAPI) AuthenticateAPIKey(key string) (string, error) {
+	return "", &api.Error{
+		Code:    api.INVALID_API_KEY,
+		Message: "API keys aren't used in handler tests",
+	}
+}
+
 // Private helper methods
 
 func addQueryParams(filter *api.Filter, r *http.Request) {
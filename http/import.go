@@ -0,0 +1,89 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleExport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org from request path, empty to export all organizations
+	org := ps.ByName(ORG_NAME)
+
+	// Call import API to export users, groups and policies
+	response, err := h.worker.ImportApi.Export(requestInfo, org)
+	if err != nil {
+		h.respondImportError(r, requestInfo, w, err)
+		return
+	}
+
+	// Return document
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleImport(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org from request path, empty to import all organizations
+	org := ps.ByName(ORG_NAME)
+
+	// Decode request
+	request := api.ExportDocument{}
+	err := json.NewDecoder(r.Body).Decode(&request)
+	if err != nil {
+		apiError := &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: err.Error(),
+		}
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Retrieve prune mode
+	prune := false
+	if value := r.URL.Query().Get("Prune"); len(value) != 0 {
+		prune, err = strconv.ParseBool(value)
+		if err != nil {
+			apiError := &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: fmt.Sprintf("Invalid parameter: Prune %v", value),
+			}
+			api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+			return
+		}
+	}
+
+	// Call import API to apply document
+	response, err := h.worker.ImportApi.Import(requestInfo, org, &request, prune)
+	if err != nil {
+		h.respondImportError(r, requestInfo, w, err)
+		return
+	}
+
+	// Return import result
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+func (h *WorkerHandler) respondImportError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,329 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestWorkerHandler_HandleExport(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org string
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   *api.ExportDocument
+		expectedError      api.Error
+		// Manager Errors
+		exportErr error
+	}{
+		"OkCaseOrg": {
+			org:                "org1",
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.ExportDocument{
+				Org: "org1",
+				Users: []api.ExportUser{
+					{
+						ExternalID: "user1",
+						Path:       "/path/",
+						Policies:   []api.PolicyIdentity{},
+					},
+				},
+				Groups: []api.ExportGroup{
+					{
+						Org:      "org1",
+						Name:     "group1",
+						Path:     "/path/",
+						Members:  []string{"user1"},
+						Policies: []string{},
+					},
+				},
+				Policies: []api.ExportPolicy{},
+			},
+		},
+		"OkCaseAllOrgs": {
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.ExportDocument{
+				Users:    []api.ExportUser{},
+				Groups:   []api.ExportGroup{},
+				Policies: []api.ExportPolicy{},
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:                "org1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			exportErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			org:                "org1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org org1",
+			},
+			exportErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org org1",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			exportErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ExportMethod][0] = test.expectedResponse
+		testApi.ArgsOut[ExportMethod][1] = test.exportErr
+
+		reqUrl := server.URL + API_VERSION_1 + "/export"
+		if test.org != "" {
+			reqUrl = server.URL + API_VERSION_1 + "/organizations/" + test.org + "/export"
+		}
+		req, err := http.NewRequest(http.MethodGet, reqUrl, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[ExportMethod][1] != test.org {
+			t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ExportMethod][1])
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			exportResponse := &api.ExportDocument{}
+			err = json.NewDecoder(res.Body).Decode(exportResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(exportResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleImport(t *testing.T) {
+	document := &api.ExportDocument{
+		Org: "org1",
+		Users: []api.ExportUser{
+			{
+				ExternalID: "user1",
+				Path:       "/path/",
+				Policies:   []api.PolicyIdentity{},
+			},
+		},
+		Groups:   []api.ExportGroup{},
+		Policies: []api.ExportPolicy{},
+	}
+	testcases := map[string]struct {
+		// API method args
+		org         string
+		request     *api.ExportDocument
+		queryParams url.Values
+		// Expected result
+		expectedStatusCode int
+		expectedPrune      bool
+		expectedResponse   *api.ImportResult
+		expectedError      api.Error
+		// Manager Errors
+		importErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			request:            document,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: &api.ImportResult{
+				Users:     api.ImportCounts{Created: 1},
+				Conflicts: []string{},
+			},
+		},
+		"OkCasePruneAllOrgs": {
+			request: document,
+			queryParams: url.Values{
+				"Prune": {"true"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedPrune:      true,
+			expectedResponse: &api.ImportResult{
+				Users:     api.ImportCounts{Unchanged: 1, Removed: 2},
+				Conflicts: []string{},
+			},
+		},
+		"ErrorCaseInvalidPrune": {
+			org:     "org1",
+			request: document,
+			queryParams: url.Values{
+				"Prune": {"maybe"},
+			},
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Prune maybe",
+			},
+		},
+		"ErrorCaseMalformedRequest": {
+			org:                "org1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "EOF",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			org:                "org1",
+			request:            document,
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			importErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			request:            document,
+			expectedStatusCode: http.StatusInternalServerError,
+			importErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsIn[ImportMethod][2] = nil
+		testApi.ArgsOut[ImportMethod][0] = test.expectedResponse
+		testApi.ArgsOut[ImportMethod][1] = test.importErr
+
+		body := bytes.NewBuffer([]byte{})
+		if test.request != nil {
+			jsonObject, err := json.Marshal(test.request)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+				continue
+			}
+			body = bytes.NewBuffer(jsonObject)
+		}
+
+		reqUrl := server.URL + API_VERSION_1 + "/import"
+		if test.org != "" {
+			reqUrl = server.URL + API_VERSION_1 + "/organizations/" + test.org + "/import"
+		}
+		req, err := http.NewRequest(http.MethodPost, reqUrl, body)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		// Check received parameters
+		if test.expectedStatusCode != http.StatusBadRequest {
+			if testApi.ArgsIn[ImportMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ImportMethod][1])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[ImportMethod][2], test.request); diff != "" {
+				t.Errorf("Test %v failed. Received different document (received/wanted) %v", n, diff)
+				continue
+			}
+			if testApi.ArgsIn[ImportMethod][3] != test.expectedPrune {
+				t.Errorf("Test case %v. Received different prune (wanted:%v / received:%v)", n, test.expectedPrune, testApi.ArgsIn[ImportMethod][3])
+				continue
+			}
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			importResponse := &api.ImportResult{}
+			err = json.NewDecoder(res.Body).Decode(importResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(importResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package http
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Tecsisa/foulkon/metrics"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Route label for requests that don't match any route
+const UNMATCHED_ROUTE = "unmatched"
+
+var (
+	// Requests by route, method and status code
+	requestsTotal = metrics.NewCounterVec("foulkon_http_requests_total",
+		"HTTP requests by route, method and status code.", "route", "method", "code")
+	requestDuration = metrics.NewHistogramVec("foulkon_http_request_duration_seconds",
+		"HTTP request latency in seconds by route, method and status code.", metrics.DefaultBuckets, "route", "method", "code")
+
+	// Proxy requests to destination hosts by resource id
+	upstreamDuration = metrics.NewHistogramVec("foulkon_proxy_upstream_duration_seconds",
+		"Latency in seconds of proxy requests to destination hosts by resource id.", metrics.DefaultBuckets, "resource")
+)
+
+// ResponseWriter that keeps the status code written
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// MetricsHandlerRouter returns the handler for the metrics listener, that exposes metrics in METRICS_URL
+func MetricsHandlerRouter() http.Handler {
+	router := httprouter.New()
+	router.Handler(http.MethodGet, METRICS_URL, metrics.Handler())
+	return router
+}
+
+// instrumentHandler records count and latency of requests, labeled with the router route that matches them
+func instrumentHandler(router *httprouter.Router, handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		handler.ServeHTTP(recorder, r)
+
+		route := getRoute(router, r)
+		code := strconv.Itoa(recorder.status)
+		requestsTotal.Inc(route, r.Method, code)
+		requestDuration.ObserveSince(start, route, r.Method, code)
+	})
+}
+
+// Retrieve the route that matches the request, replacing parameter values by their names
+// (e.g. /api/v1/users/:userid), to keep metric labels bounded
+func getRoute(router *httprouter.Router, r *http.Request) string {
+	handle, params, _ := router.Lookup(r.Method, r.URL.Path)
+	if handle == nil {
+		return UNMATCHED_ROUTE
+	}
+
+	segments := strings.Split(r.URL.Path, "/")
+	next := 0
+	for i := range segments {
+		if next < len(params) && segments[i] == params[next].Value {
+			segments[i] = ":" + params[next].Key
+			next++
+		}
+	}
+	return strings.Join(segments, "/")
+}
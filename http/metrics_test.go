@@ -0,0 +1,146 @@
+package http
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/julienschmidt/httprouter"
+)
+
+func TestMetricsHandlerRouter(t *testing.T) {
+	metricsServer := httptest.NewServer(MetricsHandlerRouter())
+	defer metricsServer.Close()
+
+	testcases := map[string]struct {
+		url                string
+		expectedStatusCode int
+		expectedMetrics    []string
+	}{
+		"OkCaseMetrics": {
+			url:                metricsServer.URL + METRICS_URL,
+			expectedStatusCode: http.StatusOK,
+			expectedMetrics: []string{
+				"foulkon_authorization_decisions_total",
+				"foulkon_http_requests_total",
+				"foulkon_http_request_duration_seconds",
+				"foulkon_proxy_upstream_duration_seconds",
+			},
+		},
+		"ErrorCaseUnknownURL": {
+			url:                metricsServer.URL + USER_ROOT_URL,
+			expectedStatusCode: http.StatusNotFound,
+		},
+	}
+
+	for n, test := range testcases {
+		res, err := http.Get(test.url)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		buffer := new(bytes.Buffer)
+		buffer.ReadFrom(res.Body)
+		res.Body.Close()
+
+		if res.StatusCode != test.expectedStatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)",
+				n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+		for _, metric := range test.expectedMetrics {
+			if !strings.Contains(buffer.String(), "# TYPE "+metric+" ") {
+				t.Errorf("Test case %v. Metric %v not found in response", n, metric)
+			}
+		}
+	}
+}
+
+func TestInstrumentHandler_MetricsURL(t *testing.T) {
+	// Metrics url is a resource like any other in worker and proxy listeners
+	authConnector.unauthenticated = true
+	res, err := http.Get(server.URL + METRICS_URL)
+	authConnector.unauthenticated = false
+	if err != nil {
+		t.Fatalf("Unexpected error calling server %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusUnauthorized {
+		t.Errorf("Received different worker http status code (wanted:%v / received:%v)", http.StatusUnauthorized, res.StatusCode)
+	}
+
+	res, err = http.Get(proxy.URL + METRICS_URL)
+	if err != nil {
+		t.Fatalf("Unexpected error calling proxy %v", err)
+	}
+	res.Body.Close()
+	if res.StatusCode != http.StatusNotFound {
+		t.Errorf("Received different proxy http status code (wanted:%v / received:%v)", http.StatusNotFound, res.StatusCode)
+	}
+}
+
+func TestInstrumentHandler_RequestsTotal(t *testing.T) {
+	before := requestsTotal.Value(USER_ROOT_URL, http.MethodGet, "401")
+
+	authConnector.unauthenticated = true
+	res, err := http.Get(server.URL + USER_ROOT_URL)
+	if err != nil {
+		t.Fatalf("Unexpected error calling server %v", err)
+	}
+	res.Body.Close()
+
+	if after := requestsTotal.Value(USER_ROOT_URL, http.MethodGet, "401"); after != before+1 {
+		t.Errorf("Received different request count (wanted:%v / received:%v)", before+1, after)
+	}
+	if count := requestDuration.Count(USER_ROOT_URL, http.MethodGet, "401"); count == 0 {
+		t.Error("Request latency not recorded")
+	}
+}
+
+func TestGetRoute(t *testing.T) {
+	handle := func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {}
+	router := httprouter.New()
+	router.GET(USER_ID_URL, handle)
+	router.GET(POLICY_ID_VERSIONS_ID_URL, handle)
+
+	testcases := map[string]struct {
+		method        string
+		path          string
+		expectedRoute string
+	}{
+		"OkCaseOneParam": {
+			method:        http.MethodGet,
+			path:          USER_ROOT_URL + "/user1",
+			expectedRoute: USER_ID_URL,
+		},
+		"OkCaseSeveralParams": {
+			method:        http.MethodGet,
+			path:          API_VERSION_1 + "/organizations/org1/policies/p1/versions/2",
+			expectedRoute: POLICY_ID_VERSIONS_ID_URL,
+		},
+		"OkCaseParamsWithSameValue": {
+			method:        http.MethodGet,
+			path:          API_VERSION_1 + "/organizations/versions/policies/versions/versions/2",
+			expectedRoute: POLICY_ID_VERSIONS_ID_URL,
+		},
+		"OkCaseUnmatchedPath": {
+			method:        http.MethodGet,
+			path:          "/unknown",
+			expectedRoute: UNMATCHED_ROUTE,
+		},
+		"OkCaseUnmatchedMethod": {
+			method:        http.MethodDelete,
+			path:          USER_ROOT_URL + "/user1",
+			expectedRoute: UNMATCHED_ROUTE,
+		},
+	}
+
+	for n, test := range testcases {
+		r, _ := http.NewRequest(test.method, test.path, nil)
+		if route := getRoute(router, r); route != test.expectedRoute {
+			t.Errorf("Test case %v. Received different route (wanted:%v / received:%v)", n, test.expectedRoute, route)
+		}
+	}
+}
@@ -65,8 +65,22 @@ func (h *WorkerHandler) HandleAddPolicy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	// Store this policy
-	response, err := h.worker.PolicyApi.AddPolicy(requestInfo, request.Name, request.Path, org, request.Statements)
+	// Retrieve dry run mode
+	dryRun, checks, err := getDryRunData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Store this policy, or only check the impact of storing it in dry run mode
+	var response interface{}
+	if dryRun {
+		response, err = h.worker.PolicyApi.DryRunAddPolicy(requestInfo, request.Name, request.Path, org, request.Statements, checks)
+	} else {
+		response, err = h.worker.PolicyApi.AddPolicy(requestInfo, request.Name, request.Path, org, request.Statements)
+	}
 
 	// Error handling
 	if err != nil {
@@ -86,7 +100,11 @@ func (h *WorkerHandler) HandleAddPolicy(w http.ResponseWriter, r *http.Request,
 		return
 	}
 
-	// Write policy to response
+	// Write impact or policy to response
+	if dryRun {
+		h.RespondOk(r, requestInfo, w, response)
+		return
+	}
 	h.RespondCreated(r, requestInfo, w, response)
 }
 
@@ -228,8 +246,22 @@ func (h *WorkerHandler) HandleUpdatePolicy(w http.ResponseWriter, r *http.Reques
 	org := ps.ByName(ORG_NAME)
 	policyName := ps.ByName(POLICY_NAME)
 
-	// Call policy API to update policy
-	response, err := h.worker.PolicyApi.UpdatePolicy(requestInfo, org, policyName, request.Name, request.Path, request.Statements)
+	// Retrieve dry run mode
+	dryRun, checks, err := getDryRunData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policy API to update policy, or only check the impact of updating it in dry run mode
+	var response interface{}
+	if dryRun {
+		response, err = h.worker.PolicyApi.DryRunUpdatePolicy(requestInfo, org, policyName, request.Name, request.Path, request.Statements, checks)
+	} else {
+		response, err = h.worker.PolicyApi.UpdatePolicy(requestInfo, org, policyName, request.Name, request.Path, request.Statements)
+	}
 
 	// Check errors
 	if err != nil {
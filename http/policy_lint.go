@@ -0,0 +1,72 @@
+package http
+
+import (
+	"net/http"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// RESPONSES
+
+type LintPoliciesResponse struct {
+	Reports []api.PolicyLintReport `json:"reports, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleLintPolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org and policy name from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Call policy API to analyze policy, with known actions from query params
+	response, err := h.worker.PolicyApi.LintPolicy(requestInfo, org, policyName, r.URL.Query()["Action"])
+	if err != nil {
+		h.respondPolicyLintError(r, requestInfo, w, err)
+		return
+	}
+
+	// Return findings
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleLintPolicies(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org from request path
+	org := ps.ByName(ORG_NAME)
+
+	// Call policy API to analyze policies of the organization, with known actions from query params
+	result, err := h.worker.PolicyApi.LintPolicies(requestInfo, org, r.URL.Query()["Action"])
+	if err != nil {
+		h.respondPolicyLintError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &LintPoliciesResponse{
+		Reports: result,
+	}
+
+	// Return findings
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+func (h *WorkerHandler) respondPolicyLintError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
@@ -0,0 +1,288 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestWorkerHandler_HandleLintPolicy(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		queryParams url.Values
+		// Expected result
+		expectedStatusCode   int
+		expectedKnownActions []string
+		expectedResponse     *api.PolicyLintReport
+		expectedError        api.Error
+		// Manager Errors
+		lintPolicyErr error
+	}{
+		"OkCase": {
+			queryParams: url.Values{
+				"Action": {"product:get", "product:list"},
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedKnownActions: []string{"product:get", "product:list"},
+			expectedResponse: &api.PolicyLintReport{
+				Org:  "org1",
+				Name: "p1",
+				Findings: []api.PolicyLintFinding{
+					{
+						Type:      api.LINT_UNKNOWN_ACTION,
+						Statement: 0,
+						Value:     "product:delete",
+						Message:   "Action product:delete doesn't match any known action",
+					},
+				},
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+			lintPolicyErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorized": {
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			lintPolicyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			lintPolicyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[LintPolicyMethod][0] = test.expectedResponse
+		testApi.ArgsOut[LintPolicyMethod][1] = test.lintPolicyErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+API_VERSION_1+"/organizations/org1/policies/p1/lint", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[LintPolicyMethod][1] != "org1" {
+			t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, "org1", testApi.ArgsIn[LintPolicyMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[LintPolicyMethod][2] != "p1" {
+			t.Errorf("Test case %v. Received different policy name (wanted:%v / received:%v)", n, "p1", testApi.ArgsIn[LintPolicyMethod][2])
+			continue
+		}
+		if diff := pretty.Compare(testApi.ArgsIn[LintPolicyMethod][3], test.expectedKnownActions); diff != "" {
+			t.Errorf("Test %v failed. Received different known actions (received/wanted) %v", n, diff)
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			lintResponse := &api.PolicyLintReport{}
+			err = json.NewDecoder(res.Body).Decode(lintResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(lintResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleLintPolicies(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		queryParams url.Values
+		// Expected result
+		expectedStatusCode   int
+		expectedKnownActions []string
+		expectedResponse     *LintPoliciesResponse
+		expectedError        api.Error
+		// Manager Results
+		lintPoliciesResult []api.PolicyLintReport
+		// Manager Errors
+		lintPoliciesErr error
+	}{
+		"OkCase": {
+			queryParams: url.Values{
+				"Action": {"product:get"},
+			},
+			expectedStatusCode:   http.StatusOK,
+			expectedKnownActions: []string{"product:get"},
+			lintPoliciesResult: []api.PolicyLintReport{
+				{
+					Org:      "org1",
+					Name:     "p1",
+					Findings: []api.PolicyLintFinding{},
+				},
+				{
+					Org:  "org1",
+					Name: "p2",
+					Findings: []api.PolicyLintFinding{
+						{
+							Type:      api.LINT_BROAD_WILDCARD,
+							Statement: 1,
+							Value:     "*",
+							Message:   "Action * allows actions of several services",
+						},
+					},
+				},
+			},
+			expectedResponse: &LintPoliciesResponse{
+				Reports: []api.PolicyLintReport{
+					{
+						Org:      "org1",
+						Name:     "p1",
+						Findings: []api.PolicyLintFinding{},
+					},
+					{
+						Org:  "org1",
+						Name: "p2",
+						Findings: []api.PolicyLintFinding{
+							{
+								Type:      api.LINT_BROAD_WILDCARD,
+								Statement: 1,
+								Value:     "*",
+								Message:   "Action * allows actions of several services",
+							},
+						},
+					},
+				},
+			},
+		},
+		"ErrorCaseInvalidOrg": {
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org org1",
+			},
+			lintPoliciesErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: org org1",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			expectedStatusCode: http.StatusInternalServerError,
+			lintPoliciesErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[LintPoliciesMethod][0] = test.lintPoliciesResult
+		testApi.ArgsOut[LintPoliciesMethod][1] = test.lintPoliciesErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+API_VERSION_1+"/organizations/org1/lint", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[LintPoliciesMethod][1] != "org1" {
+			t.Errorf("Test case %v. Received different org (wanted:%v / received:%v)", n, "org1", testApi.ArgsIn[LintPoliciesMethod][1])
+			continue
+		}
+		if diff := pretty.Compare(testApi.ArgsIn[LintPoliciesMethod][2], test.expectedKnownActions); diff != "" {
+			t.Errorf("Test %v failed. Received different known actions (received/wanted) %v", n, diff)
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			lintResponse := &LintPoliciesResponse{}
+			err = json.NewDecoder(res.Body).Decode(lintResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(lintResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -1406,3 +1406,168 @@ func TestWorkerHandler_HandleListAttachedGroups(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkerHandler_HandlePolicyDryRun(t *testing.T) {
+	testStatements := []api.Statement{
+		{
+			Effect:    "allow",
+			Actions:   []string{"product:get"},
+			Resources: []string{"urn:ews:product:instance:resource/r1"},
+		},
+	}
+	testImpact := &api.PermissionImpact{
+		Checks: []api.PermissionCheck{
+			{
+				Action:   "product:get",
+				Resource: "urn:ews:product:instance:resource/r1",
+			},
+		},
+		Users:   []string{},
+		Changes: []api.PermissionChange{},
+	}
+	testcases := map[string]struct {
+		// API method args
+		method   string
+		url      string
+		query    string
+		apiIndex string
+		// Expected result
+		expectedStatusCode int
+		expectedChecks     []api.PermissionCheck
+		expectedResponse   *api.PermissionImpact
+		expectedError      api.Error
+		// Manager Errors
+		dryRunErr error
+	}{
+		"OkCaseAdd": {
+			method:             http.MethodPost,
+			url:                "/organizations/org1/policies",
+			query:              "DryRun=true",
+			apiIndex:           DryRunAddPolicyMethod,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse:   testImpact,
+		},
+		"OkCaseUpdateReceivedChecks": {
+			method:             http.MethodPut,
+			url:                "/organizations/org1/policies/p1",
+			query:              "DryRun=true&Action=product:get&Action=product:list&Resource=urn:ews:product:instance:resource/r1",
+			apiIndex:           DryRunUpdatePolicyMethod,
+			expectedStatusCode: http.StatusOK,
+			expectedChecks: []api.PermissionCheck{
+				{
+					Action:   "product:get",
+					Resource: "urn:ews:product:instance:resource/r1",
+				},
+				{
+					Action:   "product:list",
+					Resource: "urn:ews:product:instance:resource/r1",
+				},
+			},
+			expectedResponse: testImpact,
+		},
+		"ErrorCaseAddInvalidDryRun": {
+			method:             http.MethodPost,
+			url:                "/organizations/org1/policies",
+			query:              "DryRun=maybe",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: DryRun maybe",
+			},
+		},
+		"ErrorCaseUpdatePolicyNotFound": {
+			method:             http.MethodPut,
+			url:                "/organizations/org1/policies/p1",
+			query:              "DryRun=true",
+			apiIndex:           DryRunUpdatePolicyMethod,
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Error",
+			},
+			dryRunErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsIn[DryRunAddPolicyMethod][5] = nil
+		testApi.ArgsIn[DryRunUpdatePolicyMethod][6] = nil
+		if test.apiIndex != "" {
+			testApi.ArgsOut[test.apiIndex][0] = test.expectedResponse
+			testApi.ArgsOut[test.apiIndex][1] = test.dryRunErr
+		}
+
+		jsonObject, err := json.Marshal(CreatePolicyRequest{
+			Name:       "p1",
+			Path:       "/path/",
+			Statements: testStatements,
+		})
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected marshalling api request %v", n, err)
+			continue
+		}
+		req, err := http.NewRequest(test.method, server.URL+API_VERSION_1+test.url, bytes.NewBuffer(jsonObject))
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.query
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		switch test.apiIndex {
+		case DryRunAddPolicyMethod:
+			if diff := pretty.Compare(testApi.ArgsIn[DryRunAddPolicyMethod][5], test.expectedChecks); diff != "" {
+				t.Errorf("Test %v failed. Received different checks (received/wanted) %v", n, diff)
+				continue
+			}
+		case DryRunUpdatePolicyMethod:
+			if diff := pretty.Compare(testApi.ArgsIn[DryRunUpdatePolicyMethod][6], test.expectedChecks); diff != "" {
+				t.Errorf("Test %v failed. Received different checks (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			impactResponse := &api.PermissionImpact{}
+			err = json.NewDecoder(res.Body).Decode(impactResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(impactResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,167 @@
+package http
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/julienschmidt/httprouter"
+)
+
+// RESPONSES
+
+type ListPolicyVersionsResponse struct {
+	Versions []api.PolicyVersion `json:"versions, omitempty"`
+	Limit    int                 `json:"limit, omitempty"`
+	Offset   int                 `json:"offset, omitempty"`
+	Total    int                 `json:"total, omitempty"`
+}
+
+// HANDLERS
+
+func (h *WorkerHandler) HandleListPolicyVersions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org and policy name from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Retrieve filterData
+	filterData, err := getFilterData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policy API to retrieve policy versions
+	result, total, err := h.worker.PolicyApi.ListPolicyVersions(requestInfo, org, policyName, filterData)
+	if err != nil {
+		h.respondPolicyVersionError(r, requestInfo, w, err)
+		return
+	}
+
+	// Create response
+	response := &ListPolicyVersionsResponse{
+		Versions: result,
+		Offset:   filterData.Offset,
+		Limit:    filterData.Limit,
+		Total:    total,
+	}
+
+	// Return policy versions
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleGetPolicyVersion(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org, policy name and version from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+	version, err := getVersionParam("version", ps.ByName(POLICY_VERSION))
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policy API to retrieve policy version
+	response, err := h.worker.PolicyApi.GetPolicyVersion(requestInfo, org, policyName, version)
+	if err != nil {
+		h.respondPolicyVersionError(r, requestInfo, w, err)
+		return
+	}
+
+	// Return policy version
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleDiffPolicyVersions(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org and policy name from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Retrieve versions to compare from query params
+	fromVersion, err := getVersionParam("From", r.URL.Query().Get("From"))
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+	toVersion, err := getVersionParam("To", r.URL.Query().Get("To"))
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policy API to compare policy versions
+	response, err := h.worker.PolicyApi.DiffPolicyVersions(requestInfo, org, policyName, fromVersion, toVersion)
+	if err != nil {
+		h.respondPolicyVersionError(r, requestInfo, w, err)
+		return
+	}
+
+	// Return differences
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+func (h *WorkerHandler) HandleRollbackPolicy(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve org, policy name and version from request path
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+	version, err := getVersionParam("version", ps.ByName(POLICY_VERSION))
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call policy API to restore policy version
+	response, err := h.worker.PolicyApi.RollbackPolicy(requestInfo, org, policyName, version)
+	if err != nil {
+		h.respondPolicyVersionError(r, requestInfo, w, err)
+		return
+	}
+
+	// Write policy to response
+	h.RespondOk(r, requestInfo, w, response)
+}
+
+// PRIVATE HELPER METHODS
+
+func (h *WorkerHandler) respondPolicyVersionError(r *http.Request, requestInfo api.RequestInfo, w http.ResponseWriter, err error) {
+	// Transform to API errors
+	apiError := err.(*api.Error)
+	api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+	switch apiError.Code {
+	case api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.POLICY_VERSION_NOT_FOUND:
+		h.RespondNotFound(r, requestInfo, w, apiError)
+	case api.POLICY_ALREADY_EXIST:
+		h.RespondConflict(r, requestInfo, w, apiError)
+	case api.INVALID_PARAMETER_ERROR:
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+	case api.UNAUTHORIZED_RESOURCES_ERROR:
+		h.RespondForbidden(r, requestInfo, w, apiError)
+	default: // Unexpected API error
+		h.RespondInternalServerError(r, requestInfo, w)
+	}
+}
+
+func getVersionParam(param string, value string) (int, error) {
+	version, err := strconv.Atoi(value)
+	if err != nil || version < 1 {
+		return 0, &api.Error{
+			Code:    api.INVALID_PARAMETER_ERROR,
+			Message: fmt.Sprintf("Invalid parameter: %v %v", param, value),
+		}
+	}
+	return version, nil
+}
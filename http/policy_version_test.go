@@ -0,0 +1,598 @@
+package http
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/Tecsisa/foulkon/api"
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestWorkerHandler_HandleListPolicyVersions(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testVersions := []api.PolicyVersion{
+		{
+			PolicyID:   "POLICY-ID",
+			Version:    1,
+			Name:       "p1",
+			Path:       "/path/",
+			Statements: &[]api.Statement{},
+			Author:     "123456",
+			CreateAt:   now,
+		},
+	}
+	testcases := map[string]struct {
+		// API method args
+		org          string
+		policyName   string
+		queryParams  url.Values
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedFilter     *api.Filter
+		expectedResponse   ListPolicyVersionsResponse
+		expectedError      api.Error
+		// Manager Results
+		listPolicyVersionsResult []api.PolicyVersion
+		totalVersionsResult      int
+		// Manager Errors
+		listPolicyVersionsErr error
+	}{
+		"OkCase": {
+			org:        "org1",
+			policyName: "p1",
+			queryParams: url.Values{
+				"Offset": {"0"},
+				"Limit":  {"10"},
+			},
+			expectedStatusCode: http.StatusOK,
+			expectedFilter: &api.Filter{
+				Limit: 10,
+			},
+			expectedResponse: ListPolicyVersionsResponse{
+				Versions: testVersions,
+				Limit:    10,
+				Total:    1,
+			},
+			listPolicyVersionsResult: testVersions,
+			totalVersionsResult:      1,
+		},
+		"ErrorCaseInvalidFilterParams": {
+			org:        "org1",
+			policyName: "p1",
+			queryParams: url.Values{
+				"Offset": {"-1"},
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Offset -1",
+			},
+		},
+		"ErrorCasePolicyNotFound": {
+			org:                "org1",
+			policyName:         "p1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedFilter:     &api.Filter{},
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+			listPolicyVersionsErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			policyName:         "p1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedFilter:     &api.Filter{},
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			listPolicyVersionsErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			policyName:         "p1",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedFilter:     &api.Filter{},
+			listPolicyVersionsErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[ListPolicyVersionsMethod][0] = test.listPolicyVersionsResult
+		testApi.ArgsOut[ListPolicyVersionsMethod][1] = test.totalVersionsResult
+		testApi.ArgsOut[ListPolicyVersionsMethod][2] = test.listPolicyVersionsErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/%v/policies/%v/versions", test.org, test.policyName)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		if !test.ignoreArgsIn {
+			// Check received parameters
+			if testApi.ArgsIn[ListPolicyVersionsMethod][1] != test.org {
+				t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[ListPolicyVersionsMethod][1])
+				continue
+			}
+			if testApi.ArgsIn[ListPolicyVersionsMethod][2] != test.policyName {
+				t.Errorf("Test case %v. Received different Name (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[ListPolicyVersionsMethod][2])
+				continue
+			}
+			if diff := pretty.Compare(testApi.ArgsIn[ListPolicyVersionsMethod][3], test.expectedFilter); diff != "" {
+				t.Errorf("Test %v failed. Received different filters (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			listPolicyVersionsResponse := ListPolicyVersionsResponse{}
+			err = json.NewDecoder(res.Body).Decode(&listPolicyVersionsResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(listPolicyVersionsResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleGetPolicyVersion(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		version      string
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedVersion    int
+		expectedResponse   *api.PolicyVersion
+		expectedError      api.Error
+		// Manager Errors
+		getPolicyVersionErr error
+	}{
+		"OkCase": {
+			version:            "2",
+			expectedStatusCode: http.StatusOK,
+			expectedVersion:    2,
+			expectedResponse: &api.PolicyVersion{
+				PolicyID:   "POLICY-ID",
+				Version:    2,
+				Name:       "p1",
+				Path:       "/path/",
+				Statements: &[]api.Statement{},
+				Author:     "123456",
+				CreateAt:   now,
+			},
+		},
+		"ErrorCaseInvalidVersion": {
+			version:            "last",
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: version last",
+			},
+		},
+		"ErrorCaseVersionNotFound": {
+			version:            "3",
+			expectedStatusCode: http.StatusNotFound,
+			expectedVersion:    3,
+			expectedError: api.Error{
+				Code:    api.POLICY_VERSION_NOT_FOUND,
+				Message: "Not found",
+			},
+			getPolicyVersionErr: &api.Error{
+				Code:    api.POLICY_VERSION_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			version:            "1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedVersion:    1,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getPolicyVersionErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			version:            "1",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedVersion:    1,
+			getPolicyVersionErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[GetPolicyVersionMethod][0] = test.expectedResponse
+		testApi.ArgsOut[GetPolicyVersionMethod][1] = test.getPolicyVersionErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/org1/policies/p1/versions/%v", test.version)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		if !test.ignoreArgsIn && testApi.ArgsIn[GetPolicyVersionMethod][3] != test.expectedVersion {
+			t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.expectedVersion, testApi.ArgsIn[GetPolicyVersionMethod][3])
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			policyVersion := &api.PolicyVersion{}
+			err = json.NewDecoder(res.Body).Decode(policyVersion)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(policyVersion, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleDiffPolicyVersions(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		queryParams  url.Values
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode  int
+		expectedFromVersion int
+		expectedToVersion   int
+		expectedResponse    *api.PolicyVersionDiff
+		expectedError       api.Error
+		// Manager Errors
+		diffPolicyVersionsErr error
+	}{
+		"OkCase": {
+			queryParams: url.Values{
+				"From": {"1"},
+				"To":   {"3"},
+			},
+			expectedStatusCode:  http.StatusOK,
+			expectedFromVersion: 1,
+			expectedToVersion:   3,
+			expectedResponse: &api.PolicyVersionDiff{
+				FromVersion: 1,
+				ToVersion:   3,
+				FromName:    "p1",
+				ToName:      "p1",
+				FromPath:    "/path/",
+				ToPath:      "/path2/",
+				AddedStatements: []api.Statement{
+					{
+						Effect:    "allow",
+						Actions:   []string{api.USER_ACTION_GET_USER},
+						Resources: []string{api.GetUrnPrefix("", api.RESOURCE_USER, "/path/")},
+					},
+				},
+				RemovedStatements: []api.Statement{},
+			},
+		},
+		"ErrorCaseInvalidFrom": {
+			queryParams: url.Values{
+				"To": {"3"},
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: From ",
+			},
+		},
+		"ErrorCaseInvalidTo": {
+			queryParams: url.Values{
+				"From": {"1"},
+				"To":   {"0"},
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: To 0",
+			},
+		},
+		"ErrorCaseVersionNotFound": {
+			queryParams: url.Values{
+				"From": {"1"},
+				"To":   {"5"},
+			},
+			expectedStatusCode:  http.StatusNotFound,
+			expectedFromVersion: 1,
+			expectedToVersion:   5,
+			expectedError: api.Error{
+				Code:    api.POLICY_VERSION_NOT_FOUND,
+				Message: "Not found",
+			},
+			diffPolicyVersionsErr: &api.Error{
+				Code:    api.POLICY_VERSION_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[DiffPolicyVersionsMethod][0] = test.expectedResponse
+		testApi.ArgsOut[DiffPolicyVersionsMethod][1] = test.diffPolicyVersionsErr
+
+		req, err := http.NewRequest(http.MethodGet, server.URL+API_VERSION_1+"/organizations/org1/policies/p1/diff", nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+		req.URL.RawQuery = test.queryParams.Encode()
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		if !test.ignoreArgsIn {
+			// Check received parameters
+			if testApi.ArgsIn[DiffPolicyVersionsMethod][3] != test.expectedFromVersion {
+				t.Errorf("Test case %v. Received different from version (wanted:%v / received:%v)", n, test.expectedFromVersion, testApi.ArgsIn[DiffPolicyVersionsMethod][3])
+				continue
+			}
+			if testApi.ArgsIn[DiffPolicyVersionsMethod][4] != test.expectedToVersion {
+				t.Errorf("Test case %v. Received different to version (wanted:%v / received:%v)", n, test.expectedToVersion, testApi.ArgsIn[DiffPolicyVersionsMethod][4])
+				continue
+			}
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			diffResponse := &api.PolicyVersionDiff{}
+			err = json.NewDecoder(res.Body).Decode(diffResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(diffResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleRollbackPolicy(t *testing.T) {
+	now := time.Date(2016, 10, 1, 12, 0, 0, 0, time.UTC)
+	testcases := map[string]struct {
+		// API method args
+		version      string
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedVersion    int
+		expectedResponse   *api.Policy
+		expectedError      api.Error
+		// Manager Errors
+		rollbackPolicyErr error
+	}{
+		"OkCase": {
+			version:            "1",
+			expectedStatusCode: http.StatusOK,
+			expectedVersion:    1,
+			expectedResponse: &api.Policy{
+				ID:         "POLICY-ID",
+				Name:       "p1",
+				Path:       "/path/",
+				Org:        "org1",
+				Urn:        api.CreateUrn("org1", api.RESOURCE_POLICY, "/path/", "p1"),
+				CreateAt:   now,
+				Statements: &[]api.Statement{},
+			},
+		},
+		"ErrorCaseInvalidVersion": {
+			version:            "-1",
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: version -1",
+			},
+		},
+		"ErrorCasePolicyAlreadyExist": {
+			version:            "1",
+			expectedStatusCode: http.StatusConflict,
+			expectedVersion:    1,
+			expectedError: api.Error{
+				Code:    api.POLICY_ALREADY_EXIST,
+				Message: "Policy name: p2 already exists",
+			},
+			rollbackPolicyErr: &api.Error{
+				Code:    api.POLICY_ALREADY_EXIST,
+				Message: "Policy name: p2 already exists",
+			},
+		},
+		"ErrorCaseVersionNotFound": {
+			version:            "4",
+			expectedStatusCode: http.StatusNotFound,
+			expectedVersion:    4,
+			expectedError: api.Error{
+				Code:    api.POLICY_VERSION_NOT_FOUND,
+				Message: "Not found",
+			},
+			rollbackPolicyErr: &api.Error{
+				Code:    api.POLICY_VERSION_NOT_FOUND,
+				Message: "Not found",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			version:            "1",
+			expectedStatusCode: http.StatusInternalServerError,
+			expectedVersion:    1,
+			rollbackPolicyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+		testApi.ArgsOut[RollbackPolicyMethod][0] = test.expectedResponse
+		testApi.ArgsOut[RollbackPolicyMethod][1] = test.rollbackPolicyErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/organizations/org1/policies/p1/versions/%v/rollback", test.version)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+		if !test.ignoreArgsIn && testApi.ArgsIn[RollbackPolicyMethod][3] != test.expectedVersion {
+			t.Errorf("Test case %v. Received different version (wanted:%v / received:%v)", n, test.expectedVersion, testApi.ArgsIn[RollbackPolicyMethod][3])
+			continue
+		}
+
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			policy := &api.Policy{}
+			err = json.NewDecoder(res.Body).Decode(policy)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(policy, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
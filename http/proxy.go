@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"regexp"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/Tecsisa/foulkon/api"
 	"github.com/Tecsisa/foulkon/foulkon"
@@ -25,6 +28,39 @@ const (
 
 var rUrnParam, _ = regexp.Compile(`\{(\w+)\}`)
 
+// Muxer for proxy resources, replaced when resources are reloaded
+type proxyRouter struct {
+	lock         sync.RWMutex
+	handler      http.Handler
+	proxyHandler ProxyHandler
+}
+
+func (pr *proxyRouter) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	pr.lock.RLock()
+	handler := pr.handler
+	pr.lock.RUnlock()
+	handler.ServeHTTP(w, r)
+}
+
+// Create a muxer with these resources, keeping the previous one if any resource can't be added
+func (pr *proxyRouter) setResources(resources []foulkon.APIResource) (err error) {
+	router := httprouter.New()
+	defer func() {
+		// Muxer panics with invalid or duplicated routes
+		if r := recover(); r != nil {
+			err = fmt.Errorf("Invalid proxy resources: %v", r)
+		}
+	}()
+	for _, res := range resources {
+		router.Handle(res.Method, res.Url, pr.proxyHandler.HandleRequest(res))
+	}
+
+	pr.lock.Lock()
+	defer pr.lock.Unlock()
+	pr.handler = instrumentHandler(router, router)
+	return nil
+}
+
 func (h *ProxyHandler) HandleRequest(resource foulkon.APIResource) httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
 		requestID := uuid.NewV4().String()
@@ -47,7 +83,9 @@ func (h *ProxyHandler) HandleRequest(resource foulkon.APIResource) httprouter.Ha
 			// Clean request URI because net/http send method force this
 			r.RequestURI = ""
 			// Retrieve requested resource
+			start := time.Now()
 			res, err := h.client.Do(r)
+			upstreamDuration.ObserveSince(start, resource.Id)
 			if err != nil {
 				h.TransactionErrorLog(r, requestID, workerRequestID, fmt.Sprintf("Error calling to destination host resource: %v", err.Error()))
 				h.RespondInternalServerError(w, getErrorMessage(HOST_UNREACHABLE, "Error calling destination resource"))
@@ -115,8 +153,20 @@ func (h *ProxyHandler) checkAuthorization(r *http.Request, urn string, action st
 	if err != nil {
 		return workerRequestID, getErrorMessage(api.UNKNOWN_API_ERROR, err.Error())
 	}
-	// Add all headers from original request
-	req.Header = r.Header
+	// Add headers from original request, with client address to evaluate source IP conditions. Request
+	// context headers are removed because clients could use them to satisfy statement conditions
+	for key, values := range r.Header {
+		if strings.HasPrefix(key, REQUEST_CONTEXT_HEADER_PREFIX) {
+			continue
+		}
+		req.Header[key] = append([]string{}, values...)
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		if forwardedFor := strings.Join(req.Header[FORWARDED_FOR_HEADER], ", "); forwardedFor != "" {
+			host = forwardedFor + ", " + host
+		}
+		req.Header.Set(FORWARDED_FOR_HEADER, host)
+	}
 	// Call worker to retrieve authorization
 	res, err := h.client.Do(req)
 	if err != nil {
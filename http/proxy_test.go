@@ -1,13 +1,19 @@
 package http
 
 import (
+	"bytes"
 	"encoding/json"
 	"net/http"
+	"net/http/httptest"
+	"os"
 	"testing"
 	"time"
 
+	log "github.com/Sirupsen/logrus"
 	"github.com/Tecsisa/foulkon/api"
+	"github.com/Tecsisa/foulkon/foulkon"
 	"github.com/kylelemons/godebug/pretty"
+	"github.com/pelletier/go-toml"
 )
 
 func TestProxyHandler_HandleRequest(t *testing.T) {
@@ -191,3 +197,146 @@ func TestProxyHandler_HandleRequest(t *testing.T) {
 		}
 	}
 }
+
+func TestProxyHandlerRouter_Reload(t *testing.T) {
+	proxyCore := &foulkon.Proxy{
+		Logger:     foulkon.NewLogger(bytes.NewBuffer([]byte{}), log.DebugLevel),
+		WorkerHost: server.URL,
+		APIResources: []foulkon.APIResource{
+			{
+				Id:     "previous",
+				Host:   server.URL,
+				Url:    "/previous",
+				Method: "GET",
+				Urn:    "%&",
+				Action: "example:previous",
+			},
+		},
+	}
+	router := ProxyHandlerRouter(proxyCore)
+
+	testcases := map[string]struct {
+		config              string
+		expectedErr         bool
+		expectedStatusCodes map[string]int
+	}{
+		"OkCaseReloaded": {
+			config: `
+				[[resources]]
+					id = "new"
+					host = "` + server.URL + `"
+					url = "/new"
+					method = "GET"
+					urn = "%&"
+					action = "example:new"
+			`,
+			expectedStatusCodes: map[string]int{
+				"/previous": http.StatusNotFound,
+				"/new":      http.StatusBadRequest,
+			},
+		},
+		"ErrorCaseDuplicatedResources": {
+			config: `
+				[[resources]]
+					id = "dup1"
+					host = "` + server.URL + `"
+					url = "/dup"
+					method = "GET"
+					urn = "%&"
+					action = "example:dup"
+				[[resources]]
+					id = "dup2"
+					host = "` + server.URL + `"
+					url = "/dup"
+					method = "GET"
+					urn = "%&"
+					action = "example:dup"
+			`,
+			expectedErr: true,
+			expectedStatusCodes: map[string]int{
+				"/new": http.StatusBadRequest,
+				"/dup": http.StatusNotFound,
+			},
+		},
+	}
+
+	// Test cases depend on previous ones
+	for _, n := range []string{"OkCaseReloaded", "ErrorCaseDuplicatedResources"} {
+		test := testcases[n]
+		config, err := toml.Load(test.config)
+		if err != nil {
+			t.Fatalf("Test case %v. Unexpected error parsing config %v", n, err)
+		}
+		err = proxyCore.Reload(config)
+		if test.expectedErr != (err != nil) {
+			t.Errorf("Test case %v. Unexpected reload error %v", n, err)
+			continue
+		}
+		for url, expectedStatusCode := range test.expectedStatusCodes {
+			w := httptest.NewRecorder()
+			r, _ := http.NewRequest(http.MethodGet, url, nil)
+			router.ServeHTTP(w, r)
+			if w.Code != expectedStatusCode {
+				t.Errorf("Test case %v, %v. Received different http status code (wanted:%v / received:%v)",
+					n, url, expectedStatusCode, w.Code)
+			}
+		}
+	}
+}
+
+func TestProxyHandlerRouter_ReloadWhileServing(t *testing.T) {
+	workerLogFile := createLogFile(t)
+	defer os.Remove(workerLogFile)
+	worker := createWorker(t, workerLogFile)
+	defer foulkon.CloseWorker()
+	workerServer := httptest.NewServer(WorkerHandlerRouter(worker))
+	defer workerServer.Close()
+	destServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer destServer.Close()
+
+	logFile := createLogFile(t)
+	defer os.Remove(logFile)
+	proxyConfig := func(level string) string {
+		return `
+			[server]
+			host = "localhost"
+			port = "8001"
+			worker-host = "` + workerServer.URL + `"
+			[logger]
+			type = "file"
+			level = "` + level + `"
+			[logger.file]
+			dir = "` + logFile + `"
+			[[resources]]
+				id = "resource"
+				host = "` + destServer.URL + `"
+				url = "/resource"
+				method = "GET"
+				urn = "urn:ews:example:instance1:resource/resource"
+				action = "example:get"
+		`
+	}
+	config, err := toml.Load(proxyConfig("debug"))
+	if err != nil {
+		t.Fatalf("Unexpected error parsing config %v", err)
+	}
+	proxyCore, err := foulkon.NewProxy(config)
+	if err != nil {
+		t.Fatalf("Unexpected error creating proxy %v", err)
+	}
+	defer foulkon.CloseProxy()
+	proxyServer := httptest.NewServer(ProxyHandlerRouter(proxyCore))
+	defer proxyServer.Close()
+
+	reloadWhileServing(t, logFile, func(level string) error {
+		config, err := toml.Load(proxyConfig(level))
+		if err != nil {
+			return err
+		}
+		return proxyCore.Reload(config)
+	}, func() (*http.Response, error) {
+		req, _ := http.NewRequest(http.MethodGet, proxyServer.URL+"/resource", nil)
+		req.SetBasicAuth("admin", "admin")
+		return http.DefaultClient.Do(req)
+	})
+}
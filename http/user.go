@@ -35,6 +35,13 @@ type GetGroupsByUserIdResponse struct {
 	Total  int                 `json:"total, omitempty"`
 }
 
+type ListAttachedUserPoliciesResponse struct {
+	AttachedPolicies []api.PolicyIdentity `json:"policies, omitempty"`
+	Limit            int                  `json:"limit, omitempty"`
+	Offset           int                  `json:"offset, omitempty"`
+	Total            int                  `json:"total, omitempty"`
+}
+
 // HANDLERS
 
 func (h *WorkerHandler) HandleAddUser(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
@@ -259,3 +266,112 @@ func (h *WorkerHandler) HandleListGroupsByUser(w http.ResponseWriter, r *http.Re
 	// Write user to response
 	h.RespondOk(r, requestInfo, w, response)
 }
+
+func (h *WorkerHandler) HandleAttachPolicyToUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user, org and policy from path
+	id := ps.ByName(USER_ID)
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Call user API to attach policy to user
+	err := h.worker.UserApi.AttachPolicyToUser(requestInfo, id, org, policyName)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.POLICY_BY_ORG_AND_NAME_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		case api.POLICY_IS_ALREADY_ATTACHED_TO_USER:
+			h.RespondConflict(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+func (h *WorkerHandler) HandleDetachPolicyFromUser(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user, org and policy from path
+	id := ps.ByName(USER_ID)
+	org := ps.ByName(ORG_NAME)
+	policyName := ps.ByName(POLICY_NAME)
+
+	// Call user API to detach policy from user
+	err := h.worker.UserApi.DetachPolicyFromUser(requestInfo, id, org, policyName)
+
+	// Error handling
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND, api.POLICY_BY_ORG_AND_NAME_NOT_FOUND, api.POLICY_IS_NOT_ATTACHED_TO_USER:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	h.RespondNoContent(r, requestInfo, w)
+}
+
+func (h *WorkerHandler) HandleListAttachedUserPolicies(w http.ResponseWriter, r *http.Request, ps httprouter.Params) {
+	requestInfo := h.GetRequestInfo(r)
+	// Retrieve user from path
+	id := ps.ByName(USER_ID)
+
+	// Retrieve filterData
+	filterData, err := getFilterData(r)
+	if err != nil {
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		h.RespondBadRequest(r, requestInfo, w, apiError)
+		return
+	}
+
+	// Call user API to retrieve attached policies
+	result, total, err := h.worker.UserApi.ListAttachedUserPolicies(requestInfo, id, filterData)
+	if err != nil {
+		// Transform to API errors
+		apiError := err.(*api.Error)
+		api.LogErrorMessage(h.worker.Logger, requestInfo, apiError)
+		switch apiError.Code {
+		case api.USER_BY_EXTERNAL_ID_NOT_FOUND:
+			h.RespondNotFound(r, requestInfo, w, apiError)
+		case api.UNAUTHORIZED_RESOURCES_ERROR:
+			h.RespondForbidden(r, requestInfo, w, apiError)
+		case api.INVALID_PARAMETER_ERROR:
+			h.RespondBadRequest(r, requestInfo, w, apiError)
+		default: // Unexpected API error
+			h.RespondInternalServerError(r, requestInfo, w)
+		}
+		return
+	}
+
+	// Create response
+	response := &ListAttachedUserPoliciesResponse{
+		AttachedPolicies: result,
+		Offset:           filterData.Offset,
+		Limit:            filterData.Limit,
+		Total:            total,
+	}
+
+	// Return user policies
+	h.RespondOk(r, requestInfo, w, response)
+}
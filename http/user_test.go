@@ -976,3 +976,509 @@ func TestWorkerHandler_HandleListGroupsByUser(t *testing.T) {
 		}
 	}
 }
+
+func TestWorkerHandler_HandleAttachPolicyToUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		id         string
+		policyName string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		attachUserPolicyErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseUserNotFoundErr": {
+			org:                "org1",
+			id:                 "Invalid User",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			attachUserPolicyErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCasePolicyNotFoundErr": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "Invalid Policy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			attachUserPolicyErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			attachUserPolicyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			attachUserPolicyErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCasePolicyIsAlreadyAttachedErr": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusConflict,
+			expectedError: api.Error{
+				Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_USER,
+				Message: "Policy is already attached to user",
+			},
+			attachUserPolicyErr: &api.Error{
+				Code:    api.POLICY_IS_ALREADY_ATTACHED_TO_USER,
+				Message: "Policy is already attached to user",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusInternalServerError,
+			attachUserPolicyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[AttachPolicyToUserMethod][0] = test.attachUserPolicyErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/users/%v/organizations/%v/policies/%v", test.id, test.org, test.policyName)
+		req, err := http.NewRequest(http.MethodPost, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[AttachPolicyToUserMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different ID (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[AttachPolicyToUserMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[AttachPolicyToUserMethod][2] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[AttachPolicyToUserMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[AttachPolicyToUserMethod][3] != test.policyName {
+			t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[AttachPolicyToUserMethod][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleDetachPolicyFromUser(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		org        string
+		id         string
+		policyName string
+		// Expected result
+		expectedStatusCode int
+		expectedError      api.Error
+		// Manager Errors
+		detachUserPolicyErr error
+	}{
+		"OkCase": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNoContent,
+		},
+		"ErrorCaseUserNotFoundErr": {
+			org:                "org1",
+			id:                 "Invalid User",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			detachUserPolicyErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCasePolicyNotFoundErr": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "Invalid Policy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			detachUserPolicyErr: &api.Error{
+				Code:    api.POLICY_BY_ORG_AND_NAME_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCasePolicyIsNotAttachedErr": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "Invalid Policy",
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.POLICY_IS_NOT_ATTACHED_TO_USER,
+				Message: "Policy is not attached to user",
+			},
+			detachUserPolicyErr: &api.Error{
+				Code:    api.POLICY_IS_NOT_ATTACHED_TO_USER,
+				Message: "Policy is not attached to user",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			detachUserPolicyErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			detachUserPolicyErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			org:                "org1",
+			id:                 "user1",
+			policyName:         "policy1",
+			expectedStatusCode: http.StatusInternalServerError,
+			detachUserPolicyErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[DetachPolicyFromUserMethod][0] = test.detachUserPolicyErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/users/%v/organizations/%v/policies/%v", test.id, test.org, test.policyName)
+		req, err := http.NewRequest(http.MethodDelete, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		// Check received parameters
+		if testApi.ArgsIn[DetachPolicyFromUserMethod][1] != test.id {
+			t.Errorf("Test case %v. Received different ID (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[DetachPolicyFromUserMethod][1])
+			continue
+		}
+		if testApi.ArgsIn[DetachPolicyFromUserMethod][2] != test.org {
+			t.Errorf("Test case %v. Received different Org (wanted:%v / received:%v)", n, test.org, testApi.ArgsIn[DetachPolicyFromUserMethod][2])
+			continue
+		}
+		if testApi.ArgsIn[DetachPolicyFromUserMethod][3] != test.policyName {
+			t.Errorf("Test case %v. Received different policyName (wanted:%v / received:%v)", n, test.policyName, testApi.ArgsIn[DetachPolicyFromUserMethod][3])
+			continue
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusNoContent:
+			// No message expected
+			continue
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
+
+func TestWorkerHandler_HandleListAttachedUserPolicies(t *testing.T) {
+	testcases := map[string]struct {
+		// API method args
+		id           string
+		filter       *api.Filter
+		ignoreArgsIn bool
+		// Expected result
+		expectedStatusCode int
+		expectedResponse   ListAttachedUserPoliciesResponse
+		expectedError      api.Error
+		// Manager Results
+		getListAttachedUserPoliciesResult []api.PolicyIdentity
+		totalPoliciesResult               int
+		// Manager Errors
+		getListAttachedUserPoliciesErr error
+	}{
+		"OkCase": {
+			id:                 "user1",
+			filter:             testFilter,
+			expectedStatusCode: http.StatusOK,
+			expectedResponse: ListAttachedUserPoliciesResponse{
+				AttachedPolicies: []api.PolicyIdentity{
+					{
+						Org:  "org1",
+						Name: "policy1",
+					},
+					{
+						Org:  "org2",
+						Name: "policy2",
+					},
+				},
+				Total: 2,
+			},
+			getListAttachedUserPoliciesResult: []api.PolicyIdentity{
+				{
+					Org:  "org1",
+					Name: "policy1",
+				},
+				{
+					Org:  "org2",
+					Name: "policy2",
+				},
+			},
+			totalPoliciesResult: 2,
+		},
+		"ErrorCaseInvalidFilterParams": {
+			filter: &api.Filter{
+				PathPrefix: "",
+				Offset:     -1,
+			},
+			ignoreArgsIn:       true,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid parameter: Offset -1",
+			},
+		},
+		"ErrorCaseUserNotFoundErr": {
+			id:                 "user1",
+			filter:             testFilter,
+			expectedStatusCode: http.StatusNotFound,
+			expectedError: api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+			getListAttachedUserPoliciesErr: &api.Error{
+				Code:    api.USER_BY_EXTERNAL_ID_NOT_FOUND,
+				Message: "User Not Found",
+			},
+		},
+		"ErrorCaseInvalidParameterErr": {
+			id:                 "user1",
+			filter:             testFilter,
+			expectedStatusCode: http.StatusBadRequest,
+			expectedError: api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+			getListAttachedUserPoliciesErr: &api.Error{
+				Code:    api.INVALID_PARAMETER_ERROR,
+				Message: "Invalid Parameter",
+			},
+		},
+		"ErrorCaseUnauthorizedError": {
+			id:                 "user1",
+			filter:             testFilter,
+			expectedStatusCode: http.StatusForbidden,
+			expectedError: api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+			getListAttachedUserPoliciesErr: &api.Error{
+				Code:    api.UNAUTHORIZED_RESOURCES_ERROR,
+				Message: "Unauthorized",
+			},
+		},
+		"ErrorCaseUnknownApiError": {
+			id:                 "user1",
+			filter:             testFilter,
+			expectedStatusCode: http.StatusInternalServerError,
+			getListAttachedUserPoliciesErr: &api.Error{
+				Code:    api.UNKNOWN_API_ERROR,
+				Message: "Error",
+			},
+		},
+	}
+
+	client := http.DefaultClient
+
+	for n, test := range testcases {
+
+		testApi.ArgsOut[ListAttachedUserPoliciesMethod][0] = test.getListAttachedUserPoliciesResult
+		testApi.ArgsOut[ListAttachedUserPoliciesMethod][1] = test.totalPoliciesResult
+		testApi.ArgsOut[ListAttachedUserPoliciesMethod][2] = test.getListAttachedUserPoliciesErr
+
+		url := fmt.Sprintf(server.URL+API_VERSION_1+"/users/%v/policies", test.id)
+		req, err := http.NewRequest(http.MethodGet, url, nil)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error creating http request %v", n, err)
+			continue
+		}
+
+		addQueryParams(test.filter, req)
+
+		res, err := client.Do(req)
+		if err != nil {
+			t.Errorf("Test case %v. Unexpected error calling server %v", n, err)
+			continue
+		}
+
+		if !test.ignoreArgsIn {
+			// Check received parameter
+			if testApi.ArgsIn[ListAttachedUserPoliciesMethod][1] != test.id {
+				t.Errorf("Test case %v. Received different ID (wanted:%v / received:%v)", n, test.id, testApi.ArgsIn[ListAttachedUserPoliciesMethod][1])
+				continue
+			}
+
+			filterData, ok := testApi.ArgsIn[ListAttachedUserPoliciesMethod][2].(*api.Filter)
+			if ok {
+				// Check result
+				if diff := pretty.Compare(filterData, test.filter); diff != "" {
+					t.Errorf("Test %v failed. Received different filters (received/wanted) %v", n, diff)
+					continue
+				}
+			}
+		}
+
+		// check status code
+		if test.expectedStatusCode != res.StatusCode {
+			t.Errorf("Test case %v. Received different http status code (wanted:%v / received:%v)", n, test.expectedStatusCode, res.StatusCode)
+			continue
+		}
+
+		switch res.StatusCode {
+		case http.StatusOK:
+			getUserPoliciesResponse := ListAttachedUserPoliciesResponse{}
+			err = json.NewDecoder(res.Body).Decode(&getUserPoliciesResponse)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(getUserPoliciesResponse, test.expectedResponse); diff != "" {
+				t.Errorf("Test %v failed. Received different responses (received/wanted) %v", n, diff)
+				continue
+			}
+		case http.StatusInternalServerError: // Empty message so continue
+			continue
+		default:
+			apiError := api.Error{}
+			err = json.NewDecoder(res.Body).Decode(&apiError)
+			if err != nil {
+				t.Errorf("Test case %v. Unexpected error parsing error response %v", n, err)
+				continue
+			}
+			// Check result
+			if diff := pretty.Compare(apiError, test.expectedError); diff != "" {
+				t.Errorf("Test %v failed. Received different error response (received/wanted) %v", n, diff)
+				continue
+			}
+		}
+	}
+}
@@ -0,0 +1,332 @@
+package metrics
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Default histogram buckets in seconds, for request and query latencies
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Registry used by package functions and exposed by Handler
+var DefaultRegistry = NewRegistry()
+
+// TYPE DEFINITIONS
+
+// Registry of metrics exposed in Prometheus text format
+type Registry struct {
+	sync.Mutex
+	collectors map[string]collector
+}
+
+type collector interface {
+	write(w io.Writer)
+}
+
+// CounterVec is a counter partitioned by labels
+type CounterVec struct {
+	sync.Mutex
+	name   string
+	help   string
+	labels []string
+	values map[string]*counterValue
+}
+
+type counterValue struct {
+	labelValues []string
+	value       float64
+}
+
+// HistogramVec is a histogram partitioned by labels
+type HistogramVec struct {
+	sync.Mutex
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	values  map[string]*histogramValue
+}
+
+type histogramValue struct {
+	labelValues []string
+	counts      []uint64
+	count       uint64
+	sum         float64
+}
+
+// Counter whose value is retrieved when metrics are exposed
+type counterFunc struct {
+	name  string
+	help  string
+	value func() float64
+}
+
+// NewRegistry returns an empty registry
+func NewRegistry() *Registry {
+	return &Registry{
+		collectors: make(map[string]collector),
+	}
+}
+
+// NewCounterVec creates a counter with these labels in default registry
+func NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	return DefaultRegistry.NewCounterVec(name, help, labels...)
+}
+
+// NewHistogramVec creates a histogram with these buckets and labels in default registry
+func NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	return DefaultRegistry.NewHistogramVec(name, help, buckets, labels...)
+}
+
+// RegisterCounterFunc registers a counter retrieved from value function in default registry
+func RegisterCounterFunc(name string, help string, value func() float64) {
+	DefaultRegistry.RegisterCounterFunc(name, help, value)
+}
+
+// Handler returns an http.Handler that exposes default registry metrics
+func Handler() http.Handler {
+	return DefaultRegistry
+}
+
+// REGISTRY METHODS
+
+// NewCounterVec creates a counter with these labels. It panics if name is already registered.
+func (r *Registry) NewCounterVec(name string, help string, labels ...string) *CounterVec {
+	c := &CounterVec{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*counterValue),
+	}
+	r.register(name, c)
+	return c
+}
+
+// NewHistogramVec creates a histogram with these labels. It panics if name is already registered.
+func (r *Registry) NewHistogramVec(name string, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: buckets,
+		values:  make(map[string]*histogramValue),
+	}
+	r.register(name, h)
+	return h
+}
+
+// RegisterCounterFunc registers a counter retrieved from value function, replacing any other with the same name
+func (r *Registry) RegisterCounterFunc(name string, help string, value func() float64) {
+	r.Lock()
+	defer r.Unlock()
+	r.collectors[name] = &counterFunc{
+		name:  name,
+		help:  help,
+		value: value,
+	}
+}
+
+// Write writes all metrics sorted by name
+func (r *Registry) Write(w io.Writer) error {
+	r.Lock()
+	names := make([]string, 0, len(r.collectors))
+	for name := range r.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	collectors := make([]collector, 0, len(names))
+	for _, name := range names {
+		collectors = append(collectors, r.collectors[name])
+	}
+	r.Unlock()
+
+	buffer := new(bytes.Buffer)
+	for _, c := range collectors {
+		c.write(buffer)
+	}
+	_, err := buffer.WriteTo(w)
+	return err
+}
+
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	r.Write(w)
+}
+
+func (r *Registry) register(name string, c collector) {
+	r.Lock()
+	defer r.Unlock()
+	if _, ok := r.collectors[name]; ok {
+		panic(fmt.Sprintf("metric %v already registered", name))
+	}
+	r.collectors[name] = c
+}
+
+// COUNTER METHODS
+
+// Inc increments by 1 the counter with these label values
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+// Add adds v to the counter with these label values
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	c.Lock()
+	defer c.Unlock()
+	key := strings.Join(labelValues, "\xff")
+	value, ok := c.values[key]
+	if !ok {
+		value = &counterValue{labelValues: labelValues}
+		c.values[key] = value
+	}
+	value.value += v
+}
+
+// Value returns the counter value for these label values
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.Lock()
+	defer c.Unlock()
+	if value, ok := c.values[strings.Join(labelValues, "\xff")]; ok {
+		return value.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.Lock()
+	defer c.Unlock()
+	writeHeader(w, c.name, c.help, "counter")
+	for _, key := range sortedKeys(c.values) {
+		value := c.values[key]
+		fmt.Fprintf(w, "%v%v %v\n", c.name, formatLabels(c.labels, value.labelValues, "", ""), formatValue(value.value))
+	}
+}
+
+// HISTOGRAM METHODS
+
+// Observe adds an observation to the histogram with these label values
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	h.Lock()
+	defer h.Unlock()
+	key := strings.Join(labelValues, "\xff")
+	value, ok := h.values[key]
+	if !ok {
+		value = &histogramValue{
+			labelValues: labelValues,
+			counts:      make([]uint64, len(h.buckets)),
+		}
+		h.values[key] = value
+	}
+	for i, bound := range h.buckets {
+		if v <= bound {
+			value.counts[i]++
+		}
+	}
+	value.count++
+	value.sum += v
+}
+
+// ObserveSince adds the seconds elapsed since start to the histogram with these label values
+func (h *HistogramVec) ObserveSince(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count returns the number of observations for these label values
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.Lock()
+	defer h.Unlock()
+	if value, ok := h.values[strings.Join(labelValues, "\xff")]; ok {
+		return value.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.Lock()
+	defer h.Unlock()
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range sortedKeys(h.values) {
+		value := h.values[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%v_bucket%v %v\n", h.name, formatLabels(h.labels, value.labelValues, "le", formatValue(bound)),
+				value.counts[i])
+		}
+		fmt.Fprintf(w, "%v_bucket%v %v\n", h.name, formatLabels(h.labels, value.labelValues, "le", "+Inf"), value.count)
+		fmt.Fprintf(w, "%v_sum%v %v\n", h.name, formatLabels(h.labels, value.labelValues, "", ""), formatValue(value.sum))
+		fmt.Fprintf(w, "%v_count%v %v\n", h.name, formatLabels(h.labels, value.labelValues, "", ""), value.count)
+	}
+}
+
+// COUNTER FUNC METHODS
+
+func (c *counterFunc) write(w io.Writer) {
+	writeHeader(w, c.name, c.help, "counter")
+	fmt.Fprintf(w, "%v %v\n", c.name, formatValue(c.value()))
+}
+
+// PRIVATE HELPER METHODS
+
+func writeHeader(w io.Writer, name string, help string, metricType string) {
+	help = strings.Replace(help, `\`, `\\`, -1)
+	help = strings.Replace(help, "\n", `\n`, -1)
+	fmt.Fprintf(w, "# HELP %v %v\n# TYPE %v %v\n", name, help, name, metricType)
+}
+
+// Format labels as {label1="value1",label2="value2"}, adding extra label if it isn't empty
+func formatLabels(labels []string, values []string, extraLabel string, extraValue string) string {
+	pairs := []string{}
+	for i, label := range labels {
+		value := ""
+		if i < len(values) {
+			value = values[i]
+		}
+		pairs = append(pairs, fmt.Sprintf(`%v="%v"`, label, escapeLabelValue(value)))
+	}
+	if extraLabel != "" {
+		pairs = append(pairs, fmt.Sprintf(`%v="%v"`, extraLabel, extraValue))
+	}
+	if len(pairs) == 0 {
+		return ""
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func escapeLabelValue(value string) string {
+	value = strings.Replace(value, `\`, `\\`, -1)
+	value = strings.Replace(value, `"`, `\"`, -1)
+	return strings.Replace(value, "\n", `\n`, -1)
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func sortedKeys(values interface{}) []string {
+	keys := []string{}
+	switch v := values.(type) {
+	case map[string]*counterValue:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	case map[string]*histogramValue:
+		for key := range v {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
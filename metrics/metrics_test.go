@@ -0,0 +1,112 @@
+package metrics
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/kylelemons/godebug/pretty"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	testcases := map[string]struct {
+		register       func(r *Registry)
+		expectedOutput string
+	}{
+		"OKCaseEmpty": {
+			register:       func(r *Registry) {},
+			expectedOutput: "",
+		},
+		"OKCaseCounter": {
+			register: func(r *Registry) {
+				c := r.NewCounterVec("requests_total", "Requests", "route", "code")
+				c.Inc("/b", "200")
+				c.Inc("/a", "404")
+				c.Add(2, "/b", "200")
+			},
+			expectedOutput: "# HELP requests_total Requests\n" +
+				"# TYPE requests_total counter\n" +
+				"requests_total{route=\"/a\",code=\"404\"} 1\n" +
+				"requests_total{route=\"/b\",code=\"200\"} 3\n",
+		},
+		"OKCaseEscapedLabel": {
+			register: func(r *Registry) {
+				c := r.NewCounterVec("decisions_total", "Decisions\nby action", "action")
+				c.Inc("a\"b\\c")
+			},
+			expectedOutput: "# HELP decisions_total Decisions\\nby action\n" +
+				"# TYPE decisions_total counter\n" +
+				"decisions_total{action=\"a\\\"b\\\\c\"} 1\n",
+		},
+		"OKCaseHistogram": {
+			register: func(r *Registry) {
+				h := r.NewHistogramVec("duration_seconds", "Duration", []float64{0.1, 1}, "route")
+				h.Observe(0.05, "/a")
+				h.Observe(0.5, "/a")
+				h.Observe(2, "/a")
+			},
+			expectedOutput: "# HELP duration_seconds Duration\n" +
+				"# TYPE duration_seconds histogram\n" +
+				"duration_seconds_bucket{route=\"/a\",le=\"0.1\"} 1\n" +
+				"duration_seconds_bucket{route=\"/a\",le=\"1\"} 2\n" +
+				"duration_seconds_bucket{route=\"/a\",le=\"+Inf\"} 3\n" +
+				"duration_seconds_sum{route=\"/a\"} 2.55\n" +
+				"duration_seconds_count{route=\"/a\"} 3\n",
+		},
+		"OKCaseCounterFuncSortedByName": {
+			register: func(r *Registry) {
+				r.RegisterCounterFunc("cache_misses_total", "Misses", func() float64 { return 1 })
+				r.RegisterCounterFunc("cache_hits_total", "Old hits", func() float64 { return 0 })
+				r.RegisterCounterFunc("cache_hits_total", "Hits", func() float64 { return 5 })
+			},
+			expectedOutput: "# HELP cache_hits_total Hits\n" +
+				"# TYPE cache_hits_total counter\n" +
+				"cache_hits_total 5\n" +
+				"# HELP cache_misses_total Misses\n" +
+				"# TYPE cache_misses_total counter\n" +
+				"cache_misses_total 1\n",
+		},
+	}
+
+	for n, test := range testcases {
+		registry := NewRegistry()
+		test.register(registry)
+		buffer := new(bytes.Buffer)
+		if err := registry.Write(buffer); err != nil {
+			t.Errorf("Test case %v. Unexpected error writing metrics %v", n, err)
+			continue
+		}
+		if diff := pretty.Compare(buffer.String(), test.expectedOutput); diff != "" {
+			t.Errorf("Test case %v. Received different output (received/wanted) %v", n, diff)
+		}
+	}
+}
+
+func TestRegistry_DuplicatedName(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("requests_total", "Requests")
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected panic registering duplicated metric")
+		}
+	}()
+	registry.NewHistogramVec("requests_total", "Requests", DefaultBuckets)
+}
+
+func TestRegistry_ServeHTTP(t *testing.T) {
+	registry := NewRegistry()
+	registry.NewCounterVec("requests_total", "Requests").Inc()
+
+	w := httptest.NewRecorder()
+	r, _ := http.NewRequest(http.MethodGet, "/metrics", nil)
+	registry.ServeHTTP(w, r)
+
+	if contentType := w.Header().Get("Content-Type"); contentType != "text/plain; version=0.0.4" {
+		t.Errorf("Received different content type %v", contentType)
+	}
+	expected := "# HELP requests_total Requests\n# TYPE requests_total counter\nrequests_total 1\n"
+	if diff := pretty.Compare(w.Body.String(), expected); diff != "" {
+		t.Errorf("Received different body (received/wanted) %v", diff)
+	}
+}
@@ -2,4 +2,7 @@
 prmd doc group.json > ../doc/api/group.md
 prmd doc user.json > ../doc/api/user.md
 prmd doc policy.json > ../doc/api/policy.md
-prmd doc resource.json > ../doc/api/resource.md
\ No newline at end of file
+prmd doc resource.json > ../doc/api/resource.md
+prmd doc audit.json > ../doc/api/audit.md
+prmd doc import.json > ../doc/api/import.md
+prmd doc api_key.json > ../doc/api/api_key.md
\ No newline at end of file
@@ -14,12 +14,17 @@ fi
 
 echo "--> Building..."
 #Make sure $GOPATH is set
-CGO_ENABLED=0 go install github.com/Tecsisa/foulkon/cmd/worker
-CGO_ENABLED=0 go install github.com/Tecsisa/foulkon/cmd/proxy
+# All binaries import foulkon package, that links SQLite backend with cgo. Without cgo, SQLite driver is a
+# stub that fails when used. They are linked statically to run in Docker image.
+for cmd in worker proxy foulkon foulkonctl; do
+    CGO_ENABLED=1 go install -tags 'netgo osusergo sqlite_omit_load_extension' -ldflags '-extldflags "-static"' github.com/Tecsisa/foulkon/cmd/$cmd || exit 1
+done
 
 mkdir bin/ 2>/dev/null
 cp $GOPATH/bin/worker ./bin
 cp $GOPATH/bin/proxy ./bin
+cp $GOPATH/bin/foulkon ./bin
+cp $GOPATH/bin/foulkonctl ./bin
 
 echo "----> Building Docker images..."
 docker build -t tecsisa/foulkon:$build -f scripts/docker/Dockerfile .
@@ -5,13 +5,25 @@ export GOPATH=$GOPATH
 
 echo "--> Running tests"
 echo -e '----> Running unit tests'
-go list ./... | grep -v '/vendor/' | egrep -v '/database/|auth|cmd/|foulkon/foulkon' | PATH=$TEMPDIR:$PATH xargs -n1 go test ${GOTEST_FLAGS:--cover -timeout=900s}
+go list ./... | grep -v '/vendor/' | egrep -v '/database/|auth|cmd/' | PATH=$TEMPDIR:$PATH xargs -n1 go test ${GOTEST_FLAGS:--cover -timeout=900s}
+go test ./cmd/foulkon ${GOTEST_FLAGS:--cover -timeout=900s}
+
+echo -e '\n----> Running race tests'
+go test -race -run ReloadWhileServing ./http
 
 echo -e '\n----> Running connector tests'
+# Memory
+echo -e '--------> Running memory connector'
+go test ./database/memory ${GOTEST_FLAGS:--cover -timeout=900s}
+
 # Postgres
 echo -e '--------> Running PostgreSQL connector'
 echo $(echo -e 'Starting PostgreSQL (Docker container) postgrestest with id ') \
 $(docker run --name postgrestest -p 54320:5432 -e POSTGRES_PASSWORD=password -d postgres) \
 $(echo -e '\n\n')
 go test ./database/postgresql ${GOTEST_FLAGS:--cover -timeout=900s}
-echo -e 'Removing PostgreSQL container' $(docker rm -f postgrestest) '\n'
\ No newline at end of file
+echo -e 'Removing PostgreSQL container' $(docker rm -f postgrestest) '\n'
+
+# SQLite
+echo -e '--------> Running SQLite connector'
+go test ./database/sqlite ${GOTEST_FLAGS:--cover -timeout=900s}
\ No newline at end of file